@@ -0,0 +1,48 @@
+/* alloc_test.go - Allocation tests
+ *
+ * Benchmarks generating and solving mazes with the allocations reported, and checks that the number a maze makes
+ * doesn't grow with its size, since the carver, the solver and the opening search work in the maze array and fixed
+ * direction tables rather than allocating as they go.
+ */
+package maze
+
+import (
+    "fmt"
+    "context"
+    "testing"
+)
+
+// generateSolve generates and solves a single threaded maze of width by height cells
+func generateSolve(tb testing.TB, width, height int, seed int64) {
+    m, err := NewMaze(WithSize(width, height), WithSeed(seed), WithThreads(0))
+    if err != nil {
+        tb.Fatal(err)
+    }
+    if _, err := m.Generate(context.Background()); err != nil {
+        tb.Fatal(err)
+    }
+    if _, err := m.Solve(context.Background()); err != nil {
+        tb.Fatal(err)
+    }
+}
+
+func BenchmarkGenerateSolve(b *testing.B) {
+    for _, size := range [][2]int{{50, 40}, {300, 100}} {
+        b.Run(fmt.Sprintf("%dx%d", size[0], size[1]), func(b *testing.B) {
+            b.ReportAllocs()
+            for i := 0; i < b.N; i++ {
+                generateSolve(b, size[0], size[1], int64(i + 1))
+            }
+        })
+    }
+}
+
+// TestGenerateAllocs checks that ten times the cells take no more than a few more allocations, where the route
+// walked for each pair of openings used to take thousands
+func TestGenerateAllocs(t *testing.T) {
+    small := testing.AllocsPerRun(2, func() {; generateSolve(t, 20, 10, 1); })
+    large := testing.AllocsPerRun(2, func() {; generateSolve(t, 50, 40, 1); })
+    if large > small + 20 {
+        t.Errorf("a 50x40 maze makes %.0f allocations, a 20x10 maze %.0f, allocations grow with the maze", large, small)
+    }
+}
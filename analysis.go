@@ -0,0 +1,301 @@
+/* analysis.go - Maze structure diagnostics
+ *
+ * Diagnostics computed over the finished maze.  Those that label individual locations store their
+ * values in a single auxiliary array, separate from the maze, which the live display draws as an overlay.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "sort"
+    "sync"
+    "sync/atomic"
+)
+
+// branch is a junction on the solution path along with the number of cells beyond its wrong turns
+type branch struct {
+    x, y   int
+    wasted int
+}
+
+const (
+    auxNone       = 0                        // overlays that can be shown from the auxiliary array
+    auxComponents = 1
+    auxDeadEnds   = 2
+)
+
+// analysisState is the state of the analyses of a Maze and the overlays they display
+type analysisState struct {
+    auxShown         int32                       // overlay held in auxGrid, set once it is complete and may be displayed
+    auxGrid          [maxXSize][maxYSize]int32   // per location values of the overlay being computed or displayed
+    auxMax           int32                       // largest value in auxGrid, used to scale the heat map
+
+    componentSizes   []int                       // number of cells in each component, largest first
+    deadEnds         int                         // number of dead ends and the sum and maximum of their depths
+    deadEndSum       int
+    deadEndMax       int
+
+    markersFlag      bool
+    branches         []branch                    // junctions on the solution path, most wasted cells first
+    branchesMu       sync.Mutex                  // guards branches, read by the display goroutine's ascii output
+
+    handedFlag       bool
+    maxAsymmetry     float64                     // largest allowed ratio of the two wall followers' visits, 0 for any
+    leftVisited      int32                       // cells visited by the left and right hand wall followers
+    rightVisited     int32
+}
+
+func (m *Maze) setAux(x, y, v int)       {; atomic.StoreInt32(&m.auxGrid[x][y], int32(v)); }
+func (m *Maze) getAux(x, y    int) int  {; return int(atomic.LoadInt32(&m.auxGrid[x][y])); }
+
+// clearAux hides the overlay and zeroes the auxiliary array so a new overlay can be computed
+func (m *Maze) clearAux() {
+    setInt(&m.auxShown, auxNone)
+    for i := range m.auxGrid {
+        for j := range m.auxGrid[i] {
+            m.setAux(i, j, 0)
+        }
+    }
+    clrInt(&m.auxMax)
+}
+
+// isInterior returns true if x, y is inside the perimeter path that bounds the maze
+func (m *Maze) isInterior(x, y int) bool {
+    return 0 < x && x < getInt(&m.maxX) - 1 && 0 < y && y < getInt(&m.maxY) - 1
+}
+
+// labelComponents flood fills every open region of the maze with its own label, numbering the components
+// from largest to smallest, and returns the number of components found.  Sizes are counted in cells.
+func (m *Maze) labelComponents() int {
+    m.clearAux()
+    var sizes []int
+    m.cells(func(c cell, state int) bool {
+        i, j := c.loc()
+        if m.getAux(i, j) != 0 || state == wall {
+            return true
+        }
+        label := len(sizes) + 1
+        size  := 0
+        stack := []point{{i, j}}
+        m.setAux(i, j, label)
+        for len(stack) > 0 {
+            p := stack[len(stack) - 1]
+            stack = stack[:len(stack) - 1]
+            if isEven(p.x) && isEven(p.y) {
+                size++
+            }
+            for _, dir := range stdDirection {
+                x, y := p.x + dir.x/2, p.y + dir.y/2
+                if m.isInterior(x, y) && m.getAux(x, y) == 0 && m.getMaze(x, y) != wall {
+                    m.setAux(x, y, label)
+                    stack = append(stack, point{x, y})
+                }
+            }
+        }
+        sizes = append(sizes, size)
+        return true
+    })
+
+    order := make([]int, len(sizes))                   // renumber so the largest component is label 1
+    for i := range order {
+        order[i] = i
+    }
+    sort.SliceStable(order, func(a, b int) bool {; return sizes[order[a]] > sizes[order[b]]; })
+    relabel := make([]int, len(sizes) + 1)
+    m.componentSizes = make([]int, len(sizes))
+    for rank, i := range order {
+        relabel[i + 1]       = rank + 1
+        m.componentSizes[rank] = sizes[i]
+    }
+    for i := range m.auxGrid {
+        for j := range m.auxGrid[i] {
+            m.setAux(i, j, relabel[m.getAux(i, j)])
+        }
+    }
+    setInt(&m.auxMax, len(m.componentSizes))
+    setInt(&m.auxShown, auxComponents)
+    return len(m.componentSizes)
+}
+
+// printComponents prints the number of components and their sizes
+func (m *Maze) printComponents(w io.Writer) {
+    fmt.Fprintf(w, "components: %d, sizes:", len(m.componentSizes))
+    for i, size := range m.componentSizes {
+        if i == 20 {
+            fmt.Fprintf(w, " ... (%d more)", len(m.componentSizes) - i)
+            break
+        }
+        fmt.Fprintf(w, " %d", size)
+    }
+    fmt.Fprintf(w, "\n")
+}
+
+// degree returns the number of open walls around the cell at x, y, counting an entrance or exit opening
+func (m *Maze) degree(x, y int) int {
+    n := 0
+    for _, dir := range stdDirection {
+        n += bool2int(m.getMaze(x + dir.x/2, y + dir.y/2) != wall)
+    }
+    return n
+}
+
+// labelDeadEnds walks back from every dead end to the nearest junction, labelling each location on the way
+// with its distance from that junction (corridor and junction cells stay 0), and returns the deepest dead end.
+func (m *Maze) labelDeadEnds() int {
+    m.clearAux()
+    m.deadEnds, m.deadEndSum, m.deadEndMax = 0, 0, 0
+    m.cells(func(c cell, state int) bool {
+        i, j := c.loc()
+        if state == wall || m.degree(i, j) != 1 {
+            return true
+        }
+        var walk []point                            // locations from the dead end back to the junction
+        x, y, fromX, fromY := i, j, -1, -1
+        for m.degree(x, y) <= 2 {
+            walk = append(walk, point{x, y})
+            moved := false
+            for _, dir := range stdDirection {
+                nx, ny := x + dir.x, y + dir.y
+                if m.getMaze(x + dir.x/2, y + dir.y/2) != wall && m.isInterior(nx, ny) && (nx != fromX || ny != fromY) {
+                    walk = append(walk, point{x + dir.x/2, y + dir.y/2})
+                    x, y, fromX, fromY, moved = nx, ny, x, y, true
+                    break
+                }
+            }
+            if !moved {                             // a single corridor with no junction at all
+                break
+            }
+        }
+        depth := len(walk) / 2
+        for k, p := range walk {
+            m.setAux(p.x, p.y, (len(walk) - k + 1) / 2)
+        }
+        m.deadEnds++
+        m.deadEndSum += depth
+        m.deadEndMax  = max(m.deadEndMax, depth)
+        return true
+    })
+    setInt(&m.auxMax, m.deadEndMax)
+    setInt(&m.auxShown, auxDeadEnds)
+    return m.deadEndMax
+}
+
+// printDeadEnds prints the number of dead ends with their maximum and mean depth
+func (m *Maze) printDeadEnds(w io.Writer) {
+    fmt.Fprintf(w, "dead ends: %d, max depth: %d, mean depth: %.1f\n", m.deadEnds, m.deadEndMax, float64(m.deadEndSum) / float64(nonZero(m.deadEnds)))
+}
+
+// branchSize returns the number of cells reachable from the cell at x, y without passing back through the
+// neighboring cell at fromX, fromY, i.e. the size of the subtree a solver explores after taking that turn.
+func (m *Maze) branchSize(x, y, fromX, fromY int) int {
+    type step struct {
+        x, y, fromX, fromY int
+    }
+    size  := 0
+    stack := []step{{x, y, fromX, fromY}}
+    for len(stack) > 0 {
+        s := stack[len(stack) - 1]
+        stack = stack[:len(stack) - 1]
+        size++
+        for _, dir := range stdDirection {
+            nx, ny := s.x + dir.x, s.y + dir.y
+            if m.getMaze(s.x + dir.x/2, s.y + dir.y/2) != wall && m.isInterior(nx, ny) && (nx != s.fromX || ny != s.fromY) {
+                stack = append(stack, step{nx, ny, s.x, s.y})
+            }
+        }
+    }
+    return size
+}
+
+// annotateBranches finds every junction on the solved path and the number of cells a solver would waste
+// exploring the wrong turns there, ranking the junctions from the biggest trap to the smallest.
+func (m *Maze) annotateBranches() {
+    var found []branch
+    m.cells(func(c cell, state int) bool {
+        i, j := c.loc()
+        if state != solved || m.degree(i, j) < 3 {
+            return true
+        }
+        b := branch{x: i, y: j}
+        for _, dir := range stdDirection {
+            nx, ny := i + dir.x, j + dir.y
+            if m.getMaze(i + dir.x/2, j + dir.y/2) == wall || !m.isInterior(nx, ny) || m.getMaze(nx, ny) == solved {
+                continue
+            }
+            b.wasted += m.branchSize(nx, ny, i, j)
+        }
+        found = append(found, b)
+        return true
+    })
+    sort.SliceStable(found, func(a, b int) bool {; return found[a].wasted > found[b].wasted; })
+    m.branchesMu.Lock()
+    m.branches = found
+    m.branchesMu.Unlock()
+    m.touch()
+}
+
+// branchMarker returns the digit marking the junction at x, y in the ascii output by its rank, or 0 if unmarked
+func (m *Maze) branchMarker(x, y int) byte {
+    if m.markersFlag {
+        m.branchesMu.Lock()
+        defer m.branchesMu.Unlock()
+        for rank, b := range m.branches {
+            if rank == 9 {
+                break
+            }
+            if b.x == x && b.y == y {
+                return byte('1' + rank)
+            }
+        }
+    }
+    return 0
+}
+
+// printBranches prints each junction on the solution path in logical row, column coordinates with its wasted cells
+func (m *Maze) printBranches(w io.Writer) {
+    total := 0
+    for _, b := range m.branches {
+        total += b.wasted
+    }
+    fmt.Fprintf(w, "branches: %d junctions on the solution, %d cells wasted by wrong turns\n", len(m.branches), total)
+    for rank, b := range m.branches {
+        c := cellAt(b.x, b.y)
+        fmt.Fprintf(w, "  %3d: row %3d, col %3d, wasted %d\n", rank + 1, c.row, c.col, b.wasted)
+    }
+}
+
+// measureHandedness runs a left hand and a right hand wall follower on scratch copies of the maze, counting the
+// cells each visits before reaching the exit, and returns true if their ratio is within maxAsymmetry
+func (m *Maze) measureHandedness() bool {
+    visited := func(leftHand bool) int {
+        n := 0
+        wallFollower{leftHand: leftHand}.solve(m.copyMaze(), func(fromX, fromY, toX, toY int, fresh bool) bool {
+            n += bool2int(fresh)
+            return true
+        })
+        return n
+    }
+    left, right := visited(true), visited(false)
+    setInt(&m.leftVisited , left )
+    setInt(&m.rightVisited, right)
+    return m.maxAsymmetry == 0 || float64(max(left, right)) <= m.maxAsymmetry * float64(nonZero(min(left, right)))
+}
+
+// difficulty returns the mean number of cells visited by the left and right hand wall followers as a percentage
+// of the solution length, 100 when following a wall leads straight to the exit
+func (m *Maze) difficulty() int {
+    saveLeft, saveRight := getInt(&m.leftVisited), getInt(&m.rightVisited)
+    m.measureHandedness()
+    visited := getInt(&m.leftVisited) + getInt(&m.rightVisited)
+    setInt(&m.leftVisited , saveLeft )
+    setInt(&m.rightVisited, saveRight)
+    return 100 * visited / 2 / nonZero(getInt(&m.solveLength))
+}
+
+// printHandedness prints the number of cells visited by each wall follower and their ratio
+func (m *Maze) printHandedness(w io.Writer) {
+    left, right := getInt(&m.leftVisited), getInt(&m.rightVisited)
+    fmt.Fprintf(w, "handedness: left hand visited %d cells, right hand %d, asymmetry %.2f\n", left, right,
+                float64(max(left, right)) / float64(nonZero(min(left, right))))
+}
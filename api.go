@@ -0,0 +1,365 @@
+/* api.go - Library interface
+ *
+ * The Maze type is how other programs, the maze command included, use the generator.  NewMaze sets its
+ * parameters from the options, copied into the generator's state when a maze is generated, solved or combined, and
+ * its methods return the maze and what was measured while making it.  Every Maze has its own state, so different mazes
+ * may be generated at the same time, but the methods of any one Maze must not be called concurrently.
+ */
+package maze
+
+import (
+    "io"
+    "context"
+    "sync"
+    "time"
+    "math/rand"
+)
+
+// Cell values, as returned by Cell
+const (
+    Path   = path
+    Wall   = wall
+    Solved = solved
+    Tried  = tried
+    Check  = check                  // a location being tested by the look ahead
+
+    MaxWidth  = maxWidth            // the largest maze, in cells
+    MaxHeight = maxHeight
+)
+
+// Maze is a maze and the parameters it is generated with, made by NewMaze, which checks the parameters
+type Maze struct {
+    cfg             config          // the parameters, set by the options
+
+    mazeState
+    analysisState
+    auditState
+    budgetState
+    cancelState
+    eventState
+    outputState
+    raceState
+    searchState
+    statsState
+    corridor        int             // width of the corridors when written, taken from the parameters
+    params          []Param         // the parameters of the maze a widened copy was made from
+    wide            *Maze           // the widened copy last written, made again once the maze has changed
+    wideKey         wideKey         // the size and openings wide was made with
+    wideGrid        *mazeGrid       // the maze array as wide was made from it
+    wideMu          sync.Mutex      // guards wide while it's made
+    changed         int32           // set by changes to the maze array, the solution and the branch markers
+    loaded          *Loaded         // the maze solved instead of generating one, set by Load
+    mask            *Mask           // cells left out of the maze, taken from the parameters
+    resumeState     *Checkpoint     // the state generation resumes from, set by Resume
+    solution        []point         // the solution route, saved before the solution is cleared from the maze
+    solutionMu      sync.Mutex      // guards solution, read by the display goroutine's output
+}
+
+// config holds the parameters of a maze, set by the options and copied into the generator's state when a maze is
+// generated, solved or combined
+type config struct {
+    width, height   int             // size in cells
+    depth           int             // look ahead depth while carving, 0-100
+    carveThreads    int             // additional carving and solving goroutines
+    solveThreads    int
+    minLength       int             // shortest solution accepted, more mazes are generated until it's met
+    maxAttempts     int             // mazes to generate trying to meet minLength, 0 for no limit
+    seed            int64           // random number seed, 0 for the current time
+    gap             int             // width of the entrance and exit in cells
+    corridor        int             // width of the corridors in cells when the maze is written
+    maxSolveSteps   int             // solver step budget, 0 for no limit
+    handedness      bool            // measure the cells visited by left and right hand wall followers
+    maxAsymmetry    float64         // largest allowed ratio of the wall followers' visits, 0 for any
+    algorithm       string          // name of the registered generator carving the maze, "" for DefaultAlgorithm
+    randSource      rand.Source     // random number source, never reseeded, nil for the built-in one seeded with seed
+    mask            *Mask           // cells left out of the maze, the size of the maze, nil for none
+    fps             int             // cell updates per second, paced for an animation, 0 for full speed
+    display         DisplayOptions  // what the display shows while the maze is made
+    text            TextOptions     // how the maze is written in the text formats
+    hooks           Hooks           // called as the maze is made
+}
+
+// newMaze returns a Maze with the default parameters and no size, for NewMaze to apply the options to or for a
+// maze made from others to be given their parameters
+func newMaze() *Maze {
+    m := &Maze{}
+    m.init()
+    return m
+}
+
+// init gives the maze the default parameters and a random number source
+func (m *Maze) init() {
+    m.cfg.gap, m.cfg.corridor = 1, 1
+    src         := rand.NewSource(1)
+    m.rngSource  = &countingSource{src: src, builtin: src}
+    m.rng        = rand.New(m.rngSource)
+    m.finishChan = make(chan struct{})
+    m.runStart   = time.Now()
+}
+
+// configure copies the parameters into the generator's state
+func (m *Maze) configure() {
+    c, d, t, h := &m.cfg, m.cfg.display, m.cfg.text, m.cfg.hooks
+    m.width, m.height, m.depthVal                   = c.width, c.height, c.depth
+    m.carveThreads, m.solveThreads                  = c.carveThreads, c.solveThreads
+    m.minLen, m.maxAttempts, m.seedVal              = c.minLength, c.maxAttempts, c.seed
+    m.gap, m.corridor, m.maxSolveSteps              = c.gap, c.corridor, c.maxSolveSteps
+    m.handedFlag, m.maxAsymmetry                    = c.handedness, c.maxAsymmetry
+    m.fps, m.showFlag, m.viewFlag, m.lookFlag       = c.fps, d.Show, d.View, d.Look
+    m.markOpenings, m.blockWalls, m.brailleSolution = t.MarkOpenings, t.BlockWalls, t.BrailleSolution
+    m.markersFlag, m.boxDrawing, m.charMap          = t.BranchMarkers, t.BoxDrawing, t.CharMap
+    m.lineStyle, m.mask                             = t.LineStyle, c.mask
+    m.onUpdate, m.onPath, m.onPhase                 = h.OnUpdate, h.OnPath, h.OnPhase
+    m.rngSource.use(c.randSource)
+}
+
+// Generate creates and solves mazes until one has the minimum solution length and asymmetry, or until the attempts
+// run out, and returns false if it gave up without meeting them.  A loaded maze is just solved.  If ctx is cancelled
+// it stops, leaving the maze as far as it got, and returns the context's error.  An unknown algorithm, or an error
+// from its generator, is returned too, as is a mask not the size of the maze or whose cells don't all connect.
+func (m *Maze) Generate(ctx context.Context) (bool, error) {
+    if err := ctx.Err(); err != nil {
+        return false, err
+    }
+    m.configure()
+    gen, err := m.generator()
+    if err == nil && m.loaded == nil {
+        err = m.checkMask()
+    }
+    if err != nil {
+        return false, err
+    }
+    defer m.watchContext(ctx)()
+    setBool(&m.checkFlag, m.lookFlag)
+    setInt( &m.depth    , m.depthVal)
+    notMet, err := m.generateMaze(gen)
+    if m.loaded == nil {
+        m.setGenerated(time.Now())
+    }
+    m.cfg.width, m.cfg.height = m.width, m.height
+    m.SaveSolution()
+    switch {
+        case m.stopped(): return false, ctx.Err()
+        case err != nil : return false, err
+    }
+    return !notMet, nil
+}
+
+// Solve clears any solution from the maze and solves it again from the entrance, returning the solution from the
+// entrance to the exit.  It returns ErrUnsolvable if there's no route between them and ErrBudgetExhausted if the
+// step budget ran out, leaving the cells it tried, or the context's error if ctx is cancelled.
+func (m *Maze) Solve(ctx context.Context) ([]Point, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+    m.configure()
+    if getInt(&m.maxX) == 0 {                   // nothing has been generated
+        if m.loaded == nil {
+            return nil, ErrNoMaze
+        }
+        m.installMaze(m.loaded)
+    }
+    defer m.watchContext(ctx)()
+    m.restoreMaze()
+    x, y := getInt(&m.begX), getInt(&m.begY)
+    setBool(&m.budgetOn, true)
+    m.solveMaze(&x, &y)
+    setBool(&m.budgetOn, false)
+    setInt(&m.solveLength, m.solvedLength())
+    m.setSolution(nil)
+    switch {
+        case m.stopped()                  : return nil, ctx.Err()
+        case getBool(&m.budgetExhausted)  : return nil, ErrBudgetExhausted
+        case !getBool(&m.solvedFlag)      : return nil, ErrUnsolvable
+    }
+    if route := m.solutionRoute(); m.routeComplete(route) {
+        m.setSolution(route)
+        return m.routePoints(route), nil
+    }
+    return nil, ErrUnsolvable
+}
+
+// Unsolve returns the maze to its unsolved state, clearing the solved and tried cells
+func (m *Maze) Unsolve() {
+    m.restoreMaze()
+}
+
+// Load makes a maze read with ParseASCII the maze that Generate solves instead of generating one
+func (m *Maze) Load(l *Loaded) {
+    m.loaded = l
+    m.cfg.height, m.cfg.width = l.Size()
+}
+
+// Size returns the height and width of the maze in cells
+func (m *Maze) Size() (int, int) {
+    return m.cfg.height, m.cfg.width
+}
+
+// Depth returns the look ahead depth the maze is carved with
+func (m *Maze) Depth() int {
+    return m.cfg.depth
+}
+
+// Algorithm returns the name of the generator carving the maze, "" for DefaultAlgorithm
+func (m *Maze) Algorithm() string {
+    return m.cfg.algorithm
+}
+
+// MinLength returns the shortest solution accepted, 0 for any
+func (m *Maze) MinLength() int {
+    return m.cfg.minLength
+}
+
+// Cell returns the value of maze array location x, y.  Cells are at even locations and the walls and openings
+// between them at odd ones, the maze being bounded by a perimeter path outside the walls.
+func (m *Maze) Cell(x, y int) int {
+    return m.getMaze(x, y)
+}
+
+// Extent returns the number of rows and columns of locations in the maze array, including the perimeter path
+func (m *Maze) Extent() (int, int) {
+    return getInt(&m.maxX), getInt(&m.maxY)
+}
+
+// SaveSolution records the solution route so that it's still written with the braille format and revealed by
+// RevealHints once the maze is unsolved.  Generate and Solve record the solution they find.
+func (m *Maze) SaveSolution() {
+    if route := m.solutionRoute(); m.routeComplete(route) {
+        m.setSolution(route)
+    } else {
+        m.setSolution(nil)
+    }
+}
+
+// Stats returns the generation statistics in status line order
+func (m *Maze) Stats() Stats {
+    return m.generationStats()
+}
+
+// CurrentSeed returns the random number seed of the maze being generated
+func (m *Maze) CurrentSeed() int64 {
+    return m.getSeed()
+}
+
+// MazesCreated returns the number of mazes generated so far
+func (m *Maze) MazesCreated() int {
+    return getInt(&m.numMazeCreated)
+}
+
+// SolutionLength returns the length of the recorded solution, in cells, not counting the entrance and exit outside
+// the maze, or 0 if there is none
+func (m *Maze) SolutionLength() int {
+    return len(m.getSolution())/2
+}
+
+// TurnCount returns the number of changes of direction along the recorded solution
+func (m *Maze) TurnCount() int {
+    return countTurns(m.getSolution())
+}
+
+// Degrees returns the number of dead end cells and of junction cells, those with three or more passages
+func (m *Maze) Degrees() (int, int) {
+    return m.countDegrees()
+}
+
+// Passages returns the number of open passages between adjacent cells
+func (m *Maze) Passages() int {
+    n := 0
+    m.passages(func(a, b cell) bool {; n++; return true; })
+    return n
+}
+
+// Difficulty returns the mean number of cells visited by the left and right hand wall followers as a
+// percentage of the solution length
+func (m *Maze) Difficulty() int {
+    return m.difficulty()
+}
+
+// Elapsed returns the time taken to carve and to solve the last maze
+func (m *Maze) Elapsed() (time.Duration, time.Duration) {
+    return m.genElapsed, m.solveElapsed
+}
+
+// BudgetExhausted returns true if the solver gave up when the step budget ran out
+func (m *Maze) BudgetExhausted() bool {
+    return getBool(&m.budgetExhausted)
+}
+
+// Unsolvable returns true if a loaded maze has no route between its openings
+func (m *Maze) Unsolvable() bool {
+    return m.unsolvable()
+}
+
+// Components labels the connected regions of the maze for the components overlay and returns their number
+func (m *Maze) Components() int {
+    return m.labelComponents()
+}
+
+// DeadEnds labels every dead end with its distance from the nearest junction for the dead end overlay and
+// returns the deepest
+func (m *Maze) DeadEnds() int {
+    return m.labelDeadEnds()
+}
+
+// AnnotateBranches ranks the junctions on the solution by the cells a solver wastes taking their wrong turns
+func (m *Maze) AnnotateBranches() {
+    m.annotateBranches()
+}
+
+// Race runs the solvers concurrently on copies of the maze, showing their progress as racers
+func (m *Maze) Race(racers []Racer) {
+    m.runRace(racers)
+}
+
+// RevealHints unsolves all but part of the solution route saved by SaveSolution and calls write n times, hint k
+// showing the fraction (k-1)/(n-1) of the route from the entrance, then puts the route back as it was
+func (m *Maze) RevealHints(n int, write func(k int)) {
+    m.revealRoute(m.getSolution(), n, write)
+}
+
+// Combine makes the combination of the passages of two mazes of the same size with op, one of CombineOps, the
+// current maze.  It returns an error for an unknown op, or one wrapping ErrSizeMismatch for mazes of different sizes.
+func (m *Maze) Combine(op string, a, b *Loaded) error {
+    return m.combine(op, a, b)
+}
+
+// ReportComponents writes the number of components and their sizes
+func (m *Maze) ReportComponents(w io.Writer) {
+    m.printComponents(w)
+}
+
+// ReportDeadEnds writes the number of dead ends with their maximum and mean depth
+func (m *Maze) ReportDeadEnds(w io.Writer) {
+    m.printDeadEnds(w)
+}
+
+// ReportBranches writes each junction on the solution with the cells wasted by its wrong turns
+func (m *Maze) ReportBranches(w io.Writer) {
+    m.printBranches(w)
+}
+
+// ReportHandedness writes the number of cells visited by each wall follower and their ratio
+func (m *Maze) ReportHandedness(w io.Writer) {
+    m.printHandedness(w)
+}
+
+// ReportRace writes the winner of the race and the cells visited by each solver, their names colored as on the
+// display if color is set, as it should only be for a terminal
+func (m *Maze) ReportRace(w io.Writer, color bool) {
+    m.printRace(w, color)
+}
+
+// ReportBudget writes how far the solver got before the step budget ran out
+func (m *Maze) ReportBudget(w io.Writer) {
+    m.printBudget(w)
+}
+
+// ReportUnsolvable writes how many cells of a loaded maze with no solution the solver could reach
+func (m *Maze) ReportUnsolvable(w io.Writer) {
+    m.printUnsolvable(w)
+}
+
+// ReportStaleChecks writes the location of every stale check cell cleared after carving
+func (m *Maze) ReportStaleChecks(w io.Writer) {
+    m.printStaleChecks(w)
+}
@@ -0,0 +1,543 @@
+/* api_test.go - API compatibility
+ *
+ * Compile-only checks of the supported API of the library's v1, written as a program outside the package uses it:
+ * every exported constant, variable, function, type, method and struct field, each with its type or signature.
+ * Nothing here runs.  A change that stops this file compiling breaks programs depending on v1, so it needs a new
+ * major version rather than an edit here; a new identifier is added here when it's exported.
+ */
+package maze_test
+
+import (
+    "io"
+    "iter"
+    "time"
+    "image"
+    "context"
+    "math/rand"
+    "image/color"
+    "github.com/Starfleet2/maze"
+)
+
+// The constants
+var (
+    _ int            = maze.ArrayHeight
+    _ int            = maze.ArrayWidth
+    _ int            = maze.Check
+    _ int            = maze.CountStat
+    _ string         = maze.DefaultAlgorithm
+    _ float64        = maze.DefaultEPSCellSize
+    _ float64        = maze.DefaultEPSStrokeWidth
+    _ int            = maze.DefaultHeight
+    _ int            = maze.DefaultMarkdownColumns
+    _ float64        = maze.DefaultModelCellSize
+    _ float64        = maze.DefaultModelWallHeight
+    _ float64        = maze.DefaultModelWallThickness
+    _ int            = maze.DefaultPDFMargin
+    _ int            = maze.DefaultPNGCellSize
+    _ float64        = maze.DefaultTikZLineWidth
+    _ float64        = maze.DefaultTikZScale
+    _ int            = maze.DefaultTileSize
+    _ int            = maze.DefaultWidth
+    _ int            = maze.DoubleLines
+    _ maze.Direction = maze.Down
+    _ int            = maze.DurationStat
+    _ int            = maze.HeavyLines
+    _ maze.Direction = maze.Left
+    _ int            = maze.LightLines
+    _ int            = maze.MaxDepth
+    _ int            = maze.MaxEPSCellSize
+    _ int            = maze.MaxFPS
+    _ int            = maze.MaxHeight
+    _ int            = maze.MaxImageSide
+    _ int            = maze.MaxModelCellSize
+    _ int            = maze.MaxModelWallHeight
+    _ int            = maze.MaxPDFMargin
+    _ int            = maze.MaxPNGCellSize
+    _ int            = maze.MaxPNGMargin
+    _ int            = maze.MaxStreamHeight
+    _ int            = maze.MaxStreamWidth
+    _ int            = maze.MaxTikZLineWidth
+    _ int            = maze.MaxTikZScale
+    _ int            = maze.MaxTileSize
+    _ int            = maze.MaxWidth
+    _ int            = maze.MinImageCellSize
+    _ int            = maze.MinMarkdownColumns
+    _ int            = maze.MinPNGCellSize
+    _ int            = maze.MinTileSize
+    _ int            = maze.Path
+    _ maze.Phase     = maze.PhaseCarving
+    _ maze.Phase     = maze.PhasePushing
+    _ maze.Phase     = maze.PhaseSolving
+    _ int            = maze.PlainStat
+    _ maze.Direction = maze.Right
+    _ int            = maze.Solved
+    _ int            = maze.Tried
+    _ maze.Direction = maze.Up
+    _ string         = maze.Version
+    _ int            = maze.Wall
+    _ int            = maze.WallEast
+    _ int            = maze.WallNorth
+    _ int            = maze.WallSouth
+    _ int            = maze.WallWest
+)
+
+// The variables
+var (
+    _ map[string]func(openA bool, openB bool) bool = maze.CombineOps
+    _ maze.GlyphMap                                = maze.EmojiGlyphs
+    _ error                                        = maze.ErrBudgetExhausted
+    _ error                                        = maze.ErrNegativeCost
+    _ error                                        = maze.ErrNoMaze
+    _ error                                        = maze.ErrSizeMismatch
+    _ error                                        = maze.ErrSolutionNotPath
+    _ error                                        = maze.ErrUnsolvable
+    _ color.RGBA                                   = maze.PNGSolutionColor
+)
+
+// The functions
+var (
+    _ func() []string                                                                = maze.Algorithms
+    _ func(*maze.Loaded, *maze.Loaded, bool) ([]byte, int, int, error)               = maze.Diff
+    _ func([]maze.Stat, string, bool) string                                         = maze.FormatStats
+    _ func(int, int, func(x int, y int) int, int, maze.FrameOptions) *image.Paletted = maze.ImageCells
+    _ func(*maze.Maze, *maze.Maze) (*maze.Maze, error)                               = maze.Intersect
+    _ func(int, int) int                                                             = maze.MaxMinLength
+    _ func(...maze.Option) (*maze.Maze, error)                                       = maze.NewMaze
+    _ func(string) (*maze.Loaded, error)                                             = maze.ParseASCII
+    _ func(io.Reader) (*maze.Loaded, error)                                          = maze.ParseBinary
+    _ func(string) (maze.CharMap, error)                                             = maze.ParseCharMap
+    _ func(string) (maze.GlyphMap, error)                                            = maze.ParseGlyphMap
+    _ func(io.Reader, maze.ImageOptions) (*maze.Loaded, error)                       = maze.ParseImage
+    _ func(io.Reader, int, int) (*maze.Mask, error)                                  = maze.ParseImageMask
+    _ func(io.Reader) (*maze.Loaded, error)                                          = maze.ParseJSON
+    _ func(io.Reader) (*maze.Mask, error)                                            = maze.ParseMask
+    _ func(string) (maze.MazeKey, error)                                             = maze.ParseMazeKey
+    _ func(string) ([]maze.Racer, error)                                             = maze.ParseRace
+    _ func(io.Reader) (image.Image, error)                                           = maze.ReadTileSheet
+    _ func(string, func() maze.Generator) error                                      = maze.RegisterGenerator
+    _ func(io.Writer, int, int, func(x int, y int) int, maze.PNGOptions) error       = maze.RenderPNGCells
+    _ func(io.Writer, maze.StreamOptions) error                                      = maze.RenderStream
+    _ func(*maze.Maze, *maze.Maze) (*maze.Maze, error)                               = maze.Union
+    _ func(string) maze.Option                                                       = maze.WithAlgorithm
+    _ func(int) maze.Option                                                          = maze.WithAttempts
+    _ func(int) maze.Option                                                          = maze.WithCarveThreads
+    _ func(*maze.Checkpoint) maze.Option                                             = maze.WithCheckpoint
+    _ func(int) maze.Option                                                          = maze.WithCorridor
+    _ func(int) maze.Option                                                          = maze.WithDepth
+    _ func(maze.DisplayOptions) maze.Option                                          = maze.WithDisplay
+    _ func(int) maze.Option                                                          = maze.WithFPS
+    _ func(int) maze.Option                                                          = maze.WithGap
+    _ func(bool) maze.Option                                                         = maze.WithHandedness
+    _ func(maze.Hooks) maze.Option                                                   = maze.WithHooks
+    _ func(string) maze.Option                                                       = maze.WithKey
+    _ func(*maze.Loaded) maze.Option                                                 = maze.WithLoaded
+    _ func(*maze.Mask) maze.Option                                                   = maze.WithMask
+    _ func(float64) maze.Option                                                      = maze.WithMaxAsymmetry
+    _ func(int) maze.Option                                                          = maze.WithMaxSolveSteps
+    _ func(int) maze.Option                                                          = maze.WithMinSolutionLength
+    _ func(rand.Source) maze.Option                                                  = maze.WithRandSource
+    _ func(int64) maze.Option                                                        = maze.WithSeed
+    _ func(int, int) maze.Option                                                     = maze.WithSize
+    _ func(int) maze.Option                                                          = maze.WithSolveThreads
+    _ func(maze.TextOptions) maze.Option                                             = maze.WithText
+    _ func(int) maze.Option                                                          = maze.WithThreads
+)
+
+// The methods, as method expressions taking the receiver first
+var (
+    _ func(maze.CharMap) error                                                      = maze.CharMap.Check
+    _ func(maze.CharMap, string) string                                             = maze.CharMap.Decode
+    _ func(*maze.Checkpoint) error                                                  = (*maze.Checkpoint).Check
+    _ func(maze.Direction) string                                                   = maze.Direction.String
+    _ func(maze.EPSOptions) error                                                   = maze.EPSOptions.Check
+    _ func(maze.Generator, *maze.Grid, *rand.Rand) error                            = maze.Generator.Carve
+    _ func(maze.GlyphMap) error                                                     = maze.GlyphMap.Check
+    _ func(maze.GlyphMap) int                                                       = maze.GlyphMap.Width
+    _ func(*maze.Grid, int, int) bool                                               = (*maze.Grid).Carved
+    _ func(*maze.Grid, int, int) bool                                               = (*maze.Grid).Contains
+    _ func(*maze.Grid) int                                                          = (*maze.Grid).Height
+    _ func(*maze.Grid, int, int, maze.Direction) bool                               = (*maze.Grid).IsWall
+    _ func(*maze.Grid, int, int, maze.Direction) error                              = (*maze.Grid).Join
+    _ func(*maze.Grid, int, int) error                                              = (*maze.Grid).Open
+    _ func(*maze.Grid) bool                                                         = (*maze.Grid).Stopped
+    _ func(*maze.Grid) int                                                          = (*maze.Grid).Width
+    _ func(maze.ImageOptions) error                                                 = maze.ImageOptions.Check
+    _ func(*maze.Loaded) []maze.Param                                               = (*maze.Loaded).Params
+    _ func(*maze.Loaded) (int, int)                                                 = (*maze.Loaded).Size
+    _ func(*maze.Loaded) bool                                                       = (*maze.Loaded).Transposed
+    _ func(maze.MarkdownOptions) error                                              = maze.MarkdownOptions.Check
+    _ func(*maze.Mask) error                                                        = (*maze.Mask).Check
+    _ func(*maze.Mask, int, int) bool                                               = (*maze.Mask).Excluded
+    _ func(*maze.Mask) *maze.Mask                                                   = (*maze.Mask).Inverted
+    _ func(*maze.Mask) (int, int)                                                   = (*maze.Mask).Size
+    _ func(*maze.Maze) string                                                       = (*maze.Maze).Algorithm
+    _ func(*maze.Maze)                                                              = (*maze.Maze).AnnotateBranches
+    _ func(*maze.Maze) bool                                                         = (*maze.Maze).BudgetExhausted
+    _ func(*maze.Maze, int, int) int                                                = (*maze.Maze).Cell
+    _ func(*maze.Maze) iter.Seq2[maze.Point, int]                                   = (*maze.Maze).Cells
+    _ func(*maze.Maze) *maze.Checkpoint                                             = (*maze.Maze).Checkpoint
+    _ func(*maze.Maze) *maze.Maze                                                   = (*maze.Maze).Clone
+    _ func(*maze.Maze, string, *maze.Loaded, *maze.Loaded) error                    = (*maze.Maze).Combine
+    _ func(*maze.Maze) int                                                          = (*maze.Maze).Components
+    _ func(*maze.Maze) int64                                                        = (*maze.Maze).CurrentSeed
+    _ func(*maze.Maze) int                                                          = (*maze.Maze).DeadEnds
+    _ func(*maze.Maze) (int, int)                                                   = (*maze.Maze).Degrees
+    _ func(*maze.Maze) int                                                          = (*maze.Maze).Depth
+    _ func(*maze.Maze) int                                                          = (*maze.Maze).Difficulty
+    _ func(*maze.Maze) (time.Duration, time.Duration)                               = (*maze.Maze).Elapsed
+    _ func(*maze.Maze) <-chan maze.CellEvent                                        = (*maze.Maze).Events
+    _ func(*maze.Maze) int                                                          = (*maze.Maze).EventsDropped
+    _ func(*maze.Maze) (int, int)                                                   = (*maze.Maze).Extent
+    _ func(*maze.Maze, context.Context) (bool, error)                               = (*maze.Maze).Generate
+    _ func(*maze.Maze, context.Context, string) error                               = (*maze.Maze).GenerateFromKey
+    _ func(*maze.Maze) *maze.Grid                                                   = (*maze.Maze).Grid
+    _ func(*maze.Maze, int, maze.FrameOptions) image.Image                          = (*maze.Maze).Image
+    _ func(*maze.Maze, int, int, maze.Direction) bool                               = (*maze.Maze).IsWall
+    _ func(*maze.Maze) string                                                       = (*maze.Maze).Key
+    _ func(*maze.Maze, *maze.Loaded)                                                = (*maze.Maze).Load
+    _ func(*maze.Maze) ([]byte, error)                                              = (*maze.Maze).MarshalText
+    _ func(*maze.Maze) int                                                          = (*maze.Maze).MazesCreated
+    _ func(*maze.Maze) int                                                          = (*maze.Maze).MinLength
+    _ func(*maze.Maze, int, int) []maze.Point                                       = (*maze.Maze).Neighbors
+    _ func(*maze.Maze) []maze.Param                                                 = (*maze.Maze).Params
+    _ func(*maze.Maze) iter.Seq2[maze.Point, maze.Point]                            = (*maze.Maze).PassageCells
+    _ func(*maze.Maze) int                                                          = (*maze.Maze).Passages
+    _ func(*maze.Maze, []maze.Racer)                                                = (*maze.Maze).Race
+    _ func(*maze.Maze, io.Writer, maze.RenderOptions) error                         = (*maze.Maze).RenderANSI
+    _ func(*maze.Maze, io.Writer, maze.RenderOptions, maze.Stats) error             = (*maze.Maze).RenderANSISnapshot
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderASCII
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderBinary
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderBraille
+    _ func(*maze.Maze, io.Writer, maze.RenderOptions) error                         = (*maze.Maze).RenderBrailleANSI
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderCSource
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderDOT
+    _ func(*maze.Maze, io.Writer, maze.EPSOptions) error                            = (*maze.Maze).RenderEPS
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderEdgeCSV
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderEdges
+    _ func(*maze.Maze, io.Writer, maze.RenderOptions, maze.GlyphMap) error          = (*maze.Maze).RenderGlyphANSI
+    _ func(*maze.Maze, io.Writer, maze.GlyphMap) error                              = (*maze.Maze).RenderGlyphs
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderGoSource
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderHTML
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderJSON
+    _ func(*maze.Maze, io.Writer, maze.MarkdownOptions) error                       = (*maze.Maze).RenderMarkdown
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderNodeCSV
+    _ func(*maze.Maze, io.Writer, maze.ModelOptions) error                          = (*maze.Maze).RenderOBJ
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderPBM
+    _ func(*maze.Maze, io.Writer, maze.PDFOptions) error                            = (*maze.Maze).RenderPDF
+    _ func(*maze.Maze, io.Writer) error                                             = (*maze.Maze).RenderPGM
+    _ func(*maze.Maze, io.Writer, maze.PNGOptions) error                            = (*maze.Maze).RenderPNG
+    _ func(*maze.Maze, io.Writer, maze.ModelOptions) error                          = (*maze.Maze).RenderSTL
+    _ func(*maze.Maze, io.Writer, maze.SVGOptions) error                            = (*maze.Maze).RenderSVG
+    _ func(*maze.Maze, io.Writer, maze.TikZOptions) error                           = (*maze.Maze).RenderTikZ
+    _ func(*maze.Maze, io.Writer, maze.TileOptions) error                           = (*maze.Maze).RenderTiles
+    _ func(*maze.Maze, io.Writer)                                                   = (*maze.Maze).ReportBranches
+    _ func(*maze.Maze, io.Writer)                                                   = (*maze.Maze).ReportBudget
+    _ func(*maze.Maze, io.Writer)                                                   = (*maze.Maze).ReportComponents
+    _ func(*maze.Maze, io.Writer)                                                   = (*maze.Maze).ReportDeadEnds
+    _ func(*maze.Maze, io.Writer)                                                   = (*maze.Maze).ReportHandedness
+    _ func(*maze.Maze, io.Writer, bool)                                             = (*maze.Maze).ReportRace
+    _ func(*maze.Maze, io.Writer)                                                   = (*maze.Maze).ReportStaleChecks
+    _ func(*maze.Maze, io.Writer)                                                   = (*maze.Maze).ReportUnsolvable
+    _ func(*maze.Maze, *maze.Snapshot)                                              = (*maze.Maze).Restore
+    _ func(*maze.Maze, *maze.Checkpoint) error                                      = (*maze.Maze).Resume
+    _ func(*maze.Maze, int, func(k int))                                            = (*maze.Maze).RevealHints
+    _ func(*maze.Maze)                                                              = (*maze.Maze).SaveSolution
+    _ func(*maze.Maze) (int, int)                                                   = (*maze.Maze).Size
+    _ func(*maze.Maze) *maze.Snapshot                                               = (*maze.Maze).Snapshot
+    _ func(*maze.Maze) iter.Seq[maze.Point]                                         = (*maze.Maze).SolutionCells
+    _ func(*maze.Maze) int                                                          = (*maze.Maze).SolutionLength
+    _ func(*maze.Maze) ([]maze.PathStep, error)                                     = (*maze.Maze).SolutionPath
+    _ func(*maze.Maze, context.Context) ([]maze.Point, error)                       = (*maze.Maze).Solve
+    _ func(*maze.Maze, context.Context, func(maze.Point) int) ([]maze.Point, error) = (*maze.Maze).SolveWithCost
+    _ func(*maze.Maze) maze.Stats                                                   = (*maze.Maze).Stats
+    _ func(*maze.Maze) int                                                          = (*maze.Maze).TextColumns
+    _ func(*maze.Maze) int                                                          = (*maze.Maze).TurnCount
+    _ func(*maze.Maze, []byte) error                                                = (*maze.Maze).UnmarshalText
+    _ func(*maze.Maze) bool                                                         = (*maze.Maze).Unsolvable
+    _ func(*maze.Maze)                                                              = (*maze.Maze).Unsolve
+    _ func(maze.MazeKey) string                                                     = maze.MazeKey.String
+    _ func(maze.ModelOptions) error                                                 = maze.ModelOptions.Check
+    _ func(maze.PDFOptions) error                                                   = maze.PDFOptions.Check
+    _ func(maze.PNGOptions) error                                                   = maze.PNGOptions.Check
+    _ func(maze.Phase) string                                                       = maze.Phase.String
+    _ func(maze.Renderer, *maze.Grid, maze.Stats) error                             = maze.Renderer.Frame
+    _ func(maze.SVGOptions) error                                                   = maze.SVGOptions.Check
+    _ func(maze.StreamOptions) error                                                = maze.StreamOptions.Check
+    _ func(*maze.TerminalRenderer, *maze.Grid, maze.Stats) error                    = (*maze.TerminalRenderer).Frame
+    _ func(maze.TextOptions) error                                                  = maze.TextOptions.Check
+    _ func(maze.TikZOptions) error                                                  = maze.TikZOptions.Check
+    _ func(maze.TileOptions) error                                                  = maze.TileOptions.Check
+)
+
+// The types, and the interfaces implemented
+var (
+    _ *maze.CellEvent
+    _ *maze.CharMap
+    _ *maze.Checkpoint
+    _ *maze.Direction
+    _ *maze.DisplayOptions
+    _ *maze.EPSOptions
+    _ *maze.FrameOptions
+    _ *maze.Generator
+    _ *maze.GlyphMap
+    _ *maze.Grid
+    _ *maze.Hooks
+    _ *maze.ImageOptions
+    _ *maze.Loaded
+    _ *maze.MarkdownOptions
+    _ *maze.Mask
+    _ *maze.Maze
+    _ *maze.MazeKey
+    _ *maze.ModelOptions
+    _ *maze.Option
+    _ *maze.PDFOptions
+    _ *maze.PNGOptions
+    _ *maze.Param
+    _ *maze.PathStep
+    _ *maze.Phase
+    _ *maze.Point
+    _ *maze.Racer
+    _ *maze.RenderOptions
+    _ *maze.Renderer
+    _ *maze.SVGOptions
+    _ *maze.Snapshot
+    _ *maze.Stat
+    _ *maze.Stats
+    _ *maze.StreamOptions
+    _ *maze.TerminalRenderer
+    _ *maze.TextOptions
+    _ *maze.TikZOptions
+    _ *maze.TileOptions
+    _ maze.Renderer = (*maze.TerminalRenderer)(nil)
+)
+
+// The fields of the structs, each its own function
+func _(v *maze.CellEvent) {
+    var (
+        _ int        = v.X
+        _ int        = v.Y
+        _ int        = v.Old
+        _ int        = v.New
+        _ maze.Phase = v.Phase
+    )
+}
+
+func _(v *maze.CharMap) {
+    var (
+        _ rune = v.Wall
+        _ rune = v.Path
+        _ rune = v.Solved
+        _ rune = v.Tried
+        _ rune = v.Checked
+    )
+}
+
+func _(v *maze.Checkpoint) {
+    var (
+        _ int      = v.Version
+        _ int      = v.Width
+        _ int      = v.Height
+        _ int      = v.Depth
+        _ int64    = v.Seed
+        _ uint64   = v.Draws
+        _ int      = v.Attempt
+        _ int      = v.NumPaths
+        _ int      = v.MazeLen
+        _ int      = v.MaxChecks
+        _ int      = v.NumCheckExceeded
+        _ int      = v.NumWallPush
+        _ int      = v.NumSolves
+        _ int      = v.SumSolveLength
+        _ [][]int8 = v.Grid
+    )
+}
+
+func _(v *maze.DisplayOptions) {
+    var (
+        _ bool = v.Show
+        _ bool = v.View
+        _ bool = v.Look
+    )
+}
+
+func _(v *maze.EPSOptions) {
+    var (
+        _ float64 = v.CellSize
+        _ float64 = v.StrokeWidth
+        _ bool    = v.Solution
+    )
+}
+
+func _(v *maze.FrameOptions) {
+    var (
+        _ int         = v.Margin
+        _ int         = v.WallPx
+        _ color.Color = v.Path
+        _ color.Color = v.Wall
+        _ color.Color = v.Solution
+        _ color.Color = v.Tried
+        _ color.Color = v.Check
+    )
+}
+
+func _(v *maze.GlyphMap) {
+    var (
+        _ string = v.Wall
+        _ string = v.Path
+        _ string = v.Solved
+        _ string = v.Tried
+        _ string = v.Checked
+    )
+}
+
+func _(v *maze.Hooks) {
+    var (
+        _ func()           = v.OnUpdate
+        _ func()           = v.OnPath
+        _ func(maze.Phase) = v.OnPhase
+    )
+}
+
+func _(v *maze.ImageOptions) {
+    var (
+        _ int = v.CellSize
+    )
+}
+
+func _(v *maze.MarkdownOptions) {
+    var (
+        _ int  = v.Columns
+        _ bool = v.Split
+    )
+}
+
+func _(v *maze.MazeKey) {
+    var (
+        _ int    = v.Width
+        _ int    = v.Height
+        _ int64  = v.Seed
+        _ int    = v.Depth
+        _ int    = v.Gap
+        _ string = v.Algorithm
+    )
+}
+
+func _(v *maze.ModelOptions) {
+    var (
+        _ float64 = v.CellSize
+        _ float64 = v.WallHeight
+        _ float64 = v.WallThickness
+    )
+}
+
+func _(v *maze.PDFOptions) {
+    var (
+        _ string = v.PageSize
+        _ int    = v.Margin
+        _ int    = v.WallPx
+        _ bool   = v.Solution
+    )
+}
+
+func _(v *maze.PNGOptions) {
+    var (
+        _ int         = v.CellSize
+        _ int         = v.Margin
+        _ int         = v.WallPx
+        _ color.Color = v.Solution
+    )
+}
+
+func _(v *maze.Param) {
+    var (
+        _ string = v.Name
+        _ string = v.Value
+    )
+}
+
+func _(v *maze.PathStep) {
+    var (
+        _ maze.Point     = v.Point
+        _ maze.Direction = v.Heading
+    )
+}
+
+func _(v *maze.Point) {
+    var (
+        _ int = v.X
+        _ int = v.Y
+    )
+}
+
+func _(v *maze.RenderOptions) {
+    var (
+        _ bool   = v.Blank
+        _ bool   = v.Look
+        _ int    = v.Margin
+        _ string = v.Wall
+        _ string = v.Path
+        _ string = v.Solved
+        _ string = v.Tried
+        _ string = v.Check
+    )
+}
+
+func _(v *maze.SVGOptions) {
+    var (
+        _ int = v.WallPx
+    )
+}
+
+func _(v *maze.Stat) {
+    var (
+        _ string = v.Name
+        _ int    = v.Value
+        _ int    = v.Kind
+    )
+}
+
+func _(v *maze.StreamOptions) {
+    var (
+        _ int    = v.Width
+        _ int    = v.Height
+        _ int64  = v.Seed
+        _ string = v.Algorithm
+    )
+}
+
+func _(v *maze.TerminalRenderer) {
+    var (
+        _ io.Writer          = v.W
+        _ maze.RenderOptions = v.RenderOptions
+        _ string             = v.Status
+        _ bool               = v.Braille
+        _ *maze.GlyphMap     = v.Glyphs
+    )
+}
+
+func _(v *maze.TextOptions) {
+    var (
+        _ bool         = v.MarkOpenings
+        _ bool         = v.BlockWalls
+        _ bool         = v.BoxDrawing
+        _ int          = v.LineStyle
+        _ bool         = v.BrailleSolution
+        _ bool         = v.BranchMarkers
+        _ maze.CharMap = v.CharMap
+    )
+}
+
+func _(v *maze.TikZOptions) {
+    var (
+        _ float64 = v.Scale
+        _ float64 = v.LineWidth
+        _ bool    = v.Solution
+    )
+}
+
+func _(v *maze.TileOptions) {
+    var (
+        _ image.Image = v.Sheet
+        _ int         = v.TileSize
+    )
+}
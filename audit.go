@@ -0,0 +1,45 @@
+/* audit.go - Stale check cell audit
+ *
+ * The look ahead marks the locations it's testing as check cells and puts back what was there once it's done.
+ * Once carving is complete no check cells should remain, so any that do are counted and turned back into
+ * walls or paths before the openings are chosen, and can be listed once the maze is complete.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+)
+
+// auditState is the state of the stale check cell audit of a Maze
+type auditState struct {
+    numStaleChecks int32
+    staleChecks    []point          // locations of the stale check cells cleared
+}
+
+// clearStaleChecks turns any check cells left after carving back into walls, or into paths for cells joined to a
+// passage, since the look ahead only marks walls and the cell a carver is standing on
+func (m *Maze) clearStaleChecks() {
+    for i := 1; i < getInt(&m.maxX) - 1; i++ {
+        for j := 1; j < getInt(&m.maxY) - 1; j++ {
+            if m.getMaze(i, j) != check {
+                continue
+            }
+            state := wall
+            if isEven(i) && isEven(j) && (m.getMaze(i-1, j) == path || m.getMaze(i+1, j) == path || m.getMaze(i, j-1) == path || m.getMaze(i, j+1) == path) {
+                state = path
+            }
+            m.setMaze(i, j, state)
+            incInt(&m.numStaleChecks)
+            m.staleChecks = append(m.staleChecks, point{i, j})
+        }
+    }
+}
+
+// printStaleChecks lists the location of every stale check cell cleared
+func (m *Maze) printStaleChecks(w io.Writer) {
+    fmt.Fprintf(w, "audit: %d stale check cells cleared\n", len(m.staleChecks))
+    for _, p := range m.staleChecks {
+        fmt.Fprintf(w, "audit: stale check cell at location %d, %d\n", p.x, p.y)
+    }
+}
@@ -0,0 +1,42 @@
+/* audit_test.go - Stale check cell tests
+ *
+ * Generates hundreds of small mazes with carvers racing each other and checks that none leaves a check cell
+ * behind for the audit to clear, and that each is still a perfect maze.
+ */
+package maze
+
+import (
+    "context"
+    "testing"
+)
+
+func TestNoStaleChecks(t *testing.T) {
+    runs := 300
+    if testing.Short() {
+        runs = 30
+    }
+    for seed := int64(1); seed <= int64(runs); seed++ {
+        m, err := NewMaze(WithSize(20, 10), WithSeed(seed), WithCarveThreads(4), WithSolveThreads(4))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if _, err := m.Generate(context.Background()); err != nil {
+            t.Fatalf("seed %d: %v", seed, err)
+        }
+        if n := getInt(&m.numStaleChecks); n != 0 {
+            t.Errorf("seed %d: %d stale check cells cleared, at %v", seed, n, m.staleChecks)
+        }
+        left := 0                               // walls as well as cells, which the look ahead marks too
+        for i := 0; i < getInt(&m.maxX); i++ {
+            for j := 0; j < getInt(&m.maxY); j++ {
+                left += bool2int(m.getMaze(i, j) == check)
+            }
+        }
+        if left != 0 {
+            t.Errorf("seed %d: %d check cells left in the maze", seed, left)
+        }
+        if got, want := m.Passages(), 20*10 - 1; got != want {
+            t.Errorf("seed %d: %d passages, want %d for a perfect maze", seed, got, want)
+        }
+    }
+}
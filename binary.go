@@ -0,0 +1,154 @@
+/* binary.go - Compact binary maze files
+ *
+ * Stores the maze in a few bytes for datasets of millions of mazes: a fixed header with the size, seed and
+ * openings, the json format's wall mask of each cell packed two cells to a byte, each row starting a new byte,
+ * and, if the maze is solved, the solution as two bit steps from one cell to the next.  Each maze holds its own
+ * length, so a stream of them written one after another is read back with one ParseBinary after another.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "encoding/binary"
+)
+
+const (
+    binaryVersion  = 1
+    binarySolution = 1 << 0             // the header flag
+)
+
+var binaryMagic = [4]byte{'M', 'A', 'Z', 'E'}
+
+// binaryHeader starts the binary format, big endian.  The entrance is in the top row and the exit in the bottom
+// row of the maze, in columns BegY and EndY.
+type binaryHeader struct {
+    Magic   [4]byte
+    Version uint8
+    Flags   uint8
+    Width   uint16
+    Height  uint16
+    Seed    int64
+    BegY    uint16
+    EndY    uint16
+}
+
+// binaryRoute follows the cells, if the solution flag is set, and then the Steps, four to a byte from the high
+// bits, each a direction from one cell of the solution to the next: 0 north, 1 south, 2 east and 3 west.  The route
+// runs from column Entrance above the maze to column First in the top row, then by the steps to the bottom row and
+// out to column Exit below the maze.
+type binaryRoute struct {
+    Entrance uint16
+    First    uint16
+    Exit     uint16
+    Steps    uint32
+}
+
+// binarySteps are the moves of the solution's steps, in the order of their directions
+var binarySteps = [4][2]int{{-1, 0}, {1, 0}, {0, 1}, {0, -1}}
+
+// RenderBinary writes the maze to w in the binary format, returning the first error from w.  As with the json format
+// the corridors aren't widened, the solution is the one marked in the maze and the seed of a loaded maze is 0.
+func (m *Maze) RenderBinary(w io.Writer) error {
+    ew := &errWriter{w: w}
+    var solution []Point
+    for p := range m.markedSolution() {
+        solution = append(solution, p)
+    }
+    beg, end := cellAt(getInt(&m.begX), getInt(&m.begY)), cellAt(getInt(&m.endX), getInt(&m.endY))
+    header   := binaryHeader{Magic: binaryMagic, Version: binaryVersion, Width: uint16(m.width), Height: uint16(m.height),
+                             Seed: m.getSeed(), BegY: uint16(beg.col), EndY: uint16(end.col)}
+    if m.loaded != nil {
+        header.Seed = 0
+    }
+    if len(solution) >= 3 {
+        header.Flags |= binarySolution
+    }
+    binary.Write(ew, binary.BigEndian, header)
+
+    row := make([]byte, (m.width + 1)/2)
+    for i := 2; i <= 2*m.height; i += 2 {
+        clear(row)
+        for j := 2; j <= 2*m.width; j += 2 {
+            c := j/2 - 1
+            row[c/2] |= byte(m.wallMask(i, j)) << (4*(1 - c%2))
+        }
+        ew.Write(row)
+    }
+
+    if header.Flags & binarySolution != 0 {
+        cells := solution[1:len(solution) - 1]
+        binary.Write(ew, binary.BigEndian, binaryRoute{uint16(solution[0].Y), uint16(cells[0].Y), uint16(solution[len(solution) - 1].Y), uint32(len(cells) - 1)})
+        steps := make([]byte, (len(cells) + 2)/4)
+        for k := 1; k < len(cells); k++ {
+            d := 0
+            for d < len(binarySteps) && (cells[k].X - cells[k-1].X != binarySteps[d][0] || cells[k].Y - cells[k-1].Y != binarySteps[d][1]) {
+                d++
+            }
+            steps[(k - 1)/4] |= byte(d) << (2*(3 - (k - 1)%4))
+        }
+        ew.Write(steps)
+    }
+    return ew.err
+}
+
+// ParseBinary reads a maze in the binary format, as written by RenderBinary, from r.  It reads no further than the end
+// of the maze, and validates it as ParseJSON does, the padding of the last byte of each row of an odd width maze and
+// of the last byte of the steps being zero too.
+func ParseBinary(r io.Reader) (*Loaded, error) {
+    var header binaryHeader
+    if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+        return nil, fmt.Errorf("reading the header: %v", err)
+    }
+    switch {
+        case header.Magic != binaryMagic        : return nil, fmt.Errorf("not a binary maze file")
+        case header.Version != binaryVersion    : return nil, fmt.Errorf("unsupported binary maze version %d", header.Version)
+        case header.Flags &^ binarySolution != 0: return nil, fmt.Errorf("unknown header flags %#x", header.Flags)
+    }
+    h, w := int(header.Height), int(header.Width)
+    if h < 1 || h > maxHeight || w < 1 || w > maxWidth {
+        return nil, fmt.Errorf("invalid maze size %dx%d (height 1-%d, width 1-%d)", w, h, maxHeight, maxWidth)
+    }
+    j := jsonMaze{Width: w, Height: h, Seed: header.Seed, BegY: int(header.BegY), EndX: h - 1, EndY: int(header.EndY)}
+
+    row := make([]byte, (w + 1)/2)
+    for i := 0; i < h; i++ {
+        if _, err := io.ReadFull(r, row); err != nil {
+            return nil, fmt.Errorf("reading row %d: %v", i, err)
+        }
+        if isOdd(w) && row[len(row) - 1] & 0x0f != 0 {
+            return nil, fmt.Errorf("row %d: the padding after the last cell isn't zero", i)
+        }
+        cells := make([]int, w)
+        for c := range cells {
+            cells[c] = int(row[c/2] >> (4*(1 - c%2))) & 0x0f
+        }
+        j.Cells = append(j.Cells, cells)
+    }
+
+    if header.Flags & binarySolution != 0 {
+        var route binaryRoute
+        if err := binary.Read(r, binary.BigEndian, &route); err != nil {
+            return nil, fmt.Errorf("reading the solution: %v", err)
+        }
+        if route.Steps >= uint32(w*h) {
+            return nil, fmt.Errorf("solution: %d steps is more than the maze has cells", route.Steps)
+        }
+        steps := make([]byte, (route.Steps + 3)/4)
+        if _, err := io.ReadFull(r, steps); err != nil {
+            return nil, fmt.Errorf("reading the solution: %v", err)
+        }
+        if route.Steps%4 != 0 && steps[len(steps) - 1] & (0xff >> (2*(route.Steps%4))) != 0 {
+            return nil, fmt.Errorf("solution: the padding after the last step isn't zero")
+        }
+        x, y := 0, int(route.First)
+        j.Solution = [][2]int{{-1, int(route.Entrance)}, {x, y}}
+        for k := 0; k < int(route.Steps); k++ {
+            d := binarySteps[steps[k/4] >> (2*(3 - k%4)) & 3]
+            x, y = x + d[0], y + d[1]
+            j.Solution = append(j.Solution, [2]int{x, y})
+        }
+        j.Solution = append(j.Solution, [2]int{h, int(route.Exit)})
+    }
+    return j.loaded()
+}
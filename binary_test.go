@@ -0,0 +1,85 @@
+/* binary_test.go - Binary format tests
+ *
+ * Round trips mazes of odd and even widths, whose rows end in a padding nibble or don't, solved and unsolved,
+ * through the binary format and checks that they read back as the ascii format reads them, and that a stream of
+ * mazes reads back one by one.  The cells the solver tried aren't kept, so they read back as paths.
+ */
+package maze
+
+import (
+    "bytes"
+    "testing"
+    "encoding/binary"
+)
+
+// renderBinary returns the maze in the binary format
+func renderBinary(t *testing.T, m *Maze) []byte {
+    t.Helper()
+    var b bytes.Buffer
+    if err := m.RenderBinary(&b); err != nil {
+        t.Fatal(err)
+    }
+    return b.Bytes()
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+    for _, size := range [][2]int{{1, 1}, {1, 6}, {3, 4}, {5, 5}, {7, 3}, {19, 10}, {2, 9}, {12, 8}} {
+        m := goldenMaze(t, WithSize(size[0], size[1]), WithSeed(3))
+        for _, solved := range []bool{true, false} {
+            saved := m.Snapshot()
+            if !solved {
+                m.Unsolve()
+            }
+            data := renderBinary(t, m)
+            want, err := ParseASCII(string(renderASCII(t, m)))
+            m.Restore(saved)
+            if err != nil {
+                t.Fatal(err)
+            }
+            for i := range want.grid {              // the binary format keeps the solution but not the cells tried
+                for j := range want.grid[i] {
+                    if want.grid[i][j] == tried {
+                        want.grid[i][j] = path
+                    }
+                }
+            }
+            if n := binary.Size(binaryHeader{}) + size[1]*((size[0] + 1)/2); !solved && len(data) != n {
+                t.Errorf("%dx%d: %d bytes unsolved, want %d", size[0], size[1], len(data), n)
+            }
+            got, err := ParseBinary(bytes.NewReader(data))
+            switch {
+                case err != nil:
+                    t.Errorf("%dx%d solved %v: %v", size[0], size[1], solved, err)
+                case got.grid != want.grid || got.begY != want.begY || got.endY != want.endY:
+                    t.Errorf("%dx%d solved %v: read back differently from the ascii format", size[0], size[1], solved)
+            }
+        }
+    }
+}
+
+func TestBinaryStream(t *testing.T) {
+    a, b := goldenMaze(t, WithSize(7, 3), WithSeed(1)), goldenMaze(t, WithSize(4, 6), WithSeed(2))
+    r := bytes.NewReader(append(renderBinary(t, a), renderBinary(t, b)...))
+    for _, m := range []*Maze{a, b} {
+        got, err := ParseBinary(r)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if h, w := got.Size(); h != m.cfg.height || w != m.cfg.width {
+            t.Errorf("read a %dx%d maze, want %dx%d", w, h, m.cfg.width, m.cfg.height)
+        }
+    }
+    if r.Len() != 0 {
+        t.Errorf("%d bytes left after the stream", r.Len())
+    }
+}
+
+func TestBinaryPadding(t *testing.T) {
+    m := goldenMaze(t, WithSize(5, 2), WithSeed(1))
+    m.Unsolve()
+    data := renderBinary(t, m)
+    data[binary.Size(binaryHeader{}) + 2] |= 0x01   // the padding after the last cell of the first row
+    if _, err := ParseBinary(bytes.NewReader(data)); err == nil {
+        t.Error("padding set: no error")
+    }
+}
@@ -0,0 +1,69 @@
+/* braille.go - Braille terminal display
+ *
+ * Draws the maze on a terminal with unicode braille characters, each packing a 2 by 4 block of maze locations
+ * as the braille format does, so the largest maze fits a terminal of a few hundred columns by fifty rows.  Each
+ * character has a dot raised for every wall and solution location in it and takes the color of the most telling
+ * thing in it: the solution, then a race solver, then a shown look ahead check, and otherwise the walls.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "unicode/utf8"
+)
+
+// brailleRows returns the number of rows of braille characters the maze locations inside the perimeter path take
+func (m *Maze) brailleRows() int {
+    return (getInt(&m.maxX) - 2 + 3)/4
+}
+
+// RenderBrailleANSI draws the maze to w from the top left of the screen, or of the margin, as braille characters in
+// the colors of the options.  A character with a tried cell in it has the background of the tried cells.  The Blank
+// style has no effect, the walls always being drawn.  It stops at the first write that fails and returns its error.
+func (m *Maze) RenderBrailleANSI(w io.Writer, opts RenderOptions) error {
+    rows, cols := getInt(&m.maxX) - 2, getInt(&m.maxY) - 2        // the locations inside the perimeter path
+    shown  := func(x, y int) bool {; return isShownWall(m.getMaze(x, y), opts.Look); }
+    drawn  := func(x, y int) bool {                                 // a wall, or a post with a wall leading from it
+        return shown(x, y) && (isEven(x) || isEven(y) || shown(x-1, y) || shown(x+1, y) || shown(x, y-1) || shown(x, y+1))
+    }
+    racing := getBool(&m.raceFlag)
+
+    line := []byte("\033[0;0H")
+    for r := 0; r < rows; r += 4 {
+        if opts.Margin > 0 {
+            line = fmt.Appendf(line, "\033[%d;%dH", opts.Margin + r/4 + 1, opts.Margin + 1)
+        }
+        style := ""
+        for c := 0; c < cols; c += 2 {
+            bits, fg, bg, rank := 0, opts.Wall, opts.Path, 0
+            for dr := 0; dr < 4 && r + dr < rows; dr++ {
+                for dc := 0; dc < 2 && c + dc < cols; dc++ {
+                    x, y := r + dr + 1, c + dc + 1
+                    switch v := m.getMaze(x, y); {
+                        case v == solved                   : bits |= brailleDots[dr][dc]; if rank < 3 {; fg, rank = opts.Solved, 3; }
+                        case v == check && opts.Look       : bits |= brailleDots[dr][dc]; if rank < 1 {; fg, rank = opts.Check, 1; }
+                        case drawn(x, y)                   : bits |= brailleDots[dr][dc]
+                        case v == tried && opts.Tried != "": bg = opts.Tried
+                    }
+                    if racing && rank < 2 && m.getMaze(x, y) != wall && m.getOwner(x, y) != 0 {
+                        bits |= brailleDots[dr][dc]
+                        fg, rank = racerColor(m.getOwner(x, y)), 2
+                    }
+                }
+            }
+            if s := bg + fg; s != style {
+                line  = append(line, "\033[0m"...)
+                line  = append(line, s...)
+                style = s
+            }
+            line = utf8.AppendRune(line, rune(0x2800 + bits))
+        }
+        line = append(line, "\033[0m\n"...)
+        if _, err := w.Write(line); err != nil {
+            return err
+        }
+        line = line[:0]
+    }
+    return nil
+}
@@ -0,0 +1,80 @@
+/* budget.go - Solver step budget
+ *
+ * Limits the number of steps the solvers may take so that pathological mazes end with a partial result
+ * instead of running indefinitely.  Every move forward or back along a path is one step.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "sync"
+    "sync/atomic"
+)
+
+// budgetState is the state of the solver step budget of a Maze
+type budgetState struct {
+    maxSolveSteps   int
+    budgetOn        int32                   // set while solving the finished maze, not while searching for openings
+    solveSteps      int32                   // steps taken by the solver on the current maze
+    budgetExhausted int32                   // set once the solver has given up
+
+    deepestMu       sync.Mutex              // guards the deepest point reached by the solver
+    deepestLen      int
+    deepestCell     cell
+}
+
+// solveStep counts a solver step against the budget and returns false once the budget is exhausted or the
+// solving is cancelled
+func (m *Maze) solveStep() bool {
+    if m.stopped() {
+        return false
+    }
+    if m.maxSolveSteps == 0 || !getBool(&m.budgetOn) {
+        return true
+    }
+    if int(atomic.AddInt32(&m.solveSteps, 1)) > m.maxSolveSteps {
+        setBool(&m.budgetExhausted, true)
+    }
+    return !getBool(&m.budgetExhausted)
+}
+
+// resetBudget clears the step count and deepest point before solving a maze
+func (m *Maze) resetBudget() {
+    clrInt( &m.solveSteps)
+    setBool(&m.budgetExhausted, false)
+    m.deepestMu.Lock()
+    m.deepestLen, m.deepestCell = 0, cell{}
+    m.deepestMu.Unlock()
+}
+
+// reachedCell records the cell at x, y as the deepest point reached if the current path length is the longest yet
+func (m *Maze) reachedCell(x, y int) {
+    m.deepestMu.Lock()
+    if n := getInt(&m.pathLen); n > m.deepestLen {
+        m.deepestLen, m.deepestCell = n, cellAt(x, y)
+    }
+    m.deepestMu.Unlock()
+}
+
+// markExplored turns the partial solution left by an exhausted solver into tried cells
+func (m *Maze) markExplored() {
+    for i := 0; i < getInt(&m.maxX); i++ {
+        for j := 0; j < getInt(&m.maxY); j++ {
+            if m.getMaze(i, j) == solved {
+                m.setMaze(i, j, tried)
+            }
+        }
+    }
+}
+
+// printBudget prints the deepest point the solver reached and the number of cells it visited before giving up
+func (m *Maze) printBudget(w io.Writer) {
+    visited := 0
+    m.cells(func(c cell, state int) bool {
+        visited += bool2int(state == tried)
+        return true
+    })
+    fmt.Fprintf(w, "solve: step budget of %d exhausted, deepest point row %d, col %d at path length %d, %d cells visited\n",
+                m.maxSolveSteps, m.deepestCell.row, m.deepestCell.col, m.deepestLen, visited)
+}
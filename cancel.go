@@ -0,0 +1,30 @@
+/* cancel.go - Cancellation
+ *
+ * Generate and Solve take a context so that a long run, a large maze with a deep look ahead say, can be
+ * stopped by the caller.  Like the step budget, cancellation is seen by the carving, checking and solving
+ * loops, every goroutine among them winding down on its own, and the maze is left as far as it got.
+ */
+package maze
+
+import (
+    "context"
+)
+
+// cancelState is the cancellation of the generation or solving in progress on a Maze
+type cancelState struct {
+    done <-chan struct{}                    // the context's done channel, nil when there's nothing to cancel
+}
+
+// watchContext makes the loops observe the cancellation of ctx until the returned function is called
+func (m *Maze) watchContext(ctx context.Context) func() {
+    m.done = ctx.Done()
+    return func() {; m.done = nil; }
+}
+
+// stopped returns true once the context being watched is cancelled
+func (m *Maze) stopped() bool {
+    select {
+        case <- m.done: return true
+        default       : return false
+    }
+}
@@ -0,0 +1,60 @@
+/* cancel_test.go - Cancellation tests
+ *
+ * Cancels a large threaded generation part way through and checks that Generate returns promptly with the
+ * context's error, and that every goroutine it started has wound down.
+ */
+package maze
+
+import (
+    "time"
+    "errors"
+    "context"
+    "runtime"
+    "testing"
+)
+
+// settledGoroutines returns the number of goroutines once it has stopped falling, waiting up to a second for those
+// winding down to finish
+func settledGoroutines(want int) int {
+    n := runtime.NumGoroutine()
+    for deadline := time.Now().Add(time.Second); n > want && time.Now().Before(deadline); n = runtime.NumGoroutine() {
+        time.Sleep(10*time.Millisecond)
+    }
+    return n
+}
+
+func TestCancelGenerate(t *testing.T) {
+    before := runtime.NumGoroutine()
+    m, err := NewMaze(WithSize(300, 100), WithSeed(1), WithThreads(4), WithDepth(5))
+    if err != nil {
+        t.Fatal(err)
+    }
+    ctx, cancel := context.WithCancel(context.Background())
+    time.AfterFunc(50*time.Millisecond, cancel)
+    start := time.Now()
+    _, err = m.Generate(ctx)
+    if elapsed := time.Since(start); elapsed > time.Second {
+        t.Errorf("Generate returned %v after it was cancelled", elapsed - 50*time.Millisecond)
+    }
+    if !errors.Is(err, context.Canceled) {
+        t.Errorf("cancelled generation: error %v, want %v", err, context.Canceled)
+    }
+    if after := settledGoroutines(before); after > before {
+        t.Errorf("%d goroutines left running after the cancelled generation, %d before it", after, before)
+    }
+}
+
+func TestCancelBeforeStart(t *testing.T) {
+    m, err := NewMaze(WithSize(19, 10), WithSeed(1))
+    if err != nil {
+        t.Fatal(err)
+    }
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    if _, err := m.Generate(ctx); !errors.Is(err, context.Canceled) {
+        t.Errorf("generation cancelled before it started: error %v, want %v", err, context.Canceled)
+    }
+    if _, err := m.Solve(ctx); !errors.Is(err, context.Canceled) {
+        t.Errorf("solve cancelled before it started: error %v, want %v", err, context.Canceled)
+    }
+}
@@ -0,0 +1,75 @@
+/* cells.go - Iteration over the cells and passages of the maze
+ *
+ * The maze array doubles every coordinate so that walls have locations of their own: cells sit at even
+ * locations and the walls between them at odd ones.  These iterators hide that layout from analysis code,
+ * and Cells and PassageCells from other programs.  They read the shared maze array and are not safe to call while a maze is
+ * being generated.
+ */
+package maze
+
+import (
+    "iter"
+)
+
+// cell is a maze cell in logical row, column coordinates, 0, 0 being the top left cell
+type cell struct {
+    row, col int
+}
+
+// cellAt returns the cell at the even maze array location x, y
+func cellAt(x, y int) cell {
+    return cell{x/2 - 1, y/2 - 1}
+}
+
+// loc returns the maze array location of the cell
+func (c cell) loc() (int, int) {
+    return 2*(c.row + 1), 2*(c.col + 1)
+}
+
+// cells calls yield for every cell of the maze in row order with its state (path, wall, solved or tried)
+// and stops early if yield returns false
+func (m *Maze) cells(yield func(c cell, state int) bool) {
+    for i := 2; i <= 2*m.height; i += 2 {
+        for j := 2; j <= 2*m.width; j += 2 {
+            if !yield(cellAt(i, j), m.getMaze(i, j)) {
+                return
+            }
+        }
+    }
+}
+
+// Cells returns an iterator over every cell of the maze in row order, in logical cell coordinates, with its value
+// as returned by Cell: Path, Wall for a cell never carved, Solved or Tried
+func (m *Maze) Cells() iter.Seq2[Point, int] {
+    return func(yield func(Point, int) bool) {
+        m.cells(func(c cell, state int) bool {
+            return yield(Point{c.row, c.col}, state)
+        })
+    }
+}
+
+// passages calls yield once for every open passage between two adjacent cells, the first cell being above or to
+// the left of the second, and stops early if yield returns false.  The entrance and exit openings are not included.
+func (m *Maze) passages(yield func(a, b cell) bool) {
+    for i := 2; i <= 2*m.height; i += 2 {
+        for j := 2; j <= 2*m.width; j += 2 {
+            c := cellAt(i, j)
+            if j < 2*m.width  && m.getMaze(i, j + 1) != wall && !yield(c, cell{c.row, c.col + 1}) {
+                return
+            }
+            if i < 2*m.height && m.getMaze(i + 1, j) != wall && !yield(c, cell{c.row + 1, c.col}) {
+                return
+            }
+        }
+    }
+}
+
+// PassageCells returns an iterator over every open passage between two adjacent cells, in logical cell coordinates,
+// the first cell being above or to the left of the second.  The entrance and exit openings are not included.
+func (m *Maze) PassageCells() iter.Seq2[Point, Point] {
+    return func(yield func(Point, Point) bool) {
+        m.passages(func(a, b cell) bool {
+            return yield(Point{a.row, a.col}, Point{b.row, b.col})
+        })
+    }
+}
@@ -0,0 +1,129 @@
+/* charmap.go - Characters of the ascii format
+ *
+ * Lets the characters written for each kind of location in the ascii format be replaced for other programs'
+ * parsers, walls as # and paths as . say, or 1 and 0.  A wall character replaces the whole drawing of the walls,
+ * every wall location being written with it as in the block wall style.  The characters must tell the kinds of
+ * location apart, so a file written with a map is read back by decoding it with the same map.
+ */
+package maze
+
+import (
+    "fmt"
+    "strings"
+    "unicode/utf8"
+)
+
+// CharMap holds the characters replacing the usual ones in the ascii format, 0 keeping the usual character
+type CharMap struct {
+    Wall, Path, Solved, Tried, Checked rune
+}
+
+// charMapNames are the names of the kinds of location in a character map, in the order of CharMap's fields
+var charMapNames = [...]string { "wall", "path", "solved", "tried", "check" }
+
+// runes returns pointers to the characters of the map in the order of charMapNames
+func (c *CharMap) runes() [len(charMapNames)]*rune {
+    return [...]*rune{&c.Wall, &c.Path, &c.Solved, &c.Tried, &c.Checked}
+}
+
+// ParseCharMap parses a character map of comma separated name=character pairs, e.g. wall=#,path=.,solved=* where the
+// names are wall, path, solved, tried and check.  Each character is a single unicode character, which may be a comma,
+// and a name left out keeps its usual character.  The map is checked with Check.
+func ParseCharMap(spec string) (CharMap, error) {
+    var c CharMap
+    chars := c.runes()
+    for rest := spec; rest != ""; {
+        name, value, found := strings.Cut(rest, "=")
+        k := 0
+        for k < len(charMapNames) && charMapNames[k] != name {
+            k++
+        }
+        switch {
+            case !found                : return c, fmt.Errorf("expected name=character in %q", rest)
+            case k == len(charMapNames): return c, fmt.Errorf("unknown location %q (valid locations: %s)", name, strings.Join(charMapNames[:], ", "))
+            case *chars[k] != 0        : return c, fmt.Errorf("%s given more than once", name)
+        }
+        r, size := utf8.DecodeRuneInString(value)
+        if size == 0 || r == utf8.RuneError && size == 1 {
+            return c, fmt.Errorf("expected a utf-8 character for %s", name)
+        }
+        if rest = value[size:]; rest != "" && rest[0] != ',' {
+            return c, fmt.Errorf("expected a single character for %s, found %q", name, value[:size + 1])
+        }
+        *chars[k] = r
+        rest = strings.TrimPrefix(rest, ",")
+    }
+    return c, c.Check()
+}
+
+// usual returns the characters the ascii format writes for the kind of location of charMapNames[k] without a map, or
+// with this one if it replaces the walls, any of which a replacement for another kind mustn't be
+func (c CharMap) usual(k int) string {
+    switch charMapNames[k] {
+        case "wall"  : return "+-|" + string(blockChar) + string(boxBlockChar) + string(boxLookup[1:]) + string(heavyLookup[1:]) + string(doubleLookup[1:])
+        case "path"  : return " "
+        case "solved": return "*" + string(boxSolved)
+        case "tried" : return "."
+    }
+    if c.Wall != 0 {
+        return ""                                       // written as paths, as in the block wall style
+    }
+    return "#"
+}
+
+// Check returns an error if a character of the map is a line break or would be taken for another kind of location,
+// being the same as its replacement or, if that's not replaced, one of its usual characters
+func (c CharMap) Check() error {
+    chars := c.runes()
+    for k, r := range chars {
+        if *r == '\n' || *r == '\r' {
+            return fmt.Errorf("%s can't be a line break", charMapNames[k])
+        }
+        if *r == 0 || strings.ContainsRune(c.usual(k), *r) {       // told apart by where it is, as usual
+            continue
+        }
+        for other, s := range chars {
+            switch {
+                case other == k                                          :
+                case *s == *r                                            : return fmt.Errorf("%s and %s are both %q", charMapNames[k], charMapNames[other], *r)
+                case *s == 0 && strings.ContainsRune(c.usual(other), *r) : return fmt.Errorf("%s %q would be read as %s", charMapNames[k], *r, charMapNames[other])
+            }
+        }
+    }
+    return nil
+}
+
+// glyph returns the character replacing the one usually written for a location in state, or 0 to keep it
+func (c CharMap) glyph(state int) rune {
+    switch state {
+        case wall  : return c.Wall
+        case path  : return c.Path
+        case solved: return c.Solved
+        case tried : return c.Tried
+        case check : if c.Checked != 0 || c.Wall == 0 {
+                         return c.Checked
+                     }
+                     if c.Path == 0 {                                 // hidden as a path, as in the block wall style
+                         return ' '
+                     }
+                     return c.Path
+    }
+    return 0
+}
+
+// Decode returns the text of an ascii file written with the map with the usual characters put back, the walls in the
+// block wall style, for ParseASCII.  The comment block and the header line are left as they are.
+func (c CharMap) Decode(text string) string {
+    lines := strings.SplitAfter(text, "\n")
+    skip  := commentLines(lines) + 1                  // the lines up to the header
+    var pairs []string
+    for k, usual := range [...]rune{blockChar, ' ', '*', '.', '#'} {
+        if r := *c.runes()[k]; r != 0 {
+            pairs = append(pairs, string(r), string(usual))
+        }
+    }
+    if len(pairs) == 0 || skip >= len(lines) {
+        return text
+    }
+    return strings.Join(lines[:skip], "") + strings.NewReplacer(pairs...).Replace(strings.Join(lines[skip:], ""))
+}
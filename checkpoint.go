@@ -0,0 +1,106 @@
+/* checkpoint.go - Checkpoint and resume of partially generated mazes
+ *
+ * A checkpoint is taken between paths, when the single carver's frontier is empty, so the state
+ * is just the maze array, the generation counters and the random number source's seed and draw count.
+ */
+package maze
+
+import (
+    "fmt"
+)
+
+const checkpointVersion = 1
+
+// Checkpoint is the generation state saved in a checkpoint file
+type Checkpoint struct {
+    Version          int
+    Width, Height    int
+    Depth            int
+    Seed             int64
+    Draws            uint64
+    Attempt          int
+    NumPaths         int
+    MazeLen          int
+    MaxChecks        int
+    NumCheckExceeded int
+    NumWallPush      int
+    NumSolves        int
+    SumSolveLength   int
+    Grid             [][]int8
+}
+
+// Checkpoint returns the generation state, which can be saved and later resumed when carving single threaded.
+// It must only be taken between paths, from the OnPath hook.
+func (m *Maze) Checkpoint() *Checkpoint {
+    seed, draws := m.rngSource.state()
+    cp := &Checkpoint{
+        Version          : checkpointVersion,
+        Width            : m.width,
+        Height           : m.height,
+        Depth            : getInt(&m.depth),
+        Seed             : seed,
+        Draws            : draws,
+        Attempt          : getInt(&m.numMazeCreated),
+        NumPaths         : getInt(&m.numPaths),
+        MazeLen          : getInt(&m.mazeLen),
+        MaxChecks        : getInt(&m.maxChecks),
+        NumCheckExceeded : getInt(&m.numCheckExceeded),
+        NumWallPush      : getInt(&m.numWallPush),
+        NumSolves        : getInt(&m.numSolves),
+        SumSolveLength   : getInt(&m.sumsolveLength),
+        Grid             : make([][]int8, getInt(&m.maxX)),
+    }
+    for i := range cp.Grid {
+        cp.Grid[i] = make([]int8, getInt(&m.maxY))
+        for j := range cp.Grid[i] {
+            cp.Grid[i][j] = int8(m.getMaze(i, j))
+        }
+    }
+    return cp
+}
+
+// Check returns an error if the checkpoint can't be resumed
+func (cp *Checkpoint) Check() error {
+    if cp.Version != checkpointVersion {
+        return fmt.Errorf("unsupported checkpoint version %d", cp.Version)
+    }
+    if cp.Width <= 0 || cp.Width > maxWidth || cp.Height <= 0 || cp.Height > maxHeight ||
+       len(cp.Grid) != 2*(cp.Height + 1) + 1 || len(cp.Grid[0]) != 2*(cp.Width + 1) + 1 {
+        return fmt.Errorf("invalid maze dimensions")
+    }
+    return nil
+}
+
+// Resume sets the parameters from a checkpoint, checking it, so that the next maze generated carries on from it
+func (m *Maze) Resume(cp *Checkpoint) error {
+    if err := cp.Check(); err != nil {
+        return err
+    }
+    m.cfg.width, m.cfg.height, m.cfg.depth, m.cfg.seed = cp.Width, cp.Height, cp.Depth, cp.Seed
+    setInt(&m.numMazeCreated, cp.Attempt - 1)
+    m.resumeState = cp
+    return nil
+}
+
+// restoreCheckpoint restores the maze array, counters and random number source saved in the resumed checkpoint
+func (m *Maze) restoreCheckpoint() {
+    cp := m.resumeState
+    setInt(&m.maxX, 2*(m.height + 1) + 1)
+    setInt(&m.maxY, 2*(m.width  + 1) + 1)
+    setInt(&m.begX, 2)
+    setInt(&m.endX, 2*m.height)
+    for i := range cp.Grid {
+        for j := range cp.Grid[i] {
+            m.setMaze(i, j, int(cp.Grid[i][j]))
+        }
+    }
+    setInt(&m.numPaths        , cp.NumPaths        )
+    setInt(&m.mazeLen         , cp.MazeLen         )
+    setInt(&m.maxChecks       , cp.MaxChecks       )
+    setInt(&m.numCheckExceeded, cp.NumCheckExceeded)
+    setInt(&m.numWallPush     , cp.NumWallPush     )
+    setInt(&m.numSolves       , cp.NumSolves       )
+    setInt(&m.sumsolveLength  , cp.SumSolveLength  )
+    clrInt(&m.numThreads)
+    m.rngSource.restore(cp.Seed, cp.Draws)
+}
@@ -0,0 +1,102 @@
+/* clone.go - Copies of a maze
+ *
+ * Clone makes an independent Maze from another, so a caller can keep the unsolved maze while solving, drawing
+ * or writing a copy.  A Snapshot is the lighter copy of just the maze array, the openings and the solution, put
+ * back by Restore, which the search for the best openings starts every trial from rather than undoing the last.
+ */
+package maze
+
+import (
+    "time"
+)
+
+// Snapshot is the maze array, the openings and the saved solution of a Maze at the time Snapshot was called
+type Snapshot struct {
+    rows, cols int
+    begX, endX int
+    begY, endY int
+    cells      []int8
+    solution   []point
+}
+
+// Clone returns a deep copy of the maze: its parameters, the maze array, the openings, the counters, the saved
+// solution and the random number source's state.  The copy has no hooks, event stream or overlay and isn't
+// animated, and nothing done to it changes the original.  A source given by WithRandSource isn't shared with the copy, which draws
+// from the built-in source replayed to the same seed and count.
+func (m *Maze) Clone() *Maze {
+    c := newMaze()
+    c.cfg = m.cfg
+    c.cfg.hooks, c.cfg.randSource = Hooks{}, nil
+
+    copyState(c, m)
+    for i := 0; i < getInt(&m.maxX); i++ {
+        for j := 0; j < getInt(&m.maxY); j++ {
+            c.maze[i][j] = int32(m.getMaze(i, j))
+        }
+    }
+    c.rngSource.restore(m.rngSource.state())
+
+    c.genElapsed, c.solveElapsed                     = m.genElapsed, m.solveElapsed
+    c.maxSolveSteps, c.solveSteps, c.budgetExhausted = m.maxSolveSteps, m.solveSteps, m.budgetExhausted
+    c.handedFlag, c.maxAsymmetry                     = m.handedFlag, m.maxAsymmetry
+    c.leftVisited, c.rightVisited                    = m.leftVisited, m.rightVisited
+    c.numStaleChecks, c.staleChecks                  = m.numStaleChecks, append([]point(nil), m.staleChecks...)
+    c.solution                                       = append([]point(nil), m.getSolution()...)
+    return c
+}
+
+// copyState copies the state of maze m that it's written with into c: the generator's state other than the maze
+// array, the random number source and the hooks, the output options and when it was generated.  The counters are
+// read atomically, so m may still be being carved.
+func copyState(c, m *Maze) {
+    c.showFlag, c.viewFlag, c.lookFlag              = m.showFlag, m.viewFlag, m.lookFlag
+    c.width, c.height, c.fps, c.minLen              = m.width, m.height, m.fps, m.minLen
+    c.carveThreads, c.solveThreads                  = m.carveThreads, m.solveThreads
+    c.seedVal, c.depthVal, c.maxAttempts, c.gap     = m.seedVal, m.depthVal, m.maxAttempts, m.gap
+    for _, n := range [...][2]*int32 {
+        {&c.maxX, &m.maxX}, {&c.maxY, &m.maxY}, {&c.begX, &m.begX}, {&c.endX, &m.endX}, {&c.begY, &m.begY}, {&c.endY, &m.endY},
+        {&c.depth, &m.depth}, {&c.checkFlag, &m.checkFlag}, {&c.solvedFlag, &m.solvedFlag}, {&c.mazeLen, &m.mazeLen},
+        {&c.pathLen, &m.pathLen}, {&c.turnCnt, &m.turnCnt}, {&c.numPaths, &m.numPaths}, {&c.numSolves, &m.numSolves},
+        {&c.numThreads, &m.numThreads}, {&c.numWallPush, &m.numWallPush}, {&c.numMazeCreated, &m.numMazeCreated},
+        {&c.numCheckExceeded, &m.numCheckExceeded}, {&c.maxChecks, &m.maxChecks}, {&c.dspLength, &m.dspLength},
+        {&c.dspNumChecks, &m.dspNumChecks}, {&c.solveLength, &m.solveLength}, {&c.sumsolveLength, &m.sumsolveLength},
+    } {
+        setInt(n[0], getInt(n[1]))
+    }
+    c.setSeed(m.getSeed())
+    c.setGenerated(time.Unix(0, m.getGenerated()))
+
+    c.outputState, c.runStart, c.markersFlag        = m.outputState, m.runStart, m.markersFlag
+    c.corridor, c.loaded, c.mask                    = m.corridor, m.loaded, m.mask
+}
+
+// Snapshot returns a copy of the maze array, the openings and the saved solution, for Restore to put back
+func (m *Maze) Snapshot() *Snapshot {
+    s := &Snapshot{rows: getInt(&m.maxX), cols: getInt(&m.maxY),
+                   begX: getInt(&m.begX), endX: getInt(&m.endX), begY: getInt(&m.begY), endY: getInt(&m.endY)}
+    s.cells = make([]int8, s.rows*s.cols)
+    for i := 0; i < s.rows; i++ {
+        for j := 0; j < s.cols; j++ {
+            s.cells[i*s.cols + j] = int8(m.getMaze(i, j))
+        }
+    }
+    s.solution = append([]point(nil), m.getSolution()...)
+    return s
+}
+
+// Restore returns the maze array, the openings and the saved solution to those of a snapshot of this maze.  Only
+// the locations that differ are changed, so a display or event stream sees just the changes.
+func (m *Maze) Restore(s *Snapshot) {
+    setInt(&m.maxX, s.rows)
+    setInt(&m.maxY, s.cols)
+    setInt(&m.begX, s.begX); setInt(&m.endX, s.endX)
+    setInt(&m.begY, s.begY); setInt(&m.endY, s.endY)
+    for i := 0; i < s.rows; i++ {
+        for j := 0; j < s.cols; j++ {
+            if v := int(s.cells[i*s.cols + j]); m.getMaze(i, j) != v {
+                m.setMaze(i, j, v)
+            }
+        }
+    }
+    m.setSolution(append([]point(nil), s.solution...))
+}
@@ -0,0 +1,79 @@
+/* clone_test.go - Clone and snapshot tests
+ *
+ * Solves, unsolves and regenerates a clone and checks that the original's maze array, ascii output and statistics
+ * are byte for byte what they were, and that Restore puts back a snapshot exactly.
+ */
+package maze
+
+import (
+    "bytes"
+    "context"
+    "reflect"
+    "testing"
+)
+
+// mazeArray returns every location of the maze array, perimeter path included, a byte for each
+func mazeArray(m *Maze) []byte {
+    rows, cols := m.Extent()
+    b := make([]byte, 0, rows*cols)
+    for i := 0; i < rows; i++ {
+        for j := 0; j < cols; j++ {
+            b = append(b, byte(m.Cell(i, j)))
+        }
+    }
+    return b
+}
+
+// countedStats returns the statistics of the maze but for the durations, which go on changing by themselves
+func countedStats(m *Maze) Stats {
+    var stats Stats
+    for _, s := range m.Stats() {
+        if s.Kind != DurationStat {
+            stats = append(stats, s)
+        }
+    }
+    return stats
+}
+
+func TestSolveCloneLeavesOriginal(t *testing.T) {
+    m := goldenMaze(t, WithSize(19, 10), WithSeed(1), WithDepth(5))
+    array, text, stats := mazeArray(m), marshalText(t, m), countedStats(m)
+    c := m.Clone()
+    if got := mazeArray(c); !bytes.Equal(got, array) {
+        t.Fatal("clone's maze array differs from the original's")
+    }
+    c.Unsolve()
+    if _, err := c.Solve(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    c.Unsolve()
+    if _, err := c.Generate(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    if got := mazeArray(m); !bytes.Equal(got, array) {
+        t.Error("solving the clone changed the original's maze array")
+    }
+    if got := marshalText(t, m); !bytes.Equal(got, text) {
+        t.Errorf("solving the clone changed the original's output:\n%s\nwas:\n%s", got, text)
+    }
+    if got := countedStats(m); !reflect.DeepEqual(got, stats) {
+        t.Errorf("solving the clone changed the original's statistics:\n%+v\nwere:\n%+v", got, stats)
+    }
+}
+
+func TestSnapshotRestore(t *testing.T) {
+    m := goldenMaze(t, WithSize(12, 8), WithSeed(42))
+    array, text := mazeArray(m), marshalText(t, m)
+    saved := m.Snapshot()
+    m.Unsolve()
+    if bytes.Equal(mazeArray(m), array) {
+        t.Fatal("unsolving left the maze array as it was")
+    }
+    m.Restore(saved)
+    if got := mazeArray(m); !bytes.Equal(got, array) {
+        t.Error("restored maze array differs from the snapshot")
+    }
+    if got := marshalText(t, m); !bytes.Equal(got, text) {
+        t.Errorf("restored maze written as\n%s\nsnapshot as\n%s", got, text)
+    }
+}
@@ -0,0 +1,108 @@
+/* archive.go - Zip archives of mazes
+ *
+ * With an -o file name ending in .zip the mazes of a run, one or --count of them, are written to a zip
+ * archive, each as a file of its own in the chosen format, maze_001.txt, maze_002.txt and so on, followed by
+ * an index.csv giving the seed, size and solution length of each.  Each maze is compressed into the archive
+ * as soon as it's made, so only the index grows with the number of mazes.  The archive is finished however
+ * the run ends, an interrupted run leaving a valid archive of the mazes made so far.  Every format can be
+ * archived, even those holding a single maze that can't be streamed.
+ */
+package main
+
+import (
+    "os"
+    "io"
+    "fmt"
+    "bytes"
+    "sort"
+    "time"
+    "strings"
+    "archive/zip"
+)
+
+// archive is the zip archive the mazes are written to
+type archive struct {
+    file    *os.File
+    zw      *zip.Writer
+    index   bytes.Buffer            // index.csv, written once the last maze is in
+    entries int
+}
+
+var book *archive                   // the archive with an -o file name ending in .zip
+
+// isArchive returns true if the named output file is a zip archive of mazes
+func isArchive(name string) bool {
+    return strings.HasSuffix(name, ".zip")
+}
+
+// formatExtension returns the file name extension of an output format: the format's own name if it's one of its
+// extensions, or else the first of them in alphabetical order
+func formatExtension(format string) string {
+    var exts []string
+    for ext, f := range formatExtensions {
+        if f == format {
+            exts = append(exts, ext)
+        }
+    }
+    sort.Strings(exts)
+    for _, ext := range exts {
+        if ext == format {
+            return ext
+        }
+    }
+    if len(exts) == 0 {
+        return format
+    }
+    return exts[0]
+}
+
+// openArchive creates the named zip archive
+func openArchive(name string) (*archive, error) {
+    f, err := os.Create(name)
+    if err != nil {
+        return nil, err
+    }
+    a := &archive{file: f, zw: zip.NewWriter(f)}
+    fmt.Fprintf(&a.index, "file,seed,height,width,solution_length\n")
+    return a, nil
+}
+
+// add writes the current maze, without its solution unless --keep-tried is set, to the archive as the next numbered
+// file and adds it to the index
+func (a *archive) add() error {
+    a.entries++
+    name := fmt.Sprintf("maze_%03d.%s", a.entries, formatExtension(formatName))
+    rows, cols := mz.Size()
+    fmt.Fprintf(&a.index, "%s,%d,%d,%d,%d\n", name, mz.CurrentSeed(), rows, cols, mz.SolutionLength())
+    if !keepTriedFlag && !mz.BudgetExhausted() {
+        mz.Unsolve()
+    }
+    w, err := a.create(name)
+    if err == nil {
+        err = outputFormats[formatName](mz, w)
+    }
+    if err == nil {
+        err = a.zw.Flush()
+    }
+    return err
+}
+
+// create starts the named compressed file in the archive, dated now
+func (a *archive) create(name string) (io.Writer, error) {
+    return a.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+}
+
+// close writes the index to the archive, finishes it and closes the file, returning the first error encountered
+func (a *archive) close() error {
+    w, err := a.create("index.csv")
+    if err == nil {
+        _, err = w.Write(a.index.Bytes())
+    }
+    if zerr := a.zw.Close(); err == nil {
+        err = zerr
+    }
+    if ferr := a.file.Close(); err == nil {
+        err = ferr
+    }
+    return err
+}
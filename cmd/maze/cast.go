@@ -0,0 +1,108 @@
+/* cast.go - Asciinema recordings
+ *
+ * Records everything written to the terminal, the escape codes and all, in the asciinema v2 cast format so the
+ * animation can be replayed with asciinema play or embedded in a web page with its player.  The cast is written
+ * as the run goes, a header line with the terminal size the maze was fitted to and then a line for each write
+ * with its time since the recording started.  The display still goes to standard output, so the run can be
+ * watched as it's recorded, or standard output sent elsewhere when it isn't a terminal.
+ */
+package main
+
+import (
+    "io"
+    "os"
+    "fmt"
+    "time"
+    "encoding/json"
+    "unicode/utf8"
+)
+
+var castName string
+
+// castHeader is the first line of an asciinema v2 cast
+type castHeader struct {
+    Version   int               `json:"version"`
+    Width     int               `json:"width"`
+    Height    int               `json:"height"`
+    Timestamp int64             `json:"timestamp"`
+    Env       map[string]string `json:"env"`
+}
+
+// castWriter passes what's written to the terminal on to it and adds it to the cast as an output event, holding back
+// the start of a character split between writes, which a cast's json strings can't hold
+type castWriter struct {
+    w     io.Writer
+    file  *os.File
+    start time.Time
+    part  []byte
+    err   error                     // the first error writing the cast
+}
+
+var cast *castWriter
+
+// startCast creates the cast file, writes its header for a terminal of rows by cols and sends standard output
+// through it
+func startCast(rows, cols int) error {
+    f, err := os.Create(castName)
+    if err != nil {
+        return err
+    }
+    term := os.Getenv("TERM")
+    if term == "" {
+        term = "xterm-256color"
+    }
+    cast = &castWriter{w: os.Stdout, file: f, start: time.Now()}
+    header, _ := json.Marshal(castHeader{2, cols, rows, cast.start.Unix(), map[string]string{"TERM": term}})
+    if _, err = f.Write(append(header, '\n')); err != nil {
+        f.Close()
+        return err
+    }
+    myStdout.Flush()
+    myStdout.Reset(cast)
+    return nil
+}
+
+func (c *castWriter) Write(p []byte) (int, error) {
+    n, err := c.w.Write(p)
+    data := append(c.part, p[:n]...)
+    k := len(data)
+    for i := 1; i < utf8.UTFMax && i <= len(data); i++ {       // a character started near the end but not finished
+        if utf8.RuneStart(data[len(data) - i]) {
+            if !utf8.FullRune(data[len(data) - i:]) {
+                k = len(data) - i
+            }
+            break
+        }
+    }
+    c.part = append([]byte(nil), data[k:]...)
+    c.event(data[:k])
+    return n, err
+}
+
+// event adds an output event of the data to the cast at the time since it started
+func (c *castWriter) event(data []byte) {
+    if len(data) == 0 || c.err != nil {
+        return
+    }
+    line, _ := json.Marshal([]interface{}{time.Since(c.start).Round(time.Microsecond).Seconds(), "o", string(data)})
+    _, c.err = c.file.Write(append(line, '\n'))
+}
+
+// closeCast adds anything held back to the cast and closes it, recording any failure as an I/O error
+func closeCast() {
+    if cast == nil {
+        return
+    }
+    myStdout.Flush()
+    cast.event(cast.part)
+    err := cast.err
+    if cerr := cast.file.Close(); err == nil {
+        err = cerr
+    }
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error writing cast file %s: %v\n", castName, err)
+        setBool(&ioFailed, true)
+    } else {
+        noteWritten(castName)
+    }
+}
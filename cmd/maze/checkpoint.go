@@ -0,0 +1,67 @@
+/* checkpoint.go - Checkpoint and resume files
+ *
+ * Saves the generation state to the --checkpoint file at most once a checkpoint interval, and reads the
+ * --resume file to carry on from where a checkpoint left off.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "time"
+    "encoding/gob"
+    "github.com/Starfleet2/maze"
+)
+
+const checkpointInterval = time.Second
+
+var (
+    checkpointName string
+    resumeName     string
+    resumed        *maze.Checkpoint
+    lastCheckpoint time.Time
+)
+
+// saveCheckpoint writes the current generation state to the checkpoint file if the checkpoint interval has
+// passed (or force is set).  The file is replaced atomically so an interruption never leaves a partial checkpoint.
+func saveCheckpoint(force bool) {
+    if !force && time.Since(lastCheckpoint) < checkpointInterval {
+        return
+    }
+    lastCheckpoint = time.Now()
+
+    tmpName := checkpointName + ".tmp"
+    f, err := os.Create(tmpName)
+    if err == nil {
+        err = gob.NewEncoder(f).Encode(mz.Checkpoint())
+        if closeErr := f.Close(); err == nil {
+            err = closeErr
+        }
+    }
+    if err == nil {
+        err = os.Rename(tmpName, checkpointName)
+    }
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error writing checkpoint file: %v\n", err)   // the display goroutine owns myStdout
+        setBool(&ioFailed, true)
+    }
+}
+
+// loadCheckpoint reads a checkpoint file and sets the maze parameters from it ready for generation to resume
+func loadCheckpoint(name string) error {
+    f, err := os.Open(name)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+    cp := &maze.Checkpoint{}
+    if err := gob.NewDecoder(f).Decode(cp); err != nil {
+        return fmt.Errorf("%s: %v", name, err)
+    }
+    if err := cp.Check(); err != nil {
+        return fmt.Errorf("%s: %v", name, err)
+    }
+    width, height, depth, seed = cp.Width, cp.Height, cp.Depth, int(cp.Seed)
+    resumed = cp
+    return nil
+}
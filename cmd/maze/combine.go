@@ -0,0 +1,53 @@
+/* combine.go - Maze union and intersection
+ *
+ * Combines the passages of two maze files of the same size and reports the result's components and loops.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "github.com/Starfleet2/maze"
+)
+
+var combineOp string
+
+// combineFiles loads the named mazes, combines them and writes the result to the output file, or to stdout if
+// there isn't one, followed by its component sizes and number of loops.  It returns the exit code.
+func combineFiles(op, nameA, nameB string) int {
+    a, err := loadMaze(nameA)
+    if err == nil {
+        var b *maze.Loaded
+        if b, err = loadMaze(nameB); err == nil {
+            if mz, err = maze.NewMaze(maze.WithLoaded(a), maze.WithCorridor(corridor), maze.WithText(textOptions())); err != nil {
+                fmt.Fprintf(os.Stderr, "%v\n", err)
+                return exitIOError
+            }
+            if err := mz.Combine(op, a, b); err != nil {
+                fmt.Fprintf(os.Stderr, "Error combining %s with %s: %v\n", nameA, nameB, err)
+                return exitIOError
+            }
+        }
+    }
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error loading maze: %v\n", err)
+        return exitIOError
+    }
+
+    if outputName != "" {
+        if err := outputMaze(); err != nil {
+            ioError(err)
+        }
+    } else if err := outputFormats[formatName](mz, myStdout); err != nil {
+        setBool(&ioFailed, true)
+    }
+    components := mz.Components()
+    h, w       := mz.Size()
+    mz.ReportComponents(myStdout)
+    fmt.Fprintf(myStdout, "loops: %d\n", mz.Passages() - h*w + components)
+    myStdout.Flush()
+    if getBool(&ioFailed) {
+        return exitIOError
+    }
+    return exitOK
+}
@@ -0,0 +1,75 @@
+/* csvstats.go - Statistics appended to a CSV file
+ *
+ * Appends one row of statistics for the finished maze to a CSV file, writing the header only when the file
+ * is new, so that a batch of runs builds up a spreadsheet.  The file is locked while the row is appended so
+ * that batch runs in parallel never interleave their rows or write the header twice.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "bytes"
+    "encoding/csv"
+)
+
+var statsCsvName string
+
+// csvColumns are the CSV columns in order, which must stay stable so existing files can be appended to
+var csvColumns = []string { "seed", "width", "height", "algorithm", "depth", "solve_length", "turns",
+                            "dead_ends", "junctions", "difficulty", "gen_ms", "solve_ms" }
+
+// csvRow returns the values of the CSV columns for the finished maze and its saved solution route
+func csvRow() []string {
+    deadEnds, junctions      := mz.Degrees()
+    genElapsed, solveElapsed := mz.Elapsed()
+    h, w                     := mz.Size()
+    values := []interface{} { mz.CurrentSeed(), w, h, mz.Algorithm(), mz.Depth(), mz.SolutionLength(), mz.TurnCount(),
+                              deadEnds, junctions, mz.Difficulty(), genElapsed.Milliseconds(), solveElapsed.Milliseconds() }
+    row := make([]string, len(values))
+    for i, v := range values {
+        row[i] = fmt.Sprint(v)
+    }
+    return row
+}
+
+// writeStatsCsv appends the row for the finished maze to the CSV file, preceded by the header if the file is new
+func writeStatsCsv() {
+    var buf bytes.Buffer
+    w := csv.NewWriter(&buf)
+    err := appendLocked(statsCsvName, func(size int64) []byte {
+        if size == 0 {
+            w.Write(csvColumns)
+        }
+        w.Write(csvRow())
+        w.Flush()
+        return buf.Bytes()
+    })
+    if err == nil {
+        err = w.Error()
+    }
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error writing stats to %s: %v\n", statsCsvName, err)
+        setBool(&ioFailed, true)
+    }
+}
+
+// appendLocked opens the named file for appending, creating it if needed, and while holding an exclusive lock
+// on it appends the data returned by fill, which is given the size of the file when the lock was taken
+func appendLocked(name string, fill func(size int64) []byte) error {
+    f, err := os.OpenFile(name, os.O_WRONLY | os.O_APPEND | os.O_CREATE, 0666)
+    if err != nil {
+        return err
+    }
+    if err = lockFile(f); err == nil {
+        var info os.FileInfo
+        if info, err = f.Stat(); err == nil {
+            _, err = f.Write(fill(info.Size()))
+        }
+        unlockFile(f)
+    }
+    if cerr := f.Close(); err == nil {
+        err = cerr
+    }
+    return err
+}
@@ -0,0 +1,45 @@
+/* diff.go - Maze difference report
+ *
+ * Prints the differences between the walls of two maze files, in color when the output is a terminal.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "github.com/Starfleet2/maze"
+    "golang.org/x/crypto/ssh/terminal"
+)
+
+var diffFlag bool
+
+// diffMazes loads the named mazes, prints the differences between their walls and returns the exit code:
+// exitOK if the walls are identical, exitFailure if they differ or the sizes don't match.
+func diffMazes(nameA, nameB string) int {
+    a, err := loadMaze(nameA)
+    if err == nil {
+        var b *maze.Loaded
+        if b, err = loadMaze(nameB); err == nil {
+            return diffLoaded(nameA, a, nameB, b)
+        }
+    }
+    fmt.Fprintf(os.Stderr, "Error loading maze: %v\n", err)
+    return exitIOError
+}
+
+// diffLoaded prints the differences between two loaded mazes, marking locations opened in b with + and closed
+// in b with -, or with green and red backgrounds when the output is a terminal
+func diffLoaded(nameA string, a *maze.Loaded, nameB string, b *maze.Loaded) int {
+    out, opened, closed, err := maze.Diff(a, b, terminal.IsTerminal(int(os.Stdout.Fd())))
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error comparing %s with %s: %v\n", nameB, nameA, err)
+        return exitFailure
+    }
+    if opened + closed == 0 {
+        fmt.Printf("%s and %s have identical walls\n", nameA, nameB)
+        return exitOK
+    }
+    fmt.Printf("%s differs from %s in %d locations: %d opened, %d closed\n", nameB, nameA, opened + closed, opened, closed)
+    os.Stdout.Write(out)
+    return exitFailure
+}
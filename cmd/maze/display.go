@@ -0,0 +1,173 @@
+/* display.go - Terminal display
+ *
+ * Draws a frame of the maze with the configured renderer whenever the library's update hook wakes the display
+ * goroutine.  The renderer is the library's terminal renderer, which draws with VT100 line drawing characters
+ * in the theme's colors, followed by the status line.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "context"
+    "os/signal"
+    "github.com/Starfleet2/maze"
+    "golang.org/x/crypto/ssh/terminal"
+)
+
+var (
+    renderer       maze.Renderer        // draws each frame, set by main
+    displayChan    = make(chan struct{})
+    displayDone    = make(chan struct{})
+    generating     int32                // set while the mazes are being generated, when SIGINT cancels them
+    brailleDisplay bool                 // draw the maze with braille characters, to fit a big one on the terminal
+    glyphDisplay   bool                 // draw the maze with glyphMap's glyphs, emoji unless --glyphs was given
+    glyphMap       = maze.EmojiGlyphs
+)
+
+func putchar(c byte)           {; myStdout.WriteByte(c); }
+
+func setCursorOff()            {; fmt.Fprintf(myStdout, "\033[?25l"        ); myStdout.Flush(); }
+func setCursorOn()             {; fmt.Fprintf(myStdout, "\033[?25h"        ); myStdout.Flush(); }
+func clrScreen()               {; fmt.Fprintf(myStdout, "\033[2J"          ); myStdout.Flush(); }
+
+// restoreTerminal turns the cursor back on and ends the status line, if there's a display, and flushes myStdout
+func restoreTerminal() {
+    if !displayOff() {
+        setCursorOn()
+        putchar('\n')
+    }
+    myStdout.Flush()
+}
+
+// colorReports returns true if the maze is displayed on a terminal, so that the reports printed after it may be
+// colored as the display is
+func colorReports() bool {
+    return !displayOff() && terminal.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// Terminal size assumed when the real one can't be found, as when the input isn't a terminal
+const (
+    defaultRows = 24
+    defaultCols = 80
+)
+
+// getConsoleSize returns the number of rows and columns available in the current terminal window, or an error if
+// the underlying system call fails
+func getConsoleSize() (int, int, error) {
+    cols, rows, err := terminal.GetSize(int(os.Stdout.Fd()))
+    if err != nil {
+        return 0, 0, fmt.Errorf("terminal size unknown: %v", err)
+    }
+    return rows, cols, nil
+}
+
+// displayFootprint returns the terminal space used by the terminal renderer: the rows and columns taken by each cell and
+// the rows and columns needed besides, for the top and left walls, the status line and the cursor line below it.
+func displayFootprint() (cellRows, cellCols, extraRows, extraCols int) {
+    if glyphDisplay {                       // a glyph for each location, each as wide as the glyphs
+        return 2, 2*glyphMap.Width(), 3, glyphMap.Width()
+    }
+    return 2, 4, 3, 1
+}
+
+// fitSize returns the largest maze height and width the display can draw in a terminal of the given size,
+// leaving margin rows and columns free on every side
+func fitSize(rows, cols, margin int) (int, int) {
+    if brailleDisplay {                     // a character for each 2 by 4 locations, a cell being 2 each way
+        return min(maze.MaxHeight, max((4*(rows - 2*margin - 2) - 1)/2, 1)),
+               min(maze.MaxWidth , max(cols - 2*margin - 1, 1))
+    }
+    cellRows, cellCols, extraRows, extraCols := displayFootprint()
+    return min(maze.MaxHeight, max((rows - 2*margin - extraRows)/cellRows, 1)),
+           min(maze.MaxWidth , max((cols - 2*margin - extraCols)/cellCols, 1))
+}
+
+// renderOptions returns the options the display draws the maze with: the theme's colors, the margin and the -b and
+// -l styles
+func renderOptions() maze.RenderOptions {
+    return maze.RenderOptions{Blank: blankFlag, Look: lookFlag, Margin: margin,
+                              Wall: themeCodes.wall, Path: pathColor(), Solved: themeCodes.solved, Tried: themeCodes.tried, Check: themeCodes.check}
+}
+
+// newTerminalRenderer returns the terminal renderer drawing to myStdout with the display's options and braille
+// characters or glyphs if they were chosen
+func newTerminalRenderer() *maze.TerminalRenderer {
+    r := &maze.TerminalRenderer{W: myStdout, RenderOptions: renderOptions(), Braille: brailleDisplay}
+    if glyphDisplay {
+        r.Glyphs = &glyphMap
+    }
+    if theme.status.isSet() {
+        r.Status = themeCodes.status
+    }
+    return r
+}
+
+// displayMaze draws the current maze with the renderer, then writes the output file, reporting a failure to write
+// it.  A failure writing to the terminal leaves nothing to report it on, so it only cuts the frame short.
+func displayMaze() {
+    renderer.Frame(mz.Grid(), mz.Stats())
+    if toStdout() {
+        return                              // the maze is written to standard output once, when it's finished
+    }
+    if err := outputMaze(); err != nil {
+        ioError(err)
+    }
+}
+
+// displayRoutine prints the maze whenever cells change, as seen on the library's event stream while animating, or a
+// signal on displayChan wakes it for a change outside the cells, coalescing everything pending into one frame.  The
+// display goroutine is the only writer to the terminal while it runs; once displayChan is closed it prints a final
+// frame and closes displayDone.
+func displayRoutine(events <-chan maze.CellEvent) {
+    updates := 0
+    for open := true; open; {
+        select {
+            case <- events:
+            case _, open = <- displayChan:
+        }
+        for n := len(events); n > 0; n-- {     // the changes already made are all in this frame
+            <- events
+        }
+        if open {
+            updates++
+            displayMaze()
+            captureFrame(updates)
+        }
+    }
+    displayMaze()
+    captureFinal(updates + 1)
+    close(displayDone)
+}
+
+// stopDisplay stops the display goroutine after it prints the final frame, and must only be called once
+// every goroutine that calls updateMaze has finished
+func stopDisplay() {
+    close(displayChan)
+    <- displayDone
+}
+
+// wakeDisplay signals displayChan if there is no pending signal, and is the library's update hook
+func wakeDisplay() {
+    select {
+        case displayChan <- struct{}{}:
+        default:
+    }
+}
+
+// interruptRoutine cancels the generation when SIGINT is received while mazes are being generated, so that main
+// stops with the display finished and exits with exitInterrupted.  Any other SIGINT, including a second one, restores
+// the terminal and exits straight away.  The display goroutine may be part way through a frame, so the reset is
+// written straight to os.Stdout rather than myStdout.
+func interruptRoutine(cancel context.CancelFunc) {
+    interrupt := make(chan os.Signal, 1)
+    signal.Notify(interrupt, os.Interrupt)
+    if <- interrupt; getBool(&generating) {
+        cancel()
+        <- interrupt
+    }
+    if !displayOff() {
+        fmt.Fprintf(os.Stdout, "\033(B\033[0m\033[?25h\n")
+    }
+    os.Exit(exitInterrupted)
+}
@@ -0,0 +1,93 @@
+/* frames.go - Numbered png frames of the animation
+ *
+ * Writes the recorded frames to the --frames directory as frame_000001.png, frame_000002.png and so on, each
+ * drawn as the png format draws the maze, for assembling a video with ffmpeg -i dir/frame_%06d.png.  The frames
+ * are numbered from 1 without gaps in the order they were taken, however the sampling thinned them, and those
+ * taken before the maze array was set up are left out so that every image is the same size.  Each frame is
+ * drawn from the recorder's copy of the maze array, so the walls carving threads move while a frame is being
+ * drawn don't tear it.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "path/filepath"
+    "github.com/Starfleet2/maze"
+)
+
+const framePattern = "frame_%06d.png"   // the name of each frame, numbered from 1
+
+var (
+    framesDir     string
+    framesWritten int
+)
+
+// startFrames registers the png frame exporter if --frames was given, creating the directory, and returns an error if
+// it can't be created or already holds a first frame, which would mix the frames of two runs
+func startFrames() error {
+    if framesDir == "" {
+        return nil
+    }
+    if err := os.MkdirAll(framesDir, 0777); err != nil {
+        return fmt.Errorf("Error creating frames directory: %v", err)
+    }
+    if _, err := os.Stat(framePath(1)); err == nil {
+        return fmt.Errorf("%s already holds frames, remove them or choose another directory for --frames", framesDir)
+    }
+    frameSinks = append(frameSinks, writeFrames)
+    return nil
+}
+
+// framePath returns the name of frame n in the frames directory
+func framePath(n int) string {
+    return filepath.Join(framesDir, fmt.Sprintf(framePattern, n))
+}
+
+// shown returns the state of location x, y of the frame as the display shows it, the look ahead checks being hidden
+// as walls unless -l was given
+func (f *frame) shown(x, y int) int {
+    if v := f.at(x, y); v != maze.Check || lookFlag {
+        return v
+    }
+    return maze.Wall
+}
+
+// writeFrames writes each frame the size of the last as the next numbered png file in the frames directory
+func writeFrames(frames []frame) error {
+    if len(frames) == 0 {
+        return nil
+    }
+    last := frames[len(frames) - 1]
+    for k := range frames {
+        if f := &frames[k]; f.rows == last.rows && f.cols == last.cols {
+            if err := writeFrame(framePath(framesWritten + 1), f); err != nil {
+                return err
+            }
+            framesWritten++
+        }
+    }
+    return nil
+}
+
+// writeFrame writes a frame to the named png file
+func writeFrame(name string, f *frame) error {
+    out, err := os.Create(name)
+    if err != nil {
+        return err
+    }
+    err = maze.RenderPNGCells(out, f.rows, f.cols, f.shown, pngOptions())
+    if cerr := out.Close(); err == nil {
+        err = cerr
+    }
+    return err
+}
+
+// printFrames prints how the png frames written are numbered, for an ffmpeg command line
+func printFrames() {
+    if framesWritten > 0 {
+        fmt.Fprintf(myStdout, "png frames: %s to %s, numbered from 1 for ffmpeg -i %s\n",
+                    framePath(1), filepath.Base(framePath(framesWritten)), filepath.Join(framesDir, framePattern))
+        myStdout.Flush()
+    }
+}
@@ -0,0 +1,127 @@
+/* gif.go - Animated gif recording
+ *
+ * Writes the recorded frames to the --gif file as an animated gif, each frame drawn as the png format draws the
+ * maze, --cell-pixels from one cell to the next, with the tried cells and the look ahead checks colored as well.
+ * Only the rectangle that changed since the frame before is stored for each frame, so a long animation of a big
+ * maze stays a reasonable size, and the final frame is held for a while at the end.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "image"
+    "image/gif"
+    "image/color"
+    "github.com/Starfleet2/maze"
+)
+
+const (
+    defaultGIFFrames = 500              // the --max-frames limit for a gif unless one is given
+    gifFinalHold     = 200              // how long the final frame is shown, in hundredths of a second
+)
+
+var (
+    gifName  string
+    gifDelay = 50                       // milliseconds between frames
+)
+
+// gifFrame is how each frame is drawn
+var gifFrame = maze.FrameOptions{
+    Solution: maze.PNGSolutionColor,
+    Tried   : color.RGBA{0xa8, 0xc8, 0xe8, 0xff},
+    Check   : color.RGBA{0xe8, 0xc8, 0x40, 0xff},
+}
+
+// startGIF registers the gif exporter if --gif was given, limiting the frames to defaultGIFFrames unless
+// --max-frames was given too, and returns an error if the delay is out of range
+func startGIF() error {
+    if maxFrames == -1 {
+        maxFrames = 0
+        if gifName != "" {
+            maxFrames = defaultGIFFrames
+        }
+    }
+    if gifName == "" {
+        return nil
+    }
+    if gifDelay < 0 || gifDelay > 60000 {
+        return fmt.Errorf("--gif-delay must be 0 to 60000 milliseconds")
+    }
+    frameSinks = append(frameSinks, writeGIF)
+    return nil
+}
+
+// changed returns the rectangle of the pixels that differ between two images of the same size, which is empty if
+// none do
+func changed(prev, img *image.Paletted) image.Rectangle {
+    r := image.Rectangle{Min: img.Rect.Max, Max: img.Rect.Min}
+    for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+        for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+            if k := img.PixOffset(x, y); prev.Pix[k] != img.Pix[k] {
+                r.Min.X, r.Min.Y = min(r.Min.X, x), min(r.Min.Y, y)
+                r.Max.X, r.Max.Y = max(r.Max.X, x + 1), max(r.Max.Y, y + 1)
+            }
+        }
+    }
+    if r.Empty() {
+        return image.Rectangle{}
+    }
+    return r
+}
+
+// crop returns a copy of the rectangle r of the image, so the image itself needn't be kept
+func crop(img *image.Paletted, r image.Rectangle) *image.Paletted {
+    c := image.NewPaletted(r, img.Palette)
+    for y := r.Min.Y; y < r.Max.Y; y++ {
+        copy(c.Pix[c.PixOffset(r.Min.X, y):c.PixOffset(r.Max.X, y)], img.Pix[img.PixOffset(r.Min.X, y):img.PixOffset(r.Max.X, y)])
+    }
+    return c
+}
+
+// writeGIF writes the frames to the gif file, each frame after the first holding just what changed since the one
+// before.  Frames in which nothing changed are dropped, adding their delay to the frame before.
+func writeGIF(frames []frame) error {
+    if len(frames) == 0 {
+        return nil
+    }
+    last  := frames[len(frames) - 1]
+    anim  := &gif.GIF{}
+    delay := max(1, (gifDelay + 5)/10)
+    var prev *image.Paletted
+    frame := gifFrame
+    frame.WallPx = wallPx
+    for k := range frames {
+        f := &frames[k]
+        if f.rows != last.rows || f.cols != last.cols {
+            continue                                // taken before the maze array was set up
+        }
+        img, kept := maze.ImageCells(f.rows, f.cols, f.at, cellPixels, frame), (*image.Paletted)(nil)
+        if prev == nil {
+            anim.Config, kept = image.Config{ColorModel: img.Palette, Width: img.Rect.Dx(), Height: img.Rect.Dy()}, img
+        } else if r := changed(prev, img); r.Empty() {
+            anim.Delay[len(anim.Delay) - 1] = min(anim.Delay[len(anim.Delay) - 1] + delay, 0xffff)
+            continue
+        } else {
+            kept = crop(img, r)
+        }
+        prev          = img
+        anim.Image    = append(anim.Image, kept)
+        anim.Delay    = append(anim.Delay, delay)
+        anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+    }
+    anim.Delay[len(anim.Delay) - 1] = gifFinalHold
+
+    f, err := os.Create(gifName)
+    if err != nil {
+        return err
+    }
+    err = gif.EncodeAll(f, anim)
+    if cerr := f.Close(); err == nil {
+        err = cerr
+    }
+    if err == nil {
+        noteWritten(gifName)
+    }
+    return err
+}
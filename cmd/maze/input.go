@@ -0,0 +1,73 @@
+/* input.go - Maze input files
+ *
+ * Reads maze files in the portable ascii format, or json or the binary format if the name ends in .json or .bin,
+ * optionally run length encoded or gzip compressed, or images of mazes ending in .png, .jpg or .jpeg.
+ */
+package main
+
+import (
+    "io"
+    "os"
+    "fmt"
+    "bytes"
+    "strings"
+    "compress/gzip"
+    "github.com/Starfleet2/maze"
+)
+
+var imageCellPx int                 // pixels from one wall line to the next of an image loaded, 0 to find them
+
+// readInput returns the contents of the named file, or of standard input for -, decompressing it if the name ends in
+// .gz and decoding it if the name ends in .rle, or .rle.gz
+func readInput(name string) ([]byte, error) {
+    f, err := openInput(name)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    var r io.Reader = f
+    if isCompressed(name) {
+        gz, err := gzip.NewReader(f)
+        if err != nil {
+            return nil, err
+        }
+        defer gz.Close()
+        r = gz
+    }
+    data, err := io.ReadAll(r)
+    if err == nil && isRunLength(name) {
+        data, err = decodeRLE(data)
+    }
+    return data, err
+}
+
+// loadMaze reads and validates the named maze file, in the json or binary format if the name ends in .json or .bin,
+// ignoring any trailing .rle or .gz, and the ascii format otherwise, written with the --charmap characters if they're
+// given.  A binary file must hold just the one maze.  An image is read with the walls found in it, with a warning if
+// it was open on its left and right and read on its side.
+func loadMaze(name string) (*maze.Loaded, error) {
+    data, err := readInput(name)
+    if err != nil {
+        return nil, err
+    }
+    var m *maze.Loaded
+    switch base, _ := fileLayers(name); {
+        case strings.HasSuffix(base, ".json"):
+            m, err = maze.ParseJSON(bytes.NewReader(data))
+        case strings.HasSuffix(base, ".bin"):
+            r := bytes.NewReader(data)
+            if m, err = maze.ParseBinary(r); err == nil && r.Len() > 0 {
+                err = fmt.Errorf("unexpected data after the maze")
+            }
+        case strings.HasSuffix(base, ".png") || strings.HasSuffix(base, ".jpg") || strings.HasSuffix(base, ".jpeg"):
+            if m, err = maze.ParseImage(bytes.NewReader(data), maze.ImageOptions{CellSize: imageCellPx}); err == nil && m.Transposed() && !noWarnings {
+                fmt.Fprintf(os.Stderr, "warning: %s is open on its left and right, so it was read on its side\n", name)
+            }
+        default:
+            m, err = maze.ParseASCII(charMap.Decode(string(data)))
+    }
+    if err != nil {
+        return nil, fmt.Errorf("%s: %v", name, err)
+    }
+    return m, nil
+}
@@ -0,0 +1,79 @@
+/* input_test.go - Input file tests
+ *
+ * Solves a generated maze loaded with -i and reads back the -o file written, checking that it's the maze that
+ * was loaded with its solution marked: the same walls, and a * in as many cells as the solution length, from
+ * the opening in the top border to the one in the bottom, without any of the cells tried.
+ */
+package main
+
+import (
+    "os"
+    "strconv"
+    "strings"
+    "testing"
+    "path/filepath"
+)
+
+// asciiGrid returns the lines of the grid of a maze in the ascii format, after its parameters and header
+func asciiGrid(t *testing.T, text []byte) []string {
+    t.Helper()
+    var grid []string
+    for _, line := range strings.Split(strings.TrimSuffix(string(text), "\n"), "\n") {
+        if !strings.HasPrefix(line, "#") {
+            grid = append(grid, line)
+        }
+    }
+    if len(grid) < 2 {
+        t.Fatalf("no grid in:\n%s", text)
+    }
+    return grid[1:]
+}
+
+// checkSolved checks that solved is the maze written unsolved as unsolved with only its solution marked
+func checkSolved(t *testing.T, unsolved, solved []byte) {
+    t.Helper()
+    before, after := asciiGrid(t, unsolved), asciiGrid(t, solved)
+    if strings.Join(before, "\n") != strings.ReplaceAll(strings.Join(after, "\n"), "*", " ") {
+        t.Fatalf("solved maze has different walls, or cells tried:\n%s\nloaded:\n%s", strings.Join(after, "\n"), strings.Join(before, "\n"))
+    }
+    want, err := strconv.Atoi(paramValue(t, unsolved, "solve_length"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    cells := 0
+    for r := 1; r < len(after); r += 2 {
+        for c := 1; c < len(after[r]); c += 2 {
+            if after[r][c] == '*' {; cells++; }
+        }
+    }
+    if cells != want {
+        t.Errorf("%d cells of the solution marked, want the solution length %d:\n%s", cells, want, strings.Join(after, "\n"))
+    }
+    for _, border := range []string{after[0], after[len(after) - 1]} {
+        if !strings.Contains(border, "*") {
+            t.Errorf("the solution doesn't reach the opening in the border %q", border)
+        }
+    }
+}
+
+func TestLoadWritesSolved(t *testing.T) {
+    dir := t.TempDir()
+    for k, args := range [][]string{{"-h", "6", "-w", "12", "-r", "3", "-d", "4"}, {"-h", "10", "-w", "19", "-r", "1"}} {
+        input, solved := filepath.Join(dir, strconv.Itoa(k) + ".txt"), filepath.Join(dir, strconv.Itoa(k) + "-solved.txt")
+        if stderr, status := runMaze(t, append(args, "-q", "-o", input)...); status != exitOK {
+            t.Fatalf("%v: exit %d generating: %s", args, status, stderr)
+        }
+        if stderr, status := runMaze(t, "-i", input, "-q", "-o", solved); status != exitOK {
+            t.Fatalf("%v: exit %d solving: %s", args, status, stderr)
+        }
+        unsolvedText, err := os.ReadFile(input)
+        if err != nil {
+            t.Fatal(err)
+        }
+        solvedText, err := os.ReadFile(solved)
+        if err != nil {
+            t.Fatal(err)
+        }
+        checkSolved(t, unsolvedText, solvedText)
+    }
+}
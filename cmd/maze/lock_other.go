@@ -0,0 +1,18 @@
+//go:build !unix
+
+/* lock_other.go - Advisory file locks
+ *
+ * There is no flock outside unix, so appends rely on each row being written with a single write.
+ */
+package main
+
+import "os"
+
+// lockFile does nothing where file locks aren't available
+func lockFile(f *os.File) error {
+    return nil
+}
+
+// unlockFile does nothing where file locks aren't available
+func unlockFile(f *os.File) {
+}
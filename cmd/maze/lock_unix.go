@@ -0,0 +1,22 @@
+//go:build unix
+
+/* lock_unix.go - Advisory file locks
+ *
+ * Serializes appends to files shared by parallel runs using flock.
+ */
+package main
+
+import (
+    "os"
+    "syscall"
+)
+
+// lockFile takes an exclusive lock on the open file, waiting for any other holder to release it
+func lockFile(f *os.File) error {
+    return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+// unlockFile releases the lock taken by lockFile
+func unlockFile(f *os.File) {
+    syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
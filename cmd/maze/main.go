@@ -0,0 +1,553 @@
+/* main.go - Maze generation console utility
+ * By Dirk Gates <dirk.gates@icancelli.com>
+ * Copyright 2016-2020 Dirk Gates
+ *
+ * The maze command: parses the command line into the parameters of a maze.Maze, shows the maze being
+ * generated in the terminal and writes it, with its reports, when it's done.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "context"
+    "bufio"
+    "sync/atomic"
+    "github.com/Starfleet2/maze"
+)
+
+const (
+    utsSignOn    = "\n" + "Maze Generation Console Utility "+ maze.Version +
+                   "\n" + "Copyright (c) 2016-2020" +
+                   "\n\n"
+
+    exitOK          = 0     // maze generated and solved meeting all criteria
+    exitFailure     = 1     // the -o output file can't be written, found before generating, or mazes compared by --diff differ
+    exitNotMet      = 2     // minimum path length or asymmetry not met within the attempt limit
+    exitUnsolvable  = 3     // input maze has no solution
+    exitIOError     = 4     // I/O or parse error
+    exitBudget      = 5     // solver step budget exhausted before reaching the exit
+    exitInterrupted = 130   // interrupted by SIGINT
+)
+
+// exitStatuses describes each exit status for --help, in order
+var exitStatuses = []struct {
+    code    int
+    meaning string
+} {
+    {exitOK         , "maze generated and solved meeting all criteria, or mazes compared by --diff are the same"    },
+    {exitFailure    , "an output file can't be written, found before generating, or mazes compared by --diff differ"},
+    {exitNotMet     , "minimum path length or asymmetry not met within the attempt limit"                           },
+    {exitUnsolvable , "loaded maze has no solution"                                                                 },
+    {exitIOError    , "error reading or writing a file, or parsing a maze"                                          },
+    {exitBudget     , "solver step budget exhausted before reaching the exit"                                       },
+    {exitInterrupted, "interrupted by SIGINT"                                                                       },
+}
+
+var (
+    mz                *maze.Maze
+
+    blankFlag         bool
+    keepTriedFlag     bool
+    fitFlag           bool
+    componentsFlag    bool
+    deadEndFlag       bool
+    annotateFlag      bool
+    debugCells        bool
+    margin            int
+    ioFailed          int32
+
+    myStdout          *bufio.Writer
+    outputName        string
+    formatName        string
+    loadName          string
+    raceNames         string
+)
+
+func bool2int(b bool) int      {; if b      {; return 1; }; return 0; }
+func min(x, y    int) int      {; if x <  y {; return x; }; return y; }
+func max(x, y    int) int      {; if x >  y {; return x; }; return y; }
+
+func isEven(x    int) bool     {; return (x & 1) == 0; }
+func isOdd( x    int) bool     {; return (x & 1) != 0; }
+
+func getBool(x *int32)   bool  {; return     atomic. LoadInt32(x) != 0;                }
+func setBool(x *int32, v bool) {;            atomic.StoreInt32(x, int32(bool2int(v))); }
+
+// maze main parses the command line switches and then repeatedly creates and
+// solves mazes until the minimum solution path length criteria is met.
+func main() {
+    rows, cols, sizeErr := getConsoleSize()
+    if sizeErr != nil {
+        rows, cols = defaultRows, defaultCols
+    }
+    myStdout = bufio.NewWriterSize(os.Stdout, rows*cols)
+
+    threads, carveThreads, solveThreads = -1, -1, -1
+    options := []option {
+        {"f", "fps"              , "<frames per second>", "Set refresh rate           (default: none, instant)", &fps            },
+        {"h", "height"           , "<height>"           , "Set maze height            (default: screen height)", &height         },
+        {"w", "width"            , "<width>"            , "Set maze width             (default: screen width )", &width          },
+        {"t", "threads"          , "<threads|auto>"     , "Set maze path thread count (default: per phase    )", parseThreads    },
+        {"" , "carve-threads"    , "<threads>"          , "Set carving thread count   (default: 2, 0 with -r )", &carveThreads   },
+        {"" , "solve-threads"    , "<threads>"          , "Set solving threads        (default: CPUs, 0 with -r)", &solveThreads   },
+        {"d", "depth"            , "<depth>"            , "Set path search depth      (default: 0            )", &depth          },
+        {"" , "algorithm"        , "<name>"             , "Maze algorithm: carve, backtrack   (default: carve)", &algorithm      },
+        {"p", "path"             , "<length>"           , "Set minimum path length    (default: 0            )", &minLen         },
+        {"" , "max-solve-steps"  , "<steps>"            , "Set solver step budget     (default: 0, no limit  )", &maxSolveSteps  },
+        {"" , "count"            , "<mazes>"            , "Mazes to generate, 0 for no limit  (default: 1    )", &mazeCount      },
+        {"" , "corridor"         , "<cells>"            , "Set corridor width in cells (default: 1           )", &corridor       },
+        {"" , "gap"              , "<cells>"            , "Set entrance and exit width (default: 1           )", &gap            },
+        {"" , "attempts"         , "<mazes>"            , "Give up on the path length (default: 0, no limit  )", &maxAttempts    },
+        {"r", "random"           , "<seed>"             , "Set random number seed     (default: current usec )", &seed           },
+        {"" , "key"              , "<key>"              , "Regenerate a maze from its key, overriding -h -w -r", parseKey        },
+        {"s", "show"             , ""                   , "Show intermediate results while path length not met", &showFlag       },
+        {"v", "view"             , ""                   , "Show intermediate results determining maze solution", &viewFlag       },
+        {"l", "look"             , ""                   , "Show look ahead path searches while creating maze  ", &lookFlag       },
+        {"b", "blank"            , ""                   , "Show empty maze as blank vs. lattice work of walls ", &blankFlag      },
+        {"" , "braille-display"  , ""                   , "Draw the display in braille to fit bigger mazes    ", &brailleDisplay },
+        {"" , "glyph-display"    , ""                   , "Draw the display with emoji, or the --glyphs given ", &glyphDisplay   },
+        {"i", "load"             , "<filename>"         , "Load and solve a maze file, - for standard input   ", &loadName       },
+        {"" , "input"            , "<filename>"         , "Load and solve a maze file, the same as -i/--load  ", &loadName       },
+        {"" , "cell-px"          , "<pixels>"           , "Pixels from cell to cell of a png or jpeg -i file  ", &imageCellPx    },
+        {"o", "output"           , "<filename>"         , "Output portable ASCII encoded maze, - for stdout   ", &outputName     },
+        {"" , "stream"           , "<filename>"         , "Write a huge maze as carved, --algorithm sidewinder", &streamName     },
+        {"" , "fit"              , ""                   , "Size the maze to fill the terminal, ignoring -h, -w", &fitFlag        },
+        {"" , "margin"           , "<chars>"            , "Leave a margin around the maze     (default: 0    )", &margin         },
+        {"" , "handedness"       , ""                   , "Report cells visited by left and right hand walkers", &handedFlag     },
+        {"" , "max-asymmetry"    , "<ratio>"            , "Regenerate while hand follower visits exceed ratio ", parseAsymmetry  },
+        {"" , "reveal"           , "<hints>"            , "Also write hint files revealing more of solution   ", &revealCount    },
+        {"" , "solved-output"    , "<filename>"         , "Also write the maze with its solution to a file    ", &solvedName     },
+        {"" , "keep-tried"       , ""                   , "Keep solution and explored dead ends in the output ", &keepTriedFlag  },
+        {"" , "format"           , "<format>"           , "Output format, e.g. ascii or png   (default: ext  )", &formatName     },
+        {"" , "md-columns"       , "<columns>"          , "Widest line of md output code blocks (default: 100)", &mdColumns      },
+        {"" , "md-split"         , ""                   , "Split md output wider than --md-columns into slices", &mdSplit        },
+        {"" , "cell-pixels"      , "<pixels>"           , "Pixels from cell to cell in png output (default: 8)", &cellPixels     },
+        {"" , "png-margin"       , "<pixels>"           , "Margin around png output in pixels (default: 8    )", &pngMargin      },
+        {"" , "wall-px"          , "<pixels>"           , "Wall thickness, png/svg pixels or pdf points       ", &wallPx         },
+        {"" , "tile-sheet"       , "<filename>"         , "4x4 png spritesheet of wall tiles for tiles output ", &tileSheetName  },
+        {"" , "tile-pixels"      , "<pixels>"           , "Pixels of plain tiles in tiles output (default: 16)", &tilePixels     },
+        {"" , "page-size"        , "<size>"             , "PDF page size: a4 or letter        (default: a4   )", &pageSize       },
+        {"" , "pdf-margin"       , "<points>"           , "Margin around pdf output in points (default: 36   )", &pdfMargin      },
+        {"" , "pdf-solution"     , ""                   , "Add the solved maze as a second page in pdf output ", &pdfSolution    },
+        {"" , "tikz-scale"       , "<cm>"               , "Cell size of tikz output in cm     (default: 0.5  )", &tikzScale      },
+        {"" , "tikz-line-width"  , "<points>"           , "Width of tikz output lines in points (default: 0.8)", &tikzLineWidth  },
+        {"" , "tikz-solution"    , ""                   , "Include the solution as a red path in tikz output  ", &tikzSolution   },
+        {"" , "eps-cell"         , "<mm>"               , "Cell size of eps output in mm      (default: 5    )", &epsCellSize    },
+        {"" , "eps-stroke"       , "<mm>"               , "Stroke width of eps output in mm   (default: 0.2  )", &epsStrokeWidth },
+        {"" , "eps-solution"     , ""                   , "Add the solution as a hairline layer in eps output ", &epsSolution    },
+        {"" , "model-cell"       , "<mm>"               , "Cell size of stl and obj models in mm (default: 10)", &modelCellSize  },
+        {"" , "model-height"     , "<mm>"               , "Wall height of stl/obj models in mm   (default: 10)", &modelWallHeight},
+        {"" , "model-wall"       , "<mm>"               , "Wall thickness of stl/obj models in mm (default: 2)", &modelWallWidth },
+        {"" , "braille-solution" , ""                   , "Add the solution as a second block in braille output", &brailleSolution},
+        {"" , "frame-every"      , "<updates>"          , "Record every Nth update for animation (default: 1) ", &frameEvery     },
+        {"" , "max-frames"       , "<frames>"           , "Limit animation frames (default: no limit, gif 500)", &maxFrames      },
+        {"" , "gif"              , "<filename>"         , "Record the generation as an animated gif file      ", &gifName        },
+        {"" , "frames"           , "<directory>"        , "Record the generation as numbered png frame files  ", &framesDir      },
+        {"" , "gif-delay"        , "<ms>"               , "Milliseconds between gif frames    (default: 50   )", &gifDelay       },
+        {"" , "cast"             , "<filename>"         , "Record the terminal output as an asciinema cast    ", &castName       },
+        {"" , "wall-style"       , "<style>"            , "Walls: thin, heavy, double or block (default: thin)", parseWallStyle  },
+        {"" , "charset"          , "<charset>"          , "Character set: ascii or unicode    (default: ascii)", parseCharset    },
+        {"" , "glyphs"           , "<kind=glyph,...>"   , "Glyphs of --glyph-display and glyphs output        ", parseGlyphs     },
+        {"" , "charmap"          , "<kind=char,...>"    , "Replace ascii output chars, e.g. wall=#,path=.     ", parseCharMap    },
+        {"" , "theme"            , "<theme|list>"       , "Color theme, list to show them (default: classic)  ", parseTheme      },
+        {"" , "mark-openings"    , ""                   , "Mark entrance and exit with S and E in ascii output", &markOpenings   },
+        {"" , "checkpoint"       , "<filename>"         , "Periodically save generation state (requires -t 0) ", &checkpointName },
+        {"" , "resume"           , "<filename>"         , "Resume generation from a saved checkpoint          ", &resumeName     },
+        {"" , "mask"             , "<filename>"         , "Maze shape: a text file of # and ., or an image    ", &maskName       },
+        {"" , "mask-invert"      , ""                   , "Swap the cells --mask leaves out for those it keeps", &maskInvert     },
+        {"" , "race"             , "<solver,...>"       , "Race solvers (bfs, astar, wallfollow) on the result", &raceNames      },
+        {"" , "components"       , ""                   , "Color each connected region and report their sizes ", &componentsFlag },
+        {"" , "deadend-depth"    , ""                   , "Heat map of each dead end's distance to a junction ", &deadEndFlag    },
+        {"" , "annotate-branches", ""                   , "Report cells wasted by wrong turns along solution  ", &annotateFlag   },
+        {"" , "branch-markers"   , ""                   , "Mark the 9 worst junctions with digits in output   ", &markersFlag    },
+        {"" , "diff"             , "<a.txt> <b.txt>"    , "Compare the walls of two mazes and show differences", &diffFlag       },
+        {"" , "combine"          , "<op> <a> <b>"       , "Combine two mazes' passages: union or intersect    ", &combineOp      },
+        {"" , "show-params"      , "<filename>"         , "Print the generation parameters embedded in a maze ", &showParamsName },
+        {"" , "stats-fd"         , "<fd>"               , "Write the final stats record to file descriptor fd ", &statsFd        },
+        {"" , "nodes-out"        , "<filename>"         , "Also write each cell's attributes to a csv file    ", &nodesOutName   },
+        {"" , "stats-csv"        , "<filename>"         , "Append a row of stats to a CSV file for each maze  ", &statsCsvName   },
+        {"" , "solution-out"     , "<filename>"         , "Write solution cells and headings to a csv or json ", parseSolutionOut},
+        {"" , "stats-stderr"     , ""                   , "Write the final stats record to stderr             ", &statsStderr    },
+        {"" , "debug-cells"      , ""                   , "List any stale check cells cleared after carving   ", &debugCells     },
+        {"" , "dry-run"          , ""                   , "Print the effective parameters without generating  ", &dryRunFlag     },
+        {"" , "dry-run-json"     , ""                   , "Print the effective parameters as JSON             ", &dryRunJSON     },
+        {"q", "quiet"            , ""                   , "Don't print the summary of the run at exit         ", &quietFlag      },
+        {"" , "no-warnings"      , ""                   , "Don't warn of parameters adjusted to fit the limits", &noWarnings     },
+    }
+    args, err := parseOptions(options, os.Args[1:])
+    if _, ok := maze.CombineOps[combineOp]; err == nil && combineOp != "" && !ok {
+        err = fmt.Errorf("unknown --combine operation %q (valid operations: intersect, union)", combineOp)
+    }
+    if err == nil && (diffFlag || combineOp != "") && len(args) != 2 {
+        err = fmt.Errorf("--diff and --combine require two maze files")
+    } else if err == nil && !diffFlag && combineOp == "" && len(args) > 0 {
+        err = fmt.Errorf("unexpected argument %q", args[0])
+    }
+    if err == errHelp {
+        printUsage(options)
+        os.Exit(exitOK)
+    } else if err != nil {
+        fmt.Fprintf(os.Stderr, "%v\nTry --help for a list of options\n", err)
+        os.Exit(exitIOError)
+    }
+    if themeList {
+        printThemes()
+        os.Exit(exitOK)
+    }
+    if showParamsName != "" {
+        os.Exit(showParams(showParamsName))
+    }
+    prepareTheme()
+    if diffFlag {
+        os.Exit(diffMazes(args[0], args[1]))
+    }
+    if streamName != "" {
+        os.Exit(writeStream(streamName))
+    }
+
+    if !autoThreads && threads >= 0 {
+        if carveThreads < 0 {; carveThreads = threads; }
+        if solveThreads < 0 {; solveThreads = threads; }
+    }
+    if !autoThreads {
+        defaultThreads(seed != 0, checkpointName != "" || resumeName != "")
+    }
+    if mazeKey != nil {
+        if loadName != "" || resumeName != "" || mazeCount != 1 {
+            fmt.Fprintf(os.Stderr, "--key can't be used with -i, --resume or --count\n")
+            os.Exit(exitIOError)
+        }
+        useKey(*mazeKey)                        // the key overrides the parameters that decide the maze
+    }
+    if maskName != "" && (loadName != "" || resumeName != "" || mazeKey != nil) {
+        fmt.Fprintf(os.Stderr, "--mask can't be used with -i, --resume or --key\n")
+        os.Exit(exitIOError)
+    }
+    if maskName != "" && !isImageMask(maskName) {
+        if err := loadMask(maskName); err != nil {  // the mask's size overrides -h and -w
+            fmt.Fprintf(os.Stderr, "Error loading mask: %v\n", err)
+            os.Exit(exitIOError)
+        }
+    }
+    if margin < 0 {
+        fmt.Fprintf(os.Stderr, "--margin must be 0 or more characters\n")
+        os.Exit(exitIOError)
+    }
+    if brailleDisplay && glyphDisplay {
+        fmt.Fprintf(os.Stderr, "--braille-display and --glyph-display can't both be used\n")
+        os.Exit(exitIOError)
+    }
+    sizeGuessed := sizeErr != nil && loadName == "" && resumeName == "" && shapeMask == nil &&  // the maze is sized for the assumed terminal
+                   (fitFlag || height == 0 || width == 0) && !toStdout()
+    maxHeight, maxWidth := fitSize(rows, cols, margin)
+    switch {
+        case toStdout(): maxHeight, maxWidth = useStdout()
+        case fitFlag   : height, width = maxHeight, maxWidth
+    }
+
+    if formatName == "" {
+        formatName = outputFormat(outputName)
+    }
+    if _, ok := outputFormats[formatName]; !ok {
+        fmt.Fprintf(os.Stderr, "unknown output format %q (valid formats: %s)\n", formatName, formatList())
+        os.Exit(exitIOError)
+    }
+    if err := checkImage(); err != nil {
+        fmt.Fprintf(os.Stderr, "%v\n", err)
+        os.Exit(exitIOError)
+    }
+    if outputName != "" && mazeCount == 1 && !toStdout() { // a stream is opened, and checked, once the maze is made
+        if err := checkWritable(outputName); err != nil {
+            fmt.Fprintf(os.Stderr, "Error opening output file: %v\n", err)
+            os.Exit(exitFailure)
+        }
+    }
+    if gifName != "" {
+        if err := checkWritable(gifName); err != nil {
+            fmt.Fprintf(os.Stderr, "Error opening gif file: %v\n", err)
+            os.Exit(exitFailure)
+        }
+    }
+    if solutionOutName != "" {
+        if err := checkWritable(solutionOutName); err != nil {
+            fmt.Fprintf(os.Stderr, "Error opening solution path file: %v\n", err)
+            os.Exit(exitFailure)
+        }
+    }
+    if nodesOutName != "" {
+        if err := checkWritable(nodesOutName); err != nil {
+            fmt.Fprintf(os.Stderr, "Error opening node list file: %v\n", err)
+            os.Exit(exitFailure)
+        }
+    }
+    if castName != "" && toStdout() {
+        fmt.Fprintf(os.Stderr, "--cast records the display, which -o - turns off\n")
+        os.Exit(exitIOError)
+    }
+    if castName != "" {
+        if err := checkWritable(castName); err != nil {
+            fmt.Fprintf(os.Stderr, "Error opening cast file: %v\n", err)
+            os.Exit(exitFailure)
+        }
+    }
+    if solvedName != "" {
+        if err := checkWritable(solvedName); err != nil {
+            fmt.Fprintf(os.Stderr, "Error opening solved output file: %v\n", err)
+            os.Exit(exitFailure)
+        }
+    }
+    if combineOp != "" {
+        os.Exit(combineFiles(combineOp, args[0], args[1]))
+    }
+    var loaded *maze.Loaded
+    if loadName != "" {
+        var err error
+        if loaded, err = loadMaze(loadName); err != nil {
+            fmt.Fprintf(os.Stderr, "Error loading maze: %v\n", err)
+            os.Exit(exitIOError)
+        }
+    }
+
+    if resumeName != "" {
+        if err := loadCheckpoint(resumeName); err != nil {
+            fmt.Fprintf(os.Stderr, "Error loading checkpoint: %v\n", err)
+            os.Exit(exitIOError)
+        }
+    }
+    if revealCount < 0 || (revealCount > 0 && (outputName == "" || toStdout())) {
+        fmt.Fprintf(os.Stderr, "--reveal requires a positive number of hints and an output file (-o) other than -\n")
+        os.Exit(exitIOError)
+    }
+    if mazeCount < 0 || (mazeCount != 1 && (revealCount > 0 || loadName != "" || resumeName != "")) {
+        fmt.Fprintf(os.Stderr, "--count must be 0 for no limit or a positive number of mazes, and can't be used with --reveal, -i or --resume\n")
+        os.Exit(exitIOError)
+    }
+    if solvedName != "" && mazeCount != 1 {
+        fmt.Fprintf(os.Stderr, "--solved-output can't be used with --count\n")
+        os.Exit(exitIOError)
+    }
+    if nodesOutName != "" && mazeCount != 1 {
+        fmt.Fprintf(os.Stderr, "--nodes-out can't be used with --count\n")
+        os.Exit(exitIOError)
+    }
+    if err := startGIF(); err != nil {
+        fmt.Fprintf(os.Stderr, "%v\n", err)
+        os.Exit(exitIOError)
+    }
+    if err := startFrames(); err != nil {
+        fmt.Fprintf(os.Stderr, "%v\n", err)
+        os.Exit(exitIOError)
+    }
+    if err := checkRecording(); err != nil {
+        fmt.Fprintf(os.Stderr, "%v\n", err)
+        os.Exit(exitIOError)
+    }
+    if (checkpointName != "" || resumeName != "") && carveThreads > 0 {
+        fmt.Fprintf(os.Stderr, "--checkpoint and --resume require single threaded carving (-t 0 or --carve-threads 0)\n")
+        os.Exit(exitIOError)
+    }
+
+    var racers []maze.Racer
+    if raceNames != "" {
+        var err error
+        if racers, err = maze.ParseRace(raceNames); err != nil {
+            fmt.Fprintf(os.Stderr, "%v\n", err)
+            os.Exit(exitIOError)
+        }
+    }
+
+    adjusted := clampParams(maxHeight, maxWidth)
+    if resumed != nil && (width != resumed.Width || height != resumed.Height) {
+        fmt.Fprintf(os.Stderr, "Checkpoint maze size %dx%d does not fit the maximum size %dx%d\n", resumed.Width, resumed.Height, maxWidth, maxHeight)
+        os.Exit(exitIOError)
+    }
+    if mazeKey != nil && (width != mazeKey.Width || height != mazeKey.Height) {
+        fmt.Fprintf(os.Stderr, "Key maze size %dx%d does not fit the maximum size %dx%d\n", mazeKey.Width, mazeKey.Height, maxWidth, maxHeight)
+        os.Exit(exitIOError)
+    }
+    if maskName != "" && shapeMask == nil {
+        if err := loadMask(maskName); err != nil {  // an image is scaled to the size settled on
+            fmt.Fprintf(os.Stderr, "Error loading mask: %v\n", err)
+            os.Exit(exitIOError)
+        }
+    }
+    if shapeMask != nil {
+        if h, w := shapeMask.Size(); width != w || height != h {
+            fmt.Fprintf(os.Stderr, "Mask maze size %dx%d does not fit the maximum size %dx%d\n", w, h, maxWidth, maxHeight)
+            os.Exit(exitIOError)
+        }
+    }
+    if loaded != nil {
+        height, width = loaded.Size()
+    }
+    if autoThreads {
+        chooseThreads(checkpointName != "" || resumeName != "")
+    }
+    sized := maze.WithSize(width, height)
+    switch {
+        case loaded  != nil: sized = maze.WithLoaded(loaded)
+        case resumed != nil: sized = maze.WithCheckpoint(resumed)
+        case shapeMask != nil: sized = maze.WithMask(shapeMask)
+    }
+    if err := newMaze(sized); err != nil {
+        fmt.Fprintf(os.Stderr, "%v\n", err)
+        os.Exit(exitIOError)
+    }
+
+    if dryRunFlag || dryRunJSON {
+        printParams(options, rows, cols, adjusted)
+        os.Exit(exitOK)
+    }
+
+    if (mazeCount != 1 || isArchive(outputName)) && outputName != "" {
+        if err := openStream(outputName); err != nil {
+            fmt.Fprintf(os.Stderr, "Error opening output file: %v\n", err)
+            os.Exit(exitFailure)
+        }
+    }
+
+    if castName != "" {
+        if err := startCast(rows, cols); err != nil {
+            fmt.Fprintf(os.Stderr, "Error opening cast file: %v\n", err)
+            os.Exit(exitFailure)
+        }
+    }
+    if displayOff() {
+        renderer = noDisplay{}
+    } else {
+        renderer = newTerminalRenderer()
+        clrScreen()
+        setCursorOff()
+    }
+    ctx, cancel := context.WithCancel(context.Background())
+    setBool(&generating, true)
+    var events <-chan maze.CellEvent       // nil, so never ready, unless animating
+    if fps > 0 {
+        events = mz.Events()
+    }
+    go displayRoutine(events)
+    go interruptRoutine(cancel)
+
+    met, err := mz.Generate(ctx)
+    for made := 1; err == nil && (mazeCount == 0 || made < mazeCount); made++ {
+        if streaming() {
+            if err := writeRecord(); err != nil {
+                ioError(err)
+            }
+        }
+        met, err = mz.Generate(ctx)
+    }
+    setBool(&generating, false)
+    notMet := !met
+    if err != nil {
+        stopDisplay()
+        if streaming() {
+            closeStream()                   // the run is abandoned, so a failure to finish the file is moot
+        }
+        restoreTerminal()
+        if ctx.Err() == nil {              // the generator failed rather than being interrupted
+            fmt.Fprintf(os.Stderr, "Error generating maze: %v\n", err)
+            os.Exit(exitIOError)
+        }
+        os.Exit(exitInterrupted)
+    }
+    if annotateFlag || markersFlag {
+        mz.AnnotateBranches()
+    }
+    if racers != nil {
+        mz.Race(racers)
+    }
+    if componentsFlag {
+        mz.Components()
+    }
+    if deadEndFlag {
+        mz.DeadEnds()
+    }
+    stopDisplay()
+    mz.SaveSolution()
+    if solutionOutName != "" {
+        writeSolutionOut()
+    }
+    if nodesOutName != "" {
+        writeNodesOut()
+    }
+    if streaming() {
+        err = writeRecord()
+        if cerr := closeStream(); err == nil {
+            err = cerr
+        }
+    } else {
+        switch {
+            case keepTriedFlag || mz.BudgetExhausted() || mz.Unsolvable():
+                err = outputMaze()
+            case loadName != "" && outputName != "":                // a loaded maze is written solved
+                err = writeSolved(outputName)
+            default:
+                mz.Unsolve()
+                err = outputMaze()
+        }
+        if err == nil && solvedName != "" {
+            err = writeSolved(solvedName)
+        }
+    }
+    if err != nil {
+        ioError(err)
+    }
+    if revealCount > 0 {
+        writeReveal(revealCount)
+    }
+    if recording() {
+        finishRecording()
+    }
+    restoreTerminal()
+    if racers != nil {
+        mz.ReportRace(myStdout, colorReports())
+    }
+    if componentsFlag {
+        mz.ReportComponents(myStdout)
+    }
+    if deadEndFlag {
+        mz.ReportDeadEnds(myStdout)
+    }
+    if annotateFlag {
+        mz.ReportBranches(myStdout)
+    }
+    if handedFlag {
+        mz.ReportHandedness(myStdout)
+    }
+    if mz.BudgetExhausted() {
+        mz.ReportBudget(myStdout)
+    }
+    if mz.Unsolvable() {
+        mz.ReportUnsolvable(myStdout)
+    }
+    myStdout.Flush()
+    if recording() {
+        printRecording()
+        printFrames()
+    }
+    if debugCells {
+        mz.ReportStaleChecks(myStdout)
+        myStdout.Flush()
+    }
+    printAdjustments(adjusted)                  // after the display, which would clear them from the screen
+    if sizeGuessed && !noWarnings {
+        fmt.Fprintf(os.Stderr, "warning: %v, the maze was sized for %d rows by %d columns\n", sizeErr, rows, cols)
+    }
+    writeStats()
+    if statsCsvName != "" {
+        writeStatsCsv()
+    }
+    closeCast()
+    printSummary()
+
+    switch {
+        case getBool(&ioFailed)  : os.Exit(exitIOError)
+        case mz.BudgetExhausted(): os.Exit(exitBudget)
+        case mz.Unsolvable()     : os.Exit(exitUnsolvable)
+        case notMet              : fmt.Fprintf(os.Stderr, "Minimum path length %d or asymmetry not met in %d attempts\n", minLen, maxAttempts)
+                                   os.Exit(exitNotMet)
+    }
+}
+
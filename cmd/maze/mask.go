@@ -0,0 +1,60 @@
+/* mask.go - Shape mask files
+ *
+ * Reads the --mask file, optionally run length encoded or gzip compressed, a line of # and . for each row of
+ * the maze, the maze taking its size.  A --mask image ending in .png, .gif, .jpg or .jpeg is a silhouette
+ * instead, scaled to the size of the maze with its dark pixels left out.  --mask-invert swaps the cells left
+ * out for those kept, of either kind of mask.
+ */
+package main
+
+import (
+    "fmt"
+    "bytes"
+    "strings"
+    "github.com/Starfleet2/maze"
+)
+
+var (
+    maskName   string
+    maskInvert bool
+    shapeMask  *maze.Mask           // the cells left out of the maze, read from the --mask file
+)
+
+// isImageMask returns true if the named mask file is an image, ignoring any trailing .rle or .gz
+func isImageMask(name string) bool {
+    base, _ := fileLayers(name)
+    for _, ext := range []string{".png", ".gif", ".jpg", ".jpeg"} {
+        if strings.HasSuffix(base, ext) {
+            return true
+        }
+    }
+    return false
+}
+
+// loadMask reads and checks the named mask file and sizes the maze to it, which no other option can change, or for an
+// image scales it to the size of the maze, which must be settled by then
+func loadMask(name string) error {
+    data, err := readInput(name)
+    if err != nil {
+        return err
+    }
+    var k *maze.Mask
+    if isImageMask(name) {
+        k, err = maze.ParseImageMask(bytes.NewReader(data), height, width)
+    } else {
+        k, err = maze.ParseMask(bytes.NewReader(data))
+    }
+    if err == nil && maskInvert {
+        k = k.Inverted()
+    }
+    if err == nil {
+        err = k.Check()
+    }
+    if err != nil {
+        return fmt.Errorf("%s: %v", name, err)
+    }
+    height, width = k.Size()
+    fitFlag = false
+    shapeMask = k
+    return nil
+}
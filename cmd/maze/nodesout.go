@@ -0,0 +1,31 @@
+/* nodesout.go - Node list files
+ *
+ * Writes the cells of the finished maze to the --nodes-out file as a csv node list, each flagged as under the
+ * entrance, over the exit or on the solution, to go with the csv edge list of -o maze.csv for loading the maze
+ * as a graph.  It's written once the solution is saved, so the flags hold however the output file is written.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+)
+
+var nodesOutName string
+
+// writeNodesOut writes the node list file for the finished maze
+func writeNodesOut() {
+    out, err := createOutput(nodesOutName, outputSize())
+    if err == nil {
+        err = mz.RenderNodeCSV(out)
+        if cerr := out.Close(); err == nil {
+            err = cerr
+        }
+    }
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error writing node list to %s: %v\n", nodesOutName, err)
+        setBool(&ioFailed, true)
+        return
+    }
+    noteWritten(nodesOutName)
+}
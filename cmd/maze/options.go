@@ -0,0 +1,194 @@
+/* options.go - Command line option parsing
+ *
+ * GNU style parsing of the option table: --name value, --name=value, -n value, -nvalue and grouped
+ * switches (-sv).  The single dash long spellings accepted by the flag package (-width 40) still work.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "strings"
+    "strconv"
+    "path/filepath"
+)
+
+// option describes one command line option.  value points to the int, string or bool it sets, or is a
+// func(string) error for options that need their own parsing; bool options are switches and take no value.
+type option struct {
+    short string
+    long  string
+    arg   string
+    help  string
+    value interface{}
+}
+
+// errHelp is returned by parseOptions when usage was requested
+var errHelp = fmt.Errorf("help requested")
+
+// takesValue returns true if the option requires a value
+func (o *option) takesValue() bool {
+    _, isBool := o.value.(*bool)
+    return !isBool
+}
+
+// set parses and stores a value for the option, named as it was spelled on the command line
+func (o *option) set(spelling, value string) error {
+    switch v := o.value.(type) {
+        case *int                : n, err := strconv.Atoi(value)
+                                   if err != nil {; return fmt.Errorf("invalid value %q for %s: expected an integer", value, spelling); }
+                                   *v = n
+        case *string             : *v = value
+        case *bool               : b, err := strconv.ParseBool(value)
+                                   if err != nil {; return fmt.Errorf("invalid value %q for %s: expected true or false", value, spelling); }
+                                   *v = b
+        case func(string) error  : if err := v(value); err != nil {; return fmt.Errorf("invalid value %q for %s: %v", value, spelling, err); }
+    }
+    return nil
+}
+
+// printUsage prints the sign on banner and a line for every option
+func printUsage(options []option) {
+    fmt.Printf("%sUsage: %s [options]\nOptions:\n", utsSignOn, filepath.Base(os.Args[0]))
+    for _, o := range options {
+        short := "    "
+        if o.short != "" {
+            short = "-" + o.short + ", "
+        }
+        long := "--" + o.long
+        left := fmt.Sprintf("%s%-*s%s", short, max(10, len(long) + 1), long, o.arg)
+        fmt.Printf("  %-35s%s\n", left, strings.TrimRight(o.help, " "))
+    }
+    fmt.Printf("\nExit status:\n")
+    for _, s := range exitStatuses {
+        fmt.Printf("  %-4d%s\n", s.code, s.meaning)
+    }
+    fmt.Printf("\n")
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+    prev := make([]int, len(b) + 1)
+    cur  := make([]int, len(b) + 1)
+    for j := range prev {
+        prev[j] = j
+    }
+    for i := 1; i <= len(a); i++ {
+        cur[0] = i
+        for j := 1; j <= len(b); j++ {
+            cost := bool2int(a[i-1] != b[j-1])
+            cur[j] = min(min(prev[j] + 1, cur[j-1] + 1), prev[j-1] + cost)
+        }
+        prev, cur = cur, prev
+    }
+    return prev[len(b)]
+}
+
+// unknownOption returns an error for an unrecognized option with a suggestion of the closest valid name
+func unknownOption(options []option, spelling string) error {
+    name := strings.TrimLeft(spelling, "-")
+    if eq := strings.IndexByte(name, '='); eq >= 0 {
+        name = name[:eq]
+    }
+    best, bestDist := "", 0
+    for _, o := range options {
+        d := editDistance(name, o.long)
+        if strings.HasPrefix(o.long, name) {
+            d = 1
+        }
+        if best == "" || d < bestDist {
+            best, bestDist = o.long, d
+        }
+    }
+    if len(name) >= 3 && bestDist <= max(2, len(name)/3) {
+        return fmt.Errorf("unknown option %s, did you mean --%s?", spelling, best)
+    }
+    return fmt.Errorf("unknown option %s", spelling)
+}
+
+// parseOptions parses the command line arguments against the option table and returns the non-option arguments.
+// It returns errHelp if --help, -help or -? was given.
+func parseOptions(options []option, args []string) ([]string, error) {
+    findLong := func(name string) *option {
+        for i := range options {
+            if options[i].long == name || (len(name) == 1 && options[i].short == name) {
+                return &options[i]
+            }
+        }
+        return nil
+    }
+    findShort := func(name byte) *option {
+        for i := range options {
+            if options[i].short == string(name) {
+                return &options[i]
+            }
+        }
+        return nil
+    }
+
+    var rest []string
+    for i := 0; i < len(args); i++ {
+        arg := args[i]
+        switch {
+            case arg == "--":
+                return append(rest, args[i+1:]...), nil
+            case arg == "--help" || arg == "-help" || arg == "-?":
+                return nil, errHelp
+            case len(arg) < 2 || arg[0] != '-':
+                rest = append(rest, arg)
+                continue
+        }
+
+        // long options, including the single dash spellings of the flag package
+        name, value, hasValue := strings.TrimLeft(arg, "-"), "", false
+        if eq := strings.IndexByte(name, '='); eq >= 0 {
+            name, value, hasValue = name[:eq], name[eq+1:], true
+        }
+        if o := findLong(name); o != nil && (strings.HasPrefix(arg, "--") || len(name) > 1 || hasValue) {
+            spelling := "--" + o.long
+            switch {
+                case hasValue:
+                case !o.takesValue():
+                    value = "true"
+                case i + 1 < len(args):
+                    i++
+                    value = args[i]
+                default:
+                    return nil, fmt.Errorf("option %s requires a value", spelling)
+            }
+            if err := o.set(spelling, value); err != nil {
+                return nil, err
+            }
+            continue
+        }
+        if strings.HasPrefix(arg, "--") {
+            return nil, unknownOption(options, "--" + name)
+        }
+
+        // grouped short options: switches followed by at most one option taking a value (-sv, -w40, -sw 40)
+        for k := 1; k < len(arg); k++ {
+            o := findShort(arg[k])
+            if o == nil {
+                return nil, unknownOption(options, arg)
+            }
+            spelling := "-" + o.short
+            if !o.takesValue() {
+                *o.value.(*bool) = true
+                continue
+            }
+            value := strings.TrimPrefix(arg[k+1:], "=")
+            if value == "" {
+                if i + 1 >= len(args) {
+                    return nil, fmt.Errorf("option %s requires a value", spelling)
+                }
+                i++
+                value = args[i]
+            }
+            if err := o.set(spelling, value); err != nil {
+                return nil, err
+            }
+            break
+        }
+    }
+    return rest, nil
+}
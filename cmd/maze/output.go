@@ -0,0 +1,570 @@
+/* output.go - Maze output files
+ *
+ * Writes the finished maze to the -o file in the format chosen by --format or the file name extension, one
+ * of the text formats, an ANSI colored snapshot, a markdown code block, rows of emoji, the compact binary format,
+ * a graphviz graph, a csv edge list, an html page, a pdf, LaTeX or eps document, a png, svg, pbm or pgm image, a
+ * png image drawn with tiles, an stl or obj model for 3D printing or Go or C source code.  The mazes of --count
+ * are streamed to the file one after another, or written to a zip archive.
+ */
+package main
+
+import (
+    "os"
+    "io"
+    "fmt"
+    "sort"
+    "bufio"
+    "strconv"
+    "strings"
+    "compress/gzip"
+    "github.com/Starfleet2/maze"
+)
+
+// outputFormats maps format names to the functions that write the maze in that format
+var outputFormats = map[string]func(m *maze.Maze, w io.Writer) error {
+    "ans"    : renderSnapshot,
+    "ascii"  : (*maze.Maze).RenderASCII,
+    "bin"    : (*maze.Maze).RenderBinary,
+    "edges"  : (*maze.Maze).RenderEdges,
+    "glyphs" : renderGlyphs,
+    "eps"    : renderEPS,
+    "braille": (*maze.Maze).RenderBraille,
+    "csrc"   : (*maze.Maze).RenderCSource,
+    "csv"    : (*maze.Maze).RenderEdgeCSV,
+    "dot"    : (*maze.Maze).RenderDOT,
+    "gosrc"  : (*maze.Maze).RenderGoSource,
+    "html"   : (*maze.Maze).RenderHTML,
+    "json"   : (*maze.Maze).RenderJSON,
+    "md"     : renderMarkdown,
+    "obj"    : renderOBJ,
+    "pbm"    : (*maze.Maze).RenderPBM,
+    "pdf"    : renderPDF,
+    "pgm"    : (*maze.Maze).RenderPGM,
+    "png"    : renderPNG,
+    "stl"    : renderSTL,
+    "svg"    : renderSVG,
+    "tikz"   : renderTikZ,
+    "tiles"  : renderTiles,
+}
+
+// formatExtensions maps file name extensions to the output format they select
+var formatExtensions = map[string]string {
+    "ans"  : "ans",
+    "txt"  : "ascii",
+    "bin"  : "bin",
+    "edges": "edges",
+    "eps"  : "eps",
+    "brl"  : "braille",
+    "c"    : "csrc",
+    "h"    : "csrc",
+    "csv"  : "csv",
+    "dot"  : "dot",
+    "gv"   : "dot",
+    "go"   : "gosrc",
+    "html" : "html",
+    "htm"  : "html",
+    "json" : "json",
+    "md"   : "md",
+    "obj"  : "obj",
+    "pbm"  : "pbm",
+    "pdf"  : "pdf",
+    "pgm"  : "pgm",
+    "png"  : "png",
+    "ps"   : "eps",
+    "stl"  : "stl",
+    "svg"  : "svg",
+    "tex"  : "tikz",
+}
+
+var (
+    mazeCount       = 1             // number of mazes to generate, 0 for no limit
+    stream          *outputFile     // the output file kept open for all of the mazes with --count
+    cellPixels      = maze.DefaultPNGCellSize
+    pngMargin       = 8
+    wallPx          int             // pixels, or points in a pdf, 0 for each format's own thickness
+    pageSize        = "a4"
+    pdfMargin       = maze.DefaultPDFMargin
+    pdfSolution     bool
+    tikzScale       = "0.5"         // centimetres, parsed by tikzOptions
+    tikzLineWidth   = "0.8"         // points
+    tikzSolution    bool
+    epsCellSize     = "5"           // millimetres, parsed by epsOptions
+    epsStrokeWidth  = "0.2"
+    epsSolution     bool
+    modelCellSize   = "10"          // millimetres, parsed by modelOptions
+    modelWallHeight = "10"
+    modelWallWidth  = "2"
+    tileSheetName   string          // the tiles format's spritesheet, none for the plain tiles
+    tilePixels      = maze.DefaultTileSize
+    mdColumns       = maze.DefaultMarkdownColumns
+    mdSplit         bool
+    mdWarned        bool            // the md output has been warned of being too wide, once for the run
+)
+
+// pngOptions returns the png format's options from the command line, drawing whatever part of the solution is
+// left in the maze: all of it with --keep-tried, and the part each hint reveals
+func pngOptions() maze.PNGOptions {
+    return maze.PNGOptions{CellSize: cellPixels, Margin: pngMargin, WallPx: wallPx, Solution: maze.PNGSolutionColor}
+}
+
+// renderPNG writes the maze as a png image
+func renderPNG(m *maze.Maze, w io.Writer) error {
+    return m.RenderPNG(w, pngOptions())
+}
+
+// svgOptions returns the svg format's options from the command line
+func svgOptions() maze.SVGOptions {
+    return maze.SVGOptions{WallPx: wallPx}
+}
+
+// renderSVG writes the maze as an svg drawing
+func renderSVG(m *maze.Maze, w io.Writer) error {
+    return m.RenderSVG(w, svgOptions())
+}
+
+// pdfOptions returns the pdf format's options from the command line
+func pdfOptions() maze.PDFOptions {
+    return maze.PDFOptions{PageSize: pageSize, Margin: pdfMargin, WallPx: wallPx, Solution: pdfSolution}
+}
+
+// renderPDF writes the maze as a pdf document
+func renderPDF(m *maze.Maze, w io.Writer) error {
+    return m.RenderPDF(w, pdfOptions())
+}
+
+// tikzOptions returns the tikz format's options from the command line, or an error if they aren't numbers in range
+func tikzOptions() (maze.TikZOptions, error) {
+    opts := maze.TikZOptions{Solution: tikzSolution}
+    var err error
+    if opts.Scale, err = strconv.ParseFloat(tikzScale, 64); err != nil {
+        return opts, fmt.Errorf("expected a number of centimetres for --tikz-scale")
+    }
+    if opts.LineWidth, err = strconv.ParseFloat(tikzLineWidth, 64); err != nil {
+        return opts, fmt.Errorf("expected a number of points for --tikz-line-width")
+    }
+    return opts, opts.Check()
+}
+
+// renderTikZ writes the maze as a LaTeX document
+func renderTikZ(m *maze.Maze, w io.Writer) error {
+    opts, err := tikzOptions()
+    if err != nil {
+        return err
+    }
+    return m.RenderTikZ(w, opts)
+}
+
+// tileOptions returns the tiles format's options from the command line, reading the spritesheet if there is one, or an
+// error if the sheet can't be read or the tiles are out of range
+func tileOptions() (maze.TileOptions, error) {
+    opts := maze.TileOptions{TileSize: tilePixels}
+    if tileSheetName == "" {
+        return opts, opts.Check()
+    }
+    f, err := os.Open(tileSheetName)
+    if err != nil {
+        return opts, fmt.Errorf("reading tile sheet: %v", err)
+    }
+    defer f.Close()
+    if opts.Sheet, err = maze.ReadTileSheet(f); err != nil {
+        return opts, fmt.Errorf("reading tile sheet %s: %v", tileSheetName, err)
+    }
+    return opts, nil
+}
+
+// renderTiles writes the maze as a png image drawn with tiles
+func renderTiles(m *maze.Maze, w io.Writer) error {
+    opts, err := tileOptions()
+    if err != nil {
+        return err
+    }
+    return m.RenderTiles(w, opts)
+}
+
+// epsOptions returns the eps format's options from the command line, or an error if they aren't numbers in range
+func epsOptions() (maze.EPSOptions, error) {
+    opts := maze.EPSOptions{Solution: epsSolution}
+    var err error
+    if opts.CellSize, err = strconv.ParseFloat(epsCellSize, 64); err != nil {
+        return opts, fmt.Errorf("expected a number of millimetres for --eps-cell")
+    }
+    if opts.StrokeWidth, err = strconv.ParseFloat(epsStrokeWidth, 64); err != nil {
+        return opts, fmt.Errorf("expected a number of millimetres for --eps-stroke")
+    }
+    return opts, opts.Check()
+}
+
+// renderEPS writes the maze as an eps drawing
+func renderEPS(m *maze.Maze, w io.Writer) error {
+    opts, err := epsOptions()
+    if err != nil {
+        return err
+    }
+    return m.RenderEPS(w, opts)
+}
+
+// formatChecks return an error if the options of the format selected are out of range, for the formats that have any
+var formatChecks = map[string]func() error {
+    "eps"  : func() error {; _, err := epsOptions();   return err; },
+    "md"   : func() error {; return markdownOptions().Check();      },
+    "obj"  : func() error {; _, err := modelOptions(); return err; },
+    "pdf"  : func() error {; return pdfOptions().Check();           },
+    "png"  : func() error {; return pngOptions().Check();           },
+    "stl"  : func() error {; _, err := modelOptions(); return err; },
+    "svg"  : func() error {; return svgOptions().Check();           },
+    "tikz" : func() error {; _, err := tikzOptions();  return err; },
+    "tiles": func() error {; _, err := tileOptions();  return err; },
+}
+
+// checkImage returns an error if the options of the format written are out of range, or of the png format if --frames
+// records png files, or if a png or svg image, an html page, a pdf, LaTeX or eps document, a model or source code is
+// to be streamed with --count, which has no way to separate one from the next
+func checkImage() error {
+    switch formatName {
+        case "png", "svg", "html", "pdf", "tikz", "eps", "tiles", "stl", "obj", "gosrc", "csrc":
+            if mazeCount != 1 && outputName != "" && !isArchive(outputName) {
+                return fmt.Errorf("--count can't write more than one maze to a %s file", formatName)
+            }
+    }
+    if check := formatChecks[formatName]; check != nil {
+        if err := check(); err != nil {
+            return err
+        }
+    }
+    if framesDir != "" && formatName != "png" {
+        return pngOptions().Check()
+    }
+    return nil
+}
+
+// modelOptions returns the stl and obj formats' options from the command line, or an error if they aren't numbers in
+// range
+func modelOptions() (maze.ModelOptions, error) {
+    var opts maze.ModelOptions
+    var err error
+    if opts.CellSize, err = strconv.ParseFloat(modelCellSize, 64); err != nil {
+        return opts, fmt.Errorf("expected a number of millimetres for --model-cell")
+    }
+    if opts.WallHeight, err = strconv.ParseFloat(modelWallHeight, 64); err != nil {
+        return opts, fmt.Errorf("expected a number of millimetres for --model-height")
+    }
+    if opts.WallThickness, err = strconv.ParseFloat(modelWallWidth, 64); err != nil {
+        return opts, fmt.Errorf("expected a number of millimetres for --model-wall")
+    }
+    return opts, opts.Check()
+}
+
+// renderSTL writes the maze as a binary stl model
+func renderSTL(m *maze.Maze, w io.Writer) error {
+    opts, err := modelOptions()
+    if err != nil {
+        return err
+    }
+    return m.RenderSTL(w, opts)
+}
+
+// renderOBJ writes the maze as an obj model
+func renderOBJ(m *maze.Maze, w io.Writer) error {
+    opts, err := modelOptions()
+    if err != nil {
+        return err
+    }
+    return m.RenderOBJ(w, opts)
+}
+
+// parseGlyphs sets the glyphs of the glyph display and the glyphs output
+func parseGlyphs(value string) error {
+    var err error
+    glyphMap, err = maze.ParseGlyphMap(value)
+    return err
+}
+
+// markdownOptions returns the md format's options from the command line
+func markdownOptions() maze.MarkdownOptions {
+    return maze.MarkdownOptions{Columns: mdColumns, Split: mdSplit}
+}
+
+// renderMarkdown writes the maze as markdown, warning once if it's wider than --md-columns
+func renderMarkdown(m *maze.Maze, w io.Writer) error {
+    if m.TextColumns() > mdColumns && !mdWarned && !noWarnings {
+        if mdSplit {
+            fmt.Fprintf(os.Stderr, "warning: the maze is %d columns wide, more than --md-columns %d, so it was split into slices\n", m.TextColumns(), mdColumns)
+        } else {
+            fmt.Fprintf(os.Stderr, "warning: the maze is %d columns wide, more than --md-columns %d, --md-split would split it\n", m.TextColumns(), mdColumns)
+        }
+        mdWarned = true
+    }
+    return m.RenderMarkdown(w, markdownOptions())
+}
+
+// renderSnapshot writes the maze as the display draws it, with the stats, as an ANSI colored snapshot
+func renderSnapshot(m *maze.Maze, w io.Writer) error {
+    return m.RenderANSISnapshot(w, renderOptions(), m.Stats())
+}
+
+// renderGlyphs writes the maze as rows of glyphs
+func renderGlyphs(m *maze.Maze, w io.Writer) error {
+    return m.RenderGlyphs(w, glyphMap)
+}
+
+// parseWallStyle selects the thin (line drawn, light is the same), heavy, double or block (solid) wall style for the
+// display and ascii output, the heavy and double lines being box drawing characters
+func parseWallStyle(value string) error {
+    switch value {
+        case "thin", "light": blockWalls, lineStyle = false, maze.LightLines
+        case "heavy"        : blockWalls, lineStyle = false, maze.HeavyLines
+        case "double"       : blockWalls, lineStyle = false, maze.DoubleLines
+        case "block"        : blockWalls, lineStyle = true , maze.LightLines
+        default             : return fmt.Errorf("expected a wall style of thin, light, heavy, double or block")
+    }
+    return nil
+}
+
+// parseCharset selects plain ascii or unicode box drawing characters for the walls of the ascii output
+func parseCharset(value string) error {
+    switch value {
+        case "ascii"  : boxDrawing = false
+        case "unicode": boxDrawing = true
+        default       : return fmt.Errorf("expected a charset of ascii or unicode")
+    }
+    return nil
+}
+
+// parseCharMap sets the characters replacing the usual ones in the ascii output, and in the ascii input read
+func parseCharMap(value string) error {
+    var err error
+    charMap, err = maze.ParseCharMap(value)
+    return err
+}
+
+// outputFile is a buffered output file, optionally run length encoded and gzip compressed, whose Close reports the
+// first error seen
+type outputFile struct {
+    file   *os.File
+    gz     *gzip.Writer
+    rle    *rleWriter
+    buf    *bufio.Writer
+}
+
+// isCompressed returns true if the named file should be gzip compressed (or decompressed)
+func isCompressed(name string) bool {
+    return strings.HasSuffix(name, ".gz")
+}
+
+// isRunLength returns true if the named file should be run length encoded (or decoded), before any compression
+func isRunLength(name string) bool {
+    return strings.HasSuffix(strings.TrimSuffix(name, ".gz"), ".rle")
+}
+
+// fileLayers splits the extensions of the encodings, .rle then .gz, off the end of a file name, returning the name
+// without them and the extensions
+func fileLayers(name string) (string, string) {
+    base := strings.TrimSuffix(name, ".gz")
+    base  = strings.TrimSuffix(base, ".rle")
+    return base, name[len(base):]
+}
+
+// createOutput creates the named output file, or uses standard output for -, wrapping it in a gzip compressor if the
+// name ends in .gz and a run length encoder if it ends in .rle, or .rle.gz
+func createOutput(name string, size int) (*outputFile, error) {
+    f, err := os.Stdout, error(nil)          // - is standard output, which is left open
+    if name != stdoutName {
+        if f, err = os.Create(name); err != nil {
+            return nil, err
+        }
+    }
+    o := &outputFile{file: f}
+    var w io.Writer = f
+    if isCompressed(name) {
+        o.gz = gzip.NewWriter(f)
+        w    = o.gz
+    }
+    if isRunLength(name) {
+        if o.rle, err = newRLEWriter(w); err != nil {
+            f.Close()
+            return nil, err
+        }
+        w = o.rle
+    }
+    o.buf = bufio.NewWriterSize(w, size)
+    return o, nil
+}
+
+func (o *outputFile) Write(p []byte) (int, error) {
+    return o.buf.Write(p)
+}
+
+// Flush writes out everything written so far, through the encoder and the compressor if there are any
+func (o *outputFile) Flush() error {
+    err := o.buf.Flush()
+    if o.rle != nil && err == nil {
+        err = o.rle.Flush()
+    }
+    if o.gz != nil && err == nil {
+        err = o.gz.Flush()
+    }
+    return err
+}
+
+// Close flushes the buffer, the encoder and the compressor then closes the file, unless it's standard output, returning
+// the first error encountered
+func (o *outputFile) Close() error {
+    err := o.buf.Flush()
+    if o.rle != nil {
+        if rleErr := o.rle.Close(); err == nil {
+            err = rleErr
+        }
+    }
+    if o.gz != nil {
+        if gzErr := o.gz.Close(); err == nil {
+            err = gzErr
+        }
+    }
+    if o.file == os.Stdout {
+        return err
+    }
+    if fErr := o.file.Close(); err == nil {
+        err = fErr
+    }
+    return err
+}
+
+// outputFormat returns the format selected by the file name extension, ignoring any trailing .rle or .gz,
+// and defaults to ascii for unknown extensions
+func outputFormat(name string) string {
+    name, _ = fileLayers(name)
+    if i := strings.LastIndexByte(name, '.'); i >= 0 && !strings.ContainsRune(name[i:], os.PathSeparator) {
+        if format, ok := formatExtensions[name[i+1:]]; ok {
+            return format
+        }
+    }
+    return "ascii"
+}
+
+// formatList returns the names of all output formats
+func formatList() string {
+    var names []string
+    for name := range outputFormats {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return strings.Join(names, ", ")
+}
+
+// outputSize returns the size of the buffer for an output file holding the maze
+func outputSize() int {
+    rows, cols := mz.Extent()
+    return rows*cols
+}
+
+// checkWritable returns an error if the named output file can't be created or opened for writing, leaving a file
+// that's already there as it is.  Anything but a regular file or a directory, a named pipe say, is left for the
+// write to find, since opening it may have an effect of its own.
+func checkWritable(name string) error {
+    info, statErr := os.Stat(name)
+    switch {
+        case statErr == nil && info.IsDir()            : return fmt.Errorf("%s is a directory", name)
+        case statErr == nil && !info.Mode().IsRegular(): return nil
+    }
+    f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0666)
+    if err != nil {
+        return err
+    }
+    f.Close()
+    if os.IsNotExist(statErr) {
+        os.Remove(name)
+    }
+    return nil
+}
+
+// ioError reports a failure writing an output file, which makes the exit status exitIOError
+func ioError(err error) {
+    fmt.Fprintf(myStdout, "Error %v\n", err)
+    setBool(&ioFailed, true)
+    myStdout.Flush()
+}
+
+// outputMaze writes the maze to the output file, if any, in the selected output format.  Nothing is written
+// while streaming since each maze is written to the stream once, when it's complete.
+func outputMaze() error {
+    if outputName != "" && !streaming() {
+        return writeOutput(outputName)
+    }
+    return nil
+}
+
+// writeOutput writes the maze to the named file in the selected output format
+func writeOutput(name string) error {
+    outFile, err := createOutput(name, outputSize())
+    if err != nil {
+        return fmt.Errorf("opening output file: %v", err)
+    }
+    err = outputFormats[formatName](mz, outFile)
+    if cerr := outFile.Close(); err == nil {
+        err = cerr
+    }
+    if err != nil {
+        return fmt.Errorf("writing output file: %v", err)
+    }
+    noteWritten(name)
+    return nil
+}
+
+// openStream opens the named output file once for all of the mazes generated with --count, so that a reader
+// of a named pipe sees one maze after another rather than end of file after each, or the zip archive they're
+// written to
+func openStream(name string) error {
+    var err error
+    if isArchive(name) {
+        book, err = openArchive(name)
+        return err
+    }
+    stream, err = createOutput(name, outputSize())
+    return err
+}
+
+// streaming returns true if each maze is written to the stream or the archive when it's complete
+func streaming() bool {
+    return stream != nil || book != nil
+}
+
+// writeRecord writes the current maze, without its solution unless --keep-tried is set, to the stream as a
+// record ending with a blank line and a %% separator, and flushes it through to the reader.  Binary mazes hold
+// their own length, so they're written one straight after another.  In an archive the maze is a file of its own.
+func writeRecord() error {
+    if book != nil {
+        if err := book.add(); err != nil {
+            return fmt.Errorf("writing output file: %v", err)
+        }
+        return nil
+    }
+    if !keepTriedFlag && !mz.BudgetExhausted() {
+        mz.Unsolve()
+    }
+    err := outputFormats[formatName](mz, stream)
+    if err == nil && formatName != "bin" {
+        _, err = fmt.Fprintf(stream, "\n%%%%\n")
+    }
+    if err == nil {
+        err = stream.Flush()
+    }
+    if err != nil {
+        return fmt.Errorf("writing output file: %v", err)
+    }
+    return nil
+}
+
+// closeStream closes the stream, or finishes the archive, after the last maze
+func closeStream() error {
+    noteWritten(outputName)
+    if book != nil {
+        if err := book.close(); err != nil {
+            return fmt.Errorf("writing output file: %v", err)
+        }
+        return nil
+    }
+    if err := stream.Close(); err != nil {
+        return fmt.Errorf("writing output file: %v", err)
+    }
+    return nil
+}
@@ -0,0 +1,181 @@
+/* output_test.go - Output error tests
+ *
+ * Checks that the exit statuses are the ones scripts were promised, that an -o file that can't be written is
+ * found before the maze is generated and ends the run with status 1 and a message, that only the options of the
+ * format written are checked, and that without a terminal the console size is an error main falls back from,
+ * warning that the maze was sized for a guess.  The runs are the test binary running main in a child process.
+ */
+package main
+
+import (
+    "os"
+    "bytes"
+    "errors"
+    "os/exec"
+    "strings"
+    "testing"
+    "path/filepath"
+)
+
+// mazeArgsEnv carries the arguments of main to the child process, separated by newlines
+const mazeArgsEnv = "MAZE_TEST_ARGS"
+
+// TestMazeProcess runs main with the arguments in mazeArgsEnv when the test binary is started by runMaze, and
+// does nothing otherwise
+func TestMazeProcess(t *testing.T) {
+    args, ok := os.LookupEnv(mazeArgsEnv)
+    if !ok {
+        return
+    }
+    os.Args = append([]string{"maze"}, strings.Split(args, "\n")...)
+    main()
+    os.Exit(exitOK)
+}
+
+// runMaze runs main in a child process with the arguments and its standard output going to a file rather than a
+// terminal, and returns what it wrote to standard error and its exit status
+func runMaze(t *testing.T, args ...string) (string, int) {
+    t.Helper()
+    _, stderr, status := pipeMaze(t, nil, args...)
+    return stderr, status
+}
+
+// pipeMaze runs main in a child process with the arguments and the input on its standard input, and returns what it
+// wrote to standard output, which is a file rather than a terminal, and to standard error, and its exit status
+func pipeMaze(t *testing.T, input []byte, args ...string) ([]byte, string, int) {
+    t.Helper()
+    stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer stdout.Close()
+    var stderr bytes.Buffer
+    cmd := exec.Command(os.Args[0], "-test.run=^TestMazeProcess$")
+    cmd.Env    = append(os.Environ(), mazeArgsEnv + "=" + strings.Join(args, "\n"))
+    cmd.Stdin  = bytes.NewReader(input)
+    cmd.Stdout, cmd.Stderr = stdout, &stderr
+    err = cmd.Run()
+    var exit *exec.ExitError
+    status := exitOK
+    switch {
+        case errors.As(err, &exit)  : status = exit.ExitCode()
+        case err != nil             : t.Fatal(err)
+    }
+    out, err := os.ReadFile(stdout.Name())
+    if err != nil {
+        t.Fatal(err)
+    }
+    return out, stderr.String(), status
+}
+
+func TestExitStatuses(t *testing.T) {
+    for _, s := range []struct{ got, want int }{
+        {exitOK, 0}, {exitFailure, 1}, {exitNotMet, 2}, {exitUnsolvable, 3}, {exitIOError, 4}, {exitInterrupted, 130},
+    } {
+        if s.got != s.want {
+            t.Errorf("exit status %d, want %d", s.got, s.want)
+        }
+    }
+    seen := map[int]bool{}
+    for _, s := range exitStatuses {
+        if seen[s.code] {
+            t.Errorf("exit status %d described twice", s.code)
+        }
+        seen[s.code] = true
+    }
+}
+
+func TestUnwritableOutput(t *testing.T) {
+    dir := t.TempDir()
+    for _, name := range []string{filepath.Join(dir, "missing", "maze.txt"), dir} {
+        stderr, status := runMaze(t, "-h", "5", "-w", "10", "-r", "1", "-q", "-o", name)
+        if status != 1 || !strings.Contains(stderr, "Error opening output file") {
+            t.Errorf("-o %s: exit status %d, error %q, want 1 and the file's error", name, status, stderr)
+        }
+    }
+    if os.Geteuid() != 0 {                      // root can write anywhere
+        locked := filepath.Join(dir, "locked")
+        if err := os.Mkdir(locked, 0555); err != nil {
+            t.Fatal(err)
+        }
+        if _, status := runMaze(t, "-h", "5", "-w", "10", "-r", "1", "-q", "-o", filepath.Join(locked, "maze.txt")); status != 1 {
+            t.Errorf("-o in a read only directory: exit status %d, want 1", status)
+        }
+    }
+}
+
+func TestCheckWritable(t *testing.T) {
+    dir  := t.TempDir()
+    kept := filepath.Join(dir, "kept.txt")
+    if err := os.WriteFile(kept, []byte("maze"), 0666); err != nil {
+        t.Fatal(err)
+    }
+    if err := checkWritable(kept); err != nil {
+        t.Errorf("existing file: %v", err)
+    }
+    if b, _ := os.ReadFile(kept); string(b) != "maze" {
+        t.Errorf("existing file rewritten as %q by the check", b)
+    }
+    created := filepath.Join(dir, "new.txt")
+    if err := checkWritable(created); err != nil {
+        t.Errorf("new file: %v", err)
+    }
+    if _, err := os.Stat(created); !os.IsNotExist(err) {
+        t.Errorf("new file left by the check: %v", err)
+    }
+    if err := checkWritable(dir); err == nil {
+        t.Error("directory: no error")
+    }
+}
+
+func TestNoTerminal(t *testing.T) {
+    saved := os.Stdout
+    defer func() {; os.Stdout = saved; }()
+    f, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer f.Close()
+    os.Stdout = f
+    if _, _, err := getConsoleSize(); err == nil {
+        t.Error("console size of a file: no error")
+    }
+    os.Stdout = saved
+
+    out := filepath.Join(t.TempDir(), "maze.txt")
+    stderr, status := runMaze(t, "-r", "1", "-q", "-o", out)
+    if status != exitOK || !strings.Contains(stderr, "the maze was sized for 24 rows by 80 columns") {
+        t.Errorf("sized without a terminal: exit status %d, error %q, want %d and a warning", status, stderr, exitOK)
+    }
+    if _, err := os.Stat(out); err != nil {
+        t.Errorf("maze sized without a terminal not written: %v", err)
+    }
+    if stderr, _ := runMaze(t, "-h", "5", "-w", "10", "-r", "1", "-q", "-o", out); strings.Contains(stderr, "warning") {
+        t.Errorf("sized by -h and -w without a terminal: warned %q", stderr)
+    }
+}
+
+func TestFormatOptionsChecked(t *testing.T) {
+    dir := t.TempDir()
+    for _, tc := range []struct {
+        file  string
+        args  []string
+        error string                            // what the error is about, "" for none
+    }{
+        {"maze.png", []string{"--pdf-margin", "-5"}   , ""   },
+        {"maze.png", []string{"--eps-cell", "wide"}   , ""   },
+        {"maze.png", []string{"--wall-px", "100"}     , "png"},
+        {"maze.pdf", []string{"--pdf-margin", "-5"}   , "pdf"},
+        {"maze.svg", []string{"--tikz-scale", "wide"} , ""   },
+        {"maze.txt", []string{"--wall-px", "100"}     , ""   },
+    } {
+        args := append([]string{"-h", "3", "-w", "4", "-r", "1", "-q", "-o", filepath.Join(dir, tc.file)}, tc.args...)
+        stderr, status := runMaze(t, args...)
+        switch {
+            case tc.error == "" && status != exitOK:
+                t.Errorf("-o %s %v: exit status %d, %q, want the other formats' options left alone", tc.file, tc.args, status, stderr)
+            case tc.error != "" && (status != exitIOError || !strings.Contains(stderr, tc.error)):
+                t.Errorf("-o %s %v: exit status %d, %q, want %d and an error about the %s options", tc.file, tc.args, status, stderr, exitIOError, tc.error)
+        }
+    }
+}
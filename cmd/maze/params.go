@@ -0,0 +1,272 @@
+/* params.go - Maze parameters, their limits and the dry run report
+ *
+ * The maze parameters given on the command line are fitted to the terminal and the maze array by clampParams,
+ * which records every value it lowers so that the adjustment can be reported instead of being made silently,
+ * and then passed to maze.NewMaze, which rejects any that are still invalid.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "strings"
+    "strconv"
+    "runtime"
+    "encoding/json"
+    "github.com/Starfleet2/maze"
+)
+
+// adjustment is a parameter changed from its requested value to fit the limits
+type adjustment struct {
+    Name      string `json:"name"`
+    Requested int    `json:"requested"`
+    Effective int    `json:"effective"`
+}
+
+// Maze areas, in cells, for -t auto: below autoMinCells the spawn overhead and contention in carvePath make threads
+// slower than none, and from autoFullCells on every CPU is used for solving.  Carving contends on the maze more
+// heavily so it is given at most autoCarveThreads.  Measured with BenchmarkAutoThreads on one CPU, generating and solving
+// took 6.1ms at 200 cells with no threads against 17.7ms with one and 22.6ms with two, 279ms at 2,000 cells
+// against 326ms and 322ms, and 6.2s at 10,000 cells against 5.9s and 8.8s, so with one CPU threads don't pay by
+// enough to choose and none are.  The scaling up to autoFullCells across several CPUs is the starting point
+// suggested with the feature until it's measured on such a machine.
+const (
+    autoMinCells     = 2000
+    autoFullCells    = 30000
+    autoCarveThreads = 2
+)
+
+// Carving threads when neither -t nor --carve-threads is given; solving is given one thread per CPU
+const defaultCarveThreads = 2
+
+var (
+    dryRunFlag      bool
+    dryRunJSON      bool
+    noWarnings      bool
+    autoThreads     bool
+    threads         int
+
+    width, height   int
+    fps             int
+    depth           int
+    minLen          int
+    carveThreads    int
+    solveThreads    int
+    maxSolveSteps   int
+    maxAttempts     int
+    seed            int
+    algorithm       = maze.DefaultAlgorithm
+    mazeKey         *maze.MazeKey
+    corridor        = 1
+    gap             = 1
+    maxAsymmetry    float64
+    showFlag        bool
+    viewFlag        bool
+    lookFlag        bool
+    handedFlag      bool
+    brailleSolution bool
+    markOpenings    bool
+    markersFlag     bool
+    blockWalls      bool
+    boxDrawing      bool
+    lineStyle       = maze.LightLines
+    charMap         maze.CharMap
+)
+
+// newMaze makes mz from the command line parameters, with its size set by the sized option
+func newMaze(sized maze.Option) error {
+    m, err := maze.NewMaze(maze.WithFPS(fps), maze.WithDepth(depth), maze.WithCarveThreads(carveThreads),
+                           maze.WithSolveThreads(solveThreads), maze.WithMinSolutionLength(minLen),
+                           maze.WithMaxSolveSteps(maxSolveSteps), maze.WithAttempts(maxAttempts),
+                           maze.WithSeed(int64(seed)), maze.WithCorridor(corridor), maze.WithMaxAsymmetry(maxAsymmetry),
+                           maze.WithAlgorithm(algorithm), sized, maze.WithGap(gap), maze.WithHandedness(handedFlag),
+                           maze.WithDisplay(maze.DisplayOptions{Show: showFlag, View: viewFlag, Look: lookFlag}),
+                           maze.WithText(textOptions()), maze.WithHooks(mazeHooks()))
+    if err != nil {
+        return err
+    }
+    mz = m
+    return nil
+}
+
+// textOptions returns how the maze is to be drawn in the text formats, from the command line parameters
+func textOptions() maze.TextOptions {
+    return maze.TextOptions{MarkOpenings: markOpenings, BlockWalls: blockWalls, BoxDrawing: boxDrawing, LineStyle: lineStyle,
+                            BrailleSolution: brailleSolution, BranchMarkers: markersFlag, CharMap: charMap}
+}
+
+// mazeHooks returns the hooks waking the display, capturing the phases of a recording that isn't animated and saving
+// the checkpoints between paths
+func mazeHooks() maze.Hooks {
+    hooks := maze.Hooks{OnUpdate: wakeDisplay}
+    if recording() && fps == 0 {
+        hooks.OnPhase = capturePhase
+    }
+    if checkpointName != "" {
+        hooks.OnPath = func() {; saveCheckpoint(false); }
+    }
+    return hooks
+}
+
+// parseAsymmetry sets the largest allowed asymmetry ratio, which also turns on the handedness report
+func parseAsymmetry(value string) error {
+    ratio, err := strconv.ParseFloat(value, 64)
+    if err != nil {
+        return fmt.Errorf("expected a ratio of at least 1")
+    }
+    maxAsymmetry, handedFlag = ratio, true
+    return nil
+}
+
+// parseKey records the parameters of a maze key, which override those given individually
+func parseKey(value string) error {
+    k, err := maze.ParseMazeKey(value)
+    if err != nil {
+        return err
+    }
+    mazeKey = &k
+    return nil
+}
+
+// useKey sets the parameters that decide the maze from a key, generating it single threaded at its size and
+// accepting the first maze, so that it's the maze the key was taken from
+func useKey(k maze.MazeKey) {
+    width, height, seed, depth, gap, algorithm = k.Width, k.Height, int(k.Seed), k.Depth, k.Gap, k.Algorithm
+    carveThreads, solveThreads, autoThreads    = 0, 0, false
+    minLen, maxAsymmetry, fitFlag              = 0, 0, false
+}
+
+// parseThreads sets the thread count for both carving and solving, or selects automatic counts for "auto"
+func parseThreads(value string) error {
+    if value == "auto" {
+        autoThreads = true
+        return nil
+    }
+    n, err := strconv.Atoi(value)
+    if err != nil {
+        return fmt.Errorf("expected a thread count or auto")
+    }
+    threads, autoThreads = n, false
+    return nil
+}
+
+// defaultThreads sets the carving and solving thread counts given neither by -t nor by their own option, none if
+// fixedSeed is set so that the maze is the same each time it's generated from the seed, and carving single threaded
+// if singleCarver is set
+func defaultThreads(fixedSeed, singleCarver bool) {
+    carve, solve := defaultCarveThreads, runtime.NumCPU()
+    if fixedSeed {
+        carve, solve = 0, 0
+    }
+    if singleCarver {
+        carve = 0
+    }
+    if carveThreads < 0 {; carveThreads = carve; }
+    if solveThreads < 0 {; solveThreads = solve; }
+}
+
+// autoThreadCount returns the number of threads for a maze of the given number of cells on cpus CPUs, none for
+// small mazes or a single CPU and scaling linearly up to cpus at autoFullCells
+func autoThreadCount(cells, cpus int) int {
+    if cells < autoMinCells || cpus < 2 {
+        return 0
+    }
+    return max(2, min(cpus, cpus * (cells - autoMinCells) / (autoFullCells - autoMinCells)))
+}
+
+// chooseThreads sets the carving and solving thread counts not given explicitly from the maze size, keeping
+// carving single threaded if singleCarver is set
+func chooseThreads(singleCarver bool) {
+    n := autoThreadCount(height * width, runtime.NumCPU())
+    if carveThreads < 0 {
+        carveThreads = min(n, autoCarveThreads)
+        if singleCarver {
+            carveThreads = 0
+        }
+    }
+    if solveThreads < 0 {
+        solveThreads = n
+    }
+}
+
+// clampParams lowers the maze parameters above their limits for a maximum maze size of maxHeight by maxWidth and
+// returns the parameters that were adjusted.  A zero height or width selects the largest that fits and isn't
+// reported, and values below their limits are left for maze.NewMaze to reject.
+func clampParams(maxHeight, maxWidth int) []adjustment {
+    var adjusted []adjustment
+    limit := func(name string, value *int, hi int) {
+        if *value > hi {
+            adjusted = append(adjusted, adjustment{name, *value, hi})
+            *value = hi
+        }
+    }
+    limit("depth"   , &depth   , maze.MaxDepth                                    )
+    limit("fps"     , &fps     , maze.MaxFPS                                      )
+    limit("corridor", &corridor, maze.ArrayHeight                                 )
+    maxHeight = min(maxHeight, maze.ArrayHeight/max(corridor, 1))                       // the widened maze must fit the maze array
+    maxWidth  = min(maxWidth , maze.ArrayWidth /max(corridor, 1))
+    if height == 0 {; height = maxHeight; }
+    if width  == 0 {; width  = maxWidth ; }
+    limit("height"  , &height  , maxHeight                                        )
+    limit("width"   , &width   , maxWidth                                         )
+    if height > 0 && width > 0 {
+        limit("path", &minLen  , maze.MaxMinLength(height, width)                 )
+        limit("gap" , &gap     , width                                            )
+    }
+    return adjusted
+}
+
+// printAdjustments warns on stderr of each parameter that was adjusted, unless warnings are suppressed
+func printAdjustments(adjusted []adjustment) {
+    if noWarnings {
+        return
+    }
+    for _, a := range adjusted {
+        fmt.Fprintf(os.Stderr, "warning: --%s %d adjusted to %d\n", a.Name, a.Requested, a.Effective)
+    }
+}
+
+// printParams prints the effective value of every option, marking those that were adjusted, as aligned text or JSON
+func printParams(options []option, rows, cols int, adjusted []adjustment) {
+    values := map[string]interface{}{}
+    for _, o := range options {
+        if strings.HasPrefix(o.long, "dry-run") {
+            continue
+        }
+        switch v := o.value.(type) {
+            case *int    : values[o.long] = *v
+            case *string : values[o.long] = *v
+            case *bool   : values[o.long] = *v
+        }
+    }
+
+    if dryRunJSON {
+        report := struct {
+            Terminal map[string]int         `json:"terminal"`
+            Options  map[string]interface{} `json:"options"`
+            Adjusted []adjustment           `json:"adjusted"`
+        }{map[string]int{"rows": rows, "cols": cols}, values, adjusted}
+        if report.Adjusted == nil {
+            report.Adjusted = []adjustment{}
+        }
+        enc := json.NewEncoder(os.Stdout)
+        enc.SetIndent("", "  ")
+        enc.Encode(&report)
+        return
+    }
+
+    fmt.Printf("Effective parameters for a %d row by %d column terminal:\n", rows, cols)
+    for _, o := range options {
+        value, ok := values[o.long]
+        if !ok {
+            continue
+        }
+        note := ""
+        for _, a := range adjusted {
+            if a.Name == o.long {
+                note = fmt.Sprintf("adjusted, requested %d", a.Requested)
+            }
+        }
+        fmt.Printf("%s\n", strings.TrimRight(fmt.Sprintf("  --%-20s%-12v%s", o.long, value, note), " "))
+    }
+}
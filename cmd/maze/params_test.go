@@ -0,0 +1,134 @@
+/* params_test.go - Parameter clamping tests
+ *
+ * Checks clampParams at each boundary: every parameter at its limit is left alone, one over is lowered to the
+ * limit and reported, and a zero height or width takes the largest that fits without being reported.  Checks the
+ * thread counts defaultThreads gives those options left unset, and that --key generates a maze its minimum path
+ * length took several attempts at.
+ */
+package main
+
+import (
+    "bytes"
+    "reflect"
+    "runtime"
+    "testing"
+    "github.com/Starfleet2/maze"
+)
+
+// clampCase is a set of parameters, a zero corridor, gap or maximum size being the default, and the adjustments and
+// size clampParams should leave
+type clampCase struct {
+    name                          string
+    height, width, depth, fps     int
+    corridor, gap, minLen         int
+    maxHeight, maxWidth           int
+    want                          []adjustment
+    wantHeight, wantWidth         int
+}
+
+func TestClampParams(t *testing.T) {
+    maxLen := maze.MaxMinLength(10, 20)
+    for _, tc := range []clampCase{
+        {name: "within limits", height: 10, width: 20, wantHeight: 10, wantWidth: 20},
+        {name: "height at limit", height: 50, width: 20, maxHeight: 50, wantHeight: 50, wantWidth: 20},
+        {name: "height over", height: 51, width: 20, maxHeight: 50, wantHeight: 50, wantWidth: 20,
+         want: []adjustment{{"height", 51, 50}}},
+        {name: "width at limit", height: 10, width: 80, maxWidth: 80, wantHeight: 10, wantWidth: 80},
+        {name: "width over", height: 10, width: 81, maxWidth: 80, wantHeight: 10, wantWidth: 80,
+         want: []adjustment{{"width", 81, 80}}},
+        {name: "zero size", maxHeight: 30, maxWidth: 70, wantHeight: 30, wantWidth: 70},
+        {name: "array limit", height: maze.ArrayHeight + 1, width: maze.ArrayWidth + 1, maxHeight: 1000, maxWidth: 1000,
+         wantHeight: maze.ArrayHeight, wantWidth: maze.ArrayWidth,
+         want: []adjustment{{"height", maze.ArrayHeight + 1, maze.ArrayHeight}, {"width", maze.ArrayWidth + 1, maze.ArrayWidth}}},
+        {name: "depth at limit", height: 10, width: 20, depth: maze.MaxDepth, wantHeight: 10, wantWidth: 20},
+        {name: "depth over", height: 10, width: 20, depth: maze.MaxDepth + 1, wantHeight: 10, wantWidth: 20,
+         want: []adjustment{{"depth", maze.MaxDepth + 1, maze.MaxDepth}}},
+        {name: "fps at limit", height: 10, width: 20, fps: maze.MaxFPS, wantHeight: 10, wantWidth: 20},
+        {name: "fps over", height: 10, width: 20, fps: maze.MaxFPS + 1, wantHeight: 10, wantWidth: 20,
+         want: []adjustment{{"fps", maze.MaxFPS + 1, maze.MaxFPS}}},
+        {name: "corridor over", height: 1, width: 1, corridor: maze.ArrayHeight + 1, wantHeight: 1, wantWidth: 1,
+         want: []adjustment{{"corridor", maze.ArrayHeight + 1, maze.ArrayHeight}}},
+        {name: "corridor narrows the size", height: 60, width: 200, corridor: 2, maxHeight: 1000, maxWidth: 1000,
+         wantHeight: maze.ArrayHeight/2, wantWidth: maze.ArrayWidth/2,
+         want: []adjustment{{"height", 60, maze.ArrayHeight/2}, {"width", 200, maze.ArrayWidth/2}}},
+        {name: "gap at width", height: 10, width: 20, gap: 20, wantHeight: 10, wantWidth: 20},
+        {name: "gap over width", height: 10, width: 20, gap: 21, wantHeight: 10, wantWidth: 20,
+         want: []adjustment{{"gap", 21, 20}}},
+        {name: "path at limit", height: 10, width: 20, minLen: maxLen, wantHeight: 10, wantWidth: 20},
+        {name: "path over", height: 10, width: 20, minLen: maxLen + 1, wantHeight: 10, wantWidth: 20,
+         want: []adjustment{{"path", maxLen + 1, maxLen}}},
+        {name: "below limits left", height: -1, width: 20, depth: -1, gap: -1, wantHeight: -1, wantWidth: 20},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            saved := []int{height, width, depth, fps, corridor, gap, minLen}
+            defer func() {
+                height, width, depth, fps, corridor, gap, minLen = saved[0], saved[1], saved[2], saved[3], saved[4], saved[5], saved[6]
+            }()
+            height, width, depth, fps, minLen = tc.height, tc.width, tc.depth, tc.fps, tc.minLen
+            corridor, gap = max(tc.corridor, 1), tc.gap
+            if gap == 0 {; gap = 1; }
+            maxHeight, maxWidth := tc.maxHeight, tc.maxWidth
+            if maxHeight == 0 {; maxHeight = 100; }
+            if maxWidth  == 0 {; maxWidth  = 300; }
+            got := clampParams(maxHeight, maxWidth)
+            if !reflect.DeepEqual(got, tc.want) {
+                t.Errorf("adjusted %v, want %v", got, tc.want)
+            }
+            if height != tc.wantHeight || width != tc.wantWidth {
+                t.Errorf("size %dx%d, want %dx%d", width, height, tc.wantWidth, tc.wantHeight)
+            }
+        })
+    }
+}
+
+func TestDefaultThreads(t *testing.T) {
+    cpus := runtime.NumCPU()
+    for _, tc := range []struct {
+        name                      string
+        carve, solve              int
+        fixedSeed, singleCarver   bool
+        wantCarve, wantSolve      int
+    }{
+        {"unset"               , -1, -1, false, false, defaultCarveThreads, cpus},
+        {"fixed seed"          , -1, -1, true , false, 0                  , 0   },
+        {"checkpoint"          , -1, -1, false, true , 0                  , cpus},
+        {"carving given"       ,  4, -1, false, false, 4                  , cpus},
+        {"solving given"       , -1,  3, false, false, defaultCarveThreads, 3   },
+        {"given with seed"     ,  1,  2, true , false, 1                  , 2   },
+        {"none given"          ,  0,  0, false, false, 0                  , 0   },
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            savedCarve, savedSolve := carveThreads, solveThreads
+            defer func() {; carveThreads, solveThreads = savedCarve, savedSolve; }()
+            carveThreads, solveThreads = tc.carve, tc.solve
+            defaultThreads(tc.fixedSeed, tc.singleCarver)
+            if carveThreads != tc.wantCarve || solveThreads != tc.wantSolve {
+                t.Errorf("%d carving and %d solving threads, want %d and %d", carveThreads, solveThreads, tc.wantCarve, tc.wantSolve)
+            }
+        })
+    }
+}
+
+// TestKeyMinLength generates a maze from the key of one whose first attempt from seed 3 is shorter than -p 60,
+// which useKey leaves out, and checks that it's the maze accepted
+func TestKeyMinLength(t *testing.T) {
+    grid := func(text []byte) []byte {                  // the maze without its parameters
+        last := bytes.LastIndex(text, []byte("\n# ")) + 1
+        return text[last + bytes.IndexByte(text[last:], '\n') + 1:]
+    }
+    generated, stderr, status := pipeMaze(t, nil, "-h", "10", "-w", "19", "-r", "3", "-p", "60", "-q", "-o", "-")
+    if status != exitOK {
+        t.Fatalf("exit %d generating: %s", status, stderr)
+    }
+    if seed := paramValue(t, generated, "seed"); seed == "3" {
+        t.Fatalf("the first attempt was accepted")
+    }
+    key := paramValue(t, generated, "key")
+    again, stderr, status := pipeMaze(t, nil, "--key", key, "-q", "-o", "-")
+    if status != exitOK {
+        t.Fatalf("exit %d generating key %s: %s", status, key, stderr)
+    }
+    if !bytes.Equal(grid(again), grid(generated)) {
+        t.Errorf("key %s generated\n%s\nwant\n%s", key, again, generated)
+    }
+}
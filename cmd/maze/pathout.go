@@ -0,0 +1,81 @@
+/* pathout.go - Solution path files
+ *
+ * Writes just the solution of the finished maze for programs that follow it, such as a robot navigating the
+ * maze: the cells along it in order from the entrance to the exit, in logical cell coordinates, and the heading
+ * taken from each.  The file is csv or json as its name ends, and writing it fails rather than guessing if the
+ * solved cells don't form a single path.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "bytes"
+    "strconv"
+    "strings"
+    "encoding/csv"
+    "encoding/json"
+    "github.com/Starfleet2/maze"
+)
+
+var solutionOutName string
+
+// jsonPathStep is a step of the json solution path file
+type jsonPathStep struct {
+    Row     int    `json:"row"`
+    Col     int    `json:"col"`
+    Heading string `json:"heading"`
+}
+
+// parseSolutionOut sets the solution path file, whose name must end in .csv or .json
+func parseSolutionOut(value string) error {
+    name := strings.ToLower(value)
+    if !strings.HasSuffix(name, ".csv") && !strings.HasSuffix(name, ".json") {
+        return fmt.Errorf("expected a solution path file name ending in .csv or .json")
+    }
+    solutionOutName = value
+    return nil
+}
+
+// solutionPathData returns the contents of the solution path file for the steps in the format its name gives
+func solutionPathData(steps []maze.PathStep) ([]byte, error) {
+    var buf bytes.Buffer
+    if strings.HasSuffix(strings.ToLower(solutionOutName), ".json") {
+        path := make([]jsonPathStep, len(steps))
+        for k, s := range steps {
+            path[k] = jsonPathStep{s.X, s.Y, s.Heading.String()}
+        }
+        h, w := mz.Size()
+        enc  := json.NewEncoder(&buf)
+        err  := enc.Encode(struct {
+            Width  int            `json:"width"`
+            Height int            `json:"height"`
+            Length int            `json:"length"`
+            Path   []jsonPathStep `json:"path"`
+        }{w, h, mz.SolutionLength(), path})
+        return buf.Bytes(), err
+    }
+    w := csv.NewWriter(&buf)
+    w.Write([]string{"step", "row", "col", "heading"})
+    for k, s := range steps {
+        w.Write([]string{strconv.Itoa(k), strconv.Itoa(s.X), strconv.Itoa(s.Y), s.Heading.String()})
+    }
+    w.Flush()
+    return buf.Bytes(), w.Error()
+}
+
+// writeSolutionOut writes the solution path file for the finished maze, which must still be marked solved
+func writeSolutionOut() {
+    steps, err := mz.SolutionPath()
+    var data []byte
+    if err == nil {
+        data, err = solutionPathData(steps)
+    }
+    if err == nil {
+        err = os.WriteFile(solutionOutName, data, 0666)
+    }
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error writing solution path to %s: %v\n", solutionOutName, err)
+        setBool(&ioFailed, true)
+    }
+}
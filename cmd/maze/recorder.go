@@ -0,0 +1,136 @@
+/* recorder.go - Animation frame recorder
+ *
+ * Captures the frames shown by the display for the animation exporters, sampling them so that a long
+ * generation still produces a reasonably sized animation.  Every --frame-every'th update is captured, and if
+ * --max-frames is reached every other captured frame is dropped and the interval doubled, so the frames kept
+ * stay evenly spaced over the whole run.  The final frame, with the solution, is always kept.  Without an
+ * animation (-f 0) the display's updates come whenever it happens to be woken, so a frame is offered as each
+ * phase of the generation begins instead.
+ */
+package main
+
+import (
+    "fmt"
+    "time"
+    "github.com/Starfleet2/maze"
+)
+
+// frame is a snapshot of the maze array taken when the display showed an update
+type frame struct {
+    update  int                     // display update number
+    elapsed time.Duration           // time since recording started
+    state   []byte                  // maze array locations, row by row
+    rows    int
+    cols    int
+}
+
+// frameSink is an animation exporter, called with the recorded frames once the maze is complete
+type frameSink func(frames []frame) error
+
+var (
+    frameEvery  = 1
+    maxFrames   = -1                // -1 until given, for no limit or defaultGIFFrames with --gif
+    frameSinks  []frameSink         // exporters registered from the options, recording is off if there are none
+
+    recordStart time.Time
+    frames      []frame
+    offered     int                 // updates offered to the recorder, captured or not
+    interval    int                 // current capture interval, frameEvery doubled each time frames are thinned
+)
+
+// at returns the state of location x, y in the frame
+func (f *frame) at(x, y int) int {
+    return int(f.state[x*f.cols + y])
+}
+
+// recording returns true if any exporter wants the frames
+func recording() bool {
+    return len(frameSinks) > 0
+}
+
+// checkRecording validates the sampling options
+func checkRecording() error {
+    if frameEvery < 1 {
+        return fmt.Errorf("--frame-every must be at least 1")
+    }
+    if maxFrames < 0 || maxFrames == 1 {
+        return fmt.Errorf("--max-frames must be 0 for no limit or at least 2 for the first and final frames")
+    }
+    return nil
+}
+
+// snapshot returns a frame holding the current maze
+func snapshot(update int) frame {
+    f := frame{update: update, elapsed: time.Since(recordStart)}
+    f.rows, f.cols = mz.Extent()
+    f.state = make([]byte, f.rows*f.cols)
+    for i := 0; i < f.rows; i++ {
+        for j := 0; j < f.cols; j++ {
+            f.state[i*f.cols + j] = byte(mz.Cell(i, j))
+        }
+    }
+    return f
+}
+
+// captureFrame is called by the display for each update and captures it if it falls on the sampling interval,
+// unless the frames are taken at the phases
+func captureFrame(update int) {
+    if recording() && fps > 0 {
+        offerFrame(update)
+    }
+}
+
+// capturePhase is the library's phase hook without an animation, capturing the maze as the last phase left it if
+// it falls on the sampling interval
+func capturePhase(maze.Phase) {
+    offerFrame(offered + 1)
+}
+
+// offerFrame captures an update if it falls on the sampling interval, thinning the frames taken so far when there's
+// no room left for it
+func offerFrame(update int) {
+    if offered == 0 {
+        recordStart, interval = time.Now(), frameEvery
+    }
+    offered++
+    for (offered - 1) % interval == 0 {
+        if maxFrames == 0 || len(frames) < maxFrames - 1 {  // leave room for the final frame
+            frames = append(frames, snapshot(update))
+            return
+        }
+        kept := frames[:0]
+        for i := 0; i < len(frames); i += 2 {
+            kept = append(kept, frames[i])
+        }
+        frames, interval = kept, interval*2
+    }
+}
+
+// captureFinal is called by the display for its final frame, which is always captured
+func captureFinal(update int) {
+    if !recording() {
+        return
+    }
+    if offered == 0 {
+        recordStart, interval = time.Now(), frameEvery
+    }
+    offered++
+    frames = append(frames, snapshot(update))
+}
+
+// finishRecording passes the recorded frames to each exporter, recording any failure as an I/O error
+func finishRecording() {
+    for _, sink := range frameSinks {
+        if err := sink(frames); err != nil {
+            fmt.Fprintf(myStdout, "Error writing animation: %v\n", err)
+            setBool(&ioFailed, true)
+        }
+    }
+    myStdout.Flush()
+}
+
+// printRecording prints the number of frames captured and skipped
+func printRecording() {
+    fmt.Fprintf(myStdout, "frames: %d captured, %d skipped, final interval %d updates\n", len(frames), offered - len(frames), interval)
+    myStdout.Flush()
+}
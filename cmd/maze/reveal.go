@@ -0,0 +1,32 @@
+/* reveal.go - Progressive solution hints
+ *
+ * Writes a series of output files showing more and more of the solution from the entrance, the first
+ * with none of it and the last with all of it, for handing out as escalating hints.
+ */
+package main
+
+import (
+    "fmt"
+    "strings"
+)
+
+var revealCount int
+
+// hintName returns the name of hint k of n for an output file name, e.g. maze.txt.gz becomes maze_hint2of4.txt.gz
+func hintName(name string, k, n int) string {
+    name, layers := fileLayers(name)
+    ext := ""
+    if i := strings.LastIndexByte(name, '.'); i > strings.LastIndexAny(name, "/\\") {
+        name, ext = name[:i], name[i:]
+    }
+    return fmt.Sprintf("%s_hint%dof%d%s%s", name, k, n, ext, layers)
+}
+
+// writeReveal writes n hint files, each named for the output file with its number
+func writeReveal(n int) {
+    mz.RevealHints(n, func(k int) {
+        if err := writeOutput(hintName(outputName, k, n)); err != nil {
+            ioError(err)
+        }
+    })
+}
@@ -0,0 +1,118 @@
+/* rle.go - Run length encoded files
+ *
+ * Output files whose names end in .rle, before any .gz, are run length encoded, which shrinks the long runs of
+ * spaces and wall characters in the text of a big maze to a few bytes each.  After a header line describing the
+ * encoding, a run is a 0xff byte, which never appears in utf-8, then a count byte from 1 to 255 and the utf-8
+ * character repeated, longer runs being split into runs of 255.  Runs too short to be worth it are left as they
+ * are, so the file is still mostly readable text.  Input files named the same way are decoded before they're read.
+ */
+package main
+
+import (
+    "io"
+    "fmt"
+    "bytes"
+    "unicode/utf8"
+)
+
+const (
+    rleHeader   = "maze rle 1: a 0xff byte starts a run, a count byte from 1 to 255 and the utf-8 character repeated\n"
+    rleMark     = 0xff
+    rleMaxCount = 255
+)
+
+// rleWriter run length encodes the text written to it, holding back the run in progress and any partial character
+// until more is written or it's flushed
+type rleWriter struct {
+    w     io.Writer
+    char  []byte                    // the character of the run in progress
+    count int
+    part  []byte                    // the start of a character split between writes
+}
+
+// newRLEWriter returns a run length encoder writing to w, having written the header
+func newRLEWriter(w io.Writer) (*rleWriter, error) {
+    _, err := io.WriteString(w, rleHeader)
+    return &rleWriter{w: w}, err
+}
+
+func (r *rleWriter) Write(p []byte) (int, error) {
+    data := p
+    if len(r.part) > 0 {
+        data = append(r.part, p...)
+        r.part = nil
+    }
+    for len(data) > 0 {
+        if !utf8.FullRune(data) {
+            r.part = append([]byte(nil), data...)
+            break
+        }
+        _, size := utf8.DecodeRune(data)
+        if r.count == rleMaxCount || r.count > 0 && !bytes.Equal(data[:size], r.char) {
+            if err := r.emit(); err != nil {
+                return 0, err
+            }
+        }
+        if r.count == 0 {
+            r.char = append(r.char[:0], data[:size]...)
+        }
+        r.count++
+        data = data[size:]
+    }
+    return len(p), nil
+}
+
+// emit writes the run in progress, as a run if that's shorter than the characters themselves, and always for a 0xff
+// byte, which can't be written as itself
+func (r *rleWriter) emit() error {
+    var err error
+    switch {
+        case r.count == 0                                                : return nil
+        case 2 + len(r.char) < r.count*len(r.char) || r.char[0] == rleMark: _, err = r.w.Write(append([]byte{rleMark, byte(r.count)}, r.char...))
+        default                                                          : _, err = r.w.Write(bytes.Repeat(r.char, r.count))
+    }
+    r.count = 0
+    return err
+}
+
+// Flush writes the run in progress, so a reader sees everything written so far, which splits a run that goes on
+// after it.  A partial character is held back until the rest of it is written.
+func (r *rleWriter) Flush() error {
+    return r.emit()
+}
+
+// Close writes the run in progress and then any partial character left at the end as runs of single bytes
+func (r *rleWriter) Close() error {
+    part := r.part
+    r.part = nil
+    for _, b := range part {
+        if err := r.emit(); err != nil {
+            return err
+        }
+        r.char, r.count = append(r.char[:0], b), 1
+    }
+    return r.emit()
+}
+
+// decodeRLE returns the text of a run length encoded file
+func decodeRLE(data []byte) ([]byte, error) {
+    if !bytes.HasPrefix(data, []byte(rleHeader)) {
+        return nil, fmt.Errorf("missing the run length encoding header")
+    }
+    var text []byte
+    for k := len(rleHeader); k < len(data); {
+        if data[k] != rleMark {
+            text = append(text, data[k])
+            k++
+            continue
+        }
+        switch {
+            case k + 2 >= len(data): return nil, fmt.Errorf("run length encoding: the run at byte %d is cut short", k)
+            case data[k + 1] == 0  : return nil, fmt.Errorf("run length encoding: a run of no characters at byte %d", k)
+        }
+        _, size := utf8.DecodeRune(data[k + 2:])
+        text = append(text, bytes.Repeat(data[k + 2:k + 2 + size], int(data[k + 1]))...)
+        k += 2 + size
+    }
+    return text, nil
+}
@@ -0,0 +1,35 @@
+/* showparams.go - Embedded parameter report
+ *
+ * Prints the generation parameters embedded in the comment block of an ascii maze file, so that a maze can be
+ * attributed, or regenerated from its key, without loading it into anything else.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+)
+
+var showParamsName string
+
+// showParams prints the embedded parameters of the named maze file, one name and value to a line and any other
+// comments as they were written, and returns the exit code
+func showParams(name string) int {
+    m, err := loadMaze(name)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error loading maze: %v\n", err)
+        return exitIOError
+    }
+    params := m.Params()
+    if len(params) == 0 {
+        fmt.Fprintf(os.Stderr, "%s has no embedded parameters\n", name)
+    }
+    for _, p := range params {
+        if p.Name == "" {
+            fmt.Printf("#%s\n", p.Value)
+        } else {
+            fmt.Printf("%-14s %s\n", p.Name + ":", p.Value)
+        }
+    }
+    return exitOK
+}
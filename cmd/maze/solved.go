@@ -0,0 +1,21 @@
+/* solved.go - Solved copy of the output
+ *
+ * Writes a second copy of the finished maze with its solution marked, beside the unsolved output file, so one
+ * run gives both the puzzle and its answer.  The copy is made from the solution saved once the maze is solved,
+ * without the tried cells or anything else the run leaves in the maze, so the two files have the same header
+ * and differ only in the solution's cells.
+ */
+package main
+
+var solvedName string
+
+// writeSolved writes the maze with nothing but its saved solution marked to the named file in the selected output
+// format, then puts the maze back as it was
+func writeSolved(name string) error {
+    saved := mz.Snapshot()
+    defer mz.Restore(saved)
+    mz.Unsolve()
+    var err error
+    mz.RevealHints(1, func(int) {; err = writeOutput(name); })       // the only hint of one shows all of the solution
+    return err
+}
@@ -0,0 +1,37 @@
+/* stats.go - Generation statistics record
+ *
+ * Writes the statistics shown on the status line as a machine readable record once the maze is complete,
+ * with the key that regenerates the maze when there is one.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "github.com/Starfleet2/maze"
+)
+
+var (
+    statsFd     int                  // file descriptor the stats record is written to when complete, 0 for none
+    statsStderr bool
+)
+
+// writeStats writes the final statistics as a single line of space separated name=value pairs to the stats
+// file descriptor, if one was given
+func writeStats() {
+    fd := statsFd
+    if statsStderr {
+        fd = 2
+    }
+    if fd <= 0 {
+        return
+    }
+    record := maze.FormatStats(mz.Stats(), " ", false)
+    if key := mz.Key(); key != "" {
+        record += " key=" + key
+    }
+    if _, err := fmt.Fprintf(os.NewFile(uintptr(fd), "stats"), "%s\n", record); err != nil {
+        fmt.Fprintf(os.Stderr, "Error writing stats to file descriptor %d: %v\n", fd, err)
+        setBool(&ioFailed, true)
+    }
+}
@@ -0,0 +1,34 @@
+/* stdin.go - Maze input from standard input
+ *
+ * Reads the maze to solve from standard input when the input file is -, for solving a maze at the end of a
+ * pipeline, maze -o - | transform | maze -i - -o solved.txt.  It's read in the ascii format, a header missing
+ * rows of its grid at the end of the input being an error as it is for a file.  The terminal size is taken
+ * from standard output, so it's found whatever standard input is, and the display is left out unless standard
+ * output is a terminal, there being no one to watch it otherwise.
+ */
+package main
+
+import (
+    "os"
+    "io"
+    "golang.org/x/crypto/ssh/terminal"
+)
+
+const stdinName = "-"               // the input file name standing for standard input
+
+// openInput opens the named input file, or returns standard input for -, which is left open when it's closed
+func openInput(name string) (io.ReadCloser, error) {
+    if name == stdinName {
+        return io.NopCloser(os.Stdin), nil
+    }
+    return os.Open(name)
+}
+
+// fromStdin returns true if the maze is read from standard input
+func fromStdin() bool {; return loadName == stdinName; }
+
+// displayOff returns true if nothing is drawn on the terminal: when the maze is written to standard output, or read
+// from standard input with standard output not a terminal
+func displayOff() bool {
+    return toStdout() || (fromStdin() && !terminal.IsTerminal(int(os.Stdout.Fd())))
+}
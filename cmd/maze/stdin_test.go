@@ -0,0 +1,58 @@
+/* stdin_test.go - Standard input tests
+ *
+ * Pipes a generated maze back into the solver with -input - and checks that the maze written is the one piped in
+ * with its solution marked, the solution length of the run that generated it, and that input ending before the
+ * grid its header describes is an error.
+ */
+package main
+
+import (
+    "os"
+    "bytes"
+    "strings"
+    "testing"
+    "path/filepath"
+)
+
+// paramValue returns the value of the named parameter of a maze in the ascii format
+func paramValue(t *testing.T, text []byte, name string) string {
+    t.Helper()
+    for _, line := range strings.Split(string(text), "\n") {
+        if value, ok := strings.CutPrefix(line, "# " + name + "="); ok {
+            return value
+        }
+    }
+    t.Fatalf("no %s in:\n%s", name, text)
+    return ""
+}
+
+func TestStdinPipeline(t *testing.T) {
+    for _, args := range [][]string{{"-h", "6", "-w", "12", "-r", "3", "-d", "4"}, {"-h", "10", "-w", "19", "-r", "1"}} {
+        generated, stderr, status := pipeMaze(t, nil, append(args, "-q", "-o", "-")...)
+        if status != exitOK {
+            t.Fatalf("%v: exit %d generating: %s", args, status, stderr)
+        }
+        name := filepath.Join(t.TempDir(), "solved.txt")
+        if _, stderr, status := pipeMaze(t, generated, "-input", "-", "-q", "-o", name); status != exitOK {
+            t.Fatalf("%v: exit %d solving from stdin: %s", args, status, stderr)
+        }
+        solved, err := os.ReadFile(name)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got, want := paramValue(t, solved, "solve_length"), paramValue(t, generated, "solve_length"); got != want {
+            t.Errorf("%v: solved from stdin with length %s, generated with %s", args, got, want)
+        }
+        checkSolved(t, generated, solved)
+    }
+}
+
+func TestStdinTruncated(t *testing.T) {
+    generated, _, _ := pipeMaze(t, nil, "-h", "6", "-w", "12", "-r", "3", "-q", "-o", "-")
+    lines := bytes.SplitAfter(generated, []byte("\n"))
+    truncated := bytes.Join(lines[:len(lines) - 5], nil)
+    _, stderr, status := pipeMaze(t, truncated, "-input", "-", "-q", "-o", filepath.Join(t.TempDir(), "solved.txt"))
+    if status != exitIOError || !strings.Contains(stderr, "expected 13 rows for height 6") {
+        t.Errorf("truncated stdin: exit %d, %q, want %d and the missing rows reported", status, stderr, exitIOError)
+    }
+}
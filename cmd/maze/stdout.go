@@ -0,0 +1,36 @@
+/* stdout.go - Maze output to standard output
+ *
+ * Writes the maze to standard output when the output file is -, for piping it to another program.  There's no
+ * display then, so nothing but the maze reaches standard output: the reports and the summary go to stderr, and
+ * a height or width left unset is the one fitting the assumed terminal size rather than the real terminal, so
+ * the maze written doesn't depend on the window the command was run from.
+ */
+package main
+
+import (
+    "os"
+    "github.com/Starfleet2/maze"
+)
+
+const stdoutName = "-"              // the output file name standing for standard output
+
+// noDisplay is the renderer while there is no display, drawing nothing
+type noDisplay struct{}
+
+func (noDisplay) Frame(g *maze.Grid, stats maze.Stats) error {; return nil; }
+
+// toStdout returns true if the maze is written to standard output
+func toStdout() bool {; return outputName == stdoutName; }
+
+// useStdout sends what would have gone to the terminal to stderr instead, gives a height or width left unset, or both
+// with --fit, the size the display would have for the assumed terminal, and returns the largest maze height and width,
+// there being no display for the maze to fit
+func useStdout() (int, int) {
+    myStdout.Flush()
+    myStdout.Reset(os.Stderr)
+    brailleDisplay, glyphDisplay = false, false
+    fitHeight, fitWidth := fitSize(defaultRows, defaultCols, 0)
+    if height == 0 || fitFlag {; height = fitHeight; }
+    if width  == 0 || fitFlag {; width  = fitWidth ; }
+    return maze.MaxHeight, maze.MaxWidth
+}
@@ -0,0 +1,48 @@
+/* stream.go - Streaming huge mazes
+ *
+ * --stream writes a maze sized by -h and -w straight to a file in the ascii format, row by row as it's carved,
+ * instead of generating, solving and displaying it, so that it can be far larger than the maze array.  Only a
+ * row by row algorithm can be streamed, so --algorithm sidewinder must be given with it.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "bufio"
+    "github.com/Starfleet2/maze"
+)
+
+var streamName string
+
+// writeStream writes the maze to the named file, - for standard output, and returns the exit status
+func writeStream(name string) int {
+    opts := maze.StreamOptions{Width: width, Height: height, Seed: int64(seed), Algorithm: algorithm}
+    if err := opts.Check(); err != nil {
+        fmt.Fprintf(os.Stderr, "--stream: %v\n", err)
+        return exitIOError
+    }
+    f := os.Stdout
+    if name != "-" {
+        var err error
+        if f, err = os.Create(name); err != nil {
+            fmt.Fprintf(os.Stderr, "Error opening output file: %v\n", err)
+            return exitFailure
+        }
+    }
+    w := bufio.NewWriterSize(f, 1 << 16)
+    err := maze.RenderStream(w, opts)
+    if err == nil {
+        err = w.Flush()
+    }
+    if f != os.Stdout {
+        if cerr := f.Close(); err == nil {
+            err = cerr
+        }
+    }
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", name, err)
+        return exitIOError
+    }
+    return exitOK
+}
@@ -0,0 +1,72 @@
+/* summary.go - End of run summary
+ *
+ * A short report of what was generated and written, printed once the display is done so that there is a
+ * record of the run besides the last status line.  It goes to stdout on a terminal and to stderr otherwise,
+ * or when the maze is written to stdout, so that it never mixes with a maze or stats being piped from stdout.
+ */
+package main
+
+import (
+    "io"
+    "os"
+    "fmt"
+    "time"
+    "github.com/Starfleet2/maze/internal/human"
+    "golang.org/x/crypto/ssh/terminal"
+)
+
+var (
+    runStart     = time.Now()
+    quietFlag    bool
+    writtenNames []string                   // output files written, in the order first written
+    written      = map[string]bool{}
+)
+
+// noteWritten records that the named output file was written
+func noteWritten(name string) {
+    if !written[name] {
+        written[name] = true
+        writtenNames  = append(writtenNames, name)
+    }
+}
+
+// printSummary prints the summary of the run, unless it was suppressed with --quiet
+func printSummary() {
+    if quietFlag {
+        return
+    }
+    var w io.Writer = os.Stderr
+    if terminal.IsTerminal(int(os.Stdout.Fd())) && !toStdout() {
+        myStdout.Flush()
+        w = os.Stdout
+    }
+
+    fmt.Fprintf(w, "summary:\n")
+    mazeHeight, mazeWidth := mz.Size()
+    if loadName != "" {
+        fmt.Fprintf(w, "  maze:       %dx%d (width x height), loaded from %s\n", mazeWidth, mazeHeight, loadName)
+    } else {
+        fmt.Fprintf(w, "  maze:       %dx%d (width x height), seed %d, algorithm %s\n", mazeWidth, mazeHeight, mz.CurrentSeed(), mz.Algorithm())
+        if key := mz.Key(); key != "" {
+            fmt.Fprintf(w, "  key:        %s\n", key)
+        }
+        fmt.Fprintf(w, "  attempts:   %s\n", human.Count(mz.MazesCreated()))
+    }
+    switch {
+        case mz.Unsolvable()     : fmt.Fprintf(w, "  solution:   none\n")
+        case mz.BudgetExhausted(): fmt.Fprintf(w, "  solution:   step budget exhausted\n")
+        default                  : fmt.Fprintf(w, "  solution:   %s cells (minimum %s)\n", human.Count(mz.SolutionLength()), human.Count(mz.MinLength()))
+                                   fmt.Fprintf(w, "  difficulty: %d\n", mz.Difficulty())
+    }
+    for _, name := range writtenNames {
+        if name == stdoutName {
+            fmt.Fprintf(w, "  output:     standard output\n")
+        } else if info, err := os.Stat(name); err == nil {
+            fmt.Fprintf(w, "  output:     %s (%s bytes)\n", name, human.Count(int(info.Size())))
+        }
+    }
+    if framesWritten > 0 {
+        fmt.Fprintf(w, "  frames:     %d png files in %s\n", framesWritten, framesDir)
+    }
+    fmt.Fprintf(w, "  elapsed:    %s\n", human.Duration(time.Since(runStart)))
+}
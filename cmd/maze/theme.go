@@ -0,0 +1,131 @@
+/* theme.go - Color themes
+ *
+ * A theme gives the color of each role in the display: walls, paths, the solution, tried and checked cells,
+ * the player and the status line.  Each color is given as 24 bit RGB, used directly on truecolor terminals and
+ * mapped to the 6x6x6 color cube on 256 color terminals, and as one of the 8 basic colors for everything else.
+ * The classic theme uses basic colors only, so it looks the same on every terminal.
+ */
+package main
+
+import (
+    "os"
+    "fmt"
+    "strings"
+)
+
+// themeColor is the color of a role, none for the terminal default
+type themeColor struct {
+    basic int                   // basic color 0-7, or -1 for the terminal default
+    rgb   int                   // 0xrrggbb, or -1 to use the basic color on every terminal
+    bold  bool
+}
+
+// colorTheme is a named set of role colors
+type colorTheme struct {
+    name                                          string
+    wall, path, solved, tried, check, player, status themeColor
+}
+
+var noColor = themeColor{-1, -1, false}
+
+// basic and rgb return a theme color given as a basic color, or as an RGB color with a basic color for 8 color terminals
+func basic(n int) themeColor            {; return themeColor{n, -1   , false}; }
+func rgb(n, value int) themeColor       {; return themeColor{n, value, false}; }
+func (c themeColor) bright() themeColor {; c.bold = true; return c;          }
+
+// themes lists the built in themes, the first being the default
+var themes = []colorTheme {
+    //                wall                  path                  solved                         tried                 check                          player                         status
+    {"classic"      , noColor             , noColor             , basic(2).bright()            , noColor             , basic(1).bright()            , basic(3).bright()            , noColor             },
+    {"solarized"    , rgb(7, 0x839496)    , rgb(0, 0x002b36)    , rgb(2, 0x859900).bright()    , rgb(4, 0x073642)    , rgb(1, 0xdc322f).bright()    , rgb(3, 0xb58900).bright()    , rgb(7, 0x93a1a1)    },
+    {"mono"         , noColor             , noColor             , noColor.bright()             , noColor             , noColor.bright()             , noColor.bright()             , noColor             },
+    {"high-contrast", rgb(7, 0xffffff)    , rgb(0, 0x000000)    , rgb(3, 0xffff00).bright()    , rgb(4, 0x0000ff)    , rgb(1, 0xff0000).bright()    , rgb(6, 0x00ffff).bright()    , rgb(7, 0xffffff)    },
+    {"ocean"        , rgb(6, 0x4fc3f7)    , rgb(4, 0x01243a)    , rgb(3, 0xffd54f).bright()    , rgb(6, 0x0d47a1)    , rgb(1, 0xff7043).bright()    , rgb(7, 0xffffff).bright()    , rgb(6, 0x80deea)    },
+}
+
+var (
+    theme      = &themes[0]
+    themeList  bool
+    colorDepth = terminalColors()
+    themeCodes struct {                 // the selected theme's escape sequences, built once rather than for every cell drawn
+        wall, path, solved, tried, check, status string
+    }
+)
+
+// terminalColors returns the number of colors the terminal supports, judging by the environment
+func terminalColors() int {
+    switch {
+        case os.Getenv("COLORTERM") == "truecolor" || os.Getenv("COLORTERM") == "24bit": return 1 << 24
+        case strings.Contains(os.Getenv("TERM"), "256color")                           : return 256
+    }
+    return 8
+}
+
+// parseTheme selects the named theme, or the theme list for "list"
+func parseTheme(value string) error {
+    if value == "list" {
+        themeList = true
+        return nil
+    }
+    var names []string
+    for i := range themes {
+        if themes[i].name == value {
+            theme = &themes[i]
+            return nil
+        }
+        names = append(names, themes[i].name)
+    }
+    return fmt.Errorf("expected a theme name (%s) or list", strings.Join(names, ", "))
+}
+
+// cube returns the 256 color index of the color cube entry nearest to the RGB color
+func cube(rgb int) int {
+    level := func(v int) int {; return (v*5 + 127) / 255; }
+    return 16 + 36*level(rgb >> 16) + 6*level(rgb >> 8 & 0xff) + level(rgb & 0xff)
+}
+
+// sgr returns the escape sequences selecting the color as the foreground (base 30) or background (base 40)
+func (c themeColor) sgr(base int) string {
+    s := ""
+    switch {
+        case c.rgb >= 0 && colorDepth > 256: s = fmt.Sprintf("\033[%d;2;%d;%d;%dm", base + 8, c.rgb >> 16, c.rgb >> 8 & 0xff, c.rgb & 0xff)
+        case c.rgb >= 0 && colorDepth > 8  : s = fmt.Sprintf("\033[%d;5;%dm", base + 8, cube(c.rgb))
+        case c.basic >= 0                  : s = fmt.Sprintf("\033[%dm", base + c.basic)
+    }
+    if c.bold && base == 30 {
+        s += "\033[1m"
+    }
+    return s
+}
+
+// isSet returns true if the color differs from the terminal default
+func (c themeColor) isSet() bool {
+    return c != noColor
+}
+
+// prepareTheme builds the escape sequences of the selected theme for the terminal's color depth
+func prepareTheme() {
+    themeCodes.wall  , themeCodes.path , themeCodes.solved = theme.wall.sgr(30) , theme.path.sgr(40) , theme.solved.sgr(30)
+    themeCodes.tried , themeCodes.check, themeCodes.status = theme.tried.sgr(40), theme.check.sgr(30), theme.status.sgr(30)
+}
+
+// pathColor returns the escape sequence setting the path background, which is restored after every reset
+func pathColor() string {
+    return themeCodes.path
+}
+
+// printThemes prints each theme's name followed by a swatch of each of its role colors
+func printThemes() {
+    fmt.Printf("%-14s  %-6s  %-6s  %-6s  %-6s  %-6s  %-6s  %-6s\n", "theme", "wall", "path", "solved", "tried", "check", "player", "status")
+    for _, t := range themes {
+        fmt.Printf("%-14s", t.name)
+        for _, c := range []themeColor{t.wall, t.path, t.solved, t.tried, t.check, t.player, t.status} {
+            switch {
+                case c.basic >= 0 || c.rgb >= 0: fmt.Printf("  %s      \033[0m", c.sgr(40))
+                case c.bold                     : fmt.Printf("  %-6s", "bold")
+                default                         : fmt.Printf("  %-6s", "-")
+            }
+        }
+        fmt.Printf("\n")
+    }
+}
@@ -0,0 +1,104 @@
+/* threads_test.go - Automatic thread count tests
+ *
+ * Checks the thread counts autoThreadCount picks on either side of autoMinCells and autoFullCells for one CPU and
+ * several, and those chooseThreads leaves given or sets for a checkpointed maze.  BenchmarkAutoThreads times
+ * generating and solving at each benchmark size with the automatic counts and with each fixed setting, the
+ * measurements the thresholds are taken from.
+ */
+package main
+
+import (
+    "fmt"
+    "context"
+    "runtime"
+    "testing"
+    "github.com/Starfleet2/maze"
+)
+
+func TestAutoThreadCount(t *testing.T) {
+    for _, tc := range []struct {
+        cells, cpus, want int
+    }{
+        {200              , 8, 0},
+        {autoMinCells - 1 , 8, 0},
+        {autoMinCells     , 8, 2},                  // at least two threads once any are used
+        {10000            , 8, 2},
+        {16000            , 8, 4},
+        {autoFullCells - 1, 8, 7},
+        {autoFullCells    , 8, 8},
+        {autoFullCells * 4, 8, 8},                  // never more than the CPUs
+        {autoFullCells    , 2, 2},
+        {autoFullCells * 4, 1, 0},                  // threads don't pay on a single CPU
+        {autoMinCells     , 1, 0},
+    } {
+        if got := autoThreadCount(tc.cells, tc.cpus); got != tc.want {
+            t.Errorf("%d cells on %d CPUs: %d threads, want %d", tc.cells, tc.cpus, got, tc.want)
+        }
+    }
+}
+
+func TestChooseThreads(t *testing.T) {
+    big := autoThreadCount(autoFullCells, runtime.NumCPU())
+    for _, tc := range []struct {
+        name                      string
+        height, width             int
+        carve, solve              int
+        singleCarver              bool
+        wantCarve, wantSolve      int
+    }{
+        {"small"      , 10 , 20 , -1, -1, false, 0                         , 0  },
+        {"large"      , 100, 300, -1, -1, false, min(big, autoCarveThreads), big},
+        {"checkpoint" , 100, 300, -1, -1, true , 0                         , big},
+        {"given"      , 100, 300,  3,  5, true , 3                         , 5  },
+        {"carve given", 10 , 20 ,  1, -1, false, 1                         , 0  },
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            saved := []int{height, width, carveThreads, solveThreads}
+            defer func() {; height, width, carveThreads, solveThreads = saved[0], saved[1], saved[2], saved[3]; }()
+            height, width, carveThreads, solveThreads = tc.height, tc.width, tc.carve, tc.solve
+            chooseThreads(tc.singleCarver)
+            if carveThreads != tc.wantCarve || solveThreads != tc.wantSolve {
+                t.Errorf("%d carving and %d solving threads, want %d and %d", carveThreads, solveThreads, tc.wantCarve, tc.wantSolve)
+            }
+        })
+    }
+}
+
+// BenchmarkAutoThreads generates and solves mazes of 200 cells up to autoFullCells with the threads -t auto chooses
+// on this machine and with none, one, two and one per CPU, for comparing the automatic choice with the fastest
+func BenchmarkAutoThreads(b *testing.B) {
+    for _, size := range [][2]int{{10, 20}, {autoMinCells/50, 50}, {50, 200}, {autoFullCells/300, 300}} {
+        height, width := size[0], size[1]
+        n := autoThreadCount(height * width, runtime.NumCPU())
+        type setting struct {
+            name         string
+            carve, solve int
+        }
+        settings := []setting{{"auto", min(n, autoCarveThreads), n}}
+        fixed := []int{0, 1, 2}
+        if cpus := runtime.NumCPU(); cpus > 2 {
+            fixed = append(fixed, cpus)
+        }
+        for _, threads := range fixed {
+            settings = append(settings, setting{fmt.Sprint(threads), threads, threads})
+        }
+        for _, s := range settings {
+            carve, solve := s.carve, s.solve
+            b.Run(fmt.Sprintf("%dx%d/%s", width, height, s.name), func(b *testing.B) {
+                for i := 0; i < b.N; i++ {
+                    m, err := maze.NewMaze(maze.WithSize(width, height), maze.WithSeed(int64(i + 1)),
+                                           maze.WithCarveThreads(carve), maze.WithSolveThreads(solve))
+                    if err != nil {
+                        b.Fatal(err)
+                    }
+                    if _, err := m.Generate(context.Background()); err != nil {
+                        b.Fatal(err)
+                    }
+                    if _, err := m.Solve(context.Background()); err != nil {
+                        b.Fatal(err)
+                    }
+                }
+            })
+        }
+    }
+}
@@ -0,0 +1,85 @@
+/* combine.go - Maze union and intersection
+ *
+ * Combines the passages of two mazes of the same size.  The union of two perfect mazes has loops and the
+ * intersection is generally disconnected, so the result is best reported with its components and loops.
+ */
+package maze
+
+import (
+    "fmt"
+)
+
+// CombineOps maps the operations Combine can do to whether a location is open given whether it's open in each maze
+var CombineOps = map[string]func(openA, openB bool) bool {
+    "union"    : func(openA, openB bool) bool {; return openA || openB; },
+    "intersect": func(openA, openB bool) bool {; return openA && openB; },
+}
+
+// combineMazes combines the passages of two mazes with the operation, making the result the current maze
+func (m *Maze) combineMazes(open func(openA, openB bool) bool, a, b *Loaded) {
+    m.setPhase(PhaseCarving)
+    combined := &Loaded{height: a.height, width: a.width, begY: a.begY, endY: a.endY}
+    for i := 1; i < 2*(a.height + 1); i++ {
+        for j := 1; j < 2*(a.width + 1); j++ {
+            border := i == 1 || i == 2*a.height + 1 || j == 1 || j == 2*a.width + 1
+            openA, openB := a.grid[i][j] != wall, b.grid[i][j] != wall
+            if (border && !openA) || (!border && !open(openA, openB)) {    // the openings are those of the first maze
+                combined.grid[i][j] = wall
+            }
+        }
+    }
+    m.installMaze(combined)
+    m.loaded = combined                             // with no parameters, being neither maze
+}
+
+// combine combines the passages of two mazes of the same size with the named operation, making the result the
+// current maze, or returns an error for an operation not in CombineOps or mazes of different sizes
+func (m *Maze) combine(op string, a, b *Loaded) error {
+    open, ok := CombineOps[op]
+    if !ok {
+        return fmt.Errorf("unknown combine operation %q (valid operations: intersect, union)", op)
+    }
+    if err := sameSize(a, b); err != nil {
+        return err
+    }
+    m.configure()
+    m.combineMazes(open, a, b)
+    m.cfg.height, m.cfg.width = m.height, m.width
+    return nil
+}
+
+// walls returns the walls and openings of the maze as a Loaded, solved and tried cells being open
+func (m *Maze) walls() *Loaded {
+    l := &Loaded{height: m.height, width: m.width, begY: getInt(&m.begY), endY: getInt(&m.endY)}
+    for i := 0; i < getInt(&m.maxX); i++ {
+        for j := 0; j < getInt(&m.maxY); j++ {
+            l.grid[i][j] = int32(m.getMaze(i, j))
+        }
+    }
+    return l
+}
+
+// combined returns a new maze of the passages of two mazes of the same size combined with the named operation
+func combined(op string, a, b *Maze) (*Maze, error) {
+    if getInt(&a.maxX) == 0 || getInt(&b.maxX) == 0 {
+        return nil, ErrNoMaze
+    }
+    m := newMaze()
+    if err := m.combine(op, a.walls(), b.walls()); err != nil {
+        return nil, err
+    }
+    return m, nil
+}
+
+// Union returns a new maze with the passages open in either of two generated or loaded mazes of the same size and
+// the openings of a, unsolved.  It returns an error wrapping ErrSizeMismatch for mazes of different sizes.
+func Union(a, b *Maze) (*Maze, error) {
+    return combined("union", a, b)
+}
+
+// Intersect returns a new maze with only the passages open in both of two generated or loaded mazes of the same
+// size and the openings of a, unsolved.  It's generally disconnected, and returns an error wrapping ErrSizeMismatch
+// for mazes of different sizes.
+func Intersect(a, b *Maze) (*Maze, error) {
+    return combined("intersect", a, b)
+}
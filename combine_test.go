@@ -0,0 +1,87 @@
+/* combine_test.go - Maze union and intersection tests
+ *
+ * Combines generated mazes and checks the passages of the result against those of the two mazes, and that
+ * unknown operations and mazes of different sizes are errors rather than panics.
+ */
+package maze
+
+import (
+    "errors"
+    "context"
+    "testing"
+)
+
+// passageSet returns the passages of the maze
+func passageSet(m *Maze) map[[2]Point]bool {
+    set := map[[2]Point]bool{}
+    for a, b := range m.PassageCells() {
+        set[[2]Point{a, b}] = true
+    }
+    return set
+}
+
+func TestUnionIntersect(t *testing.T) {
+    a := goldenMaze(t, WithSize(15, 9), WithSeed(1))
+    b := goldenMaze(t, WithSize(15, 9), WithSeed(2))
+    u, err := Union(a, b)
+    if err != nil {
+        t.Fatal(err)
+    }
+    n, err := Intersect(a, b)
+    if err != nil {
+        t.Fatal(err)
+    }
+    inA, inB, inU, inN := passageSet(a), passageSet(b), passageSet(u), passageSet(n)
+    for p := range inU {
+        if !inA[p] && !inB[p] {
+            t.Errorf("union passage %v is in neither maze", p)
+        }
+    }
+    for p := range inA {
+        if !inU[p] {
+            t.Errorf("passage %v of the first maze is not in the union", p)
+        }
+    }
+    for p := range inB {
+        if !inU[p] {
+            t.Errorf("passage %v of the second maze is not in the union", p)
+        }
+    }
+    for p := range inN {
+        if !inA[p] || !inB[p] {
+            t.Errorf("intersection passage %v is not in both mazes", p)
+        }
+    }
+    for p := range inA {
+        if inB[p] && !inN[p] {
+            t.Errorf("passage %v of both mazes is not in the intersection", p)
+        }
+    }
+    if loops := u.Passages() - 15*9 + u.Components(); loops == 0 {
+        t.Errorf("union of two different perfect mazes has no loops")
+    }
+    if _, err := u.Solve(context.Background()); err != nil {
+        t.Errorf("union with the openings of the first maze, which keeps its solution: %v", err)
+    }
+}
+
+func TestCombineErrors(t *testing.T) {
+    a := goldenMaze(t, WithSize(15, 9), WithSeed(1))
+    b := goldenMaze(t, WithSize(14, 9), WithSeed(1))
+    if _, err := Union(a, b); !errors.Is(err, ErrSizeMismatch) {
+        t.Errorf("union of mazes of different sizes: error %v, want %v", err, ErrSizeMismatch)
+    }
+    if _, err := Intersect(a, b); !errors.Is(err, ErrSizeMismatch) {
+        t.Errorf("intersection of mazes of different sizes: error %v, want %v", err, ErrSizeMismatch)
+    }
+    if _, err := Union(a, newMaze()); !errors.Is(err, ErrNoMaze) {
+        t.Errorf("union with a maze never generated: error %v, want %v", err, ErrNoMaze)
+    }
+    m := newMaze()
+    if err := m.Combine("xor", a.walls(), a.walls()); err == nil {
+        t.Errorf("unknown operation combined without an error")
+    }
+    if err := m.Combine("union", a.walls(), b.walls()); !errors.Is(err, ErrSizeMismatch) {
+        t.Errorf("combining mazes of different sizes: error %v, want %v", err, ErrSizeMismatch)
+    }
+}
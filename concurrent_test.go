@@ -0,0 +1,64 @@
+/* concurrent_test.go - Concurrent generation tests
+ *
+ * Generates mazes of different sizes and seeds at the same time, each with its own threads, and checks that every
+ * one is a valid perfect maze identical to the maze its seed gives when generated alone.
+ */
+package maze
+
+import (
+    "bytes"
+    "context"
+    "testing"
+)
+
+// concurrentMaze is a maze generated alongside others and what it was written as
+type concurrentMaze struct {
+    width, height int
+    seed          int64
+    m             *Maze
+    text          []byte
+    err           error
+}
+
+// generate generates the maze single threaded, so that its seed decides it, solves it and writes it as text
+func (c *concurrentMaze) generate() {
+    if c.m, c.err = NewMaze(WithSize(c.width, c.height), WithSeed(c.seed), WithThreads(0)); c.err != nil {
+        return
+    }
+    if _, c.err = c.m.Generate(context.Background()); c.err == nil {
+        c.text, c.err = c.m.MarshalText()
+    }
+}
+
+func TestConcurrentMazes(t *testing.T) {
+    mazes := []*concurrentMaze{{width: 19, height: 10, seed: 1}, {width: 12, height: 8, seed: 2},
+                               {width: 30, height: 5, seed: 3}, {width: 7, height: 16, seed: 4}}
+    done := make(chan struct{})
+    for _, c := range mazes {
+        go func() {
+            c.generate()
+            done <- struct{}{}
+        }()
+    }
+    for range mazes {
+        <-done
+    }
+    for _, c := range mazes {
+        if c.err != nil {
+            t.Fatalf("%dx%d seed %d: %v", c.width, c.height, c.seed, c.err)
+        }
+        if got, want := c.m.Passages(), c.width*c.height - 1; got != want {
+            t.Errorf("%dx%d seed %d: %d passages, want %d for a perfect maze", c.width, c.height, c.seed, got, want)
+        }
+        if !c.m.routeComplete(c.m.solutionRoute()) {
+            t.Errorf("%dx%d seed %d: the solution doesn't reach the exit", c.width, c.height, c.seed)
+        }
+        alone := concurrentMaze{width: c.width, height: c.height, seed: c.seed}
+        if alone.generate(); alone.err != nil {
+            t.Fatal(alone.err)
+        }
+        if !bytes.Equal(c.text, alone.text) {
+            t.Errorf("%dx%d seed %d: generated alongside the others as\n%s\nalone as\n%s", c.width, c.height, c.seed, c.text, alone.text)
+        }
+    }
+}
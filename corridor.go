@@ -0,0 +1,163 @@
+/* corridor.go - Wide corridors
+ *
+ * With a corridor width of N the maze is carved and solved on the usual grid of cells, then written with each
+ * cell widened into an N by N open block while the walls stay one location thick.  Each file is written from
+ * a widened copy of the maze, kept until the maze changes, so the generator, the solvers and the analyses all
+ * work on the logical maze, and the maze itself is left alone.  The solution is drawn down the middle of the corridors, which for an even N
+ * is the line of open walls inside each block.
+ */
+package maze
+
+import "sync/atomic"
+
+// ArrayHeight and ArrayWidth are the largest maze, in cells, that fits the maze array
+const (
+    ArrayHeight = (maxXSize - 3)/2
+    ArrayWidth  = (maxYSize - 3)/2
+)
+
+// corridorFits returns true if a maze of the given size still fits the maze array once widened to n cell corridors
+func corridorFits(h, w, n int) bool {
+    return h*n <= ArrayHeight && w*n <= ArrayWidth
+}
+
+// corridorSpan returns the first and last locations of the widened maze covered by location x of a maze
+// n cells across, where locations 0 and 2n+2 are the perimeter path
+func (m *Maze) corridorSpan(x, n int) (int, int) {
+    r := x/2
+    switch {
+        case x == 0        : return 0, 0
+        case isOdd(x)      : return 2*m.corridor*r + 1, 2*m.corridor*r + 1
+        case x == 2*n + 2  : return 2*m.corridor*(r - 1) + 2, 2*m.corridor*(r - 1) + 2
+    }
+    return 2*m.corridor*(r - 1) + 2, 2*m.corridor*r
+}
+
+// corridorMiddle returns the widened location down the middle of location x
+func (m *Maze) corridorMiddle(x, n int) int {
+    lo, hi := m.corridorSpan(x, n)
+    return (lo + hi)/2
+}
+
+// corridorCell returns the widened cell location at or just before the middle of the cell at location x
+func (m *Maze) corridorCell(x, n int) int {
+    lo, _ := m.corridorSpan(x, n)
+    return lo + 2*((m.corridor - 1)/2)
+}
+
+// wideOpening returns the widened cell location at or just before the middle of the opening containing column y
+// in the border at row x of a maze n cells across, which is opened across the whole width of its corridors
+func (m *Maze) wideOpening(x, y, n int) int {
+    lo, hi := m.openingRange(x, y)
+    wLo, _ := m.corridorSpan(lo, n)
+    _, wHi := m.corridorSpan(hi, n)
+    return wLo + 2*((wHi - wLo)/4)
+}
+
+// sign returns -1, 0 or 1 for a negative, zero or positive n
+func sign(n int) int {
+    return bool2int(n > 0) - bool2int(n < 0)
+}
+
+// wideKey is what a widened copy depends on besides the maze array, the solution and the branch markers
+type wideKey struct {
+    height, width, corridor   int
+    begY, endY                int
+}
+
+// touch records that the maze array, the solution or the branch markers changed, so that the widened copy is
+// made again the next time the maze is written.  The flag is only stored while it's clear so the carving and
+// solving threads setting it don't contend on it.
+func (m *Maze) touch() {
+    if !getBool(&m.changed) {
+        setBool(&m.changed, true)
+    }
+}
+
+// widened returns the maze as it's written: a copy with every cell widened into its corridor, or the maze itself
+// with corridors one cell wide.  The copy is kept and made again from the maze array as it stands only once the
+// maze has changed, so writing an unchanged maze in several formats widens it once, and it's valid until the next
+// write.  The maze is never changed, so it may still be being carved while it's written.  Solved locations become
+// a line down the middle of their corridor, tried and checked cells fill theirs, the entrance and exit are opened
+// across the whole width of their corridors, and the solution route and branch markers are moved to match.
+func (m *Maze) widened() *Maze {
+    if m.corridor == 1 {
+        return m
+    }
+    m.wideMu.Lock()
+    defer m.wideMu.Unlock()
+    h, w, bY, eY := m.height, m.width, getInt(&m.begY), getInt(&m.endY)
+    key          := wideKey{h, w, m.corridor, bY, eY}
+    changed      := atomic.SwapInt32(&m.changed, 0) != 0    // cleared before the maze array is read, so a change made meanwhile is seen next time
+    c            := m.wide
+    if c == nil {
+        c = newMaze()
+    }
+    copyState(c, m)
+    c.params = m.Params()                               // with the key of the maze rather than of its widened size
+    c.height, c.width = h*m.corridor, w*m.corridor
+    setInt(&c.maxX, 2*(c.height + 1) + 1)
+    setInt(&c.maxY, 2*(c.width  + 1) + 1)
+    setInt(&c.endX, 2*c.height)
+    c.gap = m.gap*m.corridor
+    setInt(&c.begY, m.wideOpening(getInt(&m.begX) - 1, bY, w))
+    setInt(&c.endY, m.wideOpening(getInt(&m.endX) + 1, eY, w))
+    if c == m.wide && key == m.wideKey && !changed {
+        return c
+    }
+    if m.wideGrid == nil {
+        m.wideGrid = new(mazeGrid)
+    }
+    m.wide, m.wideKey = c, key
+    c.solution, c.branches = nil, nil
+    logical := m.wideGrid                               // read once, as carving threads may still be changing it
+    for i := 0; i < 2*h + 3; i++ {
+        for j := 0; j < 2*w + 3; j++ {
+            logical[i][j] = int32(m.getMaze(i, j))
+        }
+    }
+    for x := 0; x < 2*h + 3; x++ {
+        rLo, rHi := m.corridorSpan(x, h)
+        for y := 0; y < 2*w + 3; y++ {
+            cLo, cHi := m.corridorSpan(y, w)
+            state := logical[x][y]
+            if state == solved {
+                state = path
+            }
+            for i := rLo; i <= rHi; i++ {
+                for j := cLo; j <= cHi; j++ {
+                    c.maze[i][j] = state
+                }
+            }
+        }
+    }
+    for x := 0; x < 2*h + 3; x++ {
+        for y := 0; y < 2*w + 3; y++ {
+            if logical[x][y] != solved {
+                continue
+            }
+            i, j := m.corridorMiddle(x, h), m.corridorMiddle(y, w)
+            c.maze[i][j] = solved
+            switch {                                        // join the walls to the middles of the cells either side
+                case isOdd(x) && isEven(y): for k := m.corridorMiddle(x - 1, h) + 1; k < m.corridorMiddle(x + 1, h); k++ {; c.maze[k][j] = solved; }
+                case isEven(x) && isOdd(y): for k := m.corridorMiddle(y - 1, w) + 1; k < m.corridorMiddle(y + 1, w); k++ {; c.maze[i][k] = solved; }
+            }
+        }
+    }
+    for _, p := range m.getSolution() {
+        i, j := m.corridorMiddle(p.x, h), m.corridorMiddle(p.y, w)
+        for len(c.solution) > 0 && c.solution[len(c.solution) - 1] != (point{i, j}) {    // step along the middle from the last location
+            last := c.solution[len(c.solution) - 1]
+            c.solution = append(c.solution, point{last.x + sign(i - last.x), last.y + sign(j - last.y)})
+        }
+        if len(c.solution) == 0 {
+            c.solution = append(c.solution, point{i, j})
+        }
+    }
+    m.branchesMu.Lock()
+    for _, b := range m.branches {
+        c.branches = append(c.branches, branch{m.corridorCell(b.x, h), m.corridorCell(b.y, w), b.wasted})
+    }
+    m.branchesMu.Unlock()
+    return c
+}
@@ -0,0 +1,72 @@
+/* corridor_test.go - Wide corridor tests
+ *
+ * Checks that the widened copy a maze with wide corridors is written from is kept while the maze is unchanged and
+ * made again once it's solved or unsolved, and that its entrance and exit are open across the whole width of their
+ * corridors and centered on them.
+ */
+package maze
+
+import (
+    "fmt"
+    "testing"
+)
+
+// countSolved returns the number of solved locations of the maze array
+func countSolved(m *Maze) int {
+    n := 0
+    for i := 0; i < getInt(&m.maxX); i++ {
+        for j := 0; j < getInt(&m.maxY); j++ {
+            n += bool2int(m.getMaze(i, j) == solved)
+        }
+    }
+    return n
+}
+
+func TestWidenedKept(t *testing.T) {
+    m := goldenMaze(t, WithSize(8, 5), WithSeed(3), WithCorridor(3))
+    c := m.widened()
+    if m.widened() != c {
+        t.Fatalf("an unchanged maze was widened again")
+    }
+    if countSolved(c) == 0 {
+        t.Fatalf("the widened copy of a solved maze has no solution")
+    }
+    m.Unsolve()
+    if c = m.widened(); countSolved(c) != 0 {
+        t.Errorf("the widened copy still has %d solved locations once the maze is unsolved", countSolved(c))
+    }
+}
+
+func TestWidenedOpenings(t *testing.T) {
+    for _, corridor := range []int{2, 3} {
+        for _, gap := range []int{1, 2} {
+            t.Run(fmt.Sprintf("corridor %d gap %d", corridor, gap), func(t *testing.T) {
+                m := goldenMaze(t, WithSize(8, 5), WithSeed(5), WithGap(gap), WithCorridor(corridor))
+                c := m.widened()
+                for _, row := range []struct {
+                    name string
+                    x, y int
+                }{
+                    {"entrance", getInt(&c.begX) - 1, getInt(&c.begY)},
+                    {"exit"    , getInt(&c.endX) + 1, getInt(&c.endY)},
+                } {
+                    lo, hi := c.openingRange(row.x, row.y)
+                    if cells := (hi - lo)/2 + 1; cells != gap*corridor {
+                        t.Errorf("%s opens %d cells, want %d", row.name, cells, gap*corridor)
+                    }
+                    for j := lo; j <= hi; j++ {
+                        if c.getMaze(row.x, j) == wall {
+                            t.Errorf("%s has a wall at column %d", row.name, j)
+                        }
+                    }
+                    if c.getMaze(row.x, lo - 1) != wall || c.getMaze(row.x, hi + 1) != wall {
+                        t.Errorf("%s is open beyond columns %d to %d", row.name, lo, hi)
+                    }
+                    if mid := lo + 2*((hi - lo)/4); row.y != mid {
+                        t.Errorf("%s at column %d, want the middle cell %d", row.name, row.y, mid)
+                    }
+                }
+            })
+        }
+    }
+}
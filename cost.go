@@ -0,0 +1,130 @@
+/* cost.go - Solving by the cost of the cells
+ *
+ * Solves the maze for the route whose cells cost the least in total rather than the first route found, the
+ * cost of each cell being given by the program embedding the maze, so that it can prefer some regions and keep
+ * away from others.  A perfect maze has only the one route, so the cost only changes the solution of a maze with
+ * loops in it, a loaded one, or one combined or widened.  Every cell costing 1 gives the shortest solution.
+ */
+package maze
+
+import (
+    "fmt"
+    "errors"
+    "context"
+    "container/heap"
+)
+
+// ErrNegativeCost is returned by SolveWithCost when a cell costs less than nothing
+var ErrNegativeCost = errors.New("negative cell cost")
+
+type costNode struct {
+    x, y, cost int
+}
+
+// costQueue is a min-heap of cells ordered by the cost of reaching them
+type costQueue []costNode
+
+func (q costQueue) Len() int            {; return len(q); }
+func (q costQueue) Swap(i, j int)       {; q[i], q[j] = q[j], q[i]; }
+func (q costQueue) Less(i, j int) bool  {; return q[i].cost < q[j].cost; }
+func (q *costQueue) Push(x interface{}) {; *q = append(*q, x.(costNode)); }
+func (q *costQueue) Pop() interface{}   {; old := *q; n := old[len(old) - 1]; *q = old[:len(old) - 1]; return n; }
+
+// SolveWithCost clears any solution from the maze and solves it again for the route from the entrance to the exit whose
+// cells cost the least in total, cost returning the cost of a cell inside the maze, and returns the solution as Solve
+// does.  A nil cost costs every cell 1, which gives the shortest solution.  Every open cell is costed before solving,
+// and ErrNegativeCost is returned if any costs less than 0.  It returns ErrUnsolvable if there's no route between
+// the openings and ErrNoMaze if the maze hasn't been generated or loaded.
+func (m *Maze) SolveWithCost(ctx context.Context, cost func(Point) int) ([]Point, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+    m.configure()
+    if getInt(&m.maxX) == 0 {
+        if m.loaded == nil {
+            return nil, ErrNoMaze
+        }
+        m.installMaze(m.loaded)
+    }
+    if cost == nil {
+        cost = func(Point) int {; return 1; }
+    }
+    maxX, maxY := getInt(&m.maxX), getInt(&m.maxY)
+    costs := make([][]int, maxX)                    // the cost of each open cell, -1 for the rest
+    best  := make([][]int, maxX)                    // the least cost of reaching each cell, -1 until it's reached
+    from  := make([][]point, maxX)                  // the location it was reached from
+    for i := range costs {
+        costs[i], best[i], from[i] = make([]int, maxY), make([]int, maxY), make([]point, maxY)
+        for j := range costs[i] {
+            costs[i][j], best[i][j] = -1, -1
+        }
+    }
+    m.restoreMaze()
+    var err error
+    m.cells(func(c cell, state int) bool {
+        if state != wall {
+            x, y := c.loc()
+            if costs[x][y] = cost(Point{c.row, c.col}); costs[x][y] < 0 {
+                err = fmt.Errorf("%w: %d at row %d, column %d", ErrNegativeCost, costs[x][y], c.row, c.col)
+            }
+        }
+        return err == nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    queue := &costQueue{}
+    reach := func(x, y, total int, prev point) {
+        if costs[x][y] >= 0 && (best[x][y] < 0 || total + costs[x][y] < best[x][y]) {
+            best[x][y], from[x][y] = total + costs[x][y], prev
+            heap.Push(queue, costNode{x, y, best[x][y]})
+        }
+    }
+    for y := 2; y < maxY - 1; y += 2 {              // every cell open to the entrance above it
+        if m.getMaze(1, y) != wall {
+            reach(2, y, 0, point{1, y})
+        }
+    }
+    exit := point{-1, -1}
+    for queue.Len() > 0 && exit.x < 0 {
+        n := heap.Pop(queue).(costNode)
+        switch {
+            case n.cost > best[n.x][n.y]:           // reached more cheaply since it was queued
+                continue
+            case n.x == getInt(&m.endX) && m.getMaze(n.x + 1, n.y) != wall:
+                exit = point{n.x, n.y}
+                continue
+        }
+        for _, dir := range stdDirection {
+            nx, ny := n.x + dir.x, n.y + dir.y
+            if m.isInterior(nx, ny) && m.getMaze(n.x + dir.x/2, n.y + dir.y/2) != wall {
+                reach(nx, ny, n.cost, point{n.x, n.y})
+            }
+        }
+    }
+    m.setSolution(nil)
+    if exit.x < 0 {
+        setInt(&m.solveLength, 0)
+        return nil, ErrUnsolvable
+    }
+
+    route := []point{{exit.x + 1, exit.y}}
+    for p := exit; p.x > 1; p = from[p.x][p.y] {
+        route = append(route, p)
+        if q := from[p.x][p.y]; q.x > 1 {           // the wall location between the cells
+            route = append(route, point{(p.x + q.x)/2, (p.y + q.y)/2})
+        }
+    }
+    route = append(route, from[route[len(route) - 1].x][route[len(route) - 1].y])
+    for i, j := 0, len(route) - 1; i < j; i, j = i + 1, j - 1 {
+        route[i], route[j] = route[j], route[i]
+    }
+    for _, p := range route {
+        m.setMaze(p.x, p.y, solved)
+    }
+    setBool(&m.solvedFlag, true)
+    setInt(&m.solveLength, m.solvedLength())
+    m.setSolution(route)
+    return m.routePoints(route), nil
+}
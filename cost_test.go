@@ -0,0 +1,101 @@
+/* cost_test.go - Cell cost solver tests
+ *
+ * Solves an open room, where every route is a passage, with a cost field shaped like a wall across it and checks
+ * that the solution goes around, and checks the shortest solution of the default cost and the refusal of a
+ * negative one.
+ */
+package maze
+
+import (
+    "fmt"
+    "errors"
+    "context"
+    "strings"
+    "testing"
+)
+
+// openRoom returns a maze of height by width cells with no walls inside it, open at the top and bottom of column 0
+func openRoom(t *testing.T, height, width int) *Maze {
+    t.Helper()
+    border := "+ +" + strings.Repeat("-+", width - 1)
+    rows   := []string{fmt.Sprintf("%d %d", height, width), border}
+    for r := 0; r < height; r++ {
+        if r > 0 {
+            rows = append(rows, strings.Repeat("+ ", width) + "+")
+        }
+        rows = append(rows, "|" + strings.Repeat(" ", 2*width - 1) + "|")
+    }
+    l, err := ParseASCII(strings.Join(append(rows, border), "\n") + "\n")
+    if err != nil {
+        t.Fatal(err)
+    }
+    m := newMaze()
+    m.Load(l)
+    return m
+}
+
+func TestSolveWithCostShortest(t *testing.T) {
+    m := openRoom(t, 5, 7)
+    points, err := m.SolveWithCost(context.Background(), nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, p := range points {
+        if p.Y != 0 {
+            t.Errorf("shortest solution leaves column 0 at %v: %v", p, points)
+            break
+        }
+    }
+    if got := m.SolutionLength(); got != 5 {
+        t.Errorf("solution length %d, want 5", got)
+    }
+}
+
+// TestSolveWithCostWall costs row 2 of the room 100 a cell but for the last column, a wall with a way through at the
+// far side, which the solution must go round by rather than cross
+func TestSolveWithCostWall(t *testing.T) {
+    m := openRoom(t, 5, 7)
+    points, err := m.SolveWithCost(context.Background(), func(p Point) int {
+        if p.X == 2 && p.Y != 6 {
+            return 100
+        }
+        return 1
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, p := range points {
+        if p.X == 2 && p.Y != 6 {
+            t.Errorf("solution crosses the wall at %v: %v", p, points)
+        }
+    }
+    if got, want := m.SolutionLength(), 5 + 2*6; got != want {
+        t.Errorf("solution length %d, want %d", got, want)
+    }
+    steps, err := m.SolutionPath()
+    if err != nil || len(steps) != len(points) {
+        t.Errorf("marked solution: %d steps, error %v, want %d steps", len(steps), err, len(points))
+    }
+}
+
+func TestSolveWithCostPerfect(t *testing.T) {
+    m := goldenMaze(t, WithSize(19, 10), WithSeed(1))
+    want := m.SolutionLength()
+    if _, err := m.SolveWithCost(context.Background(), func(p Point) int {; return 1 + p.Y; }); err != nil {
+        t.Fatal(err)
+    }
+    if got := m.SolutionLength(); got != want {
+        t.Errorf("perfect maze solution length %d, want its only route's %d", got, want)
+    }
+}
+
+func TestSolveWithCostNegative(t *testing.T) {
+    m := openRoom(t, 3, 3)
+    _, err := m.SolveWithCost(context.Background(), func(p Point) int {; return p.X - 1; })
+    if !errors.Is(err, ErrNegativeCost) {
+        t.Errorf("negative cost: error %v, want %v", err, ErrNegativeCost)
+    }
+    if _, err := newMaze().SolveWithCost(context.Background(), nil); err != ErrNoMaze {
+        t.Errorf("no maze: error %v, want %v", err, ErrNoMaze)
+    }
+}
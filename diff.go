@@ -0,0 +1,50 @@
+/* diff.go - Maze difference report
+ *
+ * Compares the walls of two mazes of the same size and draws the second maze with every location that
+ * was opened or closed relative to the first highlighted.
+ */
+package maze
+
+import (
+    "fmt"
+    "errors"
+)
+
+// ErrSizeMismatch is returned for two mazes that must be the same size but aren't
+var ErrSizeMismatch = errors.New("the mazes are different sizes")
+
+// sameSize returns an error wrapping ErrSizeMismatch, with the sizes, if mazes a and b aren't the same size
+func sameSize(a, b *Loaded) error {
+    heightA, widthA := a.Size()
+    heightB, widthB := b.Size()
+    if heightA != heightB || widthA != widthB {
+        return fmt.Errorf("%w: %dx%d and %dx%d (width x height)", ErrSizeMismatch, widthA, heightA, widthB, heightB)
+    }
+    return nil
+}
+
+// Diff returns the ascii rows of maze b with the locations opened relative to maze a marked with + and those closed
+// marked with -, or with green and red backgrounds if color is set, and the numbers opened and closed.  It returns
+// an error wrapping ErrSizeMismatch if the mazes aren't the same size.
+func Diff(a, b *Loaded, color bool) ([]byte, int, int, error) {
+    if err := sameSize(a, b); err != nil {
+        return nil, 0, 0, err
+    }
+    isWall := func(v int32) bool {; return v == wall || v == check; }
+    opened, closed := 0, 0
+    out := make([]byte, 0, len(b.lines) * (len(b.lines[0]) + 1))
+    for r, line := range b.lines {
+        for c := 0; c < len(line); c++ {
+            wallA, wallB := isWall(a.grid[r + 1][c + 1]), isWall(b.grid[r + 1][c + 1])
+            switch {
+                case wallA == wallB: out = append(out, line[c])
+                case color && wallB: out = append(out, "\033[41m" + line[c:c+1] + "\033[0m"...); closed++
+                case color         : out = append(out, "\033[42m" + line[c:c+1] + "\033[0m"...); opened++
+                case wallB         : out = append(out, '-'); closed++
+                default            : out = append(out, '+'); opened++
+            }
+        }
+        out = append(out, '\n')
+    }
+    return out, opened, closed, nil
+}
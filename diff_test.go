@@ -0,0 +1,43 @@
+/* diff_test.go - Maze difference report tests
+ *
+ * Diffs mazes read back from the ascii format against themselves, each other and a maze of another size.
+ */
+package maze
+
+import (
+    "errors"
+    "testing"
+)
+
+// loadedASCII returns the maze read back from its unsolved ascii output
+func loadedASCII(t *testing.T, m *Maze) *Loaded {
+    t.Helper()
+    l, err := ParseASCII(string(unsolvedASCII(t, m)))
+    if err != nil {
+        t.Fatal(err)
+    }
+    return l
+}
+
+func TestDiff(t *testing.T) {
+    a := loadedASCII(t, goldenMaze(t, WithSize(12, 8), WithSeed(1)))
+    b := loadedASCII(t, goldenMaze(t, WithSize(12, 8), WithSeed(2)))
+    if _, opened, closed, err := Diff(a, a, false); err != nil || opened + closed != 0 {
+        t.Errorf("maze against itself: %d opened, %d closed, error %v", opened, closed, err)
+    }
+    _, opened, closed, err := Diff(a, b, false)
+    if err != nil || opened == 0 || closed == 0 {
+        t.Errorf("different mazes: %d opened, %d closed, error %v", opened, closed, err)
+    }
+    if _, reopened, reclosed, _ := Diff(b, a, false); reopened != closed || reclosed != opened {
+        t.Errorf("reversed: %d opened, %d closed, want %d and %d", reopened, reclosed, closed, opened)
+    }
+}
+
+func TestDiffSizeMismatch(t *testing.T) {
+    a := loadedASCII(t, goldenMaze(t, WithSize(12, 8), WithSeed(1)))
+    b := loadedASCII(t, goldenMaze(t, WithSize(13, 8), WithSeed(1)))
+    if _, _, _, err := Diff(a, b, false); !errors.Is(err, ErrSizeMismatch) {
+        t.Errorf("mazes of different widths: error %v, want %v", err, ErrSizeMismatch)
+    }
+}
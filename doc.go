@@ -0,0 +1,19 @@
+/* doc.go - Package documentation
+ *
+ * Package maze generates, solves, reads and writes mazes.  The module is github.com/Starfleet2/maze, this
+ * library, with the maze command in cmd/maze using it as any other program would.
+ *
+ * The supported API is every exported identifier of this package: the Maze made by NewMaze, whose parameters are
+ * set only by its options and read back by Size, Depth, Algorithm and MinLength, Generate, Solve and SolveWithCost,
+ * the solution as SolutionPath and SolutionCells, the Render methods writing each format and RenderStream, the
+ * Parse functions reading them back, and the generator registry of RegisterGenerator, Algorithms, Generator and
+ * Grid.  Cell, Extent, the cell values Path to Check and CellEvent give the maze array the events, recordings
+ * and ImageCells are in terms of: cells at even locations and walls at odd ones, inside a perimeter path.  From
+ * v1.0.0 the module is versioned semantically, so no release of v1 removes or changes any of them; api_test.go
+ * declares each with its type, and fails to compile if one is.  Version is the version of the maze command and of
+ * the files it writes, not of the module.
+ *
+ * Everything else is unexported, or under internal/ where the maze command shares it, so that the display, the
+ * carving and the solvers can change without a new major version.
+ */
+package maze
@@ -0,0 +1,64 @@
+/* dot.go - Graphviz graphs of the maze
+ *
+ * Writes the graph underneath the maze for graphviz and for teaching graph algorithms: a node for each cell,
+ * named c_row_col and pinned to its place in the grid for neato, and an undirected edge for each passage between
+ * two cells.  The corridors aren't widened, so a perfect maze is a tree of width*height nodes.  The entrance and
+ * exit cells are filled in, and if the solution is marked its edges are drawn in red.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+)
+
+// dotNode returns the graphviz node name of a cell
+func dotNode(c cell) string {
+    return fmt.Sprintf("c_%d_%d", c.row, c.col)
+}
+
+// RenderDOT writes the maze to w as a graphviz graph, returning the first error from w
+func (m *Maze) RenderDOT(w io.Writer) error {
+    ew := &errWriter{w: w}
+    onSolution := map[[2]cell]bool{}                                // the solution's edges, the first cell above or left
+    var prev cell
+    inside := false
+    for p := range m.markedSolution() {
+        c := cell{p.X, p.Y}
+        if c.row < 0 || c.row >= m.height {                         // outside the openings
+            inside = false
+            continue
+        }
+        if a, b := prev, c; inside {
+            if b.row < a.row || b.col < a.col {
+                a, b = b, a
+            }
+            onSolution[[2]cell{a, b}] = true
+        }
+        prev, inside = c, true
+    }
+
+    entrance, exit := cellAt(getInt(&m.begX), getInt(&m.begY)), cellAt(getInt(&m.endX), getInt(&m.endY))
+    fmt.Fprintf(ew, "graph maze {\n    graph [layout=neato, label=\"Maze %dx%d\"];\n", m.width, m.height)
+    fmt.Fprintf(ew, "    node [shape=circle, width=0.15, fixedsize=true, label=\"\"];\n    edge [penwidth=2];\n")
+    for i := 0; i < m.height; i++ {
+        for j := 0; j < m.width; j++ {
+            c, style := cell{i, j}, ""
+            switch c {
+                case entrance: style = ", style=filled, fillcolor=\"#20a020\", xlabel=\"entrance\""
+                case exit    : style = ", style=filled, fillcolor=\"#2050d0\", xlabel=\"exit\""
+            }
+            fmt.Fprintf(ew, "    %s [pos=\"%d,%d!\"%s];\n", dotNode(c), j, -i, style)
+        }
+    }
+    m.passages(func(a, b cell) bool {
+        if onSolution[[2]cell{a, b}] {
+            fmt.Fprintf(ew, "    %s -- %s [color=\"#d02020\"];\n", dotNode(a), dotNode(b))
+        } else {
+            fmt.Fprintf(ew, "    %s -- %s;\n", dotNode(a), dotNode(b))
+        }
+        return true
+    })
+    fmt.Fprintf(ew, "}\n")
+    return ew.err
+}
@@ -0,0 +1,70 @@
+/* dot_test.go - Graphviz export tests
+ *
+ * Reads the nodes and edges back from the graphs of perfect mazes and checks that there are width*height nodes
+ * joined into a tree by width*height-1 edges between adjacent cells, with the solution's edges colored.
+ */
+package maze
+
+import (
+    "bytes"
+    "regexp"
+    "strconv"
+    "testing"
+)
+
+var (
+    dotNodeLine = regexp.MustCompile(`(?m)^    (c_\d+_\d+) \[pos=`)
+    dotEdgeLine = regexp.MustCompile(`(?m)^    c_(\d+)_(\d+) -- c_(\d+)_(\d+)( \[color="#d02020"\])?;$`)
+)
+
+// dotIndex returns the row or column in a node name
+func dotIndex(b []byte) int {
+    n, _ := strconv.Atoi(string(b))
+    return n
+}
+
+func TestDOTTree(t *testing.T) {
+    for _, size := range [][2]int{{19, 10}, {1, 7}, {12, 8}} {
+        m := goldenMaze(t, WithSize(size[0], size[1]), WithSeed(1), WithDepth(2))
+        var b bytes.Buffer
+        if err := m.RenderDOT(&b); err != nil {
+            t.Fatal(err)
+        }
+        cells := size[0]*size[1]
+        nodes := map[string]bool{}
+        for _, n := range dotNodeLine.FindAllSubmatch(b.Bytes(), -1) {
+            nodes[string(n[1])] = true
+        }
+        if len(nodes) != cells {
+            t.Errorf("%dx%d: %d nodes, want %d", size[0], size[1], len(nodes), cells)
+        }
+        parent := map[Point]Point{}             // joins the cells of each edge, to find a cycle or a part left out
+        root := func(p Point) Point {
+            for q, ok := parent[p]; ok; q, ok = parent[p] {
+                p = q
+            }
+            return p
+        }
+        edges, solution := dotEdgeLine.FindAllSubmatch(b.Bytes(), -1), 0
+        for _, e := range edges {
+            a, c := Point{dotIndex(e[1]), dotIndex(e[2])}, Point{dotIndex(e[3]), dotIndex(e[4])}
+            if d := (Point{a.X - c.X, a.Y - c.Y}); d != (Point{1, 0}) && d != (Point{-1, 0}) && d != (Point{0, 1}) && d != (Point{0, -1}) {
+                t.Errorf("%dx%d: edge %s between cells that aren't adjacent", size[0], size[1], e[0])
+            }
+            if root(a) == root(c) {
+                t.Errorf("%dx%d: edge %s closes a cycle", size[0], size[1], e[0])
+            }
+            parent[root(a)] = root(c)
+            solution += bool2int(len(e[5]) > 0)
+        }
+        if len(edges) != cells - 1 {
+            t.Errorf("%dx%d: %d edges, want %d", size[0], size[1], len(edges), cells - 1)
+        }
+        if want := m.SolutionLength() - 1; solution != want {
+            t.Errorf("%dx%d: %d solution edges, want %d", size[0], size[1], solution, want)
+        }
+        if n := bytes.Count(b.Bytes(), []byte(`xlabel="entrance"`)) + bytes.Count(b.Bytes(), []byte(`xlabel="exit"`)); n != 2 {
+            t.Errorf("%dx%d: %d entrance and exit nodes, want 2", size[0], size[1], n)
+        }
+    }
+}
@@ -0,0 +1,104 @@
+/* eps.go - Encapsulated PostScript drawings of the maze
+ *
+ * Writes the maze as an EPS file of strokes sized in millimetres for laser cutters and vector editors.  Each
+ * straight run of wall is one moveto, lineto and stroke, so the cutter follows a run in one pass rather than
+ * wall by wall.  The solution, if it's included, is a hairline path from opening to opening in a block of its
+ * own between layer comments, which can be deleted to leave the walls alone.  The bounding box covers the
+ * square capped strokes.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "math"
+    "strconv"
+)
+
+// Defaults and limits of the eps format's options, in millimetres
+const (
+    DefaultEPSCellSize    = 5.0         // from one wall to the next
+    DefaultEPSStrokeWidth = 0.2
+    MaxEPSCellSize        = 100
+    epsPoints             = 72/25.4     // points to the millimetre
+)
+
+// EPSOptions chooses the sizes of the drawing RenderEPS writes
+type EPSOptions struct {
+    CellSize    float64             // millimetres from one wall to the next, 0 for DefaultEPSCellSize
+    StrokeWidth float64             // millimetres, 0 for DefaultEPSStrokeWidth, less than the cell size
+    Solution    bool                // draw the solution Solve returns as a hairline on a layer of its own
+}
+
+// withDefaults returns the options with the defaults in place of any zero values
+func (o EPSOptions) withDefaults() EPSOptions {
+    if o.CellSize    == 0 {; o.CellSize    = DefaultEPSCellSize   ; }
+    if o.StrokeWidth == 0 {; o.StrokeWidth = DefaultEPSStrokeWidth; }
+    return o
+}
+
+// Check returns an error if the options are out of range
+func (o EPSOptions) Check() error {
+    d := o.withDefaults()
+    switch {
+        case !(o.CellSize >= 0 && o.CellSize <= MaxEPSCellSize):                 // not a number either
+            return fmt.Errorf("eps cell size %g is outside 0 to %d millimetres", o.CellSize, MaxEPSCellSize)
+        case !(o.StrokeWidth >= 0 && d.StrokeWidth < d.CellSize):
+            return fmt.Errorf("eps stroke width %g must be less than the cell size of %g millimetres", d.StrokeWidth, d.CellSize)
+    }
+    return nil
+}
+
+// epsNum returns a length in points as postscript writes it, to a thousandth of a point
+func epsNum(v float64) string {
+    return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// RenderEPS writes the maze to w as an encapsulated postscript drawing, returning an error if the options are out of
+// range or the first error from w
+func (m *Maze) RenderEPS(w io.Writer, opts EPSOptions) error {
+    if err := opts.Check(); err != nil {
+        return err
+    }
+    m = m.widened()
+
+    opts = opts.withDefaults()
+    pad           := opts.StrokeWidth/2*epsPoints               // the square caps reach half a stroke past the ends
+    width, height := float64(m.width)*opts.CellSize*epsPoints + 2*pad, float64(m.height)*opts.CellSize*epsPoints + 2*pad
+    at := func(p point) string {                                // y runs up the page, the rows down it
+        p.x = max(1, min(p.x, getInt(&m.maxX) - 2))             // the solution stops at the openings, in the box
+        x := pad + float64(p.y - 1)*opts.CellSize/2*epsPoints
+        y := height - pad - float64(p.x - 1)*opts.CellSize/2*epsPoints
+        return epsNum(x) + " " + epsNum(y)
+    }
+
+    ew := &errWriter{w: w}
+    fmt.Fprintf(ew, "%%!PS-Adobe-3.0 EPSF-3.0\n%%%%Creator: maze %s\n%%%%Title: %s\n", Version, m.sourceOrigin())
+    fmt.Fprintf(ew, "%%%%BoundingBox: 0 0 %d %d\n", int(math.Ceil(width)), int(math.Ceil(height)))
+    fmt.Fprintf(ew, "%%%%HiResBoundingBox: 0 0 %s %s\n%%%%LanguageLevel: 1\n%%%%EndComments\n", epsNum(width), epsNum(height))
+    fmt.Fprintf(ew, "gsave\n\n%% Layer: walls\n0 setgray %s setlinewidth 2 setlinecap 0 setlinejoin\n", epsNum(opts.StrokeWidth*epsPoints))
+    m.wallRuns(func(from, to point) {
+        fmt.Fprintf(ew, "%s moveto %s lineto stroke\n", at(from), at(to))
+    })
+    fmt.Fprintf(ew, "%% End layer: walls\n")
+    if opts.Solution {
+        route := m.getSolution()
+        if len(route) == 0 {
+            route = m.solutionRoute()
+        }
+        if line := m.solutionLine(route); line != nil {
+            fmt.Fprintf(ew, "\n%% Layer: solution\ngsave\n0.816 0.125 0.125 setrgbcolor 0 setlinewidth 1 setlinecap 1 setlinejoin\n")
+            last := at(line[0])
+            fmt.Fprintf(ew, "%s moveto\n", last)
+            for _, p := range line[1:] {
+                if next := at(p); next != last {                // the end stopped at the exit may be a turn
+                    fmt.Fprintf(ew, "%s lineto\n", next)
+                    last = next
+                }
+            }
+            fmt.Fprintf(ew, "stroke\ngrestore\n%% End layer: solution\n")
+        }
+    }
+    fmt.Fprintf(ew, "\ngrestore\nshowpage\n%%%%EOF\n")
+    return ew.err
+}
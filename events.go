@@ -0,0 +1,84 @@
+/* events.go - Cell change events
+ *
+ * A frontend other than the terminal display can follow the generation through a stream of events, one for
+ * every change to a location of the maze array, tagged with the phase of the generation that made it.  The
+ * generator never waits for the consumer: an event that doesn't fit in the channel's buffer is dropped and
+ * counted, and a consumer that falls behind can always read the current maze with Cell.
+ */
+package maze
+
+import (
+    "fmt"
+)
+
+// Phases of the generation, as carried by a CellEvent
+const (
+    PhaseCarving Phase = iota               // carving the paths, or loading or combining a maze
+    PhasePushing                            // pushing mid wall openings right and down
+    PhaseSolving                            // searching for the best openings and solving
+)
+
+const eventBuffer = 4096                    // events held for a slow consumer before more are dropped
+
+// Phase is the part of the generation that changed a cell
+type Phase int
+
+// CellEvent is the change of maze array location X, Y from the value Old to New, using the cell values of Cell
+type CellEvent struct {
+    X, Y     int
+    Old, New int
+    Phase    Phase
+}
+
+// eventState is the event stream of a Maze
+type eventState struct {
+    events        chan CellEvent            // nil until Events is called
+    phase         int32
+    eventsDropped int32
+}
+
+// String returns the name of the phase
+func (p Phase) String() string {
+    switch p {
+        case PhaseCarving: return "carving"
+        case PhasePushing: return "pushing"
+        case PhaseSolving: return "solving"
+    }
+    return fmt.Sprintf("Phase(%d)", int(p))
+}
+
+// Events returns the channel of cell change events, starting the stream on the first call, which must come before
+// the maze is generated.  The channel is never closed.
+func (m *Maze) Events() <-chan CellEvent {
+    if m.events == nil {
+        m.events = make(chan CellEvent, eventBuffer)
+    }
+    return m.events
+}
+
+// EventsDropped returns the number of events dropped because the consumer wasn't keeping up
+func (m *Maze) EventsDropped() int {
+    return getInt(&m.eventsDropped)
+}
+
+// setPhase sets the phase that later events are tagged with, calling the phase hook if it's a different phase
+func (m *Maze) setPhase(p Phase) {
+    if old := Phase(getInt(&m.phase)); old != p {
+        setInt(&m.phase, int(p))
+        if m.onPhase != nil {
+            m.onPhase(p)
+        }
+    }
+}
+
+// emit sends the change of location x, y from old to v, if there's a stream and the value changed, dropping it
+// rather than waiting if the channel is full
+func (m *Maze) emit(x, y, old, v int) {
+    if m.events == nil || old == v {
+        return
+    }
+    select {
+        case m.events <- CellEvent{x, y, old, v, Phase(getInt(&m.phase))}:
+        default: incInt(&m.eventsDropped)
+    }
+}
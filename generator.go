@@ -0,0 +1,190 @@
+/* generator.go - Pluggable carving algorithms
+ *
+ * The paths of a maze are carved by a Generator, chosen by name from those registered, on a Grid that starts
+ * out as solid wall.  Everything after the carving, the wall pushing, the search for the best openings, solving,
+ * the display and the output, works from the carved maze alone, so any generator gets all of it.  The look ahead
+ * carver is the default, and a plain recursive backtracker is built in alongside it.
+ */
+package maze
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "math/rand"
+)
+
+// Generator carves the paths of a maze into a grid of walls, drawing its random numbers from rng so that a seed
+// reproduces the maze.  It should return once g.Stopped is true.
+type Generator interface {
+    Carve(g *Grid, rng *rand.Rand) error
+}
+
+// Grid is a view of a maze in logical cell coordinates, those of Point, as carved by a Generator and drawn by
+// a Renderer
+type Grid struct {
+    m       *Maze
+    resumed bool                    // the maze array was restored from a checkpoint rather than set to walls
+}
+
+// generators are the registered generators by name, each of which is made afresh for each maze generated
+var generators = map[string]func() Generator {
+    DefaultAlgorithm: func() Generator {; return lookAhead{};   },
+    "backtrack"     : func() Generator {; return backtracker{}; },
+}
+
+// RegisterGenerator makes the generators returned by newGenerator available as the algorithm name, returning an
+// error if the name is empty or already taken.  It must not be called while mazes are being generated.
+func RegisterGenerator(name string, newGenerator func() Generator) error {
+    if _, ok := generators[name]; ok || name == "" || newGenerator == nil {
+        return fmt.Errorf("can't register algorithm %q, it's empty or already registered", name)
+    }
+    generators[name] = newGenerator
+    return nil
+}
+
+// Algorithms returns the names of the registered generators in order
+func Algorithms() []string {
+    names := make([]string, 0, len(generators))
+    for name := range generators {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// generator returns a new generator of the algorithm selected by WithAlgorithm, or an error if there's no such
+// algorithm or a checkpoint is to be resumed by another algorithm than the one that took it
+func (m *Maze) generator() (Generator, error) {
+    name := m.cfg.algorithm
+    if name == "" {
+        name = DefaultAlgorithm
+    }
+    newGenerator, ok := generators[name]
+    switch {
+        case !ok:
+            return nil, fmt.Errorf("unknown algorithm %q (valid algorithms: %s)", name, strings.Join(Algorithms(), ", "))
+        case m.resumeState != nil && name != DefaultAlgorithm:
+            return nil, fmt.Errorf("a checkpoint can only be resumed by the %s algorithm", DefaultAlgorithm)
+    }
+    return newGenerator(), nil
+}
+
+// Grid returns a view of the maze for a Renderer
+func (m *Maze) Grid() *Grid {
+    return &Grid{m: m}
+}
+
+// Width and Height return the size of the grid in cells
+func (g *Grid) Width()  int {; return g.m.width;  }
+func (g *Grid) Height() int {; return g.m.height; }
+
+// Contains returns true if cell x, y is inside the grid and not left out by its mask
+func (g *Grid) Contains(x, y int) bool {
+    return g.m.inMaze(x, y) && !g.m.masked(cell{x, y})
+}
+
+// Carved returns true if cell x, y has been carved, whether or not it's been solved or tried since, false if it's
+// still solid, only being checked by the look ahead, or outside the grid
+func (g *Grid) Carved(x, y int) bool {
+    if !g.m.inMaze(x, y) {
+        return false
+    }
+    i, j := cell{x, y}.loc()
+    v := g.m.getMaze(i, j)
+    return v == path || v == solved || v == tried
+}
+
+// IsWall returns true if there's a wall on side dir of cell x, y, every side being a wall until it's carved
+func (g *Grid) IsWall(x, y int, dir Direction) bool {
+    return g.m.IsWall(x, y, dir)
+}
+
+// Open carves cell x, y, starting a new path, or returns an error if it's outside the grid or masked
+func (g *Grid) Open(x, y int) error {
+    if !g.Contains(x, y) {
+        return fmt.Errorf("cell %d, %d is outside the %dx%d maze or masked", x, y, g.m.width, g.m.height)
+    }
+    if i, j := (cell{x, y}).loc(); g.m.setCell(i, j, path, update, 0, 0) {
+        incInt(&g.m.numPaths)
+    }
+    return nil
+}
+
+// Join carves cell x, y, the wall on its side dir and the cell beyond it, extending the path into that cell, or
+// returns an error if either cell is outside the grid or masked
+func (g *Grid) Join(x, y int, dir Direction) error {
+    if dir < Up || dir > Left {
+        return fmt.Errorf("invalid direction %d", dir)
+    }
+    step := directionSteps[dir]
+    if !g.Contains(x, y) || !g.Contains(x + step.X, y + step.Y) {
+        return fmt.Errorf("can't join cell %d, %d to cell %d, %d in the %dx%d maze", x, y, x + step.X, y + step.Y, g.m.width, g.m.height)
+    }
+    i, j := cell{x, y}.loc()
+    g.m.setCell(i, j, path, noUpdate, 0, 0)
+    g.m.setCell(i + step.X, j + step.Y, path, update, 0, 0)
+    if g.m.setCell(i + 2*step.X, j + 2*step.Y, path, update, 0, 0) {
+        incInt(&g.m.mazeLen)
+    }
+    return nil
+}
+
+// Stopped returns true once the generation has been cancelled
+func (g *Grid) Stopped() bool {
+    return g.m.stopped()
+}
+
+// lookAhead is the default generator, which carves each path until the look ahead finds no direction that leaves
+// the depth given clear, starting each new path at a corner of the ones before it
+type lookAhead struct{}
+
+// Carve carves the paths, with the carving threads of the maze, from a random cell or from where a resumed
+// checkpoint left off.  The paths draw from the maze's own random number source, which is the one passed to Carve.
+func (lookAhead) Carve(g *Grid, rng *rand.Rand) error {
+    x, y := 0, 0
+    if !g.resumed {
+        x = 2*(rng.Intn(g.m.height) + 1)   // random location
+        y = 2*(rng.Intn(g.m.width ) + 1)   // for first path
+        g.m.firstUnmasked(&x, &y)
+    }
+    g.m.carvePaths(x, y)
+    g.m.waitThreadsDone()
+    return nil
+}
+
+// backtracker is the recursive backtracker, a random walk into uncarved cells that backs up along its own path
+// whenever it's boxed in, carving a maze of long winding passages with few junctions
+type backtracker struct{}
+
+// Carve walks from a random cell until it has backed up to the start, keeping its path as a stack
+func (backtracker) Carve(g *Grid, rng *rand.Rand) error {
+    x, y  := cell{rng.Intn(g.Height()), rng.Intn(g.Width())}.loc()
+    g.m.firstUnmasked(&x, &y)
+    c     := cellAt(x, y)
+    start := Point{c.row, c.col}
+    if err := g.Open(start.X, start.Y); err != nil {
+        return err
+    }
+    var dirs []Direction
+    stack := []Point{start}
+    for len(stack) > 0 && !g.Stopped() {
+        p := stack[len(stack) - 1]
+        dirs = dirs[:0]
+        for dir, step := range directionSteps {
+            if n := (Point{p.X + step.X, p.Y + step.Y}); g.Contains(n.X, n.Y) && !g.Carved(n.X, n.Y) {
+                dirs = append(dirs, Direction(dir))
+            }
+        }
+        if len(dirs) == 0 {
+            stack = stack[:len(stack) - 1]
+            continue
+        }
+        dir := dirs[rng.Intn(len(dirs))]
+        if err := g.Join(p.X, p.Y, dir); err != nil {
+            return err
+        }
+        stack = append(stack, Point{p.X + directionSteps[dir].X, p.Y + directionSteps[dir].Y})
+    }
+    return nil
+}
@@ -0,0 +1,185 @@
+/* glyph.go - Emoji and other large glyphs
+ *
+ * Draws the maze with a glyph for each maze array location, emoji squares by default, on the terminal and in the
+ * glyphs format.  A glyph is a string, so it may be an emoji built of several code points, and all of them must
+ * take the same number of terminal columns for the rows to line up, two for most emoji.  That width is worked
+ * out from the characters, as a terminal does, so the display can be sized to fit.  The glyphs are drawn in
+ * their own colors, the locations being told apart by glyph alone: the solution and tried cells have their own.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "strings"
+    "unicode"
+)
+
+// GlyphMap holds the glyph drawn for each kind of location, each one or more characters taking the same number of
+// terminal columns
+type GlyphMap struct {
+    Wall, Path, Solved, Tried, Checked string
+}
+
+// EmojiGlyphs are the glyphs used unless others are given: green walls, white paths, a yellow solution, blue tried
+// cells and orange look ahead checks
+var EmojiGlyphs = GlyphMap{Wall: "\U0001f7e9", Path: "⬜", Solved: "\U0001f7e8", Tried: "\U0001f7e6", Checked: "\U0001f7e7"}
+
+// glyphs returns pointers to the glyphs of the map in the order of charMapNames
+func (g *GlyphMap) glyphs() [len(charMapNames)]*string {
+    return [...]*string{&g.Wall, &g.Path, &g.Solved, &g.Tried, &g.Checked}
+}
+
+// wideRanges are the code points a terminal draws two columns wide: the east asian wide and fullwidth ranges and
+// the emoji drawn as pictures
+var wideRanges = [][2]rune {
+    {0x1100, 0x115f}, {0x231a, 0x231b}, {0x23e9, 0x23ec}, {0x23f0, 0x23f0}, {0x23f3, 0x23f3}, {0x25fd, 0x25fe},
+    {0x2614, 0x2615}, {0x2648, 0x2653}, {0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1}, {0x26aa, 0x26ab},
+    {0x26bd, 0x26be}, {0x26c4, 0x26c5}, {0x26ce, 0x26ce}, {0x26d4, 0x26d4}, {0x26ea, 0x26ea}, {0x26f2, 0x26f3},
+    {0x26f5, 0x26f5}, {0x26fa, 0x26fa}, {0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b}, {0x2728, 0x2728},
+    {0x274c, 0x274c}, {0x274e, 0x274e}, {0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797}, {0x27b0, 0x27b0},
+    {0x27bf, 0x27bf}, {0x2b1b, 0x2b1c}, {0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x2e80, 0x303e}, {0x3041, 0xa4cf},
+    {0xac00, 0xd7a3}, {0xf900, 0xfaff}, {0xfe30, 0xfe4f}, {0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x1f004, 0x1f004},
+    {0x1f0cf, 0x1f0cf}, {0x1f18e, 0x1f18e}, {0x1f191, 0x1f19a}, {0x1f200, 0x1f251},
+    {0x1f300, 0x1f64f}, {0x1f680, 0x1f6ff}, {0x1f7e0, 0x1f7eb}, {0x1f90c, 0x1f9ff}, {0x1fa70, 0x1faff},
+    {0x20000, 0x3fffd},
+}
+
+// runeWidth returns the number of terminal columns a character takes: none for a combining mark, a joiner or another
+// format character, two for a wide one and one for any other
+func runeWidth(r rune) int {
+    if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+        return 0
+    }
+    for _, wr := range wideRanges {
+        if wr[0] <= r && r <= wr[1] {
+            return 2
+        }
+    }
+    return 1
+}
+
+// glyphWidth returns the number of terminal columns a glyph takes.  A character turned into an emoji by a variation
+// selector takes two, and the characters of an emoji joined by zero width joiners or given a skin tone are drawn as
+// one, as are the two regional indicators of a flag.
+func glyphWidth(glyph string) int {
+    width, joined := 0, false
+    for _, r := range glyph {
+        switch {
+            case r == 0xfe0f                              : width = max(width, 2)       // emoji presentation
+            case r == 0x200d                              : joined = true
+            case joined                                   : joined = false              // part of the emoji before the joiner
+            case 0x1f3fb <= r && r <= 0x1f3ff && width > 0:                             // a skin tone
+            default                                       : width += runeWidth(r)
+        }
+    }
+    return width
+}
+
+// ParseGlyphMap parses a glyph map of comma separated name=glyph pairs, e.g. wall=🧱,solved=🐾 where the
+// names are those of ParseCharMap and a glyph is any string without a comma.  A name left out keeps its EmojiGlyphs
+// glyph, so glyphs of another width must all be given, and the map is checked with Check.
+func ParseGlyphMap(spec string) (GlyphMap, error) {
+    g := EmojiGlyphs
+    var given [len(charMapNames)]bool
+    glyphs := g.glyphs()
+    for _, pair := range strings.Split(spec, ",") {
+        name, value, found := strings.Cut(pair, "=")
+        k := 0
+        for k < len(charMapNames) && charMapNames[k] != name {
+            k++
+        }
+        switch {
+            case !found                : return g, fmt.Errorf("expected name=glyph in %q", pair)
+            case k == len(charMapNames): return g, fmt.Errorf("unknown location %q (valid locations: %s)", name, strings.Join(charMapNames[:], ", "))
+            case given[k]              : return g, fmt.Errorf("%s given more than once", name)
+        }
+        *glyphs[k], given[k] = value, true
+    }
+    return g, g.Check()
+}
+
+// Check returns an error if a glyph is empty, holds a control character, isn't as wide as the others or is the same as
+// another, so that it couldn't be told apart
+func (g GlyphMap) Check() error {
+    glyphs := g.glyphs()
+    width  := glyphWidth(g.Wall)
+    for k, s := range glyphs {
+        switch {
+            case *s == ""                                    : return fmt.Errorf("%s glyph is empty", charMapNames[k])
+            case strings.IndexFunc(*s, unicode.IsControl) >= 0: return fmt.Errorf("%s glyph %q holds a control character", charMapNames[k], *s)
+            case glyphWidth(*s) != width                     : return fmt.Errorf("%s glyph %q is %d columns wide, but wall %q is %d", charMapNames[k], *s, glyphWidth(*s), g.Wall, width)
+        }
+        for other := 0; other < k; other++ {
+            if *glyphs[other] == *s {
+                return fmt.Errorf("%s and %s are both %q", charMapNames[other], charMapNames[k], *s)
+            }
+        }
+    }
+    if width == 0 {
+        return fmt.Errorf("glyphs take no columns")
+    }
+    return nil
+}
+
+// Width returns the number of terminal columns each glyph of the map takes
+func (g GlyphMap) Width() int {
+    return glyphWidth(g.Wall)
+}
+
+// glyphRow appends the glyphs of row x of the maze locations inside the perimeter path to line, drawing the look ahead
+// checks as walls unless look is set, and posts with no wall leading from them as paths
+func (m *Maze) glyphRow(line []byte, x int, g GlyphMap, look bool) []byte {
+    cell := func(i, j int) int {
+        if v := m.getMaze(i, j); v != check || look {
+            return v
+        }
+        return wall
+    }
+    for y := 1; y < getInt(&m.maxY) - 1; y++ {
+        switch v := cell(x, y); {
+            case v == wall  : if isDrawnWall(cell, x, y) {; line = append(line, g.Wall...); } else {; line = append(line, g.Path...); }
+            case v == solved: line = append(line, g.Solved...)
+            case v == tried : line = append(line, g.Tried...)
+            case v == check : line = append(line, g.Checked...)
+            default         : line = append(line, g.Path...)
+        }
+    }
+    return line
+}
+
+// RenderGlyphs writes the maze to w as a row of glyphs for each row of locations, with its corridors widened,
+// returning an error if the glyphs don't check or the first error from w
+func (m *Maze) RenderGlyphs(w io.Writer, g GlyphMap) error {
+    if err := g.Check(); err != nil {
+        return err
+    }
+    m = m.widened()
+    var line []byte
+    for x := 1; x < getInt(&m.maxX) - 1; x++ {
+        line = append(m.glyphRow(line[:0], x, g, true), '\n')
+        if _, err := w.Write(line); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// RenderGlyphANSI draws the maze to w from the top left of the screen, or of the margin, with the glyphs, a glyph for
+// each location.  The look ahead checks are drawn if the Look style is set, but the colors, the race solvers and the
+// overlays aren't, the glyphs having colors of their own.  It stops at the first write that fails and returns its
+// error.
+func (m *Maze) RenderGlyphANSI(w io.Writer, opts RenderOptions, g GlyphMap) error {
+    line := []byte("\033[0;0H")
+    for x := 1; x < getInt(&m.maxX) - 1; x++ {
+        if opts.Margin > 0 {
+            line = fmt.Appendf(line, "\033[%d;%dH", opts.Margin + x, opts.Margin + 1)
+        }
+        line = append(m.glyphRow(line, x, g, opts.Look), "\033[0m\n"...)
+        if _, err := w.Write(line); err != nil {
+            return err
+        }
+        line = line[:0]
+    }
+    return nil
+}
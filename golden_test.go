@@ -0,0 +1,140 @@
+/* golden_test.go - Golden output tests
+ *
+ * Generates small mazes from fixed seeds, single threaded, and compares their ascii output byte for byte with
+ * the golden files in testdata/golden, so a change to the generator, the solver or the writers that alters the
+ * mazes made from a seed fails here.  Run go test -update to rewrite the golden files after an intended change.
+ */
+package maze
+
+import (
+    "os"
+    "flag"
+    "time"
+    "bytes"
+    "context"
+    "testing"
+    "math/rand"
+    "path/filepath"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files with the output of the tests")
+
+// goldenMaze returns a maze generated single threaded with the options, which with a seed is the same on every run
+func goldenMaze(t *testing.T, opts ...Option) *Maze {
+    t.Helper()
+    m, err := NewMaze(append([]Option{WithThreads(0)}, opts...)...)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := m.Generate(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    return m
+}
+
+// checkGolden compares got with the named file in testdata/golden, or rewrites the file with it given -update
+func checkGolden(t *testing.T, name string, got []byte) {
+    t.Helper()
+    path := filepath.Join("testdata", "golden", name)
+    if *updateGolden {
+        if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+            t.Fatal(err)
+        }
+        if err := os.WriteFile(path, got, 0644); err != nil {
+            t.Fatal(err)
+        }
+        return
+    }
+    want, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatalf("%v (run go test -update to create it)", err)
+    }
+    if !bytes.Equal(got, want) {
+        t.Errorf("output differs from %s:\n%s\nwant:\n%s", path, got, want)
+    }
+}
+
+// renderASCII returns the maze in the ascii format
+func renderASCII(t *testing.T, m *Maze) []byte {
+    t.Helper()
+    var b bytes.Buffer
+    if err := m.RenderASCII(&b); err != nil {
+        t.Fatal(err)
+    }
+    return b.Bytes()
+}
+
+// unsolvedASCII returns the maze in the ascii format unsolved, as the maze command writes it
+func unsolvedASCII(t *testing.T, m *Maze) []byte {
+    t.Helper()
+    saved := m.Snapshot()
+    defer m.Restore(saved)
+    m.Unsolve()
+    return renderASCII(t, m)
+}
+
+// solvedASCII returns the maze in the ascii format with nothing but its saved solution marked, as the maze
+// command's --solved-output writes it
+func solvedASCII(t *testing.T, m *Maze) []byte {
+    t.Helper()
+    var out []byte
+    saved := m.Snapshot()
+    defer m.Restore(saved)
+    m.Unsolve()
+    m.RevealHints(1, func(int) {; out = renderASCII(t, m); })
+    return out
+}
+
+func TestGoldenASCII(t *testing.T) {
+    for _, tc := range []struct {
+        name          string
+        width, height int
+        seed          int64
+        depth         int
+    } {
+        {"19x10-seed1-depth0" , 19, 10,  1, 0},
+        {"19x10-seed1-depth5" , 19, 10,  1, 5},
+        {"12x8-seed42-depth0" , 12,  8, 42, 0},
+        {"12x8-seed42-depth5" , 12,  8, 42, 5},
+        {"30x15-seed7-depth0" , 30, 15,  7, 0},
+        {"30x15-seed7-depth5" , 30, 15,  7, 5},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            m := goldenMaze(t, WithSize(tc.width, tc.height), WithSeed(tc.seed), WithDepth(tc.depth))
+            checkGolden(t, tc.name + ".txt", unsolvedASCII(t, m))
+            checkGolden(t, tc.name + "-solved.txt", solvedASCII(t, m))
+        })
+    }
+}
+
+// TestGoldenRepeatable checks that a maze generated twice from the same seed is written the same both times, so a
+// golden file failing is the generator changing rather than drawing on something besides the seed, such as the time
+func TestGoldenRepeatable(t *testing.T) {
+    a := goldenMaze(t, WithSize(19, 10), WithSeed(3), WithDepth(5))
+    b := goldenMaze(t, WithSize(19, 10), WithSeed(3), WithDepth(5))
+    if got, want := unsolvedASCII(t, b), unsolvedASCII(t, a); !bytes.Equal(got, want) {
+        t.Errorf("second maze from seed 3 differs:\n%s\nfirst:\n%s", got, want)
+    }
+}
+
+// TestGeneratedParam checks that the generation time is written only for a maze from a seed taken from the clock
+func TestGeneratedParam(t *testing.T) {
+    for _, tc := range []struct {
+        name string
+        opts []Option
+        want bool
+    }{
+        {"clock seed" , nil                                        , true },
+        {"fixed seed" , []Option{WithSeed(3)}                      , false},
+        {"rand source", []Option{WithRandSource(rand.NewSource(3))}, false},
+    } {
+        m := goldenMaze(t, append([]Option{WithSize(12, 8)}, tc.opts...)...)
+        var generated string
+        for _, p := range m.Params() {
+            if p.Name == "generated" {; generated = p.Value; }
+        }
+        if _, err := time.Parse(time.RFC3339, generated); (err == nil) != tc.want {
+            t.Errorf("%s: generated=%q, want a time %v", tc.name, generated, tc.want)
+        }
+    }
+}
@@ -0,0 +1,44 @@
+/* graph.go - CSV adjacency lists of the maze
+ *
+ * Writes the maze as data rather than as a picture, for loading into pandas or a graph database: an edge list
+ * of the carved passages, a row of x1,y1,x2,y2 for each in logical cell coordinates, the row then the column as
+ * in Point, and a node list of the cells flagging the entrance, the exit and the cells along the solution.  A
+ * perfect maze is a spanning tree of its cells, so it has width*height - 1 edges.  The graph is the logical
+ * maze's, its corridors not widened.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+)
+
+// RenderEdgeCSV writes the maze to w as a csv edge list, a header then a row for each passage between two cells, the
+// first above or to the left of the second, returning the first error from w.  The openings aren't included.
+func (m *Maze) RenderEdgeCSV(w io.Writer) error {
+    ew := &errWriter{w: w}
+    fmt.Fprintf(ew, "x1,y1,x2,y2\n")
+    m.passages(func(a, b cell) bool {
+        fmt.Fprintf(ew, "%d,%d,%d,%d\n", a.row, a.col, b.row, b.col)
+        return ew.err == nil
+    })
+    return ew.err
+}
+
+// RenderNodeCSV writes the cells of the maze to w as a csv node list, a header then a row for each cell in row order
+// with 1 or 0 for whether it's under the entrance, over the exit and on the solution, returning the first error from
+// w.  The solution is the one SolutionCells returns.
+func (m *Maze) RenderNodeCSV(w io.Writer) error {
+    onSolution := map[Point]bool{}
+    for p := range m.SolutionCells() {
+        onSolution[p] = true
+    }
+    entrance, exit := cellAt(getInt(&m.begX), getInt(&m.begY)), cellAt(getInt(&m.endX), getInt(&m.endY))
+    ew := &errWriter{w: w}
+    fmt.Fprintf(ew, "x,y,is_entrance,is_exit,on_solution\n")
+    m.cells(func(c cell, state int) bool {
+        fmt.Fprintf(ew, "%d,%d,%d,%d,%d\n", c.row, c.col, bool2int(c == entrance), bool2int(c == exit), bool2int(onSolution[Point{c.row, c.col}]))
+        return ew.err == nil
+    })
+    return ew.err
+}
@@ -0,0 +1,78 @@
+/* graph_test.go - CSV adjacency list tests
+ *
+ * Reads back the edge and node lists of perfect mazes with encoding/csv and checks that there's a row for each of
+ * the width*height - 1 passages, each between two adjacent cells, and a row for each cell with one entrance, one
+ * exit and as many cells on the solution as its length.
+ */
+package maze
+
+import (
+    "bytes"
+    "strconv"
+    "testing"
+    "encoding/csv"
+)
+
+// readCSV returns the rows of the csv written by render, checking its header, each field as a number
+func readCSV(t *testing.T, render func(b *bytes.Buffer) error, header string) [][]int {
+    t.Helper()
+    var b bytes.Buffer
+    if err := render(&b); err != nil {
+        t.Fatal(err)
+    }
+    records, err := csv.NewReader(&b).ReadAll()
+    if err != nil {
+        t.Fatal(err)
+    }
+    var head bytes.Buffer
+    csv.NewWriter(&head).WriteAll(records[:1])
+    if head.String() != header + "\n" {
+        t.Fatalf("header %q, want %q", head.String(), header)
+    }
+    rows := make([][]int, len(records) - 1)
+    for i, r := range records[1:] {
+        for _, f := range r {
+            n, err := strconv.Atoi(f)
+            if err != nil {
+                t.Fatalf("row %d: %v", i + 1, err)
+            }
+            rows[i] = append(rows[i], n)
+        }
+    }
+    return rows
+}
+
+func TestEdgeCSV(t *testing.T) {
+    for _, size := range [][2]int{{19, 10}, {1, 5}, {6, 1}, {12, 8}} {
+        m := goldenMaze(t, WithSize(size[0], size[1]), WithSeed(2), WithDepth(3))
+        edges := readCSV(t, func(b *bytes.Buffer) error {; return m.RenderEdgeCSV(b); }, "x1,y1,x2,y2")
+        if want := size[0]*size[1] - 1; len(edges) != want {
+            t.Errorf("%dx%d: %d edge rows, want %d", size[0], size[1], len(edges), want)
+        }
+        seen := map[[4]int]bool{}
+        for _, e := range edges {
+            if e[2] - e[0] + e[3] - e[1] != 1 || e[2] < e[0] || e[3] < e[1] {
+                t.Errorf("%dx%d: edge %v isn't from a cell to the one below or to the right", size[0], size[1], e)
+            }
+            if seen[[4]int(e)] {
+                t.Errorf("%dx%d: edge %v listed twice", size[0], size[1], e)
+            }
+            seen[[4]int(e)] = true
+        }
+    }
+}
+
+func TestNodeCSV(t *testing.T) {
+    m := goldenMaze(t, WithSize(19, 10), WithSeed(2))
+    nodes := readCSV(t, func(b *bytes.Buffer) error {; return m.RenderNodeCSV(b); }, "x,y,is_entrance,is_exit,on_solution")
+    if len(nodes) != 19*10 {
+        t.Fatalf("%d node rows, want %d", len(nodes), 19*10)
+    }
+    var entrances, exits, solution int
+    for _, n := range nodes {
+        entrances, exits, solution = entrances + n[2], exits + n[3], solution + n[4]
+    }
+    if entrances != 1 || exits != 1 || solution != m.SolutionLength() {
+        t.Errorf("%d entrances, %d exits and %d cells on the solution, want 1, 1 and %d", entrances, exits, solution, m.SolutionLength())
+    }
+}
@@ -0,0 +1,64 @@
+/* grid.go - Grid queries
+ *
+ * Lets other programs walk the structure of the maze in logical cell coordinates, those of Point, without
+ * the doubled locations of the maze array and the perimeter path around it.  The size of the maze in cells
+ * is given by Size, which is set to the size of the maze generated or loaded.
+ */
+package maze
+
+import (
+    "fmt"
+)
+
+// Directions of the sides of a cell, as taken by IsWall
+const (
+    Up Direction = iota
+    Right
+    Down
+    Left
+)
+
+// Direction is a side of a cell
+type Direction int
+
+// directionNames are the names of the directions, in order
+var directionNames = [4]string { "up", "right", "down", "left" }
+
+// String returns the name of the direction
+func (d Direction) String() string {
+    if d < Up || d > Left {
+        return fmt.Sprintf("Direction(%d)", int(d))
+    }
+    return directionNames[d]
+}
+
+// directionSteps gives the offsets to the adjacent cell in each direction
+var directionSteps = [4]Point { {-1, 0}, {0, 1}, {1, 0}, {0, -1} }
+
+// inMaze returns true if the logical cell x, y is inside the maze
+func (m *Maze) inMaze(x, y int) bool {
+    return 0 <= x && x < m.height && 0 <= y && y < m.width
+}
+
+// IsWall returns true if there's a wall on side dir of cell x, y.  The top of the entrance cell and the bottom of
+// the exit cell are open, the rest of the border is wall, and every side of a location outside the maze is a wall.
+func (m *Maze) IsWall(x, y int, dir Direction) bool {
+    if !m.inMaze(x, y) || dir < Up || dir > Left {
+        return true
+    }
+    i, j := cell{x, y}.loc()
+    step := directionSteps[dir]
+    return m.getMaze(i + step.X, j + step.Y) == wall
+}
+
+// Neighbors returns the cells joined to cell x, y by a passage, in the order up, right, down and left.  The entrance
+// and exit, outside the maze, aren't included, nor is anything for a location outside the maze.
+func (m *Maze) Neighbors(x, y int) []Point {
+    var points []Point
+    for dir, step := range directionSteps {
+        if n := (Point{x + step.X, y + step.Y}); !m.IsWall(x, y, Direction(dir)) && m.inMaze(n.X, n.Y) {
+            points = append(points, n)
+        }
+    }
+    return points
+}
@@ -0,0 +1,66 @@
+/* grid_test.go - Grid query tests
+ *
+ * Walks generated mazes through Neighbors and IsWall alone and checks that the neighbor graph is a spanning tree
+ * of the cells, that the two queries agree, and what they return at the border and outside the maze.
+ */
+package maze
+
+import (
+    "slices"
+    "testing"
+)
+
+func TestNeighborsSpanningTree(t *testing.T) {
+    for _, seed := range []int64{1, 2, 3} {
+        m := goldenMaze(t, WithSize(19, 10), WithSeed(seed), WithDepth(5))
+        edges, seen := 0, map[Point]bool{{0, 0}: true}
+        for queue := []Point{{0, 0}}; len(queue) > 0; queue = queue[1:] {
+            p := queue[0]
+            for dir, step := range directionSteps {
+                n := Point{p.X + step.X, p.Y + step.Y}
+                if open := slices.Contains(m.Neighbors(p.X, p.Y), n); open == m.IsWall(p.X, p.Y, Direction(dir)) && m.inMaze(n.X, n.Y) {
+                    t.Errorf("seed %d: %v %v is a neighbor %v but a wall %v", seed, p, Direction(dir), open, !open)
+                }
+            }
+            for _, n := range m.Neighbors(p.X, p.Y) {
+                if !slices.Contains(m.Neighbors(n.X, n.Y), p) {
+                    t.Errorf("seed %d: %v is a neighbor of %v but not the other way round", seed, n, p)
+                }
+                edges++
+                if !seen[n] {
+                    seen[n] = true
+                    queue = append(queue, n)
+                }
+            }
+        }
+        if cells := m.cfg.width*m.cfg.height; len(seen) != cells || edges/2 != cells - 1 {
+            t.Errorf("seed %d: %d cells reached by %d passages, want %d by %d for a spanning tree", seed, len(seen), edges/2, cells, cells - 1)
+        }
+    }
+}
+
+func TestGridBorder(t *testing.T) {
+    m := goldenMaze(t, WithSize(19, 10), WithSeed(1))
+    entrance, exit := getInt(&m.begY)/2 - 1, getInt(&m.endY)/2 - 1
+    for y := 0; y < m.cfg.width; y++ {
+        if got := m.IsWall(0, y, Up); got != (y != entrance) {
+            t.Errorf("top of cell 0, %d is a wall %v", y, got)
+        }
+        if got := m.IsWall(m.cfg.height - 1, y, Down); got != (y != exit) {
+            t.Errorf("bottom of cell %d, %d is a wall %v", m.cfg.height - 1, y, got)
+        }
+    }
+    for x := 0; x < m.cfg.height; x++ {
+        if !m.IsWall(x, 0, Left) || !m.IsWall(x, m.cfg.width - 1, Right) {
+            t.Errorf("row %d is open at the side of the maze", x)
+        }
+    }
+    for _, p := range []Point{{-1, entrance}, {m.cfg.height, exit}, {0, -1}, {0, m.cfg.width}} {
+        if n := m.Neighbors(p.X, p.Y); n != nil || !m.IsWall(p.X, p.Y, Down) {
+            t.Errorf("%v outside the maze has neighbors %v", p, n)
+        }
+    }
+    if !m.IsWall(0, 0, Direction(4)) {
+        t.Error("an unknown direction isn't a wall")
+    }
+}
@@ -0,0 +1,101 @@
+/* header.go - Generation parameters of ascii files
+ *
+ * Writes the parameters a maze was generated with as a block of # comment lines ahead of the "height width"
+ * header of the ascii format, so that a maze file says where it came from and, with its key, how to make it
+ * again.  Readers skip the block, the header staying the first line that isn't a comment, but a loaded maze
+ * keeps it and writes it back out as it was read, being the maze those parameters generated.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "time"
+    "strconv"
+    "strings"
+)
+
+// Param is a line of the comment block ahead of an ascii maze: a name=value parameter, or a comment kept as it was
+// written if it has no name
+type Param struct {
+    Name, Value string
+}
+
+// Params returns the parameters written ahead of the maze in the ascii format: those it was generated with, those read
+// with a loaded maze or none before a maze is generated.  The key is given when it regenerates the maze.  The time
+// it was generated is left out of a maze from a fixed seed or random number source, so that the same parameters
+// always write the same file.
+func (m *Maze) Params() []Param {
+    if m.loaded != nil {
+        return m.loaded.params
+    }
+    if m.params != nil {
+        return m.params
+    }
+    if getInt(&m.numMazeCreated) == 0 {
+        return nil
+    }
+    algorithm := m.cfg.algorithm
+    if algorithm == "" {
+        algorithm = DefaultAlgorithm
+    }
+    params := []Param {
+        {"version"      , Version                              },
+        {"algorithm"    , algorithm                            },
+        {"seed"         , strconv.FormatInt(m.getSeed(), 10)   },
+        {"depth"        , strconv.Itoa(m.depthVal)             },
+        {"threads"      , strconv.Itoa(getInt(&m.numThreads))  },
+        {"carve_threads", strconv.Itoa(m.carveThreads)         },
+        {"solve_threads", strconv.Itoa(m.solveThreads)         },
+        {"solve_length" , strconv.Itoa(getInt(&m.solveLength)) },
+    }
+    if g := m.getGenerated(); g != 0 && m.cfg.seed == 0 && m.cfg.randSource == nil {       // not while the first maze is being carved
+        params = append(params, Param{"generated", time.Unix(0, g).UTC().Format(time.RFC3339)})
+    }
+    if key := m.Key(); key != "" {
+        params = append(params, Param{"key", key})
+    }
+    return params
+}
+
+// Params returns the comment block read ahead of the maze, if any
+func (l *Loaded) Params() []Param {
+    return l.params
+}
+
+// writeParams writes the comment block of the ascii format, a line for each parameter
+func (m *Maze) writeParams(w io.Writer) {
+    for _, p := range m.Params() {
+        if p.Name == "" {
+            fmt.Fprintf(w, "#%s\n", p.Value)
+        } else {
+            fmt.Fprintf(w, "# %s=%s\n", p.Name, p.Value)
+        }
+    }
+}
+
+// commentLines returns the number of # comment lines at the start of the lines of an ascii file, leaving at least
+// the last line for the header
+func commentLines(lines []string) int {
+    n := 0
+    for n < len(lines) - 1 && strings.HasPrefix(lines[n], "#") {
+        n++
+    }
+    return n
+}
+
+// parseParams returns the parameters of the comment lines of an ascii file, a line without a name=value pair being
+// kept as a comment
+func parseParams(lines []string) []Param {
+    params := make([]Param, 0, len(lines))
+    for _, line := range lines {
+        text := strings.TrimPrefix(line, "#")
+        name, value, found := strings.Cut(strings.TrimSpace(text), "=")
+        if !found || name == "" || strings.ContainsAny(name, " \t") {
+            params = append(params, Param{"", text})
+        } else {
+            params = append(params, Param{name, value})
+        }
+    }
+    return params
+}
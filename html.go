@@ -0,0 +1,74 @@
+/* html.go - HTML pages of the maze
+ *
+ * Writes the maze as a single self-contained page for pasting into a wiki or a web site: a css grid of cells
+ * whose borders are the walls, each cell drawing just its own top and left walls, and those on the bottom and
+ * right edges of the maze too, so every wall is drawn once.  The cells of the solution have a solution class
+ * that a checkbox above the maze reveals, without any javascript.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "strings"
+)
+
+const htmlCell = 14                     // pixels from one cell to the next in a corridor one cell wide
+
+// htmlStyle returns the page's style sheet for a maze width cells across with cells size pixels apart, which
+// draws the walls of each class of cell
+func htmlStyle(width, size int) string {
+    var b strings.Builder
+    fmt.Fprintf(&b, ".maze{display:grid;grid-template-columns:repeat(%d,%dpx);grid-auto-rows:%dpx;width:max-content;margin:1em 0}\n", width, size, size)
+    fmt.Fprintf(&b, ".maze i{box-sizing:border-box;border:0 solid #000}\n")
+    for _, side := range []struct {
+        bit  int
+        name string
+    }{{WallNorth, "top"}, {WallSouth, "bottom"}, {WallEast, "right"}, {WallWest, "left"}} {
+        var classes []string
+        for mask := 1; mask <= WallNorth | WallSouth | WallEast | WallWest; mask++ {
+            if mask & side.bit != 0 {
+                classes = append(classes, fmt.Sprintf(".c%d", mask))
+            }
+        }
+        fmt.Fprintf(&b, "%s{border-%s-width:2px}\n", strings.Join(classes, ","), side.name)
+    }
+    fmt.Fprintf(&b, "#solution:checked~.maze .solution{background:#f0a8a8}\n")
+    return b.String()
+}
+
+// RenderHTML writes the maze to w as an html page, returning the first error from w.  The solution is the one Solve
+// returns, so it's there to reveal even if the maze was written without it.  Wide corridors are drawn by making the
+// cells larger rather than by widening the maze.
+func (m *Maze) RenderHTML(w io.Writer) error {
+    ew := &errWriter{w: w}
+    onSolution := map[Point]bool{}
+    for p := range m.SolutionCells() {
+        onSolution[p] = true
+    }
+    fmt.Fprintf(ew, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Maze %dx%d</title>\n<style>\n%s</style>\n</head>\n<body>\n",
+                m.width, m.height, htmlStyle(m.width, htmlCell*max(m.corridor, 1)))
+    if len(onSolution) > 0 {
+        fmt.Fprintf(ew, "<input type=\"checkbox\" id=\"solution\"><label for=\"solution\">Show the solution</label>\n")
+    }
+    fmt.Fprintf(ew, "<div class=\"maze\">\n")
+    for i := 2; i <= 2*m.height; i += 2 {
+        for j := 2; j <= 2*m.width; j += 2 {
+            drawn := WallNorth | WallWest                               // the others are drawn by the next cells
+            if i == 2*m.height {
+                drawn |= WallSouth
+            }
+            if j == 2*m.width {
+                drawn |= WallEast
+            }
+            class := fmt.Sprintf("c%d", m.wallMask(i, j) & drawn)
+            if c := cellAt(i, j); onSolution[Point{c.row, c.col}] {
+                class = "\"" + class + " solution\""
+            }
+            fmt.Fprintf(ew, "<i class=%s></i>", class)
+        }
+        fmt.Fprintf(ew, "\n")
+    }
+    fmt.Fprintf(ew, "</div>\n</body>\n</html>\n")
+    return ew.err
+}
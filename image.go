@@ -0,0 +1,117 @@
+/* image.go - The maze as an image
+ *
+ * Draws the maze, or any maze array such as a frame recorded during the generation, as an image for a program
+ * to composite into its own, the png format and the maze command's animated gif being encoded from the same
+ * drawing.  The walls are lines a quarter of the distance from one cell to the next thick unless given thicker or
+ * thinner, always leaving a pixel of passage between them, the solved locations a line down the middle of their
+ * passages and out through the openings, and the tried and checked cells are filled, each in its color if one is
+ * given.  The image is paletted, the path color first and the wall color
+ * second, followed by just the colors given, in the order of the FrameOptions fields.
+ */
+package maze
+
+import (
+    "image"
+    "image/color"
+)
+
+// FrameOptions chooses how Image draws the maze
+type FrameOptions struct {
+    Margin   int                    // pixels around the maze in the path color, 0 to MaxPNGMargin
+    WallPx   int                    // pixels thick the walls are drawn, 0 for a quarter of the scale, below the scale
+    Path     color.Color            // the color of the paths, nil for white
+    Wall     color.Color            // the color of the walls, nil for black
+    Solution color.Color            // the color of the solved locations, nil to draw them as paths
+    Tried    color.Color            // the color of the tried cells, nil to draw them as paths
+    Check    color.Color            // the color of the look ahead checks, nil to draw them as paths
+}
+
+// Image returns the maze as it stands drawn scale pixels from one cell to the next, its corridors widened, as
+// an *image.Paletted.  The scale is kept within MinPNGCellSize to MaxPNGCellSize, 0 being DefaultPNGCellSize.
+func (m *Maze) Image(scale int, opts FrameOptions) image.Image {
+    m = m.widened()
+    return ImageCells(getInt(&m.maxX), getInt(&m.maxY), m.getMaze, scale, opts)
+}
+
+// ImageCells returns a maze array of rows by cols locations, perimeter path included, drawn as Image draws a
+// maze, cell returning the state of each location: a frame recorded during the generation, say
+func ImageCells(arrayRows, arrayCols int, cell func(x, y int) int, scale int, opts FrameOptions) *image.Paletted {
+    if scale == 0 {
+        scale = DefaultPNGCellSize
+    }
+    scale       = min(max(scale, MinPNGCellSize), MaxPNGCellSize)
+    margin     := min(max(opts.Margin, 0), MaxPNGMargin)
+    wallPx     := max(1, scale/4)
+    if opts.WallPx > 0 {
+        wallPx  = min(opts.WallPx, scale - 1)
+    }
+    band       := max(1, (scale - wallPx)/3)                   // thickness of the solution line, odd when the
+    band       += (scale - wallPx - band) % 2                  // passage is so that it's centered in it
+    rows, cols := arrayRows - 2, arrayCols - 2                 // the locations inside the perimeter path
+    ys, xs     := pixelStarts(rows, wallPx, scale - wallPx, margin), pixelStarts(cols, wallPx, scale - wallPx, margin)
+
+    palette := color.Palette{color.White, color.Black}
+    if opts.Path != nil {; palette[0] = opts.Path; }
+    if opts.Wall != nil {; palette[1] = opts.Wall; }
+    index := map[int]uint8{}                                   // the palette index of the colors given, by cell value
+    for _, c := range []struct {
+        state int
+        color color.Color
+    } {
+        {solved, opts.Solution}, {tried, opts.Tried}, {check, opts.Check},
+    } {
+        if c.color != nil {
+            index[c.state] = uint8(len(palette))
+            palette = append(palette, c.color)
+        }
+    }
+    img := image.NewPaletted(image.Rect(0, 0, xs[cols + 1] + margin, ys[rows + 1] + margin), palette)
+    fill := func(x0, y0, x1, y1 int, index uint8) {
+        for y := y0; y < y1; y++ {
+            for x := x0; x < x1; x++ {
+                img.Pix[y*img.Stride + x] = index
+            }
+        }
+    }
+    isSolved := func(i, j int) bool {
+        return 1 <= i && i <= rows && 1 <= j && j <= cols && cell(i, j) == solved
+    }
+
+    for i := 1; i <= rows; i++ {
+        for j := 1; j <= cols; j++ {
+            state := cell(i, j)
+            k, colored := index[state]
+            switch {
+                case isDrawnWall(cell, i, j):
+                    fill(xs[j], ys[i], xs[j + 1], ys[i + 1], 1)
+                case colored && state == solved:
+                    bx := (xs[j] + xs[j + 1] - band)/2                // the square in the middle of the location
+                    by := (ys[i] + ys[i + 1] - band)/2
+                    fill(bx, by, bx + band, by + band, k)
+                    if isSolved(i - 1, j) || i == 1    {; fill(bx       , ys[i]    , bx + band, by       , k); }    // up, or out of the entrance
+                    if isSolved(i + 1, j) || i == rows {; fill(bx       , by + band, bx + band, ys[i + 1], k); }    // down, or out of the exit
+                    if isSolved(i, j - 1)              {; fill(xs[j]    , by       , bx       , by + band, k); }
+                    if isSolved(i, j + 1)              {; fill(bx + band, by       , xs[j + 1], by + band, k); }
+                case colored:
+                    fill(xs[j], ys[i], xs[j + 1], ys[i + 1], k)
+            }
+        }
+    }
+    return img
+}
+
+// pixelStarts returns the first pixel of each location from 1 to last across the maze array, and at last + 1 the
+// pixel just past the last, the walls at odd locations being wall pixels thick and the cells cell pixels
+func pixelStarts(last, wall, cell, margin int) []int {
+    starts := make([]int, last + 2)
+    p := margin
+    for i := 1; i <= last + 1; i++ {
+        starts[i] = p
+        if isOdd(i) {
+            p += wall
+        } else {
+            p += cell
+        }
+    }
+    return starts
+}
@@ -0,0 +1,166 @@
+/* image_test.go - Image tests
+ *
+ * Draws a small maze from a fixed seed with Image and compares it pixel by pixel with the golden image in
+ * testdata/golden, checks that the png format is the same drawing encoded and that drawing the maze leaves it
+ * as it was, and draws the walls at many thicknesses to check that they never close a passage.
+ */
+package maze
+
+import (
+    "io"
+    "os"
+    "fmt"
+    "math"
+    "bytes"
+    "image"
+    "image/png"
+    "strconv"
+    "testing"
+    "path/filepath"
+)
+
+// imageOptions are the options the image tests draw with
+var imageOptions = FrameOptions{Margin: 2, Solution: PNGSolutionColor}
+
+// samePixels returns the first pixel at which the images differ, and false, or true if they're alike
+func samePixels(a, b image.Image) (image.Point, bool) {
+    if a.Bounds() != b.Bounds() {
+        return a.Bounds().Max, false
+    }
+    r := a.Bounds()
+    for y := r.Min.Y; y < r.Max.Y; y++ {
+        for x := r.Min.X; x < r.Max.X; x++ {
+            ar, ag, ab, aa := a.At(x, y).RGBA()
+            br, bg, bb, ba := b.At(x, y).RGBA()
+            if ar != br || ag != bg || ab != bb || aa != ba {
+                return image.Pt(x, y), false
+            }
+        }
+    }
+    return image.Point{}, true
+}
+
+func TestGoldenImage(t *testing.T) {
+    m := goldenMaze(t, WithSize(12, 8), WithSeed(42), WithDepth(5))
+    img := m.Image(8, imageOptions)
+    path := filepath.Join("testdata", "golden", "12x8-seed42-depth5.png")
+    if *updateGolden {
+        var b bytes.Buffer
+        if err := png.Encode(&b, img); err != nil {
+            t.Fatal(err)
+        }
+        checkGolden(t, filepath.Base(path), b.Bytes())
+        return
+    }
+    f, err := os.Open(path)
+    if err != nil {
+        t.Fatalf("%v (run go test -update to create it)", err)
+    }
+    defer f.Close()
+    want, err := png.Decode(f)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if p, ok := samePixels(img, want); !ok {
+        t.Errorf("image differs from %s at %v", path, p)
+    }
+}
+
+// TestImageIsPNG checks that the png format is the image Image draws
+func TestImageIsPNG(t *testing.T) {
+    m := goldenMaze(t, WithSize(19, 10), WithSeed(1))
+    before := renderASCII(t, m)
+    img := m.Image(6, imageOptions)
+    var b bytes.Buffer
+    if err := m.RenderPNG(&b, PNGOptions{CellSize: 6, Margin: imageOptions.Margin, Solution: PNGSolutionColor}); err != nil {
+        t.Fatal(err)
+    }
+    decoded, err := png.Decode(&b)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if p, ok := samePixels(img, decoded); !ok {
+        t.Errorf("png differs from the image at %v", p)
+    }
+    if after := renderASCII(t, m); !bytes.Equal(after, before) {
+        t.Errorf("drawing the image changed the maze:\n%s\nbefore:\n%s", after, before)
+    }
+}
+
+// TestWallThickness draws a maze with walls from a pixel thick to a pixel short of the cell size and checks that no
+// wall pixel falls on a location that's open, a passage, a cell or an opening, and that the solution line runs down
+// the middle of the cells it passes through
+func TestWallThickness(t *testing.T) {
+    m := goldenMaze(t, WithSize(12, 8), WithSeed(42), WithDepth(5))
+    rows, cols := getInt(&m.maxX) - 2, getInt(&m.maxY) - 2
+    for _, scale := range []int{4, 8, 16} {
+        for wallPx := 1; wallPx < scale; wallPx++ {
+            opts := imageOptions
+            opts.WallPx = wallPx
+            img := m.Image(scale, opts).(*image.Paletted)
+            ys, xs := pixelStarts(rows, wallPx, scale - wallPx, opts.Margin), pixelStarts(cols, wallPx, scale - wallPx, opts.Margin)
+            if got := xs[2] - xs[1]; got != wallPx {
+                t.Fatalf("scale %d, walls %d pixels: drawn %d pixels thick", scale, wallPx, got)
+            }
+            for i := 1; i <= rows; i++ {
+                for j := 1; j <= cols; j++ {
+                    if m.getMaze(i, j) == wall {
+                        continue
+                    }
+                    for y := ys[i]; y < ys[i + 1]; y++ {
+                        for x := xs[j]; x < xs[j + 1]; x++ {
+                            if img.ColorIndexAt(x, y) == 1 {
+                                t.Fatalf("scale %d, walls %d pixels: wall pixel %d, %d on open location %d, %d", scale, wallPx, x, y, i, j)
+                            }
+                        }
+                    }
+                    if m.getMaze(i, j) != solved || isOdd(i) || isOdd(j) {
+                        continue
+                    }
+                    y, first, last := (ys[i] + ys[i + 1])/2, -1, -1          // the solution across the middle row
+                    for x := xs[j]; x < xs[j + 1]; x++ {
+                        if img.ColorIndexAt(x, y) == 2 {
+                            if first < 0 {
+                                first = x
+                            }
+                            last = x
+                        }
+                    }
+                    if m.getMaze(i, j - 1) != solved && m.getMaze(i, j + 1) != solved && first + last + 1 != xs[j] + xs[j + 1] {
+                        t.Fatalf("scale %d, walls %d pixels: solution at %d to %d isn't centered in cell %d, %d at %d to %d",
+                                 scale, wallPx, first, last, i, j, xs[j], xs[j + 1] - 1)
+                    }
+                }
+            }
+        }
+    }
+}
+
+// TestVectorWallThickness checks that the svg and pdf walls are drawn as thick as asked, and that walls a cell thick
+// are refused in svg and drawn no thicker than three quarters of a cell in pdf
+func TestVectorWallThickness(t *testing.T) {
+    m := goldenMaze(t, WithSize(12, 8), WithSeed(42))
+    for _, wallPx := range []int{1, 4, 9} {
+        var b bytes.Buffer
+        if err := m.RenderSVG(&b, SVGOptions{WallPx: wallPx}); err != nil {
+            t.Fatal(err)
+        }
+        if want := fmt.Sprintf("stroke=\"black\" stroke-width=\"%d\"", wallPx); !bytes.Contains(b.Bytes(), []byte(want)) {
+            t.Errorf("svg walls %d thick: no %s", wallPx, want)
+        }
+    }
+    if err := m.RenderSVG(io.Discard, SVGOptions{WallPx: svgCell}); err == nil {
+        t.Errorf("svg walls a cell thick: no error")
+    }
+    for _, wallPx := range []int{2, pdfMaxCell} {
+        var b bytes.Buffer
+        if err := m.RenderPDF(&b, PDFOptions{WallPx: wallPx}); err != nil {
+            t.Fatal(err)
+        }
+        _, _, scale, _, _ := pdfLayout(PDFOptions{}, 2*8, 2*12)
+        want := strconv.FormatFloat(math.Min(float64(wallPx)/scale, 1.5), 'f', -1, 64) + " w 2 J"
+        if !bytes.Contains(b.Bytes(), []byte(want)) {
+            t.Errorf("pdf walls %d points thick: no %q", wallPx, want)
+        }
+    }
+}
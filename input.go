@@ -0,0 +1,203 @@
+/* input.go - Maze input
+ *
+ * Parses mazes written in the portable ascii format, validating the dimensions and every character as
+ * strictly as possible since the files may come from anywhere.  Files drawn with box drawing characters are
+ * read as the plain characters they stand for.
+ */
+package maze
+
+import (
+    "fmt"
+    "strings"
+    "strconv"
+)
+
+// boxChars maps each box drawing character of an ascii file to the plain character written in its place
+var boxChars = func() *strings.Replacer {
+    pairs := []string{string(boxBlockChar), string(blockChar), string(boxSolved), "*"}
+    for _, lookup := range lineLookups {
+        for k, r := range lookup {
+            if r != ' ' {
+                pairs = append(pairs, string(r), string(simpleLookup[k]))
+            }
+        }
+    }
+    return strings.NewReplacer(pairs...)
+}()
+
+// Loaded is a maze read from a file, in the maze array layout including the perimeter path
+type Loaded struct {
+    height, width int
+    grid          mazeGrid
+    lines         []string      // the ascii rows as read, without the header
+    params        []Param       // the comment block ahead of the header
+    begY, endY    int           // columns of the top and bottom openings
+    transposed    bool          // read from an image open on its left and right, its rows as columns
+}
+
+// Size returns the height and width of the maze in cells
+func (m *Loaded) Size() (int, int) {
+    return m.height, m.width
+}
+
+// Transposed returns true if the maze was read from an image with its openings in the left and right borders, its
+// rows becoming columns so that they're in the top and bottom
+func (m *Loaded) Transposed() bool {
+    return m.transposed
+}
+
+// markerColumn returns the column of the single marker character in a row of spaces, which must be above or
+// below a wall location between two cells of a maze of the given width
+func markerColumn(line string, marker byte, width int) (int, error) {
+    c := strings.IndexByte(line, marker)
+    if c < 0 || strings.Trim(line, " " + string(marker)) != "" || strings.Count(line, string(marker)) != 1 {
+        return 0, fmt.Errorf("expected a single %c marker", marker)
+    }
+    if len(line) > 2*width + 1 || isEven(c) {
+        return 0, fmt.Errorf("%c marker at column %d is not above or below a cell", marker, c + 1)
+    }
+    return c, nil
+}
+
+// ParseASCII parses a maze in the portable ascii format: any # comment lines, kept as the parameters of the maze, then
+// a "height width" header followed by 2*height+1 rows
+// of 2*width+1 characters, where walls are drawn with + - and |, and the entrance and exit are the single openings,
+// one or more cells wide, in the top and bottom rows.  Cells may be blank or marked solved (*), tried (.),
+// checked (#) or with a branch digit, or solid (| or a block wall), as those a mask leaves out are written.
+// If the rows are preceded by a row with an S marker and followed by one with an E marker, as written with
+// --mark-openings, the markers give the entrance and exit and the border is open there whatever is drawn.
+// Rows without any + - or | characters are in the block wall style, where every wall location is a # or a █.
+// Vertices may be open, as they are inside the corridors written with --corridor.  The walls may be drawn with unicode
+// box drawing characters and the solution with middle dots instead.
+func ParseASCII(text string) (*Loaded, error) {
+    text   = boxChars.Replace(text)
+    lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+    for i := range lines {
+        lines[i] = strings.TrimSuffix(lines[i], "\r")
+    }
+    skip   := commentLines(lines)                   // the comment block ahead of the header
+    params := parseParams(lines[:skip])
+    lines   = lines[skip:]
+    fields := strings.Fields(lines[0])
+    if len(fields) != 2 {
+        return nil, fmt.Errorf("line %d: expected a \"height width\" header", skip + 1)
+    }
+    h, errH := strconv.Atoi(fields[0])
+    w, errW := strconv.Atoi(fields[1])
+    if errH != nil || errW != nil || h < 1 || h > maxHeight || w < 1 || w > maxWidth {
+        return nil, fmt.Errorf("line %d: invalid maze size %q (height 1-%d, width 1-%d)", skip + 1, lines[0], maxHeight, maxWidth)
+    }
+    m := &Loaded{height: h, width: w, lines: lines[1:], params: params}
+    first, markS, markE := skip + 2, -1, -1         // line number of the first row and the marker columns, if any
+    if len(m.lines) == 2*h + 3 {
+        var err error
+        if markS, err = markerColumn(m.lines[0], 'S', w); err != nil {
+            return nil, fmt.Errorf("line %d: %v", first, err)
+        }
+        if markE, err = markerColumn(m.lines[len(m.lines) - 1], 'E', w); err != nil {
+            return nil, fmt.Errorf("line %d: %v", skip + len(lines), err)
+        }
+        m.lines, first = m.lines[1:len(m.lines) - 1], first + 1
+    }
+    if len(m.lines) != 2*h + 1 {
+        return nil, fmt.Errorf("line %d: expected %d rows for height %d, found %d", skip + 1, 2*h + 1, h, len(m.lines))
+    }
+    vertices, block := "+-| ", !strings.ContainsAny(strings.Join(m.lines, ""), "+-|")
+    if block {
+        vertices = string(blockChar)
+    }
+
+    var opens [2][]int                              // columns of the openings in the top and bottom walls
+    for r, line := range m.lines {
+        if len(line) != 2*w + 1 {
+            return nil, fmt.Errorf("line %d: expected %d characters for width %d, found %d", r + first, 2*w + 1, w, len(line))
+        }
+        for c := 0; c < len(line); c++ {
+            ch, x, y := line[c], r + 1, c + 1           // the ascii rows start inside the perimeter path
+            state, ok := path, true
+            border, marked := r == 0 || r == 2*h, r == 0 && c == markS || r == 2*h && c == markE
+            switch {
+                case border && isOdd(y) && ch == ' ' && c > 0 && c < 2*w:  // a vertex inside a wide opening
+                case isOdd(x) && isOdd(y) && ch == '*'  : state = solved   // the solution down the middle of a wide corridor
+                case isOdd(x) && isOdd(y) && ch == '.'  : state = tried
+                case isOdd(x) && isOdd(y) && ch == ' ' && block:           // inside a wide corridor
+                case isOdd(x) && isOdd(y)  : state, ok = wall, strings.IndexByte(vertices, ch) >= 0
+                case isEven(x) && isEven(y):
+                    switch {
+                        case ch == '*'            : state = solved
+                        case ch == '.'            : state = tried
+                        case ch == '#'            : state = check
+                        case ch == '|' || block && ch == blockChar:
+                                                    state = wall       // a solid cell, as a mask leaves out
+                        case '1' <= ch && ch <= '9':
+                        default                   : ok = ch == ' '
+                    }
+                case marked:                                               // open whatever is drawn
+                    opens[bool2int(r != 0)], ok = append(opens[bool2int(r != 0)], y), strings.IndexByte(" *-#", ch) >= 0
+                    if ch == '*' {; state = solved; }
+                case ch == '-' && isOdd(x) || ch == '|' && isOdd(y) || ch == blockChar && block:
+                                                    state = wall
+                case c == 0 || c == 2*w:
+                                                    ok = false
+                case border:                                               // the entrance or exit opening
+                    opens[bool2int(r != 0)], ok = append(opens[bool2int(r != 0)], y), ch == ' ' || ch == '*'
+                    if ch == '*' {; state = solved; }
+                default:
+                    switch ch {
+                        case ' ': state = path
+                        case '*': state = solved
+                        case '.': state = tried
+                        case '#': state = check
+                        default : ok = false
+                    }
+            }
+            if !ok {
+                return nil, fmt.Errorf("line %d, column %d: unexpected character %q", r + first, c + 1, ch)
+            }
+            m.grid[x][y] = int32(state)
+        }
+    }
+    var err error
+    if m.begY, err = borderOpening(&m.grid[1], opens[0], markS + 1, "top"); err != nil {
+        return nil, fmt.Errorf("line %d: %v", first, err)
+    }
+    if m.endY, err = borderOpening(&m.grid[2*h + 1], opens[1], markE + 1, "bottom"); err != nil {
+        return nil, fmt.Errorf("line %d: %v", first + 2*h, err)
+    }
+    return m, nil
+}
+
+// borderOpening returns the column of the cell to start from or finish at given the columns of the openings
+// found in a border row, which must form a single opening one or more cells wide.  That's the marked column
+// if there is one, otherwise the middle of the opening.
+func borderOpening(row *[maxYSize]int32, open []int, markY int, side string) (int, error) {
+    if len(open) == 0 {
+        return 0, fmt.Errorf("missing the opening in the %s wall", side)
+    }
+    for i := 1; i < len(open); i++ {
+        if open[i] != open[i-1] + 2 || row[open[i] - 1] == wall {
+            return 0, fmt.Errorf("more than one opening in the %s wall", side)
+        }
+    }
+    lo, hi := open[0], open[len(open) - 1]
+    if markY > 0 {
+        return markY, nil
+    }
+    return lo + 2*((hi - lo)/4), nil
+}
+
+// installMaze makes a loaded maze the current maze, taking the dimensions and openings from it
+func (m *Maze) installMaze(l *Loaded) {
+    m.height, m.width = l.height, l.width
+    setInt(&m.maxX, 2*(m.height + 1) + 1)
+    setInt(&m.maxY, 2*(m.width  + 1) + 1)
+    setInt(&m.begX, 2)
+    setInt(&m.endX, 2*m.height)
+    setInt(&m.begY, l.begY)
+    setInt(&m.endY, l.endY)
+    for i := 0; i < getInt(&m.maxX); i++ {
+        for j := 0; j < getInt(&m.maxY); j++ {
+            m.setMaze(i, j, int(l.grid[i][j]))
+        }
+    }
+}
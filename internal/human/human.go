@@ -0,0 +1,38 @@
+/* human.go - Human readable numbers
+ *
+ * The one place durations and counts are formatted for people to read, e.g. 1m23.4s and 12.3k, so that the
+ * status line and the reports agree.  Machine readable output always uses the raw numbers instead.  It's internal
+ * so that how the numbers are shortened isn't part of the library's API.
+ */
+package human
+
+import (
+    "fmt"
+    "time"
+)
+
+// Duration returns a duration as milliseconds below a second, then as seconds to a tenth, e.g. 45.6s,
+// 1m23.4s or 2h05m07s
+func Duration(d time.Duration) string {
+    switch {
+        case d < time.Second: return fmt.Sprintf("%dms", d.Milliseconds())
+        case d < time.Minute: return fmt.Sprintf("%.1fs", d.Seconds())
+        case d < time.Hour  : return fmt.Sprintf("%dm%.1fs", int(d.Minutes()), (d % time.Minute).Seconds())
+    }
+    return fmt.Sprintf("%dh%02dm%02ds", int(d.Hours()), int(d.Minutes()) % 60, int(d.Seconds()) % 60)
+}
+
+// Count returns a count as is below a thousand, otherwise to one decimal place in thousands, millions
+// or billions, e.g. 12.3k
+func Count(n int) string {
+    if n > -1000 && n < 1000 {
+        return fmt.Sprintf("%d", n)
+    }
+    v := float64(n)
+    for _, unit := range []string{"k", "M"} {
+        if v /= 1000; v > -999.95 && v < 999.95 {
+            return fmt.Sprintf("%.1f%s", v, unit)
+        }
+    }
+    return fmt.Sprintf("%.1fG", v / 1000)
+}
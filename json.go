@@ -0,0 +1,259 @@
+/* json.go - JSON maze files
+ *
+ * Writes the maze for other programs as json: its size, seed and openings, a bitmask of the walls around each
+ * cell and, if it's marked, the solution as the cells along it.  The cells are encoded a row at a time so the
+ * largest maze isn't built up in memory first.  ParseJSON reads the same structure back, validating it as
+ * strictly as ParseASCII does its files since they too may come from anywhere.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "bytes"
+    "encoding/json"
+)
+
+// Bits of a cell's wall mask in the json format, set for each side of the cell with a wall
+const (
+    WallNorth = 1 << iota
+    WallSouth
+    WallEast
+    WallWest
+)
+
+// jsonMaze is the structure of the json format, in logical cell coordinates.  The entrance is above cell begX, begY
+// in the top row and the exit below cell endX, endY in the bottom row, though either opening may be wider.  The
+// solution runs from the entrance, at row -1, to the exit, at row height, as Solve returns it.
+type jsonMaze struct {
+    Width    int      `json:"width"`
+    Height   int      `json:"height"`
+    Seed     int64    `json:"seed"`
+    BegX     int      `json:"begX"`
+    BegY     int      `json:"begY"`
+    EndX     int      `json:"endX"`
+    EndY     int      `json:"endY"`
+    Cells    [][]int  `json:"cells"`
+    Solution [][2]int `json:"solution"`
+}
+
+// wallMask returns the json format's bitmask of the walls around the cell at location x, y
+func (m *Maze) wallMask(x, y int) int {
+    isWall := func(x, y int) int {; return bool2int(isShownWall(m.getMaze(x, y), false)); }
+    return isWall(x - 1, y)*WallNorth | isWall(x + 1, y)*WallSouth | isWall(x, y + 1)*WallEast | isWall(x, y - 1)*WallWest
+}
+
+// RenderJSON writes the maze to w in the json format, a row of cells to a line, returning the first error from w.
+// The corridors aren't widened, the cells being those of the maze itself, and the seed of a loaded maze is 0.
+func (m *Maze) RenderJSON(w io.Writer) error {
+    ew := &errWriter{w: w}
+    var buf bytes.Buffer
+    enc := json.NewEncoder(&buf)
+    put := func(v any, sep string) {                                // v encoded followed by sep rather than a newline
+        buf.Reset()
+        if err := enc.Encode(v); err != nil && ew.err == nil {
+            ew.err = err
+        }
+        ew.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+        io.WriteString(ew, sep)
+    }
+
+    seed := m.getSeed()
+    if m.loaded != nil {
+        seed = 0
+    }
+    beg, end := cellAt(getInt(&m.begX), getInt(&m.begY)), cellAt(getInt(&m.endX), getInt(&m.endY))
+    fmt.Fprintf(ew, "{\"width\":%d,\"height\":%d,\"seed\":%d,\"begX\":%d,\"begY\":%d,\"endX\":%d,\"endY\":%d,\n\"cells\":[\n",
+                m.width, m.height, seed, beg.row, beg.col, end.row, end.col)
+    row := make([]int, m.width)
+    for i := 2; i <= 2*m.height; i += 2 {
+        for j := 2; j <= 2*m.width; j += 2 {
+            row[j/2 - 1] = m.wallMask(i, j)
+        }
+        sep := ",\n"
+        if i == 2*m.height {
+            sep = "\n"
+        }
+        put(row, sep)
+    }
+    var solution [][2]int
+    for p := range m.markedSolution() {
+        solution = append(solution, [2]int{p.X, p.Y})
+    }
+    if solution != nil {
+        io.WriteString(ew, "],\n\"solution\":")
+        put(solution, "}\n")
+    } else {
+        io.WriteString(ew, "]}\n")
+    }
+    return ew.err
+}
+
+// ParseJSON parses a maze in the json format, as written by RenderJSON.  Walls shared by two cells must agree, the
+// border must be closed but for one opening in the top and one in the bottom, with begX, begY and endX, endY under
+// them, and the solution, which may be left out, must run through open walls from the entrance to the exit.
+func ParseJSON(r io.Reader) (*Loaded, error) {
+    var j jsonMaze
+    dec := json.NewDecoder(r)
+    if err := dec.Decode(&j); err != nil {
+        return nil, fmt.Errorf("invalid json: %v", err)
+    }
+    if _, err := dec.Token(); err != io.EOF {
+        return nil, fmt.Errorf("unexpected data after the maze")
+    }
+    return j.loaded()
+}
+
+// loaded returns the maze the structure describes, validating it as ParseJSON does.  The binary format is read
+// into the same structure.
+func (j *jsonMaze) loaded() (*Loaded, error) {
+    h, w := j.Height, j.Width
+    if h < 1 || h > maxHeight || w < 1 || w > maxWidth {
+        return nil, fmt.Errorf("invalid maze size %dx%d (height 1-%d, width 1-%d)", w, h, maxHeight, maxWidth)
+    }
+    if len(j.Cells) != h {
+        return nil, fmt.Errorf("expected %d rows of cells for height %d, found %d", h, h, len(j.Cells))
+    }
+    m := &Loaded{height: h, width: w}
+    for x := 1; x <= 2*h + 1; x += 2 {                              // the corner posts
+        for y := 1; y <= 2*w + 1; y += 2 {
+            m.grid[x][y] = wall
+        }
+    }
+    for r, cells := range j.Cells {
+        if len(cells) != w {
+            return nil, fmt.Errorf("row %d: expected %d cells for width %d, found %d", r, w, w, len(cells))
+        }
+        for c, mask := range cells {
+            x, y := cell{r, c}.loc()
+            sides := []struct {
+                bit, x, y int
+                name      string
+                border    bool
+            }{
+                {WallNorth, x - 1, y, "north", false},                      // the openings are checked below
+                {WallSouth, x + 1, y, "south", false},
+                {WallEast , x, y + 1, "east" , c == w - 1},
+                {WallWest , x, y - 1, "west" , c == 0},
+            }
+            if mask < 0 || mask > WallNorth | WallSouth | WallEast | WallWest {
+                return nil, fmt.Errorf("row %d, column %d: invalid wall mask %d", r, c, mask)
+            }
+            for _, s := range sides {
+                isWall, shared := mask & s.bit != 0, s.bit == WallNorth && r > 0 || s.bit == WallWest && c > 0
+                switch {
+                    case s.border && !isWall:
+                        return nil, fmt.Errorf("row %d, column %d: missing the %s border wall", r, c, s.name)
+                    case shared && isWall != (m.grid[s.x][s.y] == wall):
+                        return nil, fmt.Errorf("row %d, column %d: the %s wall disagrees with the neighbouring cell", r, c, s.name)
+                    case isWall:
+                        m.grid[s.x][s.y] = wall
+                }
+            }
+        }
+    }
+
+    var opens [2][]int                                              // columns of the openings in the top and bottom walls
+    for k, x := range []int{1, 2*h + 1} {
+        for y := 2; y <= 2*w; y += 2 {
+            if m.grid[x][y] != wall {
+                if len(opens[k]) > 0 && opens[k][len(opens[k]) - 1] == y - 2 {
+                    m.grid[x][y - 1] = path                         // a corner post inside a wide opening
+                }
+                opens[k] = append(opens[k], y)
+            }
+        }
+    }
+    switch {
+        case j.BegX != 0 || j.BegY < 0 || j.BegY >= w || m.grid[1][2*j.BegY + 2] == wall:
+            return nil, fmt.Errorf("the entrance at row %d, column %d isn't under an opening in the top wall", j.BegX, j.BegY)
+        case j.EndX != h - 1 || j.EndY < 0 || j.EndY >= w || m.grid[2*h + 1][2*j.EndY + 2] == wall:
+            return nil, fmt.Errorf("the exit at row %d, column %d isn't over an opening in the bottom wall", j.EndX, j.EndY)
+    }
+    var err error
+    if m.begY, err = borderOpening(&m.grid[1], opens[0], 2*j.BegY + 2, "top"); err != nil {
+        return nil, err
+    }
+    if m.endY, err = borderOpening(&m.grid[2*h + 1], opens[1], 2*j.EndY + 2, "bottom"); err != nil {
+        return nil, err
+    }
+    if err := m.markSolution(j.Solution); err != nil {
+        return nil, fmt.Errorf("solution: %v", err)
+    }
+    m.lines = m.drawLines()
+    return m, nil
+}
+
+// markSolution marks the solution read from a json file as solved, checking that it runs from the entrance opening
+// to the exit one, each cell next to the one before it with no wall between them.  The ends may be anywhere in their
+// openings, the route running along the border to the cell next to them.
+func (m *Loaded) markSolution(solution [][2]int) error {
+    if len(solution) == 0 {
+        return nil
+    }
+    if len(solution) < 3 || solution[0][0] != -1 || solution[len(solution) - 1][0] != m.height {
+        return fmt.Errorf("expected a route from row -1 above the entrance to row %d below the exit", m.height)
+    }
+    var route []point
+    for i, p := range solution {
+        x, y := 2*(p[0] + 1), 2*(p[1] + 1)
+        switch {
+            case p[1] < 0 || p[1] >= m.width || (p[0] < 0 || p[0] >= m.height) && i > 0 && i < len(solution) - 1:
+                return fmt.Errorf("point %d (%d, %d) is outside the maze", i, p[0], p[1])
+            case i == 0                : x = 1
+            case i == len(solution) - 1: x = 2*m.height + 1
+        }
+        route = append(route, point{x, y})
+    }
+    for i, p := range route {
+        m.grid[p.x][p.y] = solved
+        if i == 0 {
+            continue
+        }
+        q := route[i - 1]
+        if i == len(route) - 1 {
+            q, p = p, q                                             // from the exit back to the last cell
+        }
+        if q.x == 1 || q.x == 2*m.height + 1 {                      // along the border to the cell next to it
+            if p.x - q.x != 1 && q.x - p.x != 1 {
+                return fmt.Errorf("point %d (%d, %d) isn't next to its opening", i, solution[i][0], solution[i][1])
+            }
+            for y := q.y; ; y += sign(p.y - y) {
+                if m.grid[q.x][y] == wall {
+                    return fmt.Errorf("point %d (%d, %d) isn't in its opening", i, solution[i][0], solution[i][1])
+                }
+                if m.grid[q.x][y] = solved; y == p.y {
+                    break
+                }
+            }
+            continue
+        }
+        mid := point{(p.x + q.x)/2, (p.y + q.y)/2}
+        if (p.x - q.x)*(p.x - q.x) + (p.y - q.y)*(p.y - q.y) != 4 || m.grid[mid.x][mid.y] == wall {
+            return fmt.Errorf("point %d (%d, %d) isn't next to the one before with no wall between them", i, solution[i][0], solution[i][1])
+        }
+        m.grid[mid.x][mid.y] = solved
+    }
+    return nil
+}
+
+// drawLines returns the ascii rows of a maze read from a format other than ascii, as Diff draws them
+func (m *Loaded) drawLines() []string {
+    lines := make([]string, 0, 2*m.height + 1)
+    for x := 1; x <= 2*m.height + 1; x++ {
+        line := make([]byte, 0, 2*m.width + 1)
+        for y := 1; y <= 2*m.width + 1; y++ {
+            ch := byte(' ')
+            switch {
+                case m.grid[x][y] == solved              : ch = '*'
+                case m.grid[x][y] != wall                :
+                case isOdd(x) && isOdd(y)                : ch = '+'
+                case isOdd(x)                            : ch = '-'
+                default                                  : ch = '|'
+            }
+            line = append(line, ch)
+        }
+        lines = append(lines, string(line))
+    }
+    return lines
+}
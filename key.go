@@ -0,0 +1,128 @@
+/* key.go - Maze keys
+ *
+ * A key is a short string recording the parameters that decide the maze generated, its size, seed, depth,
+ * gap and algorithm, so that the maze can be generated again from the key alone.  It's the URL safe base64
+ * encoding of a format version followed by the parameters as varints, and a key of a later format version,
+ * or with fields this version doesn't know, is an error rather than a different maze.
+ */
+package maze
+
+import (
+    "context"
+    "encoding/base64"
+    "encoding/binary"
+    "fmt"
+)
+
+const keyVersion = 1
+
+// MazeKey is the parameters that decide a maze, as encoded in a key
+type MazeKey struct {
+    Width, Height int
+    Seed          int64
+    Depth         int
+    Gap           int
+    Algorithm     string
+}
+
+// String returns the key encoding the parameters
+func (k MazeKey) String() string {
+    b := binary.AppendUvarint(nil, keyVersion)
+    b  = binary.AppendUvarint(b, uint64(k.Width))
+    b  = binary.AppendUvarint(b, uint64(k.Height))
+    b  = binary.AppendVarint( b, k.Seed)
+    b  = binary.AppendUvarint(b, uint64(k.Depth))
+    b  = binary.AppendUvarint(b, uint64(k.Gap))
+    b  = binary.AppendUvarint(b, uint64(len(k.Algorithm)))
+    b  = append(b, k.Algorithm...)
+    return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParseMazeKey returns the parameters encoded in a key, or an error if it isn't a valid key of a format version
+// this version knows
+func ParseMazeKey(key string) (MazeKey, error) {
+    b, err := base64.RawURLEncoding.DecodeString(key)
+    if err != nil {
+        return MazeKey{}, fmt.Errorf("invalid maze key")
+    }
+    bad := false
+    next := func() uint64 {
+        v, n := binary.Uvarint(b)
+        if n <= 0 {
+            bad = true
+            return 0
+        }
+        b = b[n:]
+        return v
+    }
+    if v := next(); bad || v == 0 {
+        return MazeKey{}, fmt.Errorf("invalid maze key")
+    } else if v > keyVersion {
+        return MazeKey{}, fmt.Errorf("maze key format version %d is newer than this version knows (%d)", v, keyVersion)
+    }
+    var k MazeKey
+    k.Width, k.Height = int(next()), int(next())
+    seed, n := binary.Varint(b)
+    if bad || n <= 0 {
+        return MazeKey{}, fmt.Errorf("invalid maze key")
+    }
+    b = b[n:]
+    k.Seed = seed
+    k.Depth, k.Gap = int(next()), int(next())
+    if l := next(); !bad && l <= uint64(len(b)) {
+        k.Algorithm, b = string(b[:l]), b[l:]
+    } else {
+        bad = true
+    }
+    switch {
+        case bad:
+            return MazeKey{}, fmt.Errorf("invalid maze key")
+        case len(b) > 0:
+            return MazeKey{}, fmt.Errorf("maze key has fields this version doesn't know")
+        case k.Width < 1 || k.Width > MaxWidth || k.Height < 1 || k.Height > MaxHeight || k.Seed == 0 ||
+             k.Depth > MaxDepth || k.Gap < 1 || k.Gap > k.Width:
+            return MazeKey{}, fmt.Errorf("maze key parameters are out of range")
+    }
+    return k, nil
+}
+
+// Key returns the key of the maze last generated, or "" if it can't be generated again from a key: if it was
+// loaded, masked, carved or solved with threads or drawn from a source given by WithRandSource, or if no maze has been generated.
+// Each attempt at a minimum solution length or asymmetry is carved from a seed of its own, and the key has the
+// seed of the attempt accepted, so the maze is generated again from the key at the first attempt without them.
+func (m *Maze) Key() string {
+    if getInt(&m.numMazeCreated) == 0 || m.getSeed() == 0 || m.loaded != nil || m.mask != nil || m.carveThreads > 0 || m.solveThreads > 0 || m.cfg.randSource != nil {
+        return ""
+    }
+    algorithm := m.cfg.algorithm
+    if algorithm == "" {
+        algorithm = DefaultAlgorithm
+    }
+    return MazeKey{m.width, m.height, m.getSeed(), m.depthVal, m.gap, algorithm}.String()
+}
+
+// useKey sets the parameters from a key, generating single threaded from the built-in random number source and
+// accepting the first maze, so that the maze generated is the one the key was taken from
+func (m *Maze) useKey(k MazeKey) error {
+    c := &m.cfg
+    c.width, c.height, c.seed, c.depth, c.gap, c.algorithm = k.Width, k.Height, k.Seed, k.Depth, k.Gap, k.Algorithm
+    c.carveThreads, c.solveThreads, c.minLength, c.maxAsymmetry = 0, 0, 0, 0
+    c.randSource, c.mask, m.loaded, m.resumeState = nil, nil, nil, nil
+    setInt(&m.numMazeCreated, 0)
+    _, err := m.generator()
+    return err
+}
+
+// GenerateFromKey generates the maze a key was taken from, returning an error if the key is invalid or names an
+// unknown algorithm, or any error of Generate
+func (m *Maze) GenerateFromKey(ctx context.Context, key string) error {
+    k, err := ParseMazeKey(key)
+    if err != nil {
+        return err
+    }
+    if err := m.useKey(k); err != nil {
+        return err
+    }
+    _, err = m.Generate(ctx)
+    return err
+}
@@ -0,0 +1,103 @@
+/* key_test.go - Maze key tests
+ *
+ * Round trips keys through their encoding, regenerates mazes from the keys they give and compares them with the
+ * originals, including mazes accepted after several attempts at a minimum solution length or asymmetry, and checks
+ * that keys of a newer format version, or with fields this version doesn't know, fail.
+ */
+package maze
+
+import (
+    "bytes"
+    "context"
+    "strings"
+    "testing"
+    "encoding/base64"
+    "encoding/binary"
+)
+
+func TestKeyRoundTrip(t *testing.T) {
+    for _, k := range []MazeKey{
+        {1, 1, 1, 0, 1, "backtrack"},
+        {19, 10, -42, 5, 3, DefaultAlgorithm},
+        {MaxWidth, MaxHeight, 1 << 62, MaxDepth, MaxWidth, "sidewinder"},
+    } {
+        got, err := ParseMazeKey(k.String())
+        if err != nil {
+            t.Errorf("%+v: %v", k, err)
+        } else if got != k {
+            t.Errorf("%+v: parsed back as %+v", k, got)
+        }
+    }
+}
+
+func TestGenerateFromKey(t *testing.T) {
+    for _, seed := range []int64{1, 2, 3} {
+        m   := goldenMaze(t, WithSize(19, 10), WithSeed(seed), WithDepth(3))
+        key := m.Key()
+        if key == "" {
+            t.Fatalf("seed %d: no key for a single threaded maze", seed)
+        }
+        again := newMaze()
+        if err := again.GenerateFromKey(context.Background(), key); err != nil {
+            t.Fatalf("seed %d: key %s: %v", seed, key, err)
+        }
+        if got, want := marshalText(t, again), marshalText(t, m); !bytes.Equal(got, want) {
+            t.Errorf("seed %d: key %s generated\n%s\nwant\n%s", seed, key, got, want)
+        }
+    }
+    if key := goldenMaze(t, WithSize(19, 10), WithSeed(1), WithSolveThreads(2)).Key(); key != "" {
+        t.Errorf("key %s for a maze solved with threads", key)
+    }
+}
+
+// TestKeyAfterAttempts regenerates mazes whose first attempt from the seed is too short or too lopsided from their
+// keys, which are of the attempt accepted and give it in one attempt
+func TestKeyAfterAttempts(t *testing.T) {
+    for _, tc := range []struct {
+        name string
+        seed int64
+        opt  Option
+    }{
+        {"minimum length", 3, WithMinSolutionLength(60)},
+        {"asymmetry"     , 1, WithMaxAsymmetry(1.2)    },
+    } {
+        m := goldenMaze(t, WithSize(19, 10), WithSeed(tc.seed), tc.opt)
+        if getInt(&m.numMazeCreated) < 2 {
+            t.Fatalf("%s: accepted the first attempt", tc.name)
+        }
+        key := m.Key()
+        if key == "" {
+            t.Fatalf("%s: no key after %d attempts", tc.name, getInt(&m.numMazeCreated))
+        }
+        again := newMaze()
+        if err := again.GenerateFromKey(context.Background(), key); err != nil {
+            t.Fatalf("%s: key %s: %v", tc.name, key, err)
+        }
+        if n := getInt(&again.numMazeCreated); n != 1 {
+            t.Errorf("%s: key %s took %d attempts", tc.name, key, n)
+        }
+        if got, want := solvedASCII(t, again), solvedASCII(t, m); !bytes.Equal(got, want) {
+            t.Errorf("%s: key %s generated\n%s\nwant\n%s", tc.name, key, got, want)
+        }
+    }
+}
+
+func TestKeyUnknownFields(t *testing.T) {
+    valid, _ := base64.RawURLEncoding.DecodeString(MazeKey{19, 10, 1, 5, 1, DefaultAlgorithm}.String())
+    newer := append(binary.AppendUvarint(nil, keyVersion + 1), valid[1:]...)
+    for _, tc := range []struct {
+        name, key, want string
+    }{
+        {"extra field"  , base64.RawURLEncoding.EncodeToString(append(valid, 7)), "fields this version doesn't know"},
+        {"newer version", base64.RawURLEncoding.EncodeToString(newer)           , "newer than this version knows"},
+        {"truncated"    , base64.RawURLEncoding.EncodeToString(valid[:4])       , "invalid maze key"},
+        {"not base64"   , "not a key!"                                          , "invalid maze key"},
+    } {
+        if _, err := ParseMazeKey(tc.key); err == nil || !strings.Contains(err.Error(), tc.want) {
+            t.Errorf("%s: error %v, want %q", tc.name, err, tc.want)
+        }
+        if err := newMaze().GenerateFromKey(context.Background(), tc.key); err == nil {
+            t.Errorf("%s: generated a maze", tc.name)
+        }
+    }
+}
@@ -0,0 +1,57 @@
+/* lines.go - Light, heavy and double wall lines
+ *
+ * The walls can be drawn with the light box drawing lines, the usual ones, or with the heavy or double lines,
+ * on the display and in the ascii format written with box drawing characters.  Each style is a table parallel
+ * to outputLookup, indexed by the walls a location joins.  The VT100 line drawing characters only have light
+ * lines, so a display in another style is drawn with UTF-8 box drawing characters instead.  The solution keeps
+ * its light lines in every style, which tells it from the walls even without color: it never joins a wall, so
+ * the mixed junctions of the heavy and double sets such as ╞ and ╡ never arise.
+ */
+package maze
+
+// Line styles of the walls
+const (
+    LightLines = iota               // ─ │, the VT100 line drawing characters on the display
+    HeavyLines                      // ━ ┃
+    DoubleLines                     // ═ ║
+)
+
+var (
+    heavyLookup  = [16]rune { ' ', '┃', '━', '┗',
+                              '┃', '┃', '┏', '┣',
+                              '━', '┛', '━', '┻',
+                              '┓', '┫', '┳', '╋' }
+
+    doubleLookup = [16]rune { ' ', '║', '═', '╚',
+                              '║', '║', '╔', '╠',
+                              '═', '╝', '═', '╩',
+                              '╗', '╣', '╦', '╬' }
+
+    // lineLookups holds the box drawing characters of each line style, indexed as outputLookup
+    lineLookups = [...]*[16]rune { &boxLookup, &heavyLookup, &doubleLookup }
+
+    // lineRunes maps each VT100 line drawing character drawn by RenderANSI to the box drawing character of each
+    // line style it stands for
+    lineRunes = func() (runes [len(lineLookups)][128]rune) {
+        for s, lookup := range lineLookups {
+            for k, c := range outputLookup {
+                runes[s][c] = lookup[k]
+            }
+            runes[s][block], runes[s][diamond] = boxBlockChar, '◆'
+        }
+        return
+    }()
+)
+
+// lineLookup returns the box drawing characters of the maze's line style
+func (m *Maze) lineLookup() [16]rune {
+    return *lineLookups[m.style()]
+}
+
+// style returns the maze's line style, light for one out of range
+func (m *Maze) style() int {
+    if m.lineStyle < 0 || m.lineStyle >= len(lineLookups) {
+        return LightLines
+    }
+    return m.lineStyle
+}
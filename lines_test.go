@@ -0,0 +1,61 @@
+/* lines_test.go - Line style snapshots
+ *
+ * Pins the solved maze in each line style, light, heavy and double, in the ascii format written with box drawing
+ * characters and on the display, and checks that in every style the solution is drawn with characters none of
+ * the walls use, and that WithText sets the style and rejects an unknown one.
+ */
+package maze
+
+import (
+    "bytes"
+    "testing"
+)
+
+var lineStyleNames = [...]string{LightLines: "light", HeavyLines: "heavy", DoubleLines: "double"}
+
+func TestGoldenLineStyles(t *testing.T) {
+    m := goldenMaze(t, WithSize(12, 8), WithSeed(42), WithDepth(5))
+    m.boxDrawing = true
+    for style, name := range lineStyleNames {
+        m.lineStyle = style
+        solved := solvedASCII(t, m)
+        checkGolden(t, "12x8-seed42-depth5-" + name + "-solved.txt", solved)
+        if !bytes.ContainsRune(solved, boxSolved) {
+            t.Errorf("%s lines: no solution drawn", name)
+        }
+        for _, r := range lineLookups[style] {
+            if r == boxSolved {
+                t.Errorf("%s lines: the solution is drawn with the wall character %c", name, r)
+            }
+        }
+
+        var b bytes.Buffer
+        if err := m.RenderANSI(&b, RenderOptions{Wall: "\033[34m", Path: "\033[47m", Solved: "\033[31m"}); err != nil {
+            t.Fatal(err)
+        }
+        checkGolden(t, "12x8-seed42-depth5-" + name + ".ansi", b.Bytes())
+    }
+    m.lineStyle = LightLines
+    var light, box bytes.Buffer
+    m.RenderANSI(&light, RenderOptions{})
+    m.lineStyle = HeavyLines
+    m.RenderANSI(&box, RenderOptions{})
+    if bytes.Equal(light.Bytes(), box.Bytes()) {
+        t.Error("the heavy lines display the same as the light")
+    }
+}
+
+func TestTextLineStyle(t *testing.T) {
+    for _, style := range []int{-1, len(lineStyleNames)} {
+        if _, err := NewMaze(WithText(TextOptions{LineStyle: style})); err == nil {
+            t.Errorf("line style %d accepted", style)
+        }
+    }
+    m, err := NewMaze(WithText(TextOptions{BoxDrawing: true, LineStyle: DoubleLines}))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if m.configure(); !m.boxDrawing || m.lineStyle != DoubleLines {
+        t.Errorf("box drawing %v in line style %d, want double lines", m.boxDrawing, m.lineStyle)
+    }
+}
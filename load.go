@@ -0,0 +1,33 @@
+/* load.go - Solving loaded mazes
+ *
+ * A maze read from a file can be solved instead of generating one, then displayed and written as usual.  A
+ * loaded maze may have no route between its openings, which is reported along with how far the solver got.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+)
+
+// loadInput makes the loaded maze the current maze, cleared of any solution, and sets x, y to the entrance
+func (m *Maze) loadInput(x, y *int) {
+    m.installMaze(m.loaded)
+    m.restoreMaze()
+    *x, *y = getInt(&m.begX), getInt(&m.begY)
+}
+
+// unsolvable returns true if the solver searched all of the loaded maze it could reach without finding the exit
+func (m *Maze) unsolvable() bool {
+    return m.loaded != nil && !getBool(&m.solvedFlag) && !getBool(&m.budgetExhausted)
+}
+
+// printUnsolvable reports that the loaded maze has no solution and how many cells the solver could reach
+func (m *Maze) printUnsolvable(w io.Writer) {
+    visited := 0
+    m.cells(func(c cell, state int) bool {
+        visited += bool2int(state == tried)
+        return true
+    })
+    fmt.Fprintf(w, "solve: no solution exists, %d cells reachable from the entrance\n", visited)
+}
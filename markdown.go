@@ -0,0 +1,108 @@
+/* markdown.go - Markdown code blocks
+ *
+ * Writes the maze for pasting into an issue or a wiki page: a small table of its height, width, seed and
+ * solution length, then the ascii grid in a fenced code block, left exactly as it is since nothing inside a
+ * fence is escaped.  The fence is made longer than any run of backquotes in the grid so a character map can't
+ * close it early.  A maze wider than the page can be split into slices stacked one above the other, each
+ * labelled with the cell columns it holds.  The slices are cut along the walls between two columns of cells and
+ * that wall is drawn in both slices, so no wall is lost at a seam.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "strings"
+)
+
+// Default and limit of the widest line of the markdown format's code blocks
+const (
+    DefaultMarkdownColumns = 100
+    MinMarkdownColumns     = 3      // a column of cells between its walls
+)
+
+// MarkdownOptions chooses how RenderMarkdown lays out the maze
+type MarkdownOptions struct {
+    Columns int                     // the widest line wanted in a code block, 0 for DefaultMarkdownColumns
+    Split   bool                    // split a maze wider than Columns into slices of whole cell columns
+}
+
+// withDefaults returns the options with the default in place of a zero value
+func (o MarkdownOptions) withDefaults() MarkdownOptions {
+    if o.Columns == 0 {; o.Columns = DefaultMarkdownColumns; }
+    return o
+}
+
+// Check returns an error if the options are out of range
+func (o MarkdownOptions) Check() error {
+    if o.Columns != 0 && o.Columns < MinMarkdownColumns {
+        return fmt.Errorf("markdown columns %d must be at least %d", o.Columns, MinMarkdownColumns)
+    }
+    return nil
+}
+
+// TextColumns returns the number of characters in each line of the ascii format's grid, corridors widened
+func (m *Maze) TextColumns() int {
+    return 2*m.width*m.corridor + 1
+}
+
+// markdownFence returns a code fence of backquotes longer than any run of them in text
+func markdownFence(text string) string {
+    longest, run := 0, 0
+    for _, r := range text {
+        if r == '`' {; run++; longest = max(longest, run); } else {; run = 0; }
+    }
+    return strings.Repeat("`", max(3, longest + 1))
+}
+
+// markdownSlices returns where the slices of lines of at most columns characters are cut, in cell columns of the maze
+// while its corridors are widened: the first column of each slice then the end of the last.  Every slice but the
+// last holds the same whole number of logical columns, at least one, so a slice of wide corridors may be wider.
+func (m *Maze) markdownSlices(columns int) []int {
+    per := max(m.corridor, (columns - 1)/2/m.corridor*m.corridor)
+    ends := []int{0}
+    for c := per; c < m.width; c += per {
+        ends = append(ends, c)
+    }
+    return append(ends, m.width)
+}
+
+// RenderMarkdown writes the maze to w as a markdown table of its parameters followed by its ascii grid in a code
+// block, in slices if it's wider than the options allow and they split it.  It returns an error if the options are
+// out of range or the first error from w.
+func (m *Maze) RenderMarkdown(w io.Writer, opts MarkdownOptions) error {
+    if err := opts.Check(); err != nil {
+        return err
+    }
+    opts = opts.withDefaults()
+    ew := &errWriter{w: w}
+    fmt.Fprintf(ew, "| Height | Width | Seed | Solution length |\n")
+    fmt.Fprintf(ew, "| -----: | ----: | ---: | --------------: |\n")
+    fmt.Fprintf(ew, "| %d | %d | %d | %d |\n", m.height, m.width, m.getSeed(), m.SolutionLength())
+    columns := m.TextColumns()
+
+    m = m.widened()
+    var grid strings.Builder
+    m.writeAsciiGrid(&grid)
+    fence := markdownFence(grid.String())
+    if !opts.Split || columns <= opts.Columns {
+        fmt.Fprintf(ew, "\n%s\n%s%s\n", fence, grid.String(), fence)
+        return ew.err
+    }
+
+    lines := strings.Split(strings.TrimSuffix(grid.String(), "\n"), "\n")
+    rows  := make([][]rune, len(lines))
+    for k, line := range lines {
+        rows[k] = []rune(line)
+    }
+    ends := m.markdownSlices(opts.Columns)
+    for k := 0; k + 1 < len(ends); k++ {
+        first, last := ends[k], ends[k+1]
+        fmt.Fprintf(ew, "\n**Columns %d to %d of %d**\n\n%s\n", first/m.corridor, (last - 1)/m.corridor, m.width/m.corridor, fence)
+        for _, row := range rows {
+            fmt.Fprintf(ew, "%s\n", string(row[2*first:2*last + 1]))      // from the wall left of the first column to the wall right of the last
+        }
+        fmt.Fprintf(ew, "%s\n", fence)
+    }
+    return ew.err
+}
@@ -0,0 +1,75 @@
+/* markdown_test.go - Markdown slice tests
+ *
+ * Splits mazes into slices at a range of widths and checks the seams: each slice starts with the wall the one
+ * before it ends with, and laying the slices side by side, that wall once, gives back the whole grid of the
+ * unsplit code block, so no wall character is lost or changed at a slice boundary.
+ */
+package maze
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+)
+
+// markdownBlocks returns the lines of each code block RenderMarkdown writes
+func markdownBlocks(t *testing.T, m *Maze, opts MarkdownOptions) [][]string {
+    t.Helper()
+    var b bytes.Buffer
+    if err := m.RenderMarkdown(&b, opts); err != nil {
+        t.Fatal(err)
+    }
+    var blocks [][]string
+    var block []string
+    inside := false
+    for _, line := range strings.Split(b.String(), "\n") {
+        switch {
+            case line == "```" && inside : blocks, inside = append(blocks, block), false
+            case line == "```"           : block, inside = nil, true
+            case inside                  : block = append(block, line)
+        }
+    }
+    if inside {
+        t.Fatalf("unclosed code block:\n%s", b.String())
+    }
+    return blocks
+}
+
+func TestMarkdownSeams(t *testing.T) {
+    for _, corridor := range []int{1, 2} {
+        m := goldenMaze(t, WithSize(19, 10), WithSeed(1), WithCorridor(corridor))
+        whole := markdownBlocks(t, m, MarkdownOptions{})
+        if len(whole) != 1 {
+            t.Fatalf("corridor %d: %d code blocks unsplit, want 1", corridor, len(whole))
+        }
+        for _, columns := range []int{3, 5, 8, 20, m.TextColumns() - 1} {
+            slices := markdownBlocks(t, m, MarkdownOptions{Columns: columns, Split: true})
+            if len(slices) < 2 {
+                t.Errorf("corridor %d, %d columns: %d slices of %d", corridor, columns, len(slices), m.TextColumns())
+                continue
+            }
+            joined := make([]string, len(whole[0]))
+            for k, slice := range slices {
+                if len(slice) != len(joined) {
+                    t.Fatalf("corridor %d, %d columns: slice %d has %d lines, want %d", corridor, columns, k, len(slice), len(joined))
+                }
+                for r, line := range slice {
+                    if len(line) > columns && len(line) > 2*corridor + 1 {
+                        t.Errorf("corridor %d, %d columns: slice %d line %d is %d wide", corridor, columns, k, r, len(line))
+                    }
+                    if k > 0 {
+                        if line[0] != joined[r][len(joined[r]) - 1] {
+                            t.Errorf("corridor %d, %d columns: slice %d line %d starts %q, the slice before ends %q",
+                                     corridor, columns, k, r, line[0], joined[r][len(joined[r]) - 1])
+                        }
+                        line = line[1:]
+                    }
+                    joined[r] += line
+                }
+            }
+            if got, want := strings.Join(joined, "\n"), strings.Join(whole[0], "\n"); got != want {
+                t.Errorf("corridor %d, %d columns: slices joined:\n%s\nwant:\n%s", corridor, columns, got, want)
+            }
+        }
+    }
+}
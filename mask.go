@@ -0,0 +1,253 @@
+/* mask.go - Shape masks
+ *
+ * A mask generates the maze inside a shape, a heart or a logo, rather than the whole rectangle.  It's a text
+ * file of the maze's height in lines of its width in characters, # for a cell left out of the maze and . for
+ * one inside it.  The cells left out are walls from the start that no path is ever carved into, and the mask's
+ * edge is treated as the perimeter path is, so the look ahead leaves no 1x1 orphans against it.  The cells
+ * inside must all connect, with gap of them side by side in the top row for the entrance and in the bottom row
+ * for the exit, and every one of them is joined to the maze once carving is done, since a cell in a narrow neck
+ * of the shape can be left without a way in.  The ascii and line drawing outputs draw the outline of the shape inside
+ * the border and leave the cells left out blank, so that read back they're pockets of path no opening leads to.  A
+ * masked maze has no key, the key not recording the mask.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "bufio"
+    "strings"
+)
+
+// Mask marks the cells left out of a maze
+type Mask struct {
+    height, width int
+    excluded      [][]bool
+}
+
+// ParseMask reads a mask, a line of # and . for each row of cells, # being a cell left out, returning an error
+// if the lines aren't all the same length, hold other characters or make a mask too large for the maze array
+func ParseMask(r io.Reader) (*Mask, error) {
+    k := &Mask{}
+    scanner := bufio.NewScanner(r)
+    scanner.Buffer(nil, 4*maxWidth)
+    for line := 1; scanner.Scan(); line++ {
+        text := strings.TrimRight(scanner.Text(), "\r")
+        if text == "" {
+            continue                        // blank lines, at the end of the file for one
+        }
+        if k.width == 0 {
+            k.width = len(text)
+        }
+        if len(text) != k.width {
+            return nil, fmt.Errorf("line %d: expected %d cells, found %d", line, k.width, len(text))
+        }
+        row := make([]bool, k.width)
+        for c, ch := range []byte(text) {
+            switch ch {
+                case '#': row[c] = true
+                case '.':
+                default : return nil, fmt.Errorf("line %d, column %d: %q isn't # or .", line, c + 1, ch)
+            }
+        }
+        k.excluded = append(k.excluded, row)
+        k.height++
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+    if k.height < 1 || k.height > maxHeight || k.width > maxWidth {
+        return nil, fmt.Errorf("invalid mask size %dx%d (height 1-%d, width 1-%d)", k.width, k.height, maxHeight, maxWidth)
+    }
+    return k, nil
+}
+
+// Size returns the height and width of the mask in cells
+func (k *Mask) Size() (int, int) {
+    return k.height, k.width
+}
+
+// Excluded returns true if cell row, col is left out of the maze, as is every cell outside the mask
+func (k *Mask) Excluded(row, col int) bool {
+    return row < 0 || row >= k.height || col < 0 || col >= k.width || k.excluded[row][col]
+}
+
+// Check returns an error if the cells inside the mask don't all connect, or if there's none in the top or bottom row
+func (k *Mask) Check() error {
+    inside, first := 0, cell{-1, -1}
+    for r := range k.excluded {
+        for c := range k.excluded[r] {
+            if !k.excluded[r][c] {
+                if inside == 0 {
+                    first = cell{r, c}
+                }
+                inside++
+            }
+        }
+    }
+    reached := make([][]bool, k.height)
+    for r := range reached {
+        reached[r] = make([]bool, k.width)
+    }
+    found, queue := 0, []cell{}
+    if inside > 0 {
+        reached[first.row][first.col], found, queue = true, 1, append(queue, first)
+    }
+    for len(queue) > 0 {
+        c := queue[0]
+        queue = queue[1:]
+        for _, step := range directionSteps {
+            n := cell{c.row + step.X, c.col + step.Y}
+            if !k.Excluded(n.row, n.col) && !reached[n.row][n.col] {
+                reached[n.row][n.col], found, queue = true, found + 1, append(queue, n)
+            }
+        }
+    }
+    switch {
+        case inside == 0:
+            return fmt.Errorf("the mask leaves no cells in the maze")
+        case found < inside:
+            return fmt.Errorf("the mask's cells don't all connect: %d of %d can't be reached from row %d, column %d", inside - found, inside, first.row, first.col)
+    }
+    return k.opens(1)
+}
+
+// run returns the most cells side by side that the mask leaves in the row
+func (k *Mask) run(row int) int {
+    longest, n := 0, 0
+    for c := 0; c < k.width; c++ {
+        n = (n + 1)*bool2int(!k.Excluded(row, c))
+        longest = max(longest, n)
+    }
+    return longest
+}
+
+// opens returns an error if the mask leaves no room in the top row for an entrance gap cells wide, or in the bottom
+// row for the exit
+func (k *Mask) opens(gap int) error {
+    switch {
+        case k.run(0) < gap:
+            return fmt.Errorf("the mask leaves no room in the top row for an entrance %d cells wide", gap)
+        case k.run(k.height - 1) < gap:
+            return fmt.Errorf("the mask leaves no room in the bottom row for an exit %d cells wide", gap)
+    }
+    return nil
+}
+
+// masked returns true if the maze has a mask leaving cell c, inside the maze, out
+func (m *Maze) masked(c cell) bool {
+    return m.mask != nil && m.inMaze(c.row, c.col) && m.mask.Excluded(c.row, c.col)
+}
+
+// excluded returns true if the maze array location x, y is a cell the mask leaves out, the perimeter path never being one
+func (m *Maze) excluded(x, y int) bool {
+    return m.mask != nil && isEven(x) && isEven(y) && m.masked(cellAt(x, y))
+}
+
+// beyondWall returns true if the location x, y on the far side of a wall from a cell is a path, the perimeter path
+// included, or a cell the mask leaves out, which are alike in ending the maze
+func (m *Maze) beyondWall(x, y int) bool {
+    return m.getMaze(x, y) == path || m.excluded(x, y)
+}
+
+// joinsMasked returns true if the wall location x, y is between a cell and one the mask leaves out
+func (m *Maze) joinsMasked(x, y int) bool {
+    if isOdd(x) {
+        return m.excluded(x - 1, y) || m.excluded(x + 1, y)
+    }
+    return m.excluded(x, y - 1) || m.excluded(x, y + 1)
+}
+
+// outside returns true if the mask leaves out every cell touching location x, y: the cell itself, those either side
+// of a wall or the four around a corner post.  The border touches the perimeter path, so it's never outside.
+func (m *Maze) outside(x, y int) bool {
+    if m.mask == nil {
+        return false
+    }
+    for i := x - (x & 1); i <= x + (x & 1); i += 2 {
+        for j := y - (y & 1); j <= y + (y & 1); j += 2 {
+            if !m.masked(cellAt(i, j)) {
+                return false
+            }
+        }
+    }
+    return true
+}
+
+// shownCell returns the value location x, y is drawn as in the ascii and line drawing outputs, a path if it's outside
+// the mask so that the walls of the shape are drawn as its outline, with nothing between it and the border
+func (m *Maze) shownCell(x, y int) int {
+    if m.outside(x, y) {
+        return path
+    }
+    return m.getMaze(x, y)
+}
+
+// gapMasked returns true if any cell of the opening centered on column y, in the row of cells at x, is masked
+func (m *Maze) gapMasked(x, y int) bool {
+    lo, hi := m.gapRange(y)
+    for j := lo; j <= hi; j += 2 {
+        if m.excluded(x, j) {
+            return true
+        }
+    }
+    return false
+}
+
+// firstUnmasked moves the cell at location x, y on to the next cell, in row order, that the mask doesn't leave out
+func (m *Maze) firstUnmasked(x, y *int) {
+    for n := 0; n < m.height*m.width && m.excluded(*x, *y); n++ {
+        if *y += 2; *y > 2*m.width {
+            *x, *y = *x + 2, 2
+            if *x > 2*m.height {
+                *x = 2
+            }
+        }
+    }
+}
+
+// fits returns an error if the mask isn't the size of a maze height by width cells or has no room for its openings
+func (k *Mask) fits(height, width, gap int) error {
+    if k.height != height || k.width != width {
+        return fmt.Errorf("mask size %dx%d isn't the maze size %dx%d", k.width, k.height, width, height)
+    }
+    return k.opens(gap)
+}
+
+// checkMask returns an error if the maze has a mask that isn't its size, has no room for its openings or whose cells
+// don't all connect
+func (m *Maze) checkMask() error {
+    if m.mask == nil {
+        return nil
+    }
+    if err := m.mask.fits(m.height, m.width, m.gap); err != nil {
+        return err
+    }
+    return m.mask.Check()
+}
+
+// joinMasked joins every cell inside the mask that carving left solid to a carved cell next to it, until none is left
+func (m *Maze) joinMasked() {
+    for joined := true; joined && m.mask != nil; {
+        joined = false
+        m.cells(func(c cell, state int) bool {
+            x, y := c.loc()
+            if state != wall || m.masked(c) {
+                return true
+            }
+            offset := m.rng.Intn(4)
+            for i := 0; i < 4; i++ {
+                dir := &stdDirection[(i + offset) % 4]
+                if m.getMaze(x + dir.x, y + dir.y) == path && !m.excluded(x + dir.x, y + dir.y) && x + dir.x > 0 && y + dir.y > 0 &&
+                   x + dir.x < 2*(m.height + 1) && y + dir.y < 2*(m.width + 1) {
+                    m.setCell(x + dir.x/2, y + dir.y/2, path, update, 0, 0)
+                    m.setCell(x, y, path, update, 0, 0)
+                    incInt(&m.mazeLen)
+                    joined = true
+                    break
+                }
+            }
+            return true
+        })
+    }
+}
@@ -0,0 +1,94 @@
+/* mask_test.go - Shape mask tests
+ *
+ * Compares a masked maze written in the ascii format, in each line style and on the display with the golden files,
+ * checks that nothing is written in the cells the mask leaves out or on the walls between them, and reads the ascii
+ * back to check that it's solved as the masked maze was, the cells left out being pockets no opening leads to.
+ */
+package maze
+
+import (
+    "bytes"
+    "context"
+    "strings"
+    "testing"
+)
+
+// goldenMask is a shape with cells left out along every edge, one of them on its own, and two pockets in the middle
+const goldenMask = "#..##...#\n" +
+                   ".........\n" +
+                   "...#.#...\n" +
+                   "##.....##\n" +
+                   "###...###\n"
+
+// maskedMaze returns the golden maze generated inside goldenMask
+func maskedMaze(t *testing.T) *Maze {
+    t.Helper()
+    k, err := ParseMask(strings.NewReader(goldenMask))
+    if err != nil {
+        t.Fatal(err)
+    }
+    return goldenMaze(t, WithSize(9, 5), WithSeed(2), WithMask(k))
+}
+
+// gridLines returns the lines of the grid of a maze in the ascii format, those after its "height width" header
+func gridLines(text []byte) []string {
+    lines := strings.Split(string(text), "\n")
+    for n, line := range lines {
+        if !strings.HasPrefix(line, "#") {
+            return lines[n + 1:]
+        }
+    }
+    return nil
+}
+
+// checkOutside fails if anything but a space is written at a location outside the mask in the ascii grid
+func checkOutside(t *testing.T, name string, m *Maze, text []byte) {
+    t.Helper()
+    lines := gridLines(text)
+    for i := 1; i < getInt(&m.maxX) - 1; i++ {
+        row := []rune(lines[i - 1])
+        for j := 1; j < getInt(&m.maxY) - 1; j++ {
+            if m.outside(i, j) && row[j - 1] != ' ' {
+                t.Errorf("%s: %c written outside the mask at %d, %d", name, row[j - 1], i, j)
+            }
+        }
+    }
+}
+
+func TestGoldenMask(t *testing.T) {
+    m := maskedMaze(t)
+    unsolved := unsolvedASCII(t, m)
+    checkGolden(t, "9x5-seed2-mask.txt", unsolved)
+    checkOutside(t, "ascii", m, unsolved)
+
+    m.boxDrawing = true
+    for style, name := range lineStyleNames {
+        m.lineStyle = style
+        solved := solvedASCII(t, m)
+        checkGolden(t, "9x5-seed2-mask-" + name + "-solved.txt", solved)
+        checkOutside(t, name, m, solved)
+
+        var b bytes.Buffer
+        if err := m.RenderANSI(&b, RenderOptions{}); err != nil {
+            t.Fatal(err)
+        }
+        checkGolden(t, "9x5-seed2-mask-" + name + ".ansi", b.Bytes())
+    }
+}
+
+func TestMaskReadBack(t *testing.T) {
+    m := maskedMaze(t)
+    l, err := ParseASCII(string(unsolvedASCII(t, m)))
+    if err != nil {
+        t.Fatal(err)
+    }
+    again := newMaze()
+    again.Load(l)
+    if _, err := again.Generate(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    got, want := gridLines(solvedASCII(t, again)), gridLines(solvedASCII(t, m))
+    if strings.Join(got, "\n") != strings.Join(want, "\n") {
+        t.Errorf("read back and solved\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+    }
+}
@@ -15,26 +15,104 @@
  * Rev 2.1 -- added multi-threaded generation
  * Rev 2.2 -- improved (more efficient) look ahead
  * Rev 2.3 -- added multi-threaded solving
+ * Rev 2.4 -- transparent gzip compression of output files
+ * Rev 2.5 -- added solver race visualization
+ * Rev 2.6 -- added connected component diagnostic
+ * Rev 2.7 -- added dead end depth heat map
+ * Rev 2.8 -- added wrong branch annotation along the solution
+ * Rev 2.9 -- added output formats, starting with an edge list
+ * Rev 3.0 -- own the random number source, added checkpoint and resume
+ * Rev 3.1 -- fixed data races on globals shared with the display
+ * Rev 3.2 -- optionally keep tried cells in the final output
+ * Rev 3.3 -- GNU style option parsing
+ * Rev 3.4 -- fit the maze to the terminal with an optional margin
+ * Rev 3.5 -- exit codes that reflect the outcome
+ * Rev 3.6 -- dry run parameter report
+ * Rev 3.7 -- stats record to a separate file descriptor
+ * Rev 3.8 -- ascii maze loader and maze difference report
+ * Rev 3.9 -- maze union and intersection
+ * Rev 4.0 -- progressive solution hint files
+ * Rev 4.1 -- left and right hand wall follower asymmetry
+ * Rev 4.2 -- braille output format
+ * Rev 4.3 -- separate carving and solving thread counts
+ * Rev 4.4 -- thread counts chosen from the maze size
+ * Rev 4.5 -- solver step budget
+ * Rev 4.6 -- entrance and exit markers in the ascii format
+ * Rev 4.7 -- frame sampling for animation exports
+ * Rev 4.8 -- block wall style
+ * Rev 4.9 -- color themes
+ * Rev 5.0 -- statistics appended to a CSV file
+ * Rev 5.1 -- solve loaded mazes, reporting those with no solution
+ * Rev 5.2 -- shuffle solver choices at junctions to remove heading bias
+ * Rev 5.3 -- human readable durations and counts on the status line
+ * Rev 5.4 -- summary of the run at exit
+ * Rev 5.5 -- continuous output of framed mazes with --count
+ * Rev 5.6 -- entrance and exit width
+ * Rev 5.7 -- wide corridors
+ * Rev 5.8 -- stale check cell audit
+ * Rev 5.9 -- opening search shown with -v
+ * Rev 6.0 -- split into the maze library package and the maze command
+ * Rev 6.1 -- state kept per Maze, so mazes can be generated concurrently
+ * Rev 6.2 -- NewMaze constructor with functional options, which rejects invalid parameters
+ * Rev 6.3 -- render the maze to any io.Writer, returning write errors
+ * Rev 6.4 -- context cancellation of generating and solving
+ * Rev 6.5 -- cell change event stream, followed by the animated display
+ * Rev 6.6 -- Solve returns the solution route in logical cell coordinates
+ * Rev 6.7 -- grid queries in logical cell coordinates
+ * Rev 6.8 -- pluggable generators, adding a recursive backtracker
+ * Rev 6.9 -- pluggable frame renderers, the terminal display among them
+ * Rev 7.0 -- errors returned to the maze command rather than swallowed
+ * Rev 7.1 -- clones and snapshots of a maze, the opening search trying each pair on the carved maze
+ * Rev 7.2 -- iterators over the cells and the solution, the solution length counted along its cells
+ * Rev 7.3 -- a random number source supplied by the caller
+ * Rev 7.4 -- maze keys, regenerating a maze from a short string
+ * Rev 7.5 -- png images of the maze
+ * Rev 7.6 -- svg drawings with the walls and the solution in separate layers
+ * Rev 7.7 -- json maze files, written and loaded
+ * Rev 7.8 -- animated gif recordings, and the OnPhase hook
+ * Rev 7.9 -- unicode box drawing characters in the ascii format
+ * Rev 8.0 -- html pages with a solution revealed by a checkbox
+ * Rev 8.1 -- pdf documents laid out on an a4 or letter page
+ * Rev 8.2 -- plain pbm and pgm bitmaps
+ * Rev 8.3 -- LaTeX documents drawing the maze with TikZ
+ * Rev 8.4 -- graphviz graphs of the cells and passages
+ * Rev 8.5 -- compact binary maze files, written and loaded
+ * Rev 8.6 -- run length encoded output and input files
+ * Rev 8.7 -- solution path files of cells and headings
+ * Rev 8.8 -- solved copy of the output file
+ * Rev 8.9 -- asciinema cast recordings of the terminal output
+ * Rev 9.0 -- braille terminal display for big mazes
+ * Rev 9.1 -- user defined characters for the ascii format
+ * Rev 9.2 -- write the maze to standard output with -o -
+ * Rev 9.3 -- generation parameters in the ascii header
+ * Rev 9.4 -- stl and obj models for 3D printing
+ * Rev 9.5 -- Go and C source code output
+ * Rev 9.6 -- tile based png images with spritesheets
+ * Rev 9.7 -- eps output for laser cutters
+ * Rev 9.8 -- numbered png frames of the animation
+ * Rev 9.9 -- csv edge and node lists
+ * Rev 10.0 -- emoji and other glyphs for the display and output
+ * Rev 10.1 -- ANSI colored snapshot files
+ * Rev 10.2 -- markdown code blocks
+ * Rev 10.3 -- zip archives of mazes
+ * Rev 10.4 -- heavy and double wall lines
+ * Rev 10.5 -- mazes to solve read from standard input
+ * Rev 10.6 -- mazes to solve read from png and jpeg images
+ * Rev 10.7 -- mazes shaped by masks
+ * Rev 10.8 -- masks from silhouette images
  */
-package main
+package maze
 
 import (
-    "os"
-    "fmt"
-    "bufio"
-    "flag"
     "time"
+    "sync"
     "math/rand"
     "sync/atomic"
-    "golang.org/x/crypto/ssh/terminal"
 )
 
 const (
-    version      = "2.3"
-    utsSignOn    = "\n" + "Maze Generation Console Utility "+ version +
-                   "\n" + "Copyright (c) 2016-2020" +
-                   "\n\n"
-    blankLine    = "                                                  ";
+    Version          = "10.8"
+    DefaultAlgorithm = "carve"                 // the look ahead path carving generator, used unless another is chosen
 
     maxWidth     = 300
     maxHeight    = 100
@@ -57,20 +135,6 @@ const (
 
     noUpdate     = false
     update       = true
-
-    blank        = ' '  // ' '
-    block        = 0x61 // '#'
-    rightBottom  = 0x6a // '+'
-    rightTop     = 0x6b // '+'
-    leftTop      = 0x6c // '+'
-    leftBottom   = 0x6d // '+'
-    intersection = 0x6e // '+'
-    horizontal   = 0x71 // '-'
-    rightTee     = 0x74 // '+'
-    leftTee      = 0x75 // '+'
-    upTee        = 0x76 // '+'
-    downTee      = 0x77 // '+'
-    vertical     = 0x78 // '|'
 )
 
 type dirTable struct {
@@ -85,19 +149,21 @@ var (
                                  { 0,  2, right},
                                  { 0, -2, left } }
 
-    outputLookup = [16]byte { blank     , vertical   , horizontal, leftBottom  ,
-                              vertical  , vertical   , leftTop   , rightTee    ,
-                              horizontal, rightBottom, horizontal, upTee       ,
-                              rightTop  , leftTee    , downTee   , intersection }
-
-    simpleLookup = [16]byte { ' ', '|', '-', '+',
+    simpleLookup = [16]rune { ' ', '|', '-', '+',
                               '|', '|', '+', '+',
                               '-', '+', '-', '+',
                               '+', '+', '+', '+' }
 
-    maze[maxXSize][maxYSize]  int32
+    boxLookup    = [16]rune { ' ', '│', '─', '└',
+                              '│', '│', '┌', '├',
+                              '─', '┘', '─', '┴',
+                              '┐', '┤', '┬', '┼' }
+)
+
+// mazeState is the state of the maze being generated, solved or written by a Maze
+type mazeState struct {
+    maze              [maxXSize][maxYSize]int32
 
-    blankFlag         bool
     showFlag          bool
     viewFlag          bool
     lookFlag          bool
@@ -105,11 +171,13 @@ var (
     width             int
     height            int
     fps               int
-    updates           int
     minLen            int
-    threads           int
-    seed              int
+    carveThreads      int
+    solveThreads      int
+    seedVal           int64
     depthVal          int
+    maxAttempts       int
+    gap               int               // width of the entrance and exit in cells
 
     maxX, maxY        int32
     begX, endX        int32
@@ -133,12 +201,15 @@ var (
     solveLength       int32
     sumsolveLength    int32
 
-    myStdout          *bufio.Writer
-    curFunc           string
-    outputName        string
-    displayChan       chan struct{}
+    rngSource         *countingSource
+    rng               *rand.Rand
+
+    seed              int64
     finishChan        chan struct{}
-)
+    onUpdate          func()            // the hooks of the Maze
+    onPath            func()
+    onPhase           func(Phase)
+}
 
 func msSleep(n   int)          {; time.Sleep(time.Duration(int64(n) * 1000 * 1000)); }
 
@@ -150,8 +221,9 @@ func nonZero(x   int) int      {; if x != 0 {; return x; }; return 1; }
 func isEven(x    int) bool     {; return (x & 1) == 0; }
 func isOdd( x    int) bool     {; return (x & 1) != 0; }
 
-func setMaze(x, y, v int) int  {; return int(atomic.SwapInt32(&maze[x][y], int32(v))); }
-func getMaze(x, y    int) int  {; return int(atomic.LoadInt32(&maze[x][y]));           }
+func (m *Maze) setMaze(x, y, v int) int  {; old := int(atomic.SwapInt32(&m.maze[x][y], int32(v))); m.emit(x, y, old, v); m.touch(); return old; }
+func (m *Maze) casMaze(x, y, old, v int) bool {; swapped := atomic.CompareAndSwapInt32(&m.maze[x][y], int32(old), int32(v)); if swapped {; m.touch(); }; return swapped; }
+func (m *Maze) getMaze(x, y    int) int  {; return int(atomic.LoadInt32(&m.maze[x][y]));           }
 
 func setInt( x *int32, v int)  {;            atomic.StoreInt32(x, int32(v));           }
 func clrInt( x *int32)         {;            atomic.StoreInt32(x,  0);                 }
@@ -162,294 +234,218 @@ func getInt( x *int32)   int   {; return int(atomic. LoadInt32(x));
 func getBool(x *int32)   bool  {; return     atomic. LoadInt32(x) != 0;                }
 func setBool(x *int32, v bool) {;            atomic.StoreInt32(x, int32(bool2int(v))); }
 
-func putchar(c byte)           {; myStdout.WriteByte(c); }
+// countingSource is a goroutine safe random number source that counts the values drawn since it was seeded,
+// so that its state can be saved as a seed and a count and restored by replaying that many draws.  The values
+// come from the source given by WithRandSource, or the built-in source if there's none, which needn't be goroutine safe.
+type countingSource struct {
+    mu      sync.Mutex
+    src     rand.Source
+    builtin rand.Source
+    seed    int64
+    draws   uint64
+}
 
-func setPosition(x, y int)     {; fmt.Fprintf(myStdout, "\033[%d;%dH", x, y); myStdout.Flush(); }
-func setLineDraw()             {; fmt.Fprintf(myStdout, "\033(0"           ); myStdout.Flush(); }
-func clrLineDraw()             {; fmt.Fprintf(myStdout, "\033(B"           ); myStdout.Flush(); }
-func setCursorOff()            {; fmt.Fprintf(myStdout, "\033[?25l"        ); myStdout.Flush(); }
-func setCursorOn()             {; fmt.Fprintf(myStdout, "\033[?25h"        ); myStdout.Flush(); }
-func clrScreen()               {; fmt.Fprintf(myStdout, "\033[2J"          ); myStdout.Flush(); }
-func setSolved()               {; fmt.Fprintf(myStdout, "\033[32m\033[1m"  ); myStdout.Flush(); }
-func clrSolved()               {; fmt.Fprintf(myStdout, "\033[30m\033[0m"  ); myStdout.Flush(); }
-func setChecked()              {; fmt.Fprintf(myStdout, "\033[31m\033[1m"  ); myStdout.Flush(); }
-func clrChecked()              {; fmt.Fprintf(myStdout, "\033[30m\033[0m"  ); myStdout.Flush(); }
+func (s *countingSource) Int63() int64 {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.draws++
+    return s.src.Int63()
+}
 
-// getConsoleSize returns the number of rows and columns available in the current terminal window.
-// Defaults to 24 rows and 80 columns if the underlying system call fails.
-func getConsoleSize() (int, int) {
-    cols, rows, err := terminal.GetSize(0)
-    if err != nil {
-        rows = 24
-        cols = 80
-    }
-    return rows, cols
+func (s *countingSource) Seed(seed int64) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.src.Seed(seed)
+    s.seed, s.draws = seed, 0
 }
 
-// initializeMaze sets the entire maze to walls and creates a path around the perimeter to bound the maze.
-// The maximum x, y values are set, and the initial x, y values are set to random values.
-func initializeMaze(x, y *int) {
-    clrInt(&maxChecks       )
-    clrInt(&mazeLen         )
-    clrInt(&numThreads      )
-    clrInt(&numPaths        )
-    clrInt(&numCheckExceeded)
+// use makes src the source values are drawn from, or the built-in source if src is nil
+func (s *countingSource) use(src rand.Source) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if src == nil {
+        src = s.builtin
+    }
+    s.src = src
+}
 
-    setInt(&maxX, 2*(height + 1) + 1)
-    setInt(&maxY, 2*(width  + 1) + 1)
+// state returns the seed and the number of values drawn since seeding
+func (s *countingSource) state() (int64, uint64) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.seed, s.draws
+}
 
-    for i := 1; i < getInt(&maxX) - 1; i++ {
-        for j := 1; j < getInt(&maxY) - 1; j++ {
-            setMaze(i, j, wall)
-        }
+// restore reseeds the source and replays draws values to return it to a saved state
+func (s *countingSource) restore(seed int64, draws uint64) {
+    s.Seed(seed)
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    for ; s.draws < draws; s.draws++ {
+        s.src.Int63()
     }
-    for i := 0; i < getInt(&maxX); i++ {; setMaze(i, 0, path); setMaze(i, 2*(width  + 1), path); }
-    for j := 0; j < getInt(&maxY); j++ {; setMaze(0, j, path); setMaze(2*(height + 1), j, path); }
+}
 
-    *x = 2*((rand.Intn(height)) + 1)   // random location
-    *y = 2*((rand.Intn(width )) + 1)   // for first path
+func (m *Maze) setSeed(v int64)          {;            atomic.StoreInt64(&m.seed, v);              }
+func (m *Maze) getSeed()         int64   {; return     atomic. LoadInt64(&m.seed);                 }
 
-    setInt(&begX, 2)                   // these will
-    setInt(&endX, 2*height)            // never change
-}
+func (m *Maze) setSolution(r []point)    {; m.solutionMu.Lock(); m.solution = r; m.solutionMu.Unlock(); m.touch(); }
+func (m *Maze) getSolution()    []point  {; m.solutionMu.Lock(); defer m.solutionMu.Unlock(); return m.solution; }
 
-// restoreMaze returns the maze to a pre-solved state by changing solved or tried cells back to paths.
-func restoreMaze()  {
-    for i := 0; i < getInt(&maxX); i++ {
-        for j := 0; j < getInt(&maxY); j++ {
-            if (getMaze(i, j) == solved ||
-                getMaze(i, j) == tried) {
-                setMaze(i, j,    path )
-            }
+// initializeMaze sets the entire maze to walls and creates a path around the perimeter to bound the maze.
+// The maximum x, y values are set, leaving the first path for the generator to start.
+func (m *Maze) initializeMaze() {
+    clrInt(&m.maxChecks       )
+    clrInt(&m.mazeLen         )
+    clrInt(&m.numThreads      )
+    clrInt(&m.numPaths        )
+    clrInt(&m.numCheckExceeded)
+
+    setInt(&m.maxX, 2*(m.height + 1) + 1)
+    setInt(&m.maxY, 2*(m.width  + 1) + 1)
+
+    for i := 1; i < getInt(&m.maxX) - 1; i++ {
+        for j := 1; j < getInt(&m.maxY) - 1; j++ {
+            m.setMaze(i, j, wall)          // cells left out by a mask stay these walls for good
         }
     }
+    for i := 0; i < getInt(&m.maxX); i++ {; m.setMaze(i, 0, path); m.setMaze(i, 2*(m.width  + 1), path); }
+    for j := 0; j < getInt(&m.maxY); j++ {; m.setMaze(0, j, path); m.setMaze(2*(m.height + 1), j, path); }
+
+    setInt(&m.begX, 2)                   // these will
+    setInt(&m.endX, 2*m.height)            // never change
 }
 
-// outputAsciiMaze outputs the maze in ascii format to a text file
-func outputAsciiMaze() {
-    if outputName != "" {
-        f, err := os.Create(outputName)
-        if err != nil {
-            fmt.Fprintf(myStdout, "Error opening output file: ", err)
-            myStdout.Flush()
-        } else {
-            outFile := bufio.NewWriterSize(f, getInt(&maxX) * getInt(&maxY))
-            fmt.Fprintf(outFile, "%d %d\n", height, width)
-            outFile.Flush()
-            for i := 1; i < getInt(&maxX) - 1; i++ {
-                for j := 1; j < getInt(&maxY) - 1; j++ {
-                    switch getMaze(i, j) {
-                        case wall  : if isOdd(i) && isOdd(j) {; fmt.Fprintf(outFile, "%c", simpleLookup[1 * bool2int(getMaze(i-1, j) == wall && (getMaze(i-1, j-1) != wall || getMaze(i-1, j+1) != wall)) +    // wall intersection point
-                                                                                                        2 * bool2int(getMaze(i, j+1) == wall && (getMaze(i-1, j+1) != wall || getMaze(i+1, j+1) != wall)) +    // check that there is a path on the diagonal
-                                                                                                        4 * bool2int(getMaze(i+1, j) == wall && (getMaze(i+1, j-1) != wall || getMaze(i+1, j+1) != wall)) +
-                                                                                                        8 * bool2int(getMaze(i, j-1) == wall && (getMaze(i-1, j-1) != wall || getMaze(i+1, j-1) != wall))])
-                                     } else if      isOdd(i) {; fmt.Fprintf(outFile, "-")
-                                     } else {                 ; fmt.Fprintf(outFile, "|"); }
-                        case path  :                            fmt.Fprintf(outFile, " ")
-                        case tried :                            fmt.Fprintf(outFile, ".")
-                        case solved:                            fmt.Fprintf(outFile, "*")
-                        case check :                            fmt.Fprintf(outFile, "#")
-                        default    :                            fmt.Fprintf(outFile, "?")
-                    }
-                }
-                fmt.Fprintf(outFile, "\n")
-                outFile.Flush()
+// restoreMaze returns the maze to a pre-solved state by changing solved or tried cells back to paths.
+func (m *Maze) restoreMaze()  {
+    for i := 0; i < getInt(&m.maxX); i++ {
+        for j := 0; j < getInt(&m.maxY); j++ {
+            if (m.getMaze(i, j) == solved ||
+                m.getMaze(i, j) == tried) {
+                m.setMaze(i, j,    path )
             }
-            f.Close()
         }
     }
 }
 
-// isWall returns true if a cell contains a wall character or a check character (to hide look ahead checks during display)
-func isWall(cell int) bool {
-    return cell == wall || (!getBool(&checkFlag) && cell == check)
-}
-
-// displayMaze displays the current maze within the terminal window using VT100 line drawing characters.
-func displayMaze()  {
-    setPosition(0, 0)
-    setLineDraw()
-
-    for i := 1; i < getInt(&maxX) - 1; i++ {
-        for j := 1; j < getInt(&maxY) - 1; j++ {
-            var vertexChar, solvedChar, leftChar, rightChar, wallChar byte
-
-            if isOdd(i) && isOdd(j) {
-                vertexChar = outputLookup[1 * bool2int(isWall(getMaze(i-1, j)) && (!isWall(getMaze(i-1, j-1)) || !isWall(getMaze(i-1, j+1)))) +    // wall intersection point
-                                          2 * bool2int(isWall(getMaze(i, j+1)) && (!isWall(getMaze(i-1, j+1)) || !isWall(getMaze(i+1, j+1)))) +    // check that there is a path on the diagonal
-                                          4 * bool2int(isWall(getMaze(i+1, j)) && (!isWall(getMaze(i+1, j-1)) || !isWall(getMaze(i+1, j+1)))) +
-                                          8 * bool2int(isWall(getMaze(i, j-1)) && (!isWall(getMaze(i-1, j-1)) || !isWall(getMaze(i+1, j-1))))];
-            } else {
-                vertexChar = outputLookup[1 * bool2int(isWall(getMaze(i-1, j)) && (!isWall(getMaze(i  , j-1)) || !isWall(getMaze(i  , j+1)))) +    // non-intersection point
-                                          2 * bool2int(isWall(getMaze(i, j+1)) && (!isWall(getMaze(i-1, j  )) || !isWall(getMaze(i+1, j  )))) +    // check that there is a path adjacent
-                                          4 * bool2int(isWall(getMaze(i+1, j)) && (!isWall(getMaze(i  , j-1)) || !isWall(getMaze(i  , j+1)))) +
-                                          8 * bool2int(isWall(getMaze(i, j-1)) && (!isWall(getMaze(i-1, j  )) || !isWall(getMaze(i+1, j  ))))];
-            }
-                solvedChar = outputLookup[1 * bool2int(getMaze(i-1, j) == getMaze(i, j)) +
-                                          2 * bool2int(getMaze(i, j+1) == getMaze(i, j)) +
-                                          4 * bool2int(getMaze(i+1, j) == getMaze(i, j)) +
-                                          8 * bool2int(getMaze(i, j-1) == getMaze(i, j))]
-
-            if isEven(i) && (getMaze(i, j-1) == solved || getMaze(i, j-1) == check) {;  leftChar = horizontal; } else {;  leftChar = blank; }
-            if isEven(i) && (getMaze(i, j+1) == solved || getMaze(i, j+1) == check) {; rightChar = horizontal; } else {; rightChar = blank; }
-
-            if blankFlag {; wallChar = vertexChar; } else {; wallChar = solvedChar; }
-
-            switch {
-                case getMaze(i, j) == solved:                           setSolved();  putchar(leftChar); if (isEven(j)) {; putchar(solvedChar); putchar(rightChar); }; clrSolved()
-                case getMaze(i, j) == check : if getBool(&checkFlag) {; setChecked(); putchar(leftChar); if (isEven(j)) {; putchar(solvedChar); putchar(rightChar); }; clrChecked();
-                                              } else                 {;               putchar(blank   ); if (isEven(j)) {; putchar(blank     ); putchar(blank    ); }}
-                case isEven(i) && isEven(j) :                                         putchar(blank   ); if (isEven(j)) {; putchar(blank     ); putchar(blank    ); }
-                case getMaze(i, j) == wall  :                                         putchar(wallChar); if (isEven(j)) {; putchar(wallChar  ); putchar(wallChar ); }
-                default                     :                                         putchar(blank   ); if (isEven(j)) {; putchar(blank     ); putchar(blank    ); }
-            }
-        }
-        putchar('\n')
-    }
-    clrLineDraw()
-    updates++;
-
-    fmt.Fprintf(myStdout, "updates=%d, height=%d, width=%d, seed=%d, num_wall_push=%d, num_maze_created=%d, num_solves=%d, avg_solve_length=%d, solve_length=%d, avg_path_length=%d, num_paths=%d, maze_len=%d, threads=%d, length=%d, checks=%d, max_checks=%d, checks_exceeded=%d %s\r",
-                           updates   , height   , width   , seed   ,
-                           getInt(&numWallPush     ),
-                           getInt(&numMazeCreated  ),
-                           getInt(&numSolves       ),
-                           getInt(&sumsolveLength  ) /
-                   nonZero(getInt(&numMazeCreated  )),
-                           getInt(&solveLength     ),
-                           getInt(&mazeLen         ) /
-                   nonZero(getInt(&numPaths        )),
-                           getInt(&numPaths        ),
-                           getInt(&mazeLen         ),
-                           getInt(&numThreads      ),
-                           getInt(&dspLength       ),
-                           getInt(&dspNumChecks    ),
-                           getInt(&maxChecks       ),
-                           getInt(&numCheckExceeded),
-                           blankLine);
-    outputAsciiMaze()
-}
-
-// displayRoutine waits to receive a signal on displayChan and then prints the maze
-func displayRoutine () {
-    for range displayChan {
-        displayMaze()
-    }
-}
-
-// updateMaze signals displayChan if there is no pending signal and then sleeps delay ms if non-zero
-func updateMaze(numChecks int) {
+// updateMaze calls the update hook, if there is one, and then sleeps delay ms if non-zero
+func (m *Maze) updateMaze(numChecks int) {
     if numChecks != 0 {
-        setInt(&dspNumChecks, numChecks)
+        setInt(&m.dspNumChecks, numChecks)
     }
-    select {
-        case displayChan <- struct{}{}:
-        default:
+    if m.onUpdate != nil {
+        m.onUpdate()
     }
-    if getInt(&delay) > 0 {
-        msSleep(getInt(&delay))
+    if getInt(&m.delay) > 0 {
+        msSleep(getInt(&m.delay))
     }
 }
 
 // setCell sets a location x, y inside the maze array to the value (wall, path, solved, tried)
 // It also displays the maze if delay is non-zero and the frame rate is less than 1000/sec
 // and only then for cells at locations with even x, y coordinates (to reduce number of refreshes)
-func setCell(x, y, value int, update bool, length, numChecks int) bool {
-    if getMaze(x, y) == check || getMaze(x, y) == value {
-        return false
-    }
-    priorValue := setMaze(x, y, value)
-    if priorValue  == check {
-        setMaze(x, y, check)
-        return false
-    }
-    if priorValue == value {
+// The location is only changed from the value it was seen to have, so a check marked by another thread in the
+// meantime is never overwritten (swapping then putting the check back could leave it behind for good if the
+// other thread had restored the location in between).
+func (m *Maze) setCell(x, y, value int, update bool, length, numChecks int) bool {
+    priorValue := m.getMaze(x, y)
+    if priorValue == check || priorValue == value || !m.casMaze(x, y, priorValue, value) {
         return false
     }
-    if (update || (getBool(&checkFlag) && getMaze(x, y) == check)) && getInt(&delay) > 0 && fps <= 1000 && isEven(x) && isEven(y) {
-        updateMaze(numChecks)
+    m.emit(x, y, priorValue, value)
+    if (update || (getBool(&m.checkFlag) && m.getMaze(x, y) == check)) && getInt(&m.delay) > 0 && m.fps <= 1000 && isEven(x) && isEven(y) {
+        m.updateMaze(numChecks)
     }
     return true
 }
 
 // checkDirections recursively checks to see if a path of a given length can be carved or traced from the given x, y location.
 // (limited to a total of 1/2 million checks)
-func checkDirections(x, y, dx, dy, limit, value int, length, minLength, checks, numChecks *int) bool {
+func (m *Maze) checkDirections(x, y, dx, dy, limit, value int, length, minLength, checks, numChecks *int) bool {
     if *length < 0 {
         return true
     }
     if *checks >= limit {
-        incInt(&numCheckExceeded)
+        incInt(&m.numCheckExceeded)
         return false
     }
-    if x + dx < 0 || y + dy < 0 || getMaze(x + dx, y + dy) != value || !setCell(x + dx/2, y + dy/2, check, getBool(&checkFlag), *length, *numChecks) {
+    if m.stopped() {
         return false
     }
-    if !setCell(x + dx, y + dy, check, getBool(&checkFlag), *length, *numChecks) {
-        setMaze(x + dx/2, y + dy/2, value)
+    if x + dx < 0 || y + dy < 0 || m.getMaze(x + dx, y + dy) != value || m.excluded(x + dx, y + dy) || !m.setCell(x + dx/2, y + dy/2, check, getBool(&m.checkFlag), *length, *numChecks) {
+        return false
+    }
+    if !m.setCell(x + dx, y + dy, check, getBool(&m.checkFlag), *length, *numChecks) {
+        m.setMaze(x + dx/2, y + dy/2, value)
         return false
     }
     *length--
     *checks++
     *numChecks++
-    offset := rand.Intn(4)
+    offset := m.rng.Intn(4)
     match  := false
-    for  i := 0; i < 4; i++ {
+    start  := *length                               // each direction starts from this length, kept in *length
+    for  i := 0; i < 4; i++ {                       // since a local whose address recurses is moved to the heap
         dir := &stdDirection[(i + offset) % 4]
-        dirLength := *length
-        if getMaze(x + dx + dir.x/2, y + dy + dir.y/2) == value &&
-           getMaze(x + dx + dir.x  , y + dy + dir.y  ) == value && checkDirections(x + dx, y + dy, dir.x, dir.y, limit, value, &dirLength, minLength, checks, numChecks) {
-           *length = dirLength
+        *length = start
+        if m.getMaze(x + dx + dir.x/2, y + dy + dir.y/2) == value &&
+           m.getMaze(x + dx + dir.x  , y + dy + dir.y  ) == value && m.checkDirections(x + dx, y + dy, dir.x, dir.y, limit, value, length, minLength, checks, numChecks) {
            match = true
            break
         }
     }
+    if !match {
+       *length = start
+    }
     if *minLength > *length {
        *minLength = *length
     }
     *length++
-    setMaze(x + dx  , y + dy  , value)
-    setMaze(x + dx/2, y + dy/2, value)
+    m.setMaze(x + dx  , y + dy  , value)
+    m.setMaze(x + dx/2, y + dy/2, value)
     return match
 }
 
 // orphan1x1 returns true if a location is surrounded by walls on all 4 sides and paths on the other side of all those walls.
-func orphan1x1(x, y int) bool {
+// A cell left out by a mask is never an orphan, and counts as a path beyond a wall as the perimeter path does.
+func (m *Maze) orphan1x1(x, y int) bool {
     return         x > 1             &&         y > 1             &&  // bounds check
-           getMaze(x + 1, y) == wall && getMaze(x + 2, y) == path &&  // vertical (look down & up)
-           getMaze(x - 1, y) == wall && getMaze(x - 2, y) == path &&
-           getMaze(x, y + 1) == wall && getMaze(x, y + 2) == path &&  // horizontal (right & left)
-           getMaze(x, y - 1) == wall && getMaze(x, y - 2) == path
+           x < getInt(&m.maxX) - 2   && y < getInt(&m.maxY) - 2   &&  // the perimeter path has nothing beyond it
+           !m.excluded(x, y)                                      &&
+           m.getMaze(x + 1, y) == wall && m.beyondWall(x + 2, y)  &&  // vertical (look down & up)
+           m.getMaze(x - 1, y) == wall && m.beyondWall(x - 2, y)  &&
+           m.getMaze(x, y + 1) == wall && m.beyondWall(x, y + 2)  &&  // horizontal (right & left)
+           m.getMaze(x, y - 1) == wall && m.beyondWall(x, y - 2)
 }
 
 // checkOrphan returns true if carving a path at a given location x,y in a given direction dx, dy
 // would create a 1x1 orphan left, right, above, or below the path.
-func checkOrphan(x, y, dx, dy, length int) bool {
+func (m *Maze) checkOrphan(x, y, dx, dy, length int) bool {
     orphan := false;
-    if      x > 1  && y > 1   && length > 0 && length == getInt(&depth) &&  // this only makes sense when carving paths, not when solving, and only if we haven't exhausted our search depth
-       getMaze(x + dx  , y + dy  ) ==  wall                             &&
-       getMaze(x + dx/2, y + dy/2) ==  wall                             &&
-       setCell(x + dx  , y + dy  , path, noUpdate, length, 0)           &&  // temporarily set new path
-       setCell(x + dx/2, y + dy/2, path, noUpdate, length, 0) {
+    if      x > 1  && y > 1   && length > 0 && length == getInt(&m.depth) &&  // this only makes sense when carving paths, not when solving, and only if we haven't exhausted our search depth
+       m.getMaze(x + dx  , y + dy  ) ==  wall                             &&
+       m.getMaze(x + dx/2, y + dy/2) ==  wall                             &&
+       m.setCell(x + dx  , y + dy  , path, noUpdate, length, 0)           &&  // temporarily set new path
+       m.setCell(x + dx/2, y + dy/2, path, noUpdate, length, 0) {
 
-        orphan = orphan1x1(x + dx + 2, y + dy    ) ||   // check for 1x1 orphans below & above of the new location
-                 orphan1x1(x + dx - 2, y + dy    ) ||
-                 orphan1x1(x + dx    , y + dy + 2) ||   // check for 1x1 orphans right & left  of the new location
-                 orphan1x1(x + dx    , y + dy - 2)
+        orphan = m.orphan1x1(x + dx + 2, y + dy    ) ||   // check for 1x1 orphans below & above of the new location
+                 m.orphan1x1(x + dx - 2, y + dy    ) ||
+                 m.orphan1x1(x + dx    , y + dy + 2) ||   // check for 1x1 orphans right & left  of the new location
+                 m.orphan1x1(x + dx    , y + dy - 2)
 
-        setMaze(x + dx  , y + dy  , wall)               // restore original walls
-        setMaze(x + dx/2, y + dy/2, wall)
+        m.setMaze(x + dx  , y + dy  , wall)               // restore original walls
+        m.setMaze(x + dx/2, y + dy/2, wall)
     }
     return orphan
 }
 
 // look returns 1 if at a given location x, y a path of a given length can be carved or traced in a given direction dx, dy without creating 1x1 orphans.
 // The direction (heading, dx, dy) is stored in the direction table directions if the path can be created.
-func look(heading, x, y, dx, dy, num, value int, directions []dirTable, length, minLength, numChecks *int) int {
+func (m *Maze) look(heading, x, y, dx, dy, num, value int, directions *[4]dirTable, length, minLength, numChecks *int) int {
     checks := 0
     if         x > 1  && y > 1              &&
-       getMaze(x + dx/2, y + dy/2) == value &&
-       getMaze(x + dx  , y + dy  ) == value && !checkOrphan(x, y, dx, dy, *length) && checkDirections(x, y, dx, dy, 10*(getInt(&depth) + 1), value, length, minLength, &checks, numChecks) {
+       m.getMaze(x + dx/2, y + dy/2) == value &&
+       m.getMaze(x + dx  , y + dy  ) == value && !m.excluded(x + dx, y + dy) && !m.checkOrphan(x, y, dx, dy, *length) && m.checkDirections(x, y, dx, dy, 10*(getInt(&m.depth) + 1), value, length, minLength, &checks, numChecks) {
         directions[num].x = dx
         directions[num].y = dy
         directions[num].heading = heading
@@ -460,19 +456,19 @@ func look(heading, x, y, dx, dy, num, value int, directions []dirTable, length,
 
 // findDirections returns the number of directions that a path can be carved or traces from a given location x, y.
 // The path length requirement of length is enforced.
-func findDirections(x, y int, length *int, value int, directions []dirTable) int {
+func (m *Maze) findDirections(x, y int, length *int, value int, directions *[4]dirTable) int {
     num       := 0
     numChecks := 0
-    if value != wall || (getMaze(x, y) == path && setCell(x, y, check, noUpdate, *length, numChecks)) {
+    if value != wall || (m.getMaze(x, y) == path && m.setCell(x, y, check, noUpdate, *length, numChecks)) {
         minLength := [4]int {*length, *length, *length, *length}
         len := *length
         for {
-            setInt(&dspLength, len)
+            setInt(&m.dspLength, len)
             dirLength := [4]int {len, len, len, len}
-            offset    := rand.Intn(4)
+            offset    := m.rng.Intn(4)
             for i := 0; i < 4; i++ {
                 dir := &stdDirection[(i + offset) % 4]
-                num += look(dir.heading, x, y, dir.x, dir.y, num, value, directions, &dirLength[i] , &minLength[i], &numChecks)
+                num += m.look(dir.heading, x, y, dir.x, dir.y, num, value, directions, &dirLength[i] , &minLength[i], &numChecks)
             }
             if num > 0 || len < 0 {
                break
@@ -483,40 +479,43 @@ func findDirections(x, y int, length *int, value int, directions []dirTable) int
             }
             len -= minLength
         }
-        if len == *length && len < getInt(&depth) {
+        if len == *length && len < getInt(&m.depth) {
            len++
         }
         *length = len
-        if getMaze(x, y) == check {
-           setMaze(x, y, path)
+        if m.getMaze(x, y) == check {
+           m.setMaze(x, y, path)
+        }
+        if value == path && num > 1 {    // the rotated scan favors the heading after a gap, so shuffle for solving
+           m.rng.Shuffle(num, func(i, j int) {; directions[i], directions[j] = directions[j], directions[i]; })
         }
     }
-    if getInt(&maxChecks) < numChecks  {
-       setInt(&maxChecks  , numChecks)
+    if getInt(&m.maxChecks) < numChecks  {
+       setInt(&m.maxChecks  , numChecks)
     }
     return (num);
 }
 
 // straightThru returns true if the path at the given location x, y has a path left and right of it, or above and below it
-func straightThru(x, y, value int) bool {
+func (m *Maze) straightThru(x, y, value int) bool {
     return           x > 1              &&         y > 1               &&
-           ((getMaze(x - 1, y) == value && getMaze(x - 2, y) == value  &&  // vertical   (look up & down)
-             getMaze(x + 1, y) == value && getMaze(x + 2, y) == value) ||
-            (getMaze(x, y - 1) == value && getMaze(x, y - 2) == value  &&  // horizontal (look left & right)
-             getMaze(x, y + 1) == value && getMaze(x, y + 2) == value))
+           ((m.getMaze(x - 1, y) == value && m.getMaze(x - 2, y) == value  &&  // vertical   (look up & down)
+             m.getMaze(x + 1, y) == value && m.getMaze(x + 2, y) == value) ||
+            (m.getMaze(x, y - 1) == value && m.getMaze(x, y - 2) == value  &&  // horizontal (look left & right)
+             m.getMaze(x, y + 1) == value && m.getMaze(x, y + 2) == value))
 }
 
 // findPathStart starts looking at a random x, y location for a position along an existing non-straight through path that can start a new path
-func findPathStart(x, y *int) bool {
-    directions := make([]dirTable, 4, 4)
-    xStart := rand.Intn(height)
-    yStart := rand.Intn(width )
+func (m *Maze) findPathStart(x, y *int) bool {
+    var directions [4]dirTable
+    xStart := m.rng.Intn(m.height)
+    yStart := m.rng.Intn(m.width )
     length := -1
-    for  i := 0; i < height; i++ {
-        for j := 0; j < width; j++ {
-            *x = 2*((xStart + i) % height + 1)
-            *y = 2*((yStart + j) % width  + 1)
-            if (getMaze(*x, *y) == path && !straightThru(*x, *y, path) && findDirections(*x, *y, &length, wall, directions) > 0) {
+    for  i := 0; i < m.height; i++ {
+        for j := 0; j < m.width; j++ {
+            *x = 2*((xStart + i) % m.height + 1)
+            *y = 2*((yStart + j) % m.width  + 1)
+            if (m.getMaze(*x, *y) == path && !m.straightThru(*x, *y, path) && m.findDirections(*x, *y, &length, wall, &directions) > 0) {
                 return true
             }
         }
@@ -527,90 +526,91 @@ func findPathStart(x, y *int) bool {
 // carvePath carves a new path in the maze starting at location x, y
 // It does this by repeatedly determining the number of possible directions to move
 // and then randomly choosing one of them and then marking the new cells on the path
-func carvePath(x, y *int) bool {
-    directions := make([]dirTable, 4, 4)
-    length     := getInt(&depth)
+func (m *Maze) carvePath(x, y *int) bool {
+    var directions [4]dirTable
+    length     := getInt(&m.depth)
     pathLength := 0
-    incInt(&numPaths)
-    setCell(*x, *y, path, noUpdate, 0, 0)
+    incInt(&m.numPaths)
+    m.setCell(*x, *y, path, noUpdate, 0, 0)
     for {
-        num := findDirections(*x, *y, &length, wall, directions)
+        num := m.findDirections(*x, *y, &length, wall, &directions)
         if num == 0 {
            break
         }
-        dir := rand.Intn(num)
-        if !setCell(*x + directions[dir].x/2, *y + directions[dir].y/2, path, update, 0, 0) {
+        dir := m.rng.Intn(num)
+        if !m.setCell(*x + directions[dir].x/2, *y + directions[dir].y/2, path, update, 0, 0) {
             continue
         }
-        if !setCell(*x + directions[dir].x  , *y + directions[dir].y  , path, update, 0, 0) {
-            setCell(*x + directions[dir].x/2, *y + directions[dir].y/2, wall, update, 0, 0)
+        if !m.setCell(*x + directions[dir].x  , *y + directions[dir].y  , path, update, 0, 0) {
+            m.setCell(*x + directions[dir].x/2, *y + directions[dir].y/2, wall, update, 0, 0)
             continue
         }
         *x += directions[dir].x
         *y += directions[dir].y
-        incInt(&mazeLen)
+        incInt(&m.mazeLen)
         pathLength++
     }
-    if getInt(&delay) > 0 {
-        updateMaze(0)
+    if getInt(&m.delay) > 0 {
+        m.updateMaze(0)
     }
-    if getInt(&numThreads) < threads {
-       incInt(&numThreads)
-       go carveRoutine()
+    if getInt(&m.numThreads) < m.carveThreads {
+       incInt(&m.numThreads)
+       go m.carveRoutine()
     }
     return pathLength > 0
 }
 
 // followDir marks the maze solved in the given direction starting at x, y
 // and updates the path length & turn count accordingly
-func followDir (x, y *int, direction dirTable, lastDir int) {
-    setCell(*x + direction.x/2, *y + direction.y/2, solved, update, 0, 0)
-    setCell(*x + direction.x  , *y + direction.y  , solved, update, 0, 0)
-    incInt(&pathLen)
+func (m *Maze) followDir (x, y *int, direction dirTable, lastDir int) {
+    m.setCell(*x + direction.x/2, *y + direction.y/2, solved, update, 0, 0)
+    m.setCell(*x + direction.x  , *y + direction.y  , solved, update, 0, 0)
+    incInt(&m.pathLen)
     if (lastDir != direction.heading)  {
         lastDir  = direction.heading
-        incInt(&turnCnt)
+        incInt(&m.turnCnt)
     }
 }
 
 // unfollowDir marks the maze tried in the given direction starting at x, y
 // and updates the path length & turn count accordingly
-func unfollowDir (x, y *int, direction dirTable, lastDir int) {
-    setCell(*x                , *y                , tried, update, 0, 0)
-    setCell(*x + direction.x/2, *y + direction.y/2, tried, update, 0, 0)
-    decInt(&pathLen)
+func (m *Maze) unfollowDir (x, y *int, direction dirTable, lastDir int) {
+    m.setCell(*x                , *y                , tried, update, 0, 0)
+    m.setCell(*x + direction.x/2, *y + direction.y/2, tried, update, 0, 0)
+    decInt(&m.pathLen)
     if (lastDir != direction.heading)  {
         lastDir  = direction.heading
-        decInt(&turnCnt)
+        decInt(&m.turnCnt)
     }
 }
 
 // followPath follows a path in the maze starting at location x, y
 // It does this by repeatedly determining if there are any possible directions to move
 // and then choosing the first of them and then marking the new cells on the path as solved
-func followPath(x, y *int) bool {
-    directions := make([]dirTable, 4, 4)
+func (m *Maze) followPath(x, y *int) bool {
+    var directions [4]dirTable
     lastDir    :=  0
     length     := -1
-    setCell(*x, *y, solved, noUpdate, 0, 0)
-    for getInt(&begX) <= *x && *x <= getInt(&endX) {
-        num := findDirections(*x, *y, &length, path, directions)
-        if num == 0 {
+    m.setCell(*x, *y, solved, noUpdate, 0, 0)
+    for getInt(&m.begX) <= *x && *x <= getInt(&m.endX) {
+        num := m.findDirections(*x, *y, &length, path, &directions)
+        if num == 0 || !m.solveStep() {
             break
         }
-        followDir(x, y, directions[0], lastDir)
-        if threads > 1 && num > 1 && getBool(&solvedFlag) == false {
+        m.followDir(x, y, directions[0], lastDir)
+        m.reachedCell(*x + directions[0].x, *y + directions[0].y)
+        if m.solveThreads > 1 && num > 1 && getBool(&m.solvedFlag) == false {
             for i := 1; i < num; i++ {
-                incInt(&numThreads)
-                followDir(x, y, directions[i], lastDir)
-                go solve(*x  +  directions[i].x, *y + directions[i].y)
+                incInt(&m.numThreads)
+                m.followDir(x, y, directions[i], lastDir)
+                go m.solve(*x  +  directions[i].x, *y + directions[i].y)
             }
         }
         *x += directions[0].x
         *y += directions[0].y
     }
-    if *x > getInt(&endX) {
-        setBool(&solvedFlag, true)
+    if *x > getInt(&m.endX) {
+        setBool(&m.solvedFlag, true)
         return true
     } else {
         return false
@@ -620,148 +620,220 @@ func followPath(x, y *int) bool {
 // backTrackPath backtracks a path in the maze starting at location x, y
 // It does this by repeatedly determining if there are any possible directions to move
 // and then choosing the first of them and then marking the new cells on the path as tried (not solved)
-func backTrackPath(x, y *int) {
-    directions := make([]dirTable, 4, 4)
+// Returns false if it backed out of the entrance with no untried direction left anywhere (the maze has no solution)
+func (m *Maze) backTrackPath(x, y *int) bool {
+    var directions [4]dirTable
     lastDir    :=  0
     length     := -1
-    for (threads > 1 || findDirections(*x, *y, &length, path  , directions) == 0) &&
-                        findDirections(*x, *y, &length, solved, directions) == 1 {
-        unfollowDir(x, y, directions[0], lastDir)
+    for {
+        if m.solveThreads <= 1 && m.findDirections(*x, *y, &length, path, &directions) > 0 {
+            return true
+        }
+        if m.findDirections(*x, *y, &length, solved, &directions) != 1 {
+            return false
+        }
+        if !m.solveStep() {
+            return true
+        }
+        m.unfollowDir(x, y, directions[0], lastDir)
         *x += directions[0].x
         *y += directions[0].y
     }
 }
 
 // solveRoutine calls follows a single path and backtracks if it doesn't complete the maze
-func solve(x, y int) {
-    if   !followPath(&x, &y) {
-       backTrackPath(&x, &y)
+func (m *Maze) solve(x, y int) {
+    if   !m.followPath(&x, &y) {
+       m.backTrackPath(&x, &y)
     }
-    finishChan <- struct{}{}
+    m.finishChan <- struct{}{}
 }
 
 // waitThreadsDone waits until numThreads signals are received on the finish channel
-func waitThreadsDone() {
-    for i := 0; i < getInt(&numThreads); i++ {
-        <- finishChan
+func (m *Maze) waitThreadsDone() {
+    for i := 0; i < getInt(&m.numThreads); i++ {
+        <- m.finishChan
     }
 }
 
 // solveMaze solves a maze by starting at the beginning and following each path,
 // back tracking when they dead end, until the end of the maze is found.
-func solveMaze(x, y *int) {
-    saveCheck := getBool(&checkFlag); setBool(&checkFlag, false)
-    saveDepth := getInt( &depth    ); setInt( &depth    , -1   )
-    setBool(&solvedFlag, false)
-    setInt( &pathLen   , 0)
-    setInt( &turnCnt   , 0)
-
-    setMaze(getInt(&begX) - 2, getInt(&begY), solved)
-    setMaze(getInt(&begX) - 1, getInt(&begY), solved)
-    if threads > 1 {
-        setInt(&numThreads, 1)
-        go solve(*x, *y)
-        waitThreadsDone()
+func (m *Maze) solveMaze(x, y *int) {
+    m.setPhase(PhaseSolving)
+    saveCheck := getBool(&m.checkFlag); setBool(&m.checkFlag, false)
+    saveDepth := getInt( &m.depth    ); setInt( &m.depth    , -1   )
+    setBool(&m.solvedFlag, false)
+    setInt( &m.pathLen   , 0)
+    setInt( &m.turnCnt   , 0)
+    m.resetBudget()
+
+    lo, hi := m.openingRange(getInt(&m.begX) - 1, getInt(&m.begY))
+    for j := lo; j <= hi; j++ {     // keep the solver from leaving by the rest of a wide entrance
+        m.setMaze(getInt(&m.begX) - 2, j, solved)
+    }
+    m.setMaze(getInt(&m.begX) - 1, getInt(&m.begY), solved)
+    if m.solveThreads > 1 {
+        setInt(&m.numThreads, 1)
+        go m.solve(*x, *y)
+        m.waitThreadsDone()
     } else {
-        for  !followPath(x, y) {
-           backTrackPath(x, y)
+        for  !m.followPath(x, y) && !getBool(&m.budgetExhausted) && !m.stopped() {
+           if !m.backTrackPath(x, y) {
+              break                             // backed out of the entrance, there's nothing left to try
+           }
         }
     }
-    setMaze(getInt(&endX) + 1, getInt(&endY), solved)
-    setMaze(getInt(&endX) + 2, getInt(&endY), solved)
-    setBool(&checkFlag, saveCheck)
-    setInt( &depth    , saveDepth)
+    if getBool(&m.budgetExhausted) || !getBool(&m.solvedFlag) {      // the exit was marked solved on the way out otherwise
+        m.markExplored()
+    }
+    setBool(&m.checkFlag, saveCheck)
+    setInt( &m.depth    , saveDepth)
+}
+
+// gapRange returns the first and last columns of the cells under an opening gap cells wide centered on
+// column y, moved over as needed to keep it within the maze
+func (m *Maze) gapRange(y int) (int, int) {
+    lo := max(2, min(y - 2*((m.gap - 1)/2), 2*(m.width - m.gap + 1)))
+    return lo, lo + 2*(m.gap - 1)
+}
+
+// setOpening sets the border locations at row x over the opening centered on column y to value
+func (m *Maze) setOpening(x, y, value int) {
+    lo, hi := m.gapRange(y)
+    for j := lo; j <= hi; j++ {
+        m.setMaze(x, j, value)
+    }
 }
 
-// createOpenings marks the top and bottom of the maze at locations begX, x and endX, y as paths
-// and then sets x, y to the start of the maze: begX, begY.
-func createOpenings(x, y *int) {
-    setInt(&begY, *x)
-    setInt(&endY, *y)
-    setMaze(getInt(&begX) - 1, getInt(&begY), path)
-    setMaze(getInt(&endX) + 1, getInt(&endY), path)
-    *x = getInt(&begX)
-    *y = getInt(&begY)
+// openingRange returns the first and last columns of the cells under the opening containing column y in the
+// border at row x, which may be wider than gap for a loaded maze
+func (m *Maze) openingRange(x, y int) (int, int) {
+    lo, hi := y, y
+    for lo > 2         && m.getMaze(x, lo - 1) != wall && m.getMaze(x, lo - 2) != wall {; lo -= 2; }
+    for hi < 2*m.width   && m.getMaze(x, hi + 1) != wall && m.getMaze(x, hi + 2) != wall {; hi += 2; }
+    return lo, hi
 }
 
-// deleteOpenings marks the openings in the maze by setting the locations begX, begY and endX, endY back to wall.
-func deleteOpenings()  {
-    setMaze(getInt(&begX) - 1, getInt(&begY), wall)
-    setMaze(getInt(&endX) + 1, getInt(&endY), wall)
+// createOpenings marks the top and bottom of the maze at locations begX, x and endX, y as paths, widened
+// to gap cells, and then sets x, y to the start of the maze: begX, begY.
+func (m *Maze) createOpenings(x, y *int) {
+    setInt(&m.begY, *x)
+    setInt(&m.endY, *y)
+    m.setOpening(getInt(&m.begX) - 1, getInt(&m.begY), path)
+    m.setOpening(getInt(&m.endX) + 1, getInt(&m.endY), path)
+    *x = getInt(&m.begX)
+    *y = getInt(&m.begY)
+}
+
+// openingScore is the solution found with the entrance at column start and the exit at column finish
+type openingScore struct {
+    pathLen, turns, junctions int
+    start, finish             int
+}
+
+// betterOpening returns true if the openings scored a are preferred to those scored b: the longer solution path,
+// then the one with more turns, then more junctions along it, then the lowest entrance column and the lowest exit
+// column.  No two pairs of openings tie, so the choice doesn't depend on the order the pairs are tried in.
+func betterOpening(a, b openingScore) bool {
+    switch {
+        case a.pathLen   != b.pathLen  : return a.pathLen   > b.pathLen
+        case a.turns     != b.turns    : return a.turns     > b.turns
+        case a.junctions != b.junctions: return a.junctions > b.junctions
+        case a.start     != b.start    : return a.start     < b.start
+    }
+    return a.finish < b.finish
+}
+
+// solvedJunctions returns the number of junctions, cells with three or more passages, along the solution path
+func (m *Maze) solvedJunctions() int {
+    junctions := 0
+    m.cells(func(c cell, state int) bool {
+        junctions += bool2int(state == solved && m.degree(c.loc()) >= 3)
+        return true
+    })
+    return junctions
 }
 
 // searchBestOpenings sets the top an bottom openings to all possible locations and repeatedly solves the maze
-// keeping track of which set of openings produces the longest solution path, then sets x, y to the result.
-func searchBestOpenings(x, y *int) {
-    bestPathLen := 0
-    bestTurnCnt := 0
-    bestStart   := 2
-    bestFinish  := 2
-    saveDelay   := getInt(&delay)    // don't print updates while solving for best openings
-    setInt(&delay, 0)
-
-    for i := 0; i < width; i++ {
-        for j := 0; j < width; j++ {
-            start  := 2*(i + 1)
-            finish := 2*(j + 1)
-            *x = start
-            *y = finish
-            if getMaze(getInt(&begX), start  - 1) != wall && getMaze(getInt(&begX), start  + 1) != wall {; continue; }
-            if getMaze(getInt(&endX), finish - 1) != wall && getMaze(getInt(&endX), finish + 1) != wall {; continue; }
-            createOpenings(x, y)
-            solveMaze(x, y)
-            if getInt(&pathLen)  >  bestPathLen ||
-              (getInt(&pathLen)  == bestPathLen &&
-               getInt(&turnCnt)  >  bestTurnCnt) {
-               bestStart   = start
-               bestFinish  = finish
-               bestTurnCnt = getInt(&turnCnt)
-               bestPathLen = getInt(&pathLen)
-               setInt(&solveLength, getInt(&pathLen))
+// keeping track of which set of openings produces the best solution path, then sets x, y to the result.  Openings
+// are never over cells left out by a mask, nor in the middle of a corridor unless a mask leaves nowhere else.
+func (m *Maze) searchBestOpenings(x, y *int) {
+    m.setPhase(PhaseSolving)
+    best      := openingScore{start: 2, finish: 2}
+    saveDelay := getInt(&m.delay)    // don't print updates while solving for best openings
+    setInt(&m.delay, 0)
+    unsolved  := m.Snapshot()        // each pair of openings is tried on the maze as it was carved
+
+    found     := false
+    for _, corridors := range []bool{false, true} {     // mid corridor openings only where a mask leaves no others
+        for i := 0; i < m.width && !m.stopped(); i++ {
+            for j := 0; j < m.width && !m.stopped(); j++ {
+                start  := 2*(i + 1)
+                finish := 2*(j + 1)
+                *x = start
+                *y = finish
+                if !corridors && m.getMaze(getInt(&m.begX), start  - 1) != wall && m.getMaze(getInt(&m.begX), start  + 1) != wall {; continue; }
+                if !corridors && m.getMaze(getInt(&m.endX), finish - 1) != wall && m.getMaze(getInt(&m.endX), finish + 1) != wall {; continue; }
+                if m.gapMasked(getInt(&m.begX), start) || m.gapMasked(getInt(&m.endX), finish) {; continue; }   // openings into the mask
+                m.createOpenings(x, y)
+                m.showSearch(start, finish, best.start, best.finish)
+                m.solveMaze(x, y)
+                found = found || getBool(&m.solvedFlag)
+                if score := (openingScore{m.solvedLength(), getInt(&m.turnCnt), m.solvedJunctions(), start, finish}); betterOpening(score, best) && !m.stopped() {
+                   best = score
+                   setInt(&m.solveLength, score.pathLen)
+                }
+                m.Restore(unsolved)
+                incInt(&m.numSolves)
             }
-            restoreMaze()
-            deleteOpenings()
-            incInt(&numSolves)
+        }
+        if found || m.mask == nil {
+            break
         }
     }
-    addInt(&sumsolveLength, getInt(&solveLength))
-    if viewFlag {
-        setInt(&delay, saveDelay)   // only restore delay value if view solve flag is set
+    m.endSearch()
+    addInt(&m.sumsolveLength, getInt(&m.solveLength))
+    if m.viewFlag {
+        setInt(&m.delay, saveDelay)   // only restore delay value if view solve flag is set
     }
-    *x = bestStart
-    *y = bestFinish
-    createOpenings(x, y)
+    *x = best.start
+    *y = best.finish
+    m.createOpenings(x, y)
 }
 
 // midWallOpening returns true if there is a mid wall (non-corner) opening in a path at location x, y
-func midWallOpening(x, y int) bool {
+func (m *Maze) midWallOpening(x, y int) bool {
     return        x > 0 && y > 0         &&
-           getMaze(x    , y    ) == path &&
-           getMaze(x - 1, y - 1) != wall &&
-           getMaze(x - 1, y + 1) != wall &&
-           getMaze(x + 1, y - 1) != wall &&
-           getMaze(x + 1, y + 1) != wall
+           m.getMaze(x    , y    ) == path &&
+           m.getMaze(x - 1, y - 1) != wall &&
+           m.getMaze(x - 1, y + 1) != wall &&
+           m.getMaze(x + 1, y - 1) != wall &&
+           m.getMaze(x + 1, y + 1) != wall
 }
 
 // pushMidWallOpenings loops over all locations in the maze searching for mid wall openings and pushes horizontal
 // openings to the right, and vertical openings down, and then returns the number of mid wall openings moved.
-func pushMidWallOpenings() {
+func (m *Maze) pushMidWallOpenings() {
+    m.setPhase(PhasePushing)
     for {
         moves := 0
-        for i := 1; i < 2 * (height + 1); i++ {
-            for j := (i & 1) + 1; j < 2 * (width + 1); j += 2 {
-                if (midWallOpening(i, j)) {
-                    setCell(i, j, wall, noUpdate, 0, 0)
-                    if isOdd(i) {; setCell(i,  j + 2, path, update, 0, 0)   // push right
-                    } else {;      setCell(i + 2,  j, path, update, 0, 0)   // push down
+        for i := 1; i < 2 * (m.height + 1); i++ {
+            for j := (i & 1) + 1; j < 2 * (m.width + 1); j += 2 {
+                if (m.midWallOpening(i, j)) {
+                    x, y := i + 2, j                            // push down
+                    if isOdd(i) {; x, y = i, j + 2; }           // push right
+                    if m.joinsMasked(x, y) {
+                        continue                                // never into a cell left out by the mask
                     }
+                    m.setCell(i, j, wall, noUpdate, 0, 0)
+                    m.setCell(x, y, path, update, 0, 0)
                     moves++
-                    incInt(&numWallPush)
+                    incInt(&m.numWallPush)
                 }
             }
         }
-        if getInt(&delay) > 0 {
-            updateMaze(0)
+        if getInt(&m.delay) > 0 {
+            m.updateMaze(0)
         }
         if moves == 0 {
             break
@@ -769,129 +841,113 @@ func pushMidWallOpenings() {
     }
 }
 
-// carvePaths continuuosly carves new paths while it can find starting locations for new paths
-func carvePaths(x, y int) {
+// carvePaths continuuosly carves new paths while it can find starting locations for new paths, until cancelled
+func (m *Maze) carvePaths(x, y int) {
     if x > 0 && y > 0 {
-        carvePath(&x, &y)
+        m.carvePath(&x, &y)
     }
-    for findPathStart(&x, &y) &&
-            carvePath(&x, &y) {
+    for !m.stopped() && m.findPathStart(&x, &y) &&
+                        m.carvePath(&x, &y) {
+        if m.onPath != nil {
+            m.onPath()
+        }
     }
 }
 
 // carveRoutine calls carvePaths and sends a signal finshChan when complete
-func carveRoutine() {
+func (m *Maze) carveRoutine() {
     msSleep(10)
-    carvePaths(0, 0)
-    finishChan <- struct{}{}
+    m.carvePaths(0, 0)
+    m.finishChan <- struct{}{}
 }
 
-// createMaze initializes the maze array then repeatedly carves new paths until no new path starting locations can be found.
+// createMaze initializes the maze array then has the generator carve its paths, returning the generator's error.
 // Following this it then repeatedly pushes mid wall openings right or down until there are no longer any mid wall openings.
 // Lastly it searches for the best openings, top and bottom, to create the maze with the longest solution path.
-func createMaze(x, y *int) {
-    initializeMaze(x, y)
-    carvePaths(*x, *y)
-    waitThreadsDone()
-    pushMidWallOpenings()
-    searchBestOpenings(x, y)
-}
-
-// maze main parses the command line switches and then repeatedly creates and
-// solves mazes until the minimum solution path length criteria is met.
-func main() {
-    flag.Usage = func() {
-        fmt.Printf("%s\nUsage: %s [options]\n%s", utsSignOn, flag.Arg(0),
-             "Options:"                                                                                 + "\n" +
-             "  -f, --fps     <frames per second>  Set refresh rate           (default: none, instant)" + "\n" +
-             "  -h, --height  <height>             Set maze height            (default: screen height)" + "\n" +
-             "  -w, --width   <width>              Set maze width             (default: screen width )" + "\n" +
-             "  -t, --threads <threads>            Set maze path thread count (default: 0            )" + "\n" +
-             "  -d, --depth   <depth>              Set path search depth      (default: 0            )" + "\n" +
-             "  -p, --path    <length>             Set minimum path length    (default: 0            )" + "\n" +
-             "  -r, --random  <seed>               Set random number seed     (default: current usec )" + "\n" +
-             "  -s, --show                         Show intermediate results while path length not met" + "\n" +
-             "  -v, --view                         Show intermediate results determining maze solution" + "\n" +
-             "  -l, --look                         Show look ahead path searches while creating maze  " + "\n" +
-             "  -b, --blank                        Show empty maze as blank vs. lattice work of walls " + "\n" +
-             "  -o, --output  <filename>           Output portable ASCII encoded maze when completed  " + "\n\n")
-    }
-    rows, cols := getConsoleSize()
-    maxHeight  := min(maxHeight, (rows - 3)/2)
-    maxWidth   := min(maxWidth , (cols - 1)/4)
-    myStdout    = bufio.NewWriterSize(os.Stdout, rows*cols)
-    displayChan = make(chan struct{});
-    finishChan  = make(chan struct{});
-
-    flag.IntVar(   &fps       , "fps"    , 0        , "refresh rate"               );
-    flag.IntVar(   &fps       , "f"      , 0        , "refresh rate    (shorthand)");
-    flag.IntVar(   &height    , "height" , maxHeight, "maze height"                );
-    flag.IntVar(   &height    , "h"      , maxHeight, "maze height     (shorthand)");
-    flag.IntVar(   &width     , "width"  , maxWidth , "maze width"                 );
-    flag.IntVar(   &width     , "w"      , maxWidth , "maze width      (shorthand)");
-    flag.IntVar(   &threads   , "threads", 0        , "path threads"               );
-    flag.IntVar(   &threads   , "t"      , 0        , "path threads    (shorthand)");
-    flag.IntVar(   &depthVal  , "depth"  , 0        , "search depth"               );
-    flag.IntVar(   &depthVal  , "d"      , 0        , "search depth    (shorthand)");
-    flag.IntVar(   &minLen    , "path"   , 0        , "path length"                );
-    flag.IntVar(   &minLen    , "p"      , 0        , "path length     (shorthand)");
-    flag.IntVar(   &seed      , "random" , 0        , "random seed"                );
-    flag.IntVar(   &seed      , "r"      , 0        , "random seed     (shorthand)");
-    flag.BoolVar(  &showFlag  , "show"   , false    , "show working"               );
-    flag.BoolVar(  &showFlag  , "s"      , false    , "show working    (shorthand)");
-    flag.BoolVar(  &viewFlag  , "view"   , false    , "show solving"               );
-    flag.BoolVar(  &viewFlag  , "v"      , false    , "show solving    (shorthand)");
-    flag.BoolVar(  &lookFlag  , "look"   , false    , "show look ahead"            );
-    flag.BoolVar(  &lookFlag  , "l"      , false    , "show look ahead (shorthand)");
-    flag.BoolVar(  &blankFlag , "blank"  , false    , "blank walls"                );
-    flag.BoolVar(  &blankFlag , "b"      , false    , "blank walls     (shorthand)");
-    flag.StringVar(&outputName, "output" , ""       , "output ascii"               );
-    flag.StringVar(&outputName, "o"      , ""       , "output ascii    (shorthand)");
-
-    flag.Parse()
-
-    if depthVal <  0 || depthVal > 100            {; depthVal = 100           ;}
-    if fps      <  0 || fps      > 100000         {; fps      = 100000        ;}
-    if height   <= 0 || height   > maxHeight      {; height   = maxHeight     ;}
-    if width    <= 0 || width    > maxWidth       {; width    = maxWidth      ;}
-    if minLen   <  0 || minLen   > height*width/3 {; minLen   = height*width/3;}
-
-    setBool(&checkFlag, lookFlag);
-    setInt( &depth    , depthVal);
-
-    clrScreen()
-    setCursorOff()
-    go displayRoutine()
+func (m *Maze) createMaze(gen Generator, x, y *int) error {
+    g := &Grid{m: m}
+    if m.resumeState != nil {
+        m.restoreCheckpoint()
+        g.resumed = true
+    } else {
+        m.initializeMaze()
+    }
+    if err := gen.Carve(g, m.rng); err != nil {
+        return err
+    }
+    m.clearStaleChecks()
+    m.joinMasked()
+    m.pushMidWallOpenings()
+    m.searchBestOpenings(x, y)
+    return nil
+}
 
+// generateMaze creates and solves mazes carved by gen until one has the minimum path length and asymmetry, or until
+// the attempts run out, and returns true if it gave up without meeting them (a loaded maze is just solved), or the
+// error of a generator that fails
+func (m *Maze) generateMaze(gen Generator) (bool, error) {
+    notMet   := false
+    attempts := 0
     for {
         switch {
-            case fps ==    0: setInt(&delay,       0      )
-            case fps <= 1000: setInt(&delay,    1000 / fps)
-            default:          setInt(&delay, 1000000 / fps)
+            case m.fps ==    0: setInt(&m.delay,       0      )
+            case m.fps <= 1000: setInt(&m.delay,    1000 / m.fps)
+            default:          setInt(&m.delay, 1000000 / m.fps)
         }
 
-        incInt(&numMazeCreated)
-        if (getInt(&numMazeCreated) > 1 || seed == 0) {
-            seed = time.Now().Nanosecond()
+        incInt(&m.numMazeCreated)
+        attempts++
+        if m.resumeState == nil && m.cfg.randSource == nil {       // a source of the caller's own is drawn from as it stands
+            if (getInt(&m.numMazeCreated) > 1 || m.seedVal == 0) {
+                m.seedVal = int64(time.Now().Nanosecond())
+            }
+            m.rng.Seed(m.seedVal);
         }
-        rand.Seed(int64(seed));
+        m.setSeed(m.seedVal)
 
         var pathStartX int
         var pathStartY int
 
-        createMaze(&pathStartX, &pathStartY); if showFlag {; updateMaze(0);  msSleep(1000); }
-         solveMaze(&pathStartX, &pathStartY); if showFlag {; updateMaze(0);  msSleep(1000); }
+        m.setPhase(PhaseCarving)
+
+        start := time.Now()
+        if m.loaded != nil {
+            m.loadInput(&pathStartX, &pathStartY)
+        } else if err := m.createMaze(gen, &pathStartX, &pathStartY); err != nil {
+            m.resumeState = nil
+            return false, err
+        }
+        m.genElapsed = time.Since(start); if m.showFlag {; m.updateMaze(0);  msSleep(1000); }
+        m.resumeState = nil
+        if m.stopped() {
+           break
+        }
+        setBool(&m.budgetOn, true)
+        start = time.Now()
+         m.solveMaze(&pathStartX, &pathStartY); m.solveElapsed = time.Since(start); if m.showFlag {; m.updateMaze(0);  msSleep(1000); }
+        setBool(&m.budgetOn, false)
 
-        if getInt(&solveLength) >= minLen {
+        if m.stopped() {
+           break
+        }
+        if m.loaded != nil {
+           setInt(&m.solveLength, m.solvedLength())
+           if m.handedFlag && !m.unsolvable() {
+              m.measureHandedness()
+           }
+           break
+        }
+        if getBool(&m.budgetExhausted) {
+           break
+        }
+        balanced := !m.handedFlag || m.measureHandedness()
+        if getInt(&m.solveLength) >= m.minLen && balanced {
+           break
+        }
+        if m.maxAttempts > 0 && attempts >= m.maxAttempts {
+           notMet = true
            break
         }
     }
-    updateMaze(0)
-    msSleep(100)
-    restoreMaze()
-    outputAsciiMaze()
-    setCursorOn()
-    putchar('\n')
-    myStdout.Flush()
+    return notMet, nil
 }
-
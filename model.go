@@ -0,0 +1,319 @@
+/* model.go - 3D models of the maze
+ *
+ * Builds the maze as a solid for 3D printing: a base plate with the walls standing on it, written as a binary
+ * STL or a Wavefront OBJ mesh in millimetres.  The walls are merged into rectangles as large as they go, and the
+ * mesh is the surface of the one solid they make with the plate, every edge shared by exactly two triangles, so
+ * a slicer takes it as it is.  The openings are left in the outer wall and the plate runs on around it as a rim.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "sort"
+    "strconv"
+    "encoding/binary"
+)
+
+// Defaults and limits of the model formats' options, in millimetres
+const (
+    DefaultModelCellSize      = 10.0  // from one wall to the next
+    DefaultModelWallHeight    = 10.0  // above the plate
+    DefaultModelWallThickness = 2.0
+    MaxModelCellSize          = 100
+    MaxModelWallHeight        = 100
+    modelPlate                = 2.0   // thickness of the base plate
+)
+
+// ModelOptions chooses the sizes of the models RenderSTL and RenderOBJ build
+type ModelOptions struct {
+    CellSize      float64           // millimetres from one wall to the next, 0 for DefaultModelCellSize
+    WallHeight    float64           // millimetres the walls stand above the plate, 0 for DefaultModelWallHeight
+    WallThickness float64           // millimetres, 0 for DefaultModelWallThickness, less than the cell size
+}
+
+// withDefaults returns the options with the defaults in place of any zero values
+func (o ModelOptions) withDefaults() ModelOptions {
+    if o.CellSize      == 0 {; o.CellSize      = DefaultModelCellSize     ; }
+    if o.WallHeight    == 0 {; o.WallHeight    = DefaultModelWallHeight   ; }
+    if o.WallThickness == 0 {; o.WallThickness = DefaultModelWallThickness; }
+    return o
+}
+
+// Check returns an error if the options are out of range
+func (o ModelOptions) Check() error {
+    d := o.withDefaults()
+    switch {
+        case !(o.CellSize >= 0 && o.CellSize <= MaxModelCellSize):               // not a number either
+            return fmt.Errorf("model cell size %g is outside 0 to %d millimetres", o.CellSize, MaxModelCellSize)
+        case !(o.WallHeight >= 0 && o.WallHeight <= MaxModelWallHeight):
+            return fmt.Errorf("model wall height %g is outside 0 to %d millimetres", o.WallHeight, MaxModelWallHeight)
+        case !(o.WallThickness >= 0 && d.WallThickness < d.CellSize):
+            return fmt.Errorf("model wall thickness %g must be less than the cell size of %g millimetres", d.WallThickness, d.CellSize)
+    }
+    return nil
+}
+
+// modelPoint is a corner of the model in grid units: the columns and rows of the maze array's locations, the rows
+// counted up from the bottom, and the levels of the bottom, the top of the plate and the tops of the walls
+type modelPoint [3]int
+
+// modelFace is a rectangle of the model's surface, its corners anticlockwise seen from outside, and the axis and
+// sign of its outward normal
+type modelFace struct {
+    corners [4]modelPoint
+    axis    int
+    sign    int
+}
+
+// modelTriangle is a triangle of the mesh in millimetres, anticlockwise seen from outside, and its outward normal
+type modelTriangle struct {
+    normal [3]float32
+    v      [3][3]float64
+}
+
+// newModelFace returns the rectangle with its normal along axis, at position at on it, covering lo to hi on the
+// other two axes in their cyclic order
+func newModelFace(axis, sign, at int, lo, hi [2]int) modelFace {
+    b, c := (axis + 1) % 3, (axis + 2) % 3
+    corner := func(u, v int) modelPoint {
+        var p modelPoint
+        p[axis], p[b], p[c] = at, u, v
+        return p
+    }
+    f := modelFace{[4]modelPoint{corner(lo[0], lo[1]), corner(hi[0], lo[1]), corner(hi[0], hi[1]), corner(lo[0], hi[1])}, axis, sign}
+    if sign < 0 {
+        f.corners[1], f.corners[3] = f.corners[3], f.corners[1]
+    }
+    return f
+}
+
+// modelFaces returns the rectangles of the surface of the solid the maze makes, for a grid of levels of cols by rows
+// locations: the tops, greedily merged into rectangles, the sides wherever the level steps, merged along each grid
+// line, and the bottom
+func modelFaces(level [][]int, cols, rows int) []modelFace {
+    at := func(c, r int) int {                                      // 0 outside the model
+        if c < 0 || c >= cols || r < 0 || r >= rows {
+            return 0
+        }
+        return level[c][r]
+    }
+    faces := []modelFace{newModelFace(2, -1, 0, [2]int{0, 0}, [2]int{cols, rows})}
+    done := make([][]bool, cols)
+    for c := range done {
+        done[c] = make([]bool, rows)
+    }
+    for r := 0; r < rows; r++ {
+        for c := 0; c < cols; c++ {
+            if done[c][r] {
+                continue
+            }
+            c1, r1 := c + 1, r + 1
+            for c1 < cols && !done[c1][r] && level[c1][r] == level[c][r] {
+                c1++
+            }
+            for ; r1 < rows; r1++ {                                 // while the whole row above matches too
+                k := c
+                for k < c1 && !done[k][r1] && level[k][r1] == level[c][r] {
+                    k++
+                }
+                if k < c1 {
+                    break
+                }
+            }
+            for k := c; k < c1; k++ {
+                for j := r; j < r1; j++ {
+                    done[k][j] = true
+                }
+            }
+            faces = append(faces, newModelFace(2, 1, level[c][r], [2]int{c, r}, [2]int{c1, r1}))
+        }
+    }
+    // the steps between locations a and b on either side of a grid line: normal along axis, pointing to b if a is
+    // higher, along the line from k to k1
+    steps := func(axis, line, n int, a, b func(k int) int) {
+        for k := 0; k < n; {
+            lo, hi := a(k), b(k)
+            k1 := k + 1
+            for k1 < n && a(k1) == lo && b(k1) == hi {
+                k1++
+            }
+            switch {
+                case lo > hi && axis == 0: faces = append(faces, newModelFace(0,  1, line, [2]int{k, hi}, [2]int{k1, lo}))
+                case lo < hi && axis == 0: faces = append(faces, newModelFace(0, -1, line, [2]int{k, lo}, [2]int{k1, hi}))
+                case lo > hi             : faces = append(faces, newModelFace(1,  1, line, [2]int{hi, k}, [2]int{lo, k1}))
+                case lo < hi             : faces = append(faces, newModelFace(1, -1, line, [2]int{lo, k}, [2]int{hi, k1}))
+            }
+            k = k1
+        }
+    }
+    for c := 0; c <= cols; c++ {
+        steps(0, c, rows, func(r int) int {; return at(c - 1, r); }, func(r int) int {; return at(c, r); })
+    }
+    for r := 0; r <= rows; r++ {
+        steps(1, r, cols, func(c int) int {; return at(c, r - 1); }, func(c int) int {; return at(c, r); })
+    }
+    return faces
+}
+
+// modelMesh returns the triangles of the faces, in millimetres at the given positions of the grid lines on each axis.
+// Where corners of other faces lie along a face's edges the face is split at them, as a fan from its middle, so
+// that every edge of the mesh is an edge of exactly two triangles.
+func modelMesh(faces []modelFace, pos [3][]float64) []modelTriangle {
+    type lineKey struct {
+        axis int
+        at   modelPoint                                             // the point with the position along axis zeroed
+    }
+    lines := map[lineKey][]int{}
+    seen  := map[modelPoint]bool{}
+    for _, f := range faces {
+        for _, p := range f.corners {
+            if seen[p] {
+                continue
+            }
+            seen[p] = true
+            for axis := 0; axis < 3; axis++ {
+                key := lineKey{axis, p}
+                key.at[axis] = 0
+                lines[key] = append(lines[key], p[axis])
+            }
+        }
+    }
+    for _, l := range lines {
+        sort.Ints(l)
+    }
+    mm := func(p modelPoint) [3]float64 {; return [3]float64{pos[0][p[0]], pos[1][p[1]], pos[2][p[2]]}; }
+
+    var mesh []modelTriangle
+    for _, f := range faces {
+        var normal [3]float32
+        normal[f.axis] = float32(f.sign)
+        var ring [][3]float64                                       // the corners and the points along the edges
+        for k, p := range f.corners {
+            ring = append(ring, mm(p))
+            q := f.corners[(k + 1) % 4]
+            axis := 0
+            for p[axis] == q[axis] {
+                axis++
+            }
+            key := lineKey{axis, p}
+            key.at[axis] = 0
+            l := lines[key]
+            lo, hi := p[axis], q[axis]
+            i, j := sort.SearchInts(l, min(lo, hi) + 1), sort.SearchInts(l, max(lo, hi))
+            for n := 0; n < j - i; n++ {
+                at := l[i + n]
+                if lo > hi {
+                    at = l[j - 1 - n]
+                }
+                between := p
+                between[axis] = at
+                ring = append(ring, mm(between))
+            }
+        }
+        if len(ring) == 4 {
+            mesh = append(mesh, modelTriangle{normal, [3][3]float64{ring[0], ring[1], ring[2]}},
+                                modelTriangle{normal, [3][3]float64{ring[0], ring[2], ring[3]}})
+            continue
+        }
+        var middle [3]float64
+        a, c := mm(f.corners[0]), mm(f.corners[2])
+        for d := 0; d < 3; d++ {
+            middle[d] = (a[d] + c[d])/2
+        }
+        for k := range ring {
+            mesh = append(mesh, modelTriangle{normal, [3][3]float64{middle, ring[k], ring[(k + 1) % len(ring)]}})
+        }
+    }
+    return mesh
+}
+
+// model returns the triangles of the maze as a solid with the options' sizes, the walls being the wall locations
+// other than lone posts, and a post filled in wherever a wall meets it
+func (m *Maze) model(opts ModelOptions) []modelTriangle {
+    opts = opts.withDefaults()
+    rows, cols := getInt(&m.maxX), getInt(&m.maxY)
+    solid := func(x, y int) bool {
+        if isOdd(x) && isOdd(y) {
+            return m.getMaze(x-1, y) == wall || m.getMaze(x+1, y) == wall || m.getMaze(x, y-1) == wall || m.getMaze(x, y+1) == wall
+        }
+        return m.getMaze(x, y) == wall
+    }
+    level := make([][]int, cols)                                    // by column then row up from the bottom
+    for y := range level {
+        level[y] = make([]int, rows)
+        for x := 0; x < rows; x++ {
+            level[y][rows - 1 - x] = 1 + bool2int(solid(x, y))
+        }
+    }
+    across := func(n int) []float64 {                               // the rim is a wall's thickness across
+        starts, p := make([]float64, n + 1), 0.0
+        for i := 0; i < n; i++ {
+            starts[i] = p
+            if isOdd(i) || i == 0 || i == n - 1 {
+                p += opts.WallThickness
+            } else {
+                p += opts.CellSize - opts.WallThickness
+            }
+        }
+        starts[n] = p
+        return starts
+    }
+    pos := [3][]float64{across(cols), across(rows), {0, modelPlate, modelPlate + opts.WallHeight}}
+    return modelMesh(modelFaces(level, cols, rows), pos)
+}
+
+// RenderSTL writes the maze to w as a binary STL model, returning an error if the options are out of range or the
+// first error from w
+func (m *Maze) RenderSTL(w io.Writer, opts ModelOptions) error {
+    if err := opts.Check(); err != nil {
+        return err
+    }
+    m = m.widened()
+    mesh := m.model(opts)
+    ew   := &errWriter{w: w}
+    var header [80]byte
+    copy(header[:], fmt.Sprintf("maze %dx%d, version %s", m.width, m.height, Version))
+    ew.Write(header[:])
+    binary.Write(ew, binary.LittleEndian, uint32(len(mesh)))
+    for _, t := range mesh {
+        var record [12]float32
+        copy(record[:3], t.normal[:])
+        for k, v := range t.v {
+            for d := 0; d < 3; d++ {
+                record[3 + 3*k + d] = float32(v[d])
+            }
+        }
+        binary.Write(ew, binary.LittleEndian, record)
+        binary.Write(ew, binary.LittleEndian, uint16(0))
+    }
+    return ew.err
+}
+
+// RenderOBJ writes the maze to w as a Wavefront OBJ model, each vertex written once, returning an error if the
+// options are out of range or the first error from w
+func (m *Maze) RenderOBJ(w io.Writer, opts ModelOptions) error {
+    if err := opts.Check(); err != nil {
+        return err
+    }
+    m = m.widened()
+    mesh := m.model(opts)
+    ew   := &errWriter{w: w}
+    fmt.Fprintf(ew, "# maze %dx%d, version %s, millimetres\no maze\n", m.width, m.height, Version)
+    num   := func(v float64) string {; return strconv.FormatFloat(v, 'f', -1, 32); }      // as precise as stl
+    index := map[[3]float64]int{}
+    faces := make([][3]int, len(mesh))
+    for i, t := range mesh {
+        for k, v := range t.v {
+            if index[v] == 0 {
+                index[v] = len(index) + 1
+                fmt.Fprintf(ew, "v %s %s %s\n", num(v[0]), num(v[1]), num(v[2]))
+            }
+            faces[i][k] = index[v]
+        }
+    }
+    for _, f := range faces {
+        fmt.Fprintf(ew, "f %d %d %d\n", f[0], f[1], f[2])
+    }
+    return ew.err
+}
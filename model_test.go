@@ -0,0 +1,137 @@
+/* model_test.go - 3D model tests
+ *
+ * Reads back the stl and obj models of small mazes from fixed seeds and checks that they're watertight, every
+ * edge shared by exactly two triangles that run along it in opposite directions, and that the walls stand clear
+ * of the entrance and exit.
+ */
+package maze
+
+import (
+    "bytes"
+    "strings"
+    "strconv"
+    "testing"
+    "encoding/binary"
+)
+
+// modelVertex is a corner of a triangle read back from a model
+type modelVertex [3]float32
+
+// readSTL returns the triangles of a binary stl model
+func readSTL(t *testing.T, stl []byte) [][3]modelVertex {
+    t.Helper()
+    var count uint32
+    r := bytes.NewReader(stl[80:])
+    if err := binary.Read(r, binary.LittleEndian, &count); err != nil || len(stl) != 84 + 50*int(count) {
+        t.Fatalf("stl of %d bytes for %d triangles: %v", len(stl), count, err)
+    }
+    triangles := make([][3]modelVertex, count)
+    for i := range triangles {
+        var record [12]float32
+        var attributes uint16
+        binary.Read(r, binary.LittleEndian, &record)
+        binary.Read(r, binary.LittleEndian, &attributes)
+        for k := range triangles[i] {
+            copy(triangles[i][k][:], record[3 + 3*k:6 + 3*k])
+        }
+    }
+    return triangles
+}
+
+// readOBJ returns the triangles of an obj model
+func readOBJ(t *testing.T, obj []byte) [][3]modelVertex {
+    t.Helper()
+    var vertices  []modelVertex
+    var triangles [][3]modelVertex
+    for _, line := range strings.Split(string(obj), "\n") {
+        fields := strings.Fields(line)
+        if len(fields) != 4 {
+            continue
+        }
+        var v [3]float64
+        for k := range v {
+            v[k], _ = strconv.ParseFloat(fields[k + 1], 32)
+        }
+        switch fields[0] {
+            case "v":
+                vertices = append(vertices, modelVertex{float32(v[0]), float32(v[1]), float32(v[2])})
+            case "f":
+                var tri [3]modelVertex
+                for k := range tri {
+                    if n := int(v[k]); n < 1 || n > len(vertices) {
+                        t.Fatalf("face %s refers to vertex %d of %d", line, n, len(vertices))
+                    } else {
+                        tri[k] = vertices[n - 1]
+                    }
+                }
+                triangles = append(triangles, tri)
+        }
+    }
+    return triangles
+}
+
+// checkWatertight checks that each edge of the triangles is run along once in each direction, so it's shared by two
+// triangles facing the same way
+func checkWatertight(t *testing.T, name string, triangles [][3]modelVertex) {
+    t.Helper()
+    edges := map[[2]modelVertex]int{}
+    for _, tri := range triangles {
+        for k := range tri {
+            edges[[2]modelVertex{tri[k], tri[(k + 1) % 3]}]++
+        }
+    }
+    for e, n := range edges {
+        if back := edges[[2]modelVertex{e[1], e[0]}]; n != 1 || back != 1 {
+            t.Fatalf("%s: edge %v run along %d times and back %d times, want once each", name, e, n, back)
+        }
+    }
+}
+
+func TestModelWatertight(t *testing.T) {
+    for _, size := range [][2]int{{1, 1}, {4, 3}, {12, 8}} {
+        m := goldenMaze(t, WithSize(size[0], size[1]), WithSeed(1))
+        var stl, obj bytes.Buffer
+        if err := m.RenderSTL(&stl, ModelOptions{}); err != nil {
+            t.Fatal(err)
+        }
+        if err := m.RenderOBJ(&obj, ModelOptions{WallHeight: 5, WallThickness: 1}); err != nil {
+            t.Fatal(err)
+        }
+        checkWatertight(t, "stl", readSTL(t, stl.Bytes()))
+        checkWatertight(t, "obj", readOBJ(t, obj.Bytes()))
+    }
+}
+
+// TestModelOpenings checks that no wall top lies over the entrance or the exit, in the wall lines of the border
+func TestModelOpenings(t *testing.T) {
+    m := goldenMaze(t, WithSize(4, 3), WithSeed(1))
+    var stl bytes.Buffer
+    if err := m.RenderSTL(&stl, ModelOptions{}); err != nil {
+        t.Fatal(err)
+    }
+    cell, wallPx := float32(DefaultModelCellSize), float32(DefaultModelWallThickness)
+    column := func(y int) float32 {             // the millimetres across to the middle of cell location y, past
+        return wallPx + float32(y/2 - 1)*cell + cell/2                  // the rim and a wall
+    }
+    top := float32(modelPlate + DefaultModelWallHeight)
+    for _, opening := range []struct {
+        name string
+        x, y float32
+    }{
+        {"entrance", column(getInt(&m.begY)), float32(m.cfg.height)*cell + wallPx + wallPx/2},
+        {"exit"    , column(getInt(&m.endY)), wallPx + wallPx/2},
+    } {
+        for _, tri := range readSTL(t, stl.Bytes()) {
+            lo, hi := tri[0], tri[0]
+            for _, v := range tri {
+                for k := range v {
+                    if v[k] < lo[k] {; lo[k] = v[k]; }
+                    if v[k] > hi[k] {; hi[k] = v[k]; }
+                }
+            }
+            if lo[2] == top && hi[2] == top && lo[0] < opening.x && opening.x < hi[0] && lo[1] < opening.y && opening.y < hi[1] {
+                t.Errorf("wall top over the %s: %v", opening.name, tri)
+            }
+        }
+    }
+}
@@ -0,0 +1,70 @@
+/* netpbm.go - PBM and PGM bitmaps of the maze
+ *
+ * Writes the maze in the plain netpbm formats that image tools read from a pipe, one pixel for each maze
+ * location in the rows of the ascii format.  The pbm bitmap has a 1 for each wall and a 0 for each path; the
+ * pgm graymap has the walls black, the paths white and the solved locations gray.  Tried cells are paths.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "strconv"
+)
+
+// Values of the pgm format's pixels
+const (
+    pgmMaxval = 255
+    pgmSolved = 128
+)
+
+const pnmLineLength = 70                // the longest line the plain formats allow
+
+// writeNetpbm writes the maze as a plain netpbm image with the given magic number and, unless it's 0, maximum pixel
+// value: a pixel for each location inside the perimeter path with the value pixel returns for it, the pixels separated
+// by sep and each row starting a new line
+func (m *Maze) writeNetpbm(w io.Writer, magic string, maxval int, sep string, pixel func(x, y int) int) {
+    rows, cols := getInt(&m.maxX) - 2, getInt(&m.maxY) - 2
+    fmt.Fprintf(w, "%s\n%d %d\n", magic, cols, rows)
+    if maxval > 0 {
+        fmt.Fprintf(w, "%d\n", maxval)
+    }
+    line := make([]byte, 0, pnmLineLength + 1)
+    for i := 1; i <= rows; i++ {
+        for j := 1; j <= cols; j++ {
+            v := strconv.Itoa(pixel(i, j))
+            if len(line) > 0 && len(line) + len(sep) + len(v) > pnmLineLength {
+                w.Write(append(line, '\n'))
+                line = line[:0]
+            }
+            if len(line) > 0 {
+                line = append(line, sep...)
+            }
+            line = append(line, v...)
+        }
+        w.Write(append(line, '\n'))
+        line = line[:0]
+    }
+}
+
+// RenderPBM writes the maze to w as a plain pbm bitmap, returning the first error from w
+func (m *Maze) RenderPBM(w io.Writer) error {
+    m = m.widened()
+    ew := &errWriter{w: w}
+    m.writeNetpbm(ew, "P1", 0, "", func(x, y int) int {; return bool2int(isShownWall(m.getMaze(x, y), false)); })
+    return ew.err
+}
+
+// RenderPGM writes the maze to w as a plain pgm graymap, returning the first error from w
+func (m *Maze) RenderPGM(w io.Writer) error {
+    m = m.widened()
+    ew := &errWriter{w: w}
+    m.writeNetpbm(ew, "P2", pgmMaxval, " ", func(x, y int) int {
+        switch v := m.getMaze(x, y); {
+            case isShownWall(v, false): return 0
+            case v == solved          : return pgmSolved
+        }
+        return pgmMaxval
+    })
+    return ew.err
+}
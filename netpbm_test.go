@@ -0,0 +1,98 @@
+/* netpbm_test.go - PBM and PGM tests
+ *
+ * Decodes the plain pbm and pgm images of generated mazes and checks that they're the maze array inside the
+ * perimeter path, pixel for location, and that no line is longer than the plain formats allow.
+ */
+package maze
+
+import (
+    "bytes"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// decodeNetpbm returns the pixels of a plain pbm or pgm image with the magic number, by row, its maximum value
+// being maxval
+func decodeNetpbm(t *testing.T, image []byte, magic string, maxval int) [][]int {
+    t.Helper()
+    for _, line := range strings.Split(string(image), "\n") {
+        if len(line) > pnmLineLength {
+            t.Fatalf("line of %d characters, longer than %d: %s", len(line), pnmLineLength, line)
+        }
+    }
+    fields := strings.Fields(string(image))
+    if len(fields) < 3 || fields[0] != magic {
+        t.Fatalf("not a %s image:\n%s", magic, image)
+    }
+    cols, err1 := strconv.Atoi(fields[1])
+    rows, err2 := strconv.Atoi(fields[2])
+    if err1 != nil || err2 != nil {
+        t.Fatalf("size %s %s", fields[1], fields[2])
+    }
+    fields = fields[3:]
+    if maxval > 0 {
+        if fields[0] != strconv.Itoa(maxval) {
+            t.Fatalf("maximum value %s, want %d", fields[0], maxval)
+        }
+        fields = fields[1:]
+    } else {                                    // a pbm's pixels needn't be separated
+        fields = strings.Split(strings.Join(fields, ""), "")
+    }
+    if len(fields) != rows*cols {
+        t.Fatalf("%d pixels for %d rows of %d", len(fields), rows, cols)
+    }
+    pixels := make([][]int, rows)
+    for i := range pixels {
+        pixels[i] = make([]int, cols)
+        for j := range pixels[i] {
+            v, err := strconv.Atoi(fields[i*cols + j])
+            if err != nil || v < 0 || v > max(maxval, 1) {
+                t.Fatalf("pixel %d, %d is %q", i, j, fields[i*cols + j])
+            }
+            pixels[i][j] = v
+        }
+    }
+    return pixels
+}
+
+// checkPixels checks that the pixels are the maze array inside the perimeter path, as want gives each location
+func checkPixels(t *testing.T, m *Maze, pixels [][]int, want func(state int) int) {
+    t.Helper()
+    if rows, cols := getInt(&m.maxX) - 2, getInt(&m.maxY) - 2; len(pixels) != rows || len(pixels[0]) != cols {
+        t.Fatalf("%d by %d pixels, want %d by %d", len(pixels), len(pixels[0]), rows, cols)
+    }
+    for i, row := range pixels {
+        for j, v := range row {
+            if state := m.getMaze(i + 1, j + 1); v != want(state) {
+                t.Fatalf("pixel %d, %d is %d for location state %d, want %d", i, j, v, state, want(state))
+            }
+        }
+    }
+}
+
+func TestPBMGrid(t *testing.T) {
+    for _, seed := range []int64{1, 2} {
+        m := goldenMaze(t, WithSize(19, 10), WithSeed(seed), WithDepth(5))
+        var b bytes.Buffer
+        if err := m.RenderPBM(&b); err != nil {
+            t.Fatal(err)
+        }
+        checkPixels(t, m, decodeNetpbm(t, b.Bytes(), "P1", 0), func(state int) int {; return bool2int(state == wall); })
+    }
+}
+
+func TestPGMGrid(t *testing.T) {
+    m := goldenMaze(t, WithSize(12, 8), WithSeed(42))
+    var b bytes.Buffer
+    if err := m.RenderPGM(&b); err != nil {
+        t.Fatal(err)
+    }
+    checkPixels(t, m, decodeNetpbm(t, b.Bytes(), "P2", pgmMaxval), func(state int) int {
+        switch state {
+            case wall  : return 0
+            case solved: return pgmSolved
+        }
+        return pgmMaxval
+    })
+}
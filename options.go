@@ -0,0 +1,333 @@
+/* options.go - Functional options
+ *
+ * NewMaze builds a Maze from a list of options, each of which checks its own value, and then checks the
+ * combination before returning it, so an invalid maze is an error from the constructor rather than a value
+ * quietly adjusted to something else.
+ */
+package maze
+
+import (
+    "fmt"
+    "math/rand"
+)
+
+// Limits on the parameters checked by NewMaze, besides the maze fitting the maze array
+const (
+    MaxDepth = 100                  // deepest look ahead while carving
+    MaxFPS   = 100000               // fastest animation, in cell updates per second
+)
+
+// DefaultWidth and DefaultHeight are the size of a Maze made by NewMaze without WithSize, the largest maze that
+// the maze command displays in an 80 column by 24 row terminal
+const (
+    DefaultWidth  = 19
+    DefaultHeight = 10
+)
+
+// Option sets a parameter of the Maze made by NewMaze, returning an error if its value is invalid
+type Option func(m *Maze) error
+
+// NewMaze returns a Maze with the default parameters changed by the options, applied in order, or an error if an
+// option's value or the combination of them is invalid
+func NewMaze(opts ...Option) (*Maze, error) {
+    m := newMaze()
+    m.cfg.width, m.cfg.height = DefaultWidth, DefaultHeight
+    for _, opt := range opts {
+        if err := opt(m); err != nil {
+            return nil, err
+        }
+    }
+    if err := m.validate(); err != nil {
+        return nil, err
+    }
+    return m, nil
+}
+
+// MaxMinLength returns the longest minimum solution length accepted for a maze of the given size, a third of its
+// cells, beyond which mazes meeting it are too rare to wait for
+func MaxMinLength(height, width int) int {
+    return height*width/3
+}
+
+// validate returns an error if the combination of parameters is invalid
+func (m *Maze) validate() error {
+    c := &m.cfg
+    switch cells := c.width*c.height; {
+        case !corridorFits(c.height, c.width, c.corridor):
+            return fmt.Errorf("maze size %dx%d is too large to widen with a corridor of %d cells", c.width, c.height, c.corridor)
+        case c.gap > c.width:
+            return fmt.Errorf("entrance and exit width %d is wider than the %d cell maze", c.gap, c.width)
+        case c.carveThreads > cells || c.solveThreads > cells:
+            return fmt.Errorf("%d carving and %d solving threads are more than the %d cells of the maze", c.carveThreads, c.solveThreads, cells)
+        case c.minLength > MaxMinLength(c.height, c.width):
+            return fmt.Errorf("minimum solution length %d is more than a third of the %d cells of the maze", c.minLength, cells)
+        case c.mask != nil && m.loaded == nil && c.mask.fits(c.height, c.width, c.gap) != nil:
+            return c.mask.fits(c.height, c.width, c.gap)
+    }
+    _, err := m.generator()
+    return err
+}
+
+// WithSize sets the width and height of the maze in cells
+func WithSize(w, h int) Option {
+    return func(m *Maze) error {
+        if w < 1 || w > MaxWidth || h < 1 || h > MaxHeight {
+            return fmt.Errorf("maze size %dx%d is outside 1x1 to %dx%d", w, h, MaxWidth, MaxHeight)
+        }
+        m.cfg.width, m.cfg.height = w, h
+        return nil
+    }
+}
+
+// WithSeed sets the random number seed, 0 for the current time
+func WithSeed(s int64) Option {
+    return func(m *Maze) error {
+        m.cfg.seed = s
+        return nil
+    }
+}
+
+// WithDepth sets the look ahead depth while carving
+func WithDepth(d int) Option {
+    return func(m *Maze) error {
+        if d < 0 || d > MaxDepth {
+            return fmt.Errorf("look ahead depth %d is outside 0 to %d", d, MaxDepth)
+        }
+        m.cfg.depth = d
+        return nil
+    }
+}
+
+// WithThreads sets the number of additional carving and solving goroutines
+func WithThreads(n int) Option {
+    return func(m *Maze) error {
+        if n < 0 {
+            return fmt.Errorf("thread count %d is negative", n)
+        }
+        m.cfg.carveThreads, m.cfg.solveThreads = n, n
+        return nil
+    }
+}
+
+// WithCarveThreads sets the number of additional carving goroutines
+func WithCarveThreads(n int) Option {
+    return func(m *Maze) error {
+        if n < 0 {
+            return fmt.Errorf("carving thread count %d is negative", n)
+        }
+        m.cfg.carveThreads = n
+        return nil
+    }
+}
+
+// WithSolveThreads sets the number of additional solving goroutines
+func WithSolveThreads(n int) Option {
+    return func(m *Maze) error {
+        if n < 0 {
+            return fmt.Errorf("solving thread count %d is negative", n)
+        }
+        m.cfg.solveThreads = n
+        return nil
+    }
+}
+
+// WithMinSolutionLength sets the shortest solution accepted, more mazes being generated until it's met
+func WithMinSolutionLength(n int) Option {
+    return func(m *Maze) error {
+        if n < 0 {
+            return fmt.Errorf("minimum solution length %d is negative", n)
+        }
+        m.cfg.minLength = n
+        return nil
+    }
+}
+
+// WithAttempts sets the number of mazes to generate trying to meet the minimum solution length, 0 for no limit
+func WithAttempts(n int) Option {
+    return func(m *Maze) error {
+        if n < 0 {
+            return fmt.Errorf("attempt limit %d is negative", n)
+        }
+        m.cfg.maxAttempts = n
+        return nil
+    }
+}
+
+// WithMaxSolveSteps sets the solver step budget, 0 for no limit
+func WithMaxSolveSteps(n int) Option {
+    return func(m *Maze) error {
+        if n < 0 {
+            return fmt.Errorf("solver step budget %d is negative", n)
+        }
+        m.cfg.maxSolveSteps = n
+        return nil
+    }
+}
+
+// WithGap sets the width of the entrance and exit in cells
+func WithGap(n int) Option {
+    return func(m *Maze) error {
+        if n < 1 {
+            return fmt.Errorf("entrance and exit width %d is less than 1 cell", n)
+        }
+        m.cfg.gap = n
+        return nil
+    }
+}
+
+// WithCorridor sets the width of the corridors in cells when the maze is written
+func WithCorridor(n int) Option {
+    return func(m *Maze) error {
+        if n < 1 {
+            return fmt.Errorf("corridor width %d is less than 1 cell", n)
+        }
+        m.cfg.corridor = n
+        return nil
+    }
+}
+
+// WithFPS paces the generation for an animation at n cell updates per second, 0 for full speed
+func WithFPS(n int) Option {
+    return func(m *Maze) error {
+        if n < 0 || n > MaxFPS {
+            return fmt.Errorf("update rate %d is outside 0 to %d per second", n, MaxFPS)
+        }
+        m.cfg.fps = n
+        return nil
+    }
+}
+
+// WithMaxAsymmetry sets the largest allowed ratio of the cells visited by the left and right hand wall followers,
+// which also turns on measuring them, or 0 for any
+func WithMaxAsymmetry(ratio float64) Option {
+    return func(m *Maze) error {
+        if ratio != 0 && ratio < 1 {
+            return fmt.Errorf("asymmetry ratio %g is less than 1", ratio)
+        }
+        m.cfg.maxAsymmetry = ratio
+        m.cfg.handedness   = m.cfg.handedness || ratio != 0
+        return nil
+    }
+}
+
+// WithAlgorithm selects the registered generator that carves the maze, by name
+func WithAlgorithm(name string) Option {
+    return func(m *Maze) error {
+        m.cfg.algorithm = name
+        _, err := m.generator()
+        return err
+    }
+}
+
+// WithRandSource draws the random numbers from src instead of the built-in source.  The source is never seeded by
+// the maze, so it's the caller's seeding alone that decides the mazes generated from it, one after another.
+func WithRandSource(src rand.Source) Option {
+    return func(m *Maze) error {
+        if src == nil {
+            return fmt.Errorf("no random number source")
+        }
+        m.cfg.randSource = src
+        return nil
+    }
+}
+
+// WithKey sets the parameters from a key, as GenerateFromKey does, so that the maze generated is the one the key was
+// taken from
+func WithKey(key string) Option {
+    return func(m *Maze) error {
+        k, err := ParseMazeKey(key)
+        if err != nil {
+            return err
+        }
+        return m.useKey(k)
+    }
+}
+
+// WithLoaded makes a maze read with ParseASCII the maze that Generate solves, taking its size
+func WithLoaded(l *Loaded) Option {
+    return func(m *Maze) error {
+        m.Load(l)
+        return nil
+    }
+}
+
+// WithMask generates the maze inside the cells of a mask read with ParseMask, taking its size
+func WithMask(k *Mask) Option {
+    return func(m *Maze) error {
+        m.cfg.mask = k
+        m.cfg.height, m.cfg.width = k.Size()
+        return k.Check()
+    }
+}
+
+// WithCheckpoint carries on from a checkpoint, taking its size, depth and seed
+func WithCheckpoint(cp *Checkpoint) Option {
+    return func(m *Maze) error {
+        return m.Resume(cp)
+    }
+}
+
+// DisplayOptions are what the display shows while the maze is generated and solved
+type DisplayOptions struct {
+    Show bool                       // pause at the end of each attempt and each solution
+    View bool                       // show the search for the best openings
+    Look bool                       // show the look ahead checks
+}
+
+// TextOptions are how the maze is drawn in the ascii and braille formats and on the display
+type TextOptions struct {
+    MarkOpenings    bool            // mark the entrance and exit with S and E in the ascii format
+    BlockWalls      bool            // write every wall location as a # in the ascii format
+    BoxDrawing      bool            // draw the walls with unicode box drawing characters in the ascii format
+    LineStyle       int             // LightLines, HeavyLines or DoubleLines, the wall lines of the display and box drawing
+    BrailleSolution bool            // add the solution as a second block in the braille format
+    BranchMarkers   bool            // mark the 9 worst junctions with digits in the ascii format
+    CharMap         CharMap         // characters replacing the usual ones in the ascii format
+}
+
+// Check returns an error if the line style is unknown or the character map is invalid
+func (o TextOptions) Check() error {
+    if o.LineStyle < 0 || o.LineStyle >= len(lineLookups) {
+        return fmt.Errorf("line style %d is not LightLines, HeavyLines or DoubleLines", o.LineStyle)
+    }
+    return o.CharMap.Check()
+}
+
+// Hooks are called by the maze as it's generated, a nil hook not being called
+type Hooks struct {
+    OnUpdate func()                 // called whenever the maze changes, to wake a display without blocking
+    OnPath   func()                 // called between paths while carving single threaded
+    OnPhase  func(Phase)            // called as the generation moves on to a phase, with the maze as the last left it
+}
+
+// WithHandedness measures the cells visited by the left and right hand wall followers, for ReportHandedness
+func WithHandedness(on bool) Option {
+    return func(m *Maze) error {
+        m.cfg.handedness = on || m.cfg.maxAsymmetry != 0
+        return nil
+    }
+}
+
+// WithDisplay sets what the display shows while the maze is generated and solved
+func WithDisplay(opts DisplayOptions) Option {
+    return func(m *Maze) error {
+        m.cfg.display = opts
+        return nil
+    }
+}
+
+// WithText sets how the maze is drawn in the ascii and braille formats and on the display
+func WithText(opts TextOptions) Option {
+    return func(m *Maze) error {
+        m.cfg.text = opts
+        return opts.Check()
+    }
+}
+
+// WithHooks sets the functions called as the maze is generated
+func WithHooks(hooks Hooks) Option {
+    return func(m *Maze) error {
+        m.cfg.hooks = hooks
+        return nil
+    }
+}
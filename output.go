@@ -0,0 +1,164 @@
+/* output.go - Maze output formats
+ *
+ * Writes the finished maze in the portable ascii format, optionally drawn with unicode box drawing characters,
+ * as an edge list or as unicode braille.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "strings"
+)
+
+// brailleDots maps a row, column within a braille character's 4 by 2 cell to its dot bit
+var brailleDots = [4][2]int { {0x01, 0x08},
+                              {0x02, 0x10},
+                              {0x04, 0x20},
+                              {0x40, 0x80} }
+
+// outputState holds the output format options of a Maze
+type outputState struct {
+    brailleSolution bool
+    markOpenings    bool            // mark the entrance and exit with S and E outside the border in ascii output
+    blockWalls      bool            // draw every wall location as a solid block rather than as thin lines
+    boxDrawing      bool            // draw the walls with unicode box drawing characters in ascii output
+    lineStyle       int             // the box drawing lines of the walls, which are drawn with them unless light
+    charMap         CharMap         // characters replacing the usual ones in ascii output
+}
+
+// Wall and solution characters of ascii files
+const (
+    blockChar    = '#'                  // the walls of the block wall style
+    boxBlockChar = '\u2588'             // the same with box drawing characters
+    boxSolved    = '\u00b7'             // the solution with box drawing characters
+)
+
+// writeAsciiMaze writes the maze in the portable ascii format: the comment block of its parameters, then a "height
+// width" header followed by the grid
+func (m *Maze) writeAsciiMaze(w io.Writer) {
+    m.writeParams(w)
+    fmt.Fprintf(w, "%d %d\n", m.height, m.width)
+    m.writeAsciiGrid(w)
+}
+
+// writeAsciiGrid writes the grid of the ascii format, a line for each row of locations inside the perimeter path with
+// the S and E markers above and below it if the openings are marked, and blank outside a mask.
+// In the block wall style every wall location is written as a # and any look ahead checks as paths.  With box
+// drawing characters, which the heavy and double line styles always use, the walls are drawn as the display draws
+// them, the solution as a middle dot and the blocks as full blocks.
+func (m *Maze) writeAsciiGrid(w io.Writer) {
+    lookup, horizontal, vertical, solvedChar, block := simpleLookup, '-', '|', '*', rune(blockChar)
+    if m.boxDrawing || m.style() != LightLines {
+        lookup = m.lineLookup()
+        horizontal, vertical, solvedChar, block = lookup[2], lookup[1], boxSolved, boxBlockChar
+    }
+    cell := m.shownCell
+    if m.markOpenings {
+        m.writeMarker(w, 'S', getInt(&m.begY))
+    }
+    for i := 1; i < getInt(&m.maxX) - 1; i++ {
+        for j := 1; j < getInt(&m.maxY) - 1; j++ {
+            put := func(r rune) {                                   // the character mapped for the location
+                if g := m.charMap.glyph(cell(i, j)); g != 0 {
+                    r = g
+                }
+                fmt.Fprintf(w, "%c", r)
+            }
+            if m.outside(i, j)                      {; fmt.Fprintf(w, " "); continue; }
+            if m.blockWalls && cell(i, j) == wall  {; put(block); continue; }
+            if m.blockWalls && cell(i, j) == check {; put(' '  ); continue; }
+            switch cell(i, j) {
+                case wall  : if isOdd(i) && isOdd(j) {; put(lookup[1 * bool2int(cell(i-1, j) == wall && (cell(i-1, j-1) != wall || cell(i-1, j+1) != wall)) +    // wall intersection point
+                                                                    2 * bool2int(cell(i, j+1) == wall && (cell(i-1, j+1) != wall || cell(i+1, j+1) != wall)) +    // check that there is a path on the diagonal
+                                                                    4 * bool2int(cell(i+1, j) == wall && (cell(i+1, j-1) != wall || cell(i+1, j+1) != wall)) +
+                                                                    8 * bool2int(cell(i, j-1) == wall && (cell(i-1, j-1) != wall || cell(i+1, j-1) != wall))])
+                             } else if      isOdd(i) {; put(horizontal)
+                             } else {                 ; put(vertical  ); }
+                case path  : if  c := m.branchMarker(i, j); c != 0 {; fmt.Fprintf(w, "%c", c)
+                             } else {                 ; put(' '); }
+                case tried :                            put('.')
+                case solved: if  c := m.branchMarker(i, j); c != 0 {; fmt.Fprintf(w, "%c", c)
+                             } else {                 ; put(solvedChar); }
+                case check :                            put('#')
+                default    :                            fmt.Fprintf(w, "?")
+            }
+        }
+        fmt.Fprintf(w, "\n")
+    }
+    if m.markOpenings {
+        m.writeMarker(w, 'E', getInt(&m.endY))
+    }
+}
+
+// writeMarker writes a row of the ascii format holding just the marker character above or below column y
+func (m *Maze) writeMarker(w io.Writer, marker byte, y int) {
+    row := []byte(strings.Repeat(" ", getInt(&m.maxY) - 2))
+    if y > 0 {                                                      // no openings yet while the maze is being carved
+        row[y - 1] = marker
+    }
+    fmt.Fprintf(w, "%s\n", row)
+}
+
+// writeEdges writes the maze as an edge list: a "height width" header, one "r1,c1 r2,c2" line in logical cell
+// coordinates for every carved passage, then the entrance and exit cells
+func (m *Maze) writeEdges(w io.Writer) {
+    fmt.Fprintf(w, "%d %d\n", m.height, m.width)
+    m.passages(func(a, b cell) bool {
+        fmt.Fprintf(w, "%d,%d %d,%d\n", a.row, a.col, b.row, b.col)
+        return true
+    })
+    entrance, exit := cellAt(getInt(&m.begX), getInt(&m.begY)), cellAt(getInt(&m.endX), getInt(&m.endY))
+    fmt.Fprintf(w, "entrance %d,%d\n", entrance.row, entrance.col)
+    fmt.Fprintf(w, "exit %d,%d\n"    , exit.row    , exit.col    )
+}
+
+// isDrawnWall returns true if location x, y is drawn as wall by the braille and png formats: a wall between two cells,
+// or a corner post with a wall leading from it
+func (m *Maze) isDrawnWall(x, y int) bool {
+    return isDrawnWall(m.getMaze, x, y)
+}
+
+// isDrawnWall returns true if location x, y of the maze array cell reads is drawn as wall, as for Maze.isDrawnWall
+func isDrawnWall(cell func(int, int) int, x, y int) bool {
+    if cell(x, y) != wall {
+        return false
+    }
+    if isEven(x) || isEven(y) {
+        return true
+    }
+    return cell(x-1, y) == wall || cell(x+1, y) == wall || cell(x, y-1) == wall || cell(x, y+1) == wall
+}
+
+// writeBraille writes the maze as unicode braille: a "height width" header followed by rows of characters that each
+// pack a 2 by 4 block of maze locations, with a raised dot for every wall.  Dots past the edge of the maze are left
+// lowered.  With --braille-solution a second block, after a blank line, raises the dots of the solution instead.
+func (m *Maze) writeBraille(w io.Writer) {
+    rows, cols := getInt(&m.maxX) - 2, getInt(&m.maxY) - 2        // the locations inside the perimeter path
+    onSolution := map[point]bool{}
+    for _, p := range m.getSolution() {
+        onSolution[p] = true
+    }
+    block := func(raised func(x, y int) bool) {
+        for r := 0; r < rows; r += 4 {
+            for c := 0; c < cols; c += 2 {
+                bits := 0
+                for dr := 0; dr < 4 && r + dr < rows; dr++ {
+                    for dc := 0; dc < 2 && c + dc < cols; dc++ {
+                        if raised(r + dr + 1, c + dc + 1) {
+                            bits |= brailleDots[dr][dc]
+                        }
+                    }
+                }
+                fmt.Fprintf(w, "%c", rune(0x2800 + bits))
+            }
+            fmt.Fprintf(w, "\n")
+        }
+    }
+    fmt.Fprintf(w, "%d %d\n", m.height, m.width)
+    block(m.isDrawnWall)
+    if m.brailleSolution {
+        fmt.Fprintf(w, "\n")
+        block(func(x, y int) bool {; return onSolution[point{x, y}]; })
+    }
+}
@@ -0,0 +1,73 @@
+/* path.go - Solution paths for other programs
+ *
+ * Gives the solution marked in the maze as the cells along it with the heading taken from each, for programs
+ * that follow the route rather than draw it.  It's checked first that the solved locations are a single simple
+ * path from the entrance to the exit, nothing branching off it and no solved location left off it, since the
+ * threads solving a maze together can leave a solution that isn't.
+ */
+package maze
+
+import (
+    "fmt"
+    "errors"
+)
+
+// ErrSolutionNotPath is returned by SolutionPath when the solved cells don't form one simple path
+var ErrSolutionNotPath = errors.New("the solved cells don't form a single simple path from the entrance to the exit")
+
+// PathStep is a cell of the solution path and the direction of the step from it to the next one
+type PathStep struct {
+    Point
+    Heading Direction
+}
+
+// SolutionPath returns the solution marked in the maze as the cells along it, from the entrance at row -1 to the exit
+// at the row the height of the maze, the exit heading down out of the maze.  There are SolutionLength steps inside
+// the maze and each step is to a cell beside the one before.  It returns ErrUnsolvable if the maze isn't marked
+// solved and ErrSolutionNotPath if the solved cells branch or there are solved cells off the route.
+func (m *Maze) SolutionPath() ([]PathStep, error) {
+    if getInt(&m.maxX) == 0 {
+        return nil, ErrNoMaze
+    }
+    route   := m.solutionRoute()              // which stops at a dead end if it's taken a branch
+    onRoute := make(map[point]bool, len(route))
+    for _, p := range route {
+        n := 0
+        for _, dir := range stdDirection {
+            if x, y := p.x + dir.x/2, p.y + dir.y/2; m.isInterior(x, y) && m.getMaze(x, y) == solved {
+                n++
+            }
+        }
+        if n > 2 {
+            c := cellAt(p.x &^ 1, p.y &^ 1)
+            return nil, fmt.Errorf("%w: it branches at row %d, column %d", ErrSolutionNotPath, c.row, c.col)
+        }
+        onRoute[p] = true
+    }
+    if !m.routeComplete(route) {
+        return nil, ErrUnsolvable
+    }
+    for i := 1; i < getInt(&m.maxX) - 1; i++ {                 // the stubs the solver marks on the perimeter path
+        for j := 1; j < getInt(&m.maxY) - 1; j++ {             // outside the openings aren't part of the route
+            if m.getMaze(i, j) == solved && !onRoute[point{i, j}] {
+                c := cellAt(i &^ 1, j &^ 1)
+                return nil, fmt.Errorf("%w: row %d, column %d is solved but off the route", ErrSolutionNotPath, c.row, c.col)
+            }
+        }
+    }
+
+    points := m.routePoints(route)
+    steps  := make([]PathStep, len(points))
+    for k, p := range points {
+        steps[k] = PathStep{p, Down}
+        if k + 1 < len(points) {
+            next := points[k + 1]
+            for dir, d := range directionSteps {
+                if next.X - p.X == d.X && next.Y - p.Y == d.Y {
+                    steps[k].Heading = Direction(dir)
+                }
+            }
+        }
+    }
+    return steps, nil
+}
@@ -0,0 +1,60 @@
+/* path_test.go - Solution path tests
+ *
+ * Checks that the solution path of generated mazes has a step for each cell of the solution, that each step is
+ * to a cell beside the one before in the heading given, and that a solution that branches or isn't there fails.
+ */
+package maze
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestSolutionPath(t *testing.T) {
+    for _, seed := range []int64{1, 2, 3, 4} {
+        m := goldenMaze(t, WithSize(19, 10), WithSeed(seed), WithDepth(3))
+        steps, err := m.SolutionPath()
+        if err != nil {
+            t.Fatalf("seed %d: %v", seed, err)
+        }
+        if inside := len(steps) - 2; inside != getInt(&m.solveLength) || inside != m.SolutionLength() {
+            t.Errorf("seed %d: %d steps inside the maze, solve length %d", seed, inside, getInt(&m.solveLength))
+        }
+        if first, last := steps[0], steps[len(steps) - 1]; first.X != -1 || last.X != m.cfg.height || last.Heading != Down {
+            t.Errorf("seed %d: path from %v to %v, want from row -1 to row %d heading down", seed, first, last, m.cfg.height)
+        }
+        for k := 0; k + 1 < len(steps); k++ {
+            p, next := steps[k], steps[k + 1]
+            if d := directionSteps[p.Heading]; next.X != p.X + d.X || next.Y != p.Y + d.Y {
+                t.Errorf("seed %d: step %d from %v heading %v reaches %v, not beside it that way", seed, k, p.Point, p.Heading, next.Point)
+            }
+        }
+    }
+}
+
+func TestSolutionPathErrors(t *testing.T) {
+    if _, err := newMaze().SolutionPath(); err != ErrNoMaze {
+        t.Errorf("no maze: error %v, want %v", err, ErrNoMaze)
+    }
+    m := goldenMaze(t, WithSize(19, 10), WithSeed(1))
+    saved := m.Snapshot()
+    m.Unsolve()
+    if _, err := m.SolutionPath(); !errors.Is(err, ErrUnsolvable) {
+        t.Errorf("unsolved: error %v, want %v", err, ErrUnsolvable)
+    }
+    m.Restore(saved)
+    branched := false                           // a passage off the solution marked solved as a branch
+    m.passages(func(a, b cell) bool {
+        ax, ay := a.loc()
+        bx, by := b.loc()
+        if m.getMaze(ax, ay) == solved && m.getMaze(bx, by) != solved {
+            m.setMaze((ax + bx)/2, (ay + by)/2, solved)
+            m.setMaze(bx, by, solved)
+            branched = true
+        }
+        return !branched
+    })
+    if _, err := m.SolutionPath(); !branched || !errors.Is(err, ErrSolutionNotPath) {
+        t.Errorf("branched solution: error %v, want %v", err, ErrSolutionNotPath)
+    }
+}
@@ -0,0 +1,142 @@
+/* pdf.go - PDF pages of the maze
+ *
+ * Lays the maze out for printing on an A4 or Letter page, scaled to fill the page inside its margins and turned
+ * to landscape when that lets it be drawn larger, as it does for a wide maze.  An optional second page has the
+ * solved maze.  The file is written directly, being just a few objects of stroked lines, one for each run of
+ * wall and one for the solution.  The walls may be drawn thicker, for a large maze on a small page, but no more
+ * than three quarters of a cell so that the passages stay open, the solution staying the same width.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "math"
+    "bytes"
+    "strconv"
+)
+
+// Limits on the pdf format's options, in points
+const (
+    DefaultPDFMargin = 36
+    MaxPDFMargin     = 144
+    pdfMaxCell       = 72               // the largest a cell is drawn, so a small maze isn't spread over the page
+)
+
+// pdfPageSizes are the portrait page sizes by name, in points
+var pdfPageSizes = map[string][2]int{
+    "a4"    : {595, 842},
+    "letter": {612, 792},
+}
+
+// PDFOptions chooses how RenderPDF lays out the maze
+type PDFOptions struct {
+    PageSize string                 // a4 or letter, "" for a4
+    Margin   int                    // points of white around the maze at the edges of the page
+    WallPx   int                    // points thick the walls are drawn, 0 for a fifth of a cell, at most 3/4 of one
+    Solution bool                   // add a second page with the solution drawn in
+}
+
+// Check returns an error if the options are out of range
+func (o PDFOptions) Check() error {
+    if _, ok := pdfPageSizes[o.PageSize]; !ok && o.PageSize != "" {
+        return fmt.Errorf("expected a pdf page size of a4 or letter")
+    }
+    if o.Margin < 0 || o.Margin > MaxPDFMargin {
+        return fmt.Errorf("pdf margin %d is outside 0 to %d points", o.Margin, MaxPDFMargin)
+    }
+    if o.WallPx < 0 || o.WallPx > pdfMaxCell {
+        return fmt.Errorf("pdf wall thickness %d is outside 0 to %d points", o.WallPx, pdfMaxCell)
+    }
+    return nil
+}
+
+// pdfLayout returns the page size, landscape if that gives the maze a larger scale, and the points per maze array
+// location and the page coordinates of the top left corner of a maze rows by cols array locations centered on it
+func pdfLayout(opts PDFOptions, rows, cols int) (width, height int, scale, left, top float64) {
+    size := pdfPageSizes[opts.PageSize]
+    if opts.PageSize == "" {
+        size = pdfPageSizes["a4"]
+    }
+    fit := func(width, height int) float64 {
+        return math.Min(math.Min(float64(width - 2*opts.Margin)/float64(cols), float64(height - 2*opts.Margin)/float64(rows)), pdfMaxCell/2)
+    }
+    width, height = size[0], size[1]
+    if fit(height, width) > fit(width, height) {
+        width, height = height, width
+    }
+    scale = fit(width, height)
+    return width, height, scale, (float64(width) - scale*float64(cols))/2, (float64(height) + scale*float64(rows))/2
+}
+
+// RenderPDF writes the maze to w as a pdf document, returning an error if the options are out of range or the first
+// error from w.  The first page draws the part of the solution marked in the maze, the second, if there is one, the
+// whole of the solution Solve returns.
+func (m *Maze) RenderPDF(w io.Writer, opts PDFOptions) error {
+    if err := opts.Check(); err != nil {
+        return err
+    }
+    m = m.widened()
+
+    rows, cols := 2*m.height, 2*m.width
+    width, height, scale, left, top := pdfLayout(opts, rows, cols)
+    wall := 0.4                                                     // in maze array locations, two to a cell
+    if opts.WallPx > 0 {
+        wall = math.Min(float64(opts.WallPx)/scale, 1.5)            // leaving a quarter of the passages open
+    }
+    routes := [][]point{m.solutionRoute()}
+    if opts.Solution {
+        route := m.getSolution()
+        if len(route) == 0 {
+            route = routes[0]
+        }
+        routes = append(routes, route)
+    }
+
+    ew     := &errWriter{w: w}
+    offset := 0
+    var objects []int                                               // the offset of each object, numbered from 1
+    object := func(format string, args ...any) {
+        objects = append(objects, offset)
+        n, _ := fmt.Fprintf(ew, "%d 0 obj\n" + format + "\nendobj\n", append([]any{len(objects)}, args...)...)
+        offset += n
+    }
+    n, _ := fmt.Fprintf(ew, "%%PDF-1.4\n")
+    offset += n
+
+    kids := ""
+    for k := range routes {
+        kids += fmt.Sprintf(" %d 0 R", 3 + 2*k)
+    }
+    object("<< /Type /Catalog /Pages 2 0 R >>")
+    object("<< /Type /Pages /Kids [%s ] /Count %d >>", kids, len(routes))
+    for _, route := range routes {
+        var page bytes.Buffer                                       // in maze array locations, down from the top left
+        fmt.Fprintf(&page, "%.3f 0 0 %.3f %.3f %.3f cm\n", scale, -scale, left, top)
+        fmt.Fprintf(&page, "%s w 2 J\n", strconv.FormatFloat(wall, 'f', -1, 64))
+        m.wallRuns(func(from, to point) {
+            fmt.Fprintf(&page, "%d %d m %d %d l\n", from.y - 1, from.x - 1, to.y - 1, to.x - 1)
+        })
+        fmt.Fprintf(&page, "S\n")
+        if line := m.solutionLine(route); line != nil {
+            fmt.Fprintf(&page, "0.816 0.125 0.125 RG 0.4 w 1 J 1 j\n")
+            for i, p := range line {
+                op := "l"
+                if i == 0 {
+                    op = "m"
+                }
+                fmt.Fprintf(&page, "%d %d %s\n", p.y - 1, p.x - 1, op)
+            }
+            fmt.Fprintf(&page, "S\n")
+        }
+        object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Contents %d 0 R >>", width, height, len(objects) + 2)
+        object("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.Bytes())
+    }
+
+    fmt.Fprintf(ew, "xref\n0 %d\n0000000000 65535 f \n", len(objects) + 1)
+    for _, o := range objects {
+        fmt.Fprintf(ew, "%010d 00000 n \n", o)
+    }
+    fmt.Fprintf(ew, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects) + 1, offset)
+    return ew.err
+}
@@ -0,0 +1,69 @@
+/* pdf_test.go - PDF export tests
+ *
+ * Writes a tiny maze from a fixed seed as a pdf and checks its header, cross reference table and the number of
+ * line operators, a run of wall for each counted by hand from its ascii output plus the segments of the solution,
+ * and checks the solution page and the turn to landscape of a wide maze.
+ */
+package maze
+
+import (
+    "bytes"
+    "regexp"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// renderPDF returns the maze written as a pdf with the options
+func renderPDF(t *testing.T, m *Maze, opts PDFOptions) []byte {
+    t.Helper()
+    var b bytes.Buffer
+    if err := m.RenderPDF(&b, opts); err != nil {
+        t.Fatal(err)
+    }
+    return b.Bytes()
+}
+
+var (
+    pdfWallRun = regexp.MustCompile(`(?m)^\d+ \d+ m \d+ \d+ l$`)
+    pdfLineTo  = regexp.MustCompile(`(?m)^-?\d+ -?\d+ l$`)
+    pdfXref    = regexp.MustCompile(`(?m)^(\d{10}) 00000 n $`)
+)
+
+// TestPDFLineOperators draws the 4 by 3 maze of seed 1, whose ascii output has six horizontal runs of wall and six
+// vertical ones, and a solution line of 13 points
+func TestPDFLineOperators(t *testing.T) {
+    pdf := renderPDF(t, goldenMaze(t, WithSize(4, 3), WithSeed(1)), PDFOptions{})
+    if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+        t.Fatalf("not a pdf file:\n%s", pdf)
+    }
+    if got := len(pdfWallRun.FindAll(pdf, -1)); got != 12 {
+        t.Errorf("%d runs of wall drawn, want 12", got)
+    }
+    if got := len(pdfLineTo.FindAll(pdf, -1)); got != 12 {
+        t.Errorf("%d segments of solution drawn, want 12", got)
+    }
+    if got := bytes.Count(pdf, []byte(" l\n")); got != 24 {
+        t.Errorf("%d line operators, want 24", got)
+    }
+    for k, offset := range pdfXref.FindAllSubmatch(pdf, -1) {
+        n, _ := strconv.Atoi(string(offset[1]))
+        if want := strconv.Itoa(k + 1) + " 0 obj\n"; !bytes.HasPrefix(pdf[n:], []byte(want)) {
+            t.Errorf("cross reference %d is at %q, want %q", k + 1, pdf[n:min(n + 10, len(pdf))], want)
+        }
+    }
+}
+
+func TestPDFPages(t *testing.T) {
+    m := goldenMaze(t, WithSize(4, 3), WithSeed(1))
+    if pdf := string(renderPDF(t, m, PDFOptions{Solution: true})); !strings.Contains(pdf, "/Count 2 >>") {
+        t.Errorf("no solution page:\n%s", pdf)
+    }
+    wide := goldenMaze(t, WithSize(40, 4), WithSeed(1))
+    if pdf := string(renderPDF(t, wide, PDFOptions{PageSize: "letter"})); !strings.Contains(pdf, "/MediaBox [0 0 792 612]") {
+        t.Errorf("wide maze not on a landscape letter page:\n%s", pdf)
+    }
+    if pdf := string(renderPDF(t, m, PDFOptions{})); !strings.Contains(pdf, "/MediaBox [0 0 595 842]") {
+        t.Errorf("small maze not on a portrait a4 page:\n%s", pdf)
+    }
+}
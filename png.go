@@ -0,0 +1,74 @@
+/* png.go - PNG images of the maze
+ *
+ * Encodes the maze drawn as Image draws it, with the walls as black lines on white, each cell CellSize pixels
+ * from the next with walls a quarter of that thick or WallPx.  The tried cells aren't drawn, but the solved locations, if
+ * a solution color is given, are drawn as a line down the middle of their passages and out through the openings.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "image/color"
+    "image/png"
+)
+
+// Limits on the png format's options, in pixels
+const (
+    DefaultPNGCellSize = 8
+    MinPNGCellSize     = 2
+    MaxPNGCellSize     = 64
+    MaxPNGMargin       = 1024
+)
+
+// PNGSolutionColor is the color the solution is usually drawn in
+var PNGSolutionColor = color.RGBA{0xd0, 0x20, 0x20, 0xff}
+
+// PNGOptions chooses how RenderPNG draws the maze
+type PNGOptions struct {
+    CellSize int                    // pixels from one cell to the next, including one wall, 0 for DefaultPNGCellSize
+    Margin   int                    // pixels of white around the maze
+    WallPx   int                    // pixels thick the walls are drawn, 0 for a quarter of the cell size
+    Solution color.Color            // the color of the solved locations, nil to draw them as paths
+}
+
+// Check returns an error if the options are out of range
+func (o PNGOptions) Check() error {
+    cellSize := o.CellSize
+    if cellSize == 0 {
+        cellSize = DefaultPNGCellSize
+    }
+    switch {
+        case o.CellSize != 0 && (o.CellSize < MinPNGCellSize || o.CellSize > MaxPNGCellSize):
+            return fmt.Errorf("png cell size %d is outside %d to %d pixels", o.CellSize, MinPNGCellSize, MaxPNGCellSize)
+        case o.Margin < 0 || o.Margin > MaxPNGMargin:
+            return fmt.Errorf("png margin %d is outside 0 to %d pixels", o.Margin, MaxPNGMargin)
+        case o.WallPx < 0 || o.WallPx >= cellSize:
+            return fmt.Errorf("png wall thickness %d is outside 0 to %d pixels, less than the cell size", o.WallPx, cellSize - 1)
+    }
+    return nil
+}
+
+// frame returns the options Image draws the png format with
+func (o PNGOptions) frame() FrameOptions {
+    return FrameOptions{Margin: o.Margin, WallPx: o.WallPx, Solution: o.Solution}
+}
+
+// RenderPNG writes the maze to w as a png image, returning an error if the options are out of range or the first
+// error from w
+func (m *Maze) RenderPNG(w io.Writer, opts PNGOptions) error {
+    if err := opts.Check(); err != nil {
+        return err
+    }
+    return png.Encode(w, m.Image(opts.CellSize, opts.frame()))
+}
+
+// RenderPNGCells writes a maze array of rows by cols locations, perimeter path included, to w as a png image drawn as
+// RenderPNG draws a maze, cell returning the state of each location: a frame recorded during the generation, say.  It
+// returns an error if the options are out of range or the first error from w.
+func RenderPNGCells(w io.Writer, rows, cols int, cell func(x, y int) int, opts PNGOptions) error {
+    if err := opts.Check(); err != nil {
+        return err
+    }
+    return png.Encode(w, ImageCells(rows, cols, cell, opts.CellSize, opts.frame()))
+}
@@ -0,0 +1,93 @@
+/* png_test.go - PNG export tests
+ *
+ * Writes a solved maze from a fixed seed with RenderPNG and compares it pixel by pixel with its golden image,
+ * checks that cells the solver tried are drawn as paths, and that a 300 by 100 maze at 8 pixels a cell is a png
+ * of the size expected.
+ */
+package maze
+
+import (
+    "os"
+    "bytes"
+    "image"
+    "image/png"
+    "testing"
+    "path/filepath"
+)
+
+// pngOptions are the options the golden png is written with
+var pngOptions = PNGOptions{CellSize: 8, Margin: 4, Solution: PNGSolutionColor}
+
+// decodePNG returns the png written by RenderPNG with the options
+func decodePNG(t *testing.T, m *Maze, opts PNGOptions) image.Image {
+    t.Helper()
+    var b bytes.Buffer
+    if err := m.RenderPNG(&b, opts); err != nil {
+        t.Fatal(err)
+    }
+    img, err := png.Decode(&b)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return img
+}
+
+func TestGoldenPNG(t *testing.T) {
+    m := goldenMaze(t, WithSize(19, 10), WithSeed(1))
+    if *updateGolden {
+        var b bytes.Buffer
+        if err := m.RenderPNG(&b, pngOptions); err != nil {
+            t.Fatal(err)
+        }
+        checkGolden(t, "19x10-seed1-solved.png", b.Bytes())
+        return
+    }
+    path := filepath.Join("testdata", "golden", "19x10-seed1-solved.png")
+    f, err := os.Open(path)
+    if err != nil {
+        t.Fatalf("%v (run go test -update to create it)", err)
+    }
+    defer f.Close()
+    want, err := png.Decode(f)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if p, ok := samePixels(decodePNG(t, m, pngOptions), want); !ok {
+        t.Errorf("png differs from %s at %v", path, p)
+    }
+}
+
+func TestPNGHidesTried(t *testing.T) {
+    m := goldenMaze(t, WithSize(19, 10), WithSeed(1))
+    want := decodePNG(t, m, pngOptions)
+    marked := 0
+    m.cells(func(c cell, state int) bool {
+        if state == path {
+            x, y := c.loc()
+            m.setMaze(x, y, tried)
+            marked++
+        }
+        return true
+    })
+    if marked == 0 {
+        t.Fatal("no cells off the solution to mark tried")
+    }
+    if p, ok := samePixels(decodePNG(t, m, pngOptions), want); !ok {
+        t.Errorf("tried cells drawn, at %v", p)
+    }
+}
+
+func TestLargePNG(t *testing.T) {
+    var b bytes.Buffer
+    if err := RenderStream(&b, StreamOptions{Width: 300, Height: 100, Seed: 1}); err != nil {
+        t.Fatal(err)
+    }
+    var m Maze
+    if err := m.UnmarshalText(b.Bytes()); err != nil {
+        t.Fatal(err)
+    }
+    img := decodePNG(t, &m, PNGOptions{CellSize: 8})
+    if got, want := img.Bounds().Size(), image.Pt(300*8 + 2, 100*8 + 2); got != want {    // and the far wall, 2 pixels thick
+        t.Errorf("300x100 at 8 pixels a cell is %v, want %v", got, want)
+    }
+}
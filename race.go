@@ -0,0 +1,344 @@
+/* race.go - Solver race visualization
+ *
+ * Runs a set of named solvers concurrently, each on its own private copy of the maze, and
+ * records which solver entered each cell on a display-only overlay so that the shared display
+ * can draw every solver's frontier in its own color without touching the real maze array.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "strings"
+    "container/heap"
+    "sync"
+    "sync/atomic"
+)
+
+// mazeGrid is a private copy of the maze array
+type mazeGrid [maxXSize][maxYSize]int32
+
+// raceGrid is the copy of the maze owned by a single solver in a race, with the openings and size of the maze
+type raceGrid struct {
+    cell          mazeGrid
+    begX, begY    int
+    endX, endY    int
+    width, height int
+}
+
+// point is a location in the maze array
+type point struct {
+    x, y int
+}
+
+// Racer is a solver that can take part in a race.  solve explores its own copy of the maze starting at
+// begX, begY, calling visit each time it moves from one cell to an adjacent cell (fresh is true the first time
+// a cell is entered), and gives up as soon as visit returns false.  It returns true if it reached endX, endY.
+type Racer interface {
+    name()  string
+    solve(g *raceGrid, visit func(fromX, fromY, toX, toY int, fresh bool) bool) bool
+}
+
+// raceResult records the outcome of a single solver in a race
+type raceResult struct {
+    name      string
+    visited   int
+    moves     int
+    finished  bool
+    exhausted bool              // gave up when the step budget ran out
+}
+
+// raceState is the state of the race run on a Maze
+type raceState struct {
+    raceOwner  [maxXSize][maxYSize]int32   // index + 1 of the solver that last entered each cell (display only)
+    raceFlag   int32                       // set while the race overlay should be displayed
+    raceBest   int32                       // fewest moves taken by a solver to reach the exit so far
+    raceWinner int
+    raceResults []raceResult
+}
+
+var (
+    raceColors = [...]string { "\033[34m\033[1m", "\033[35m\033[1m", "\033[36m\033[1m", "\033[33m\033[1m" }
+
+    raceSolvers = map[string]func() Racer {
+        "bfs"       : func() Racer {; return bfsSolver{}                   },
+        "astar"     : func() Racer {; return astarSolver{}                 },
+        "wallfollow": func() Racer {; return wallFollower{leftHand: true}  },
+    }
+)
+
+// clockwise lists the directions in clockwise order so that wall followers can turn left and right
+var clockwise = [4]dirTable { {-2,  0, up   },
+                              { 0,  2, right},
+                              { 2,  0, down },
+                              { 0, -2, left } }
+
+func (m *Maze) setOwner(x, y, v int)     {; atomic.StoreInt32(&m.raceOwner[x][y], int32(v)); }
+func (m *Maze) getOwner(x, y    int) int {; return int(atomic.LoadInt32(&m.raceOwner[x][y])); }
+
+// racerColor returns the escape sequence selecting the color the solver numbered owner, from 1, is shown in
+func racerColor(owner int) string {
+    return raceColors[(owner - 1) % len(raceColors)]
+}
+
+// copyMaze returns a private, unsolved copy of the current maze array
+func (m *Maze) copyMaze() *raceGrid {
+    g := &raceGrid{begX: getInt(&m.begX), begY: getInt(&m.begY), endX: getInt(&m.endX), endY: getInt(&m.endY), width: m.width, height: m.height}
+    for i := 0; i < getInt(&m.maxX); i++ {
+        for j := 0; j < getInt(&m.maxY); j++ {
+            if v := m.getMaze(i, j); v != solved && v != tried {
+                g.cell[i][j] = int32(v)
+            }
+        }
+    }
+    return g
+}
+
+// canMove returns true if there is an opening from the cell at x, y in the given direction to another cell inside the maze
+func (g *raceGrid) canMove(x, y int, dir dirTable) bool {
+    nx, ny := x + dir.x, y + dir.y
+    return g.begX <= nx && nx <= g.endX    &&
+           2      <= ny && ny <= 2*g.width &&
+           g.cell[x + dir.x/2][y + dir.y/2] != wall
+}
+
+// enter marks the cell at x, y as tried in the solver's copy and returns true if it hadn't been entered before
+func (g *raceGrid) enter(x, y int) bool {
+    fresh       := g.cell[x][y] != tried
+    g.cell[x][y] = tried
+    return fresh
+}
+
+// isExit returns true if x, y is the last cell before the bottom opening
+func (g *raceGrid) isExit(x, y int) bool {
+    return x == g.endX && y == g.endY
+}
+
+// bfsSolver solves the maze with a breadth first search, expanding all cells at a given distance before the next
+type bfsSolver struct{}
+
+func (bfsSolver) name() string {; return "bfs"; }
+
+func (bfsSolver) solve(g *raceGrid, visit func(fromX, fromY, toX, toY int, fresh bool) bool) bool {
+    start := point{g.begX, g.begY}
+    queue := []point{start}
+    g.enter(start.x, start.y)
+    if !visit(start.x, start.y, start.x, start.y, true) {
+        return false
+    }
+    for len(queue) > 0 {
+        p := queue[0]
+        queue = queue[1:]
+        if g.isExit(p.x, p.y) {
+            return true
+        }
+        for _, dir := range stdDirection {
+            if g.canMove(p.x, p.y, dir) && g.cell[p.x + dir.x][p.y + dir.y] != tried {
+                g.enter(p.x + dir.x, p.y + dir.y)
+                if !visit(p.x, p.y, p.x + dir.x, p.y + dir.y, true) {
+                    return false
+                }
+                queue = append(queue, point{p.x + dir.x, p.y + dir.y})
+            }
+        }
+    }
+    return false
+}
+
+// astarSolver solves the maze with an A* search using the manhattan distance to the exit as the heuristic
+type astarSolver struct{}
+
+type astarNode struct {
+    x, y, cost, estimate int
+}
+
+// astarQueue is a min-heap of nodes ordered by estimated total cost, preferring nodes further along on ties
+type astarQueue []astarNode
+
+func (q astarQueue) Len() int            {; return len(q); }
+func (q astarQueue) Swap(i, j int)       {; q[i], q[j] = q[j], q[i]; }
+func (q astarQueue) Less(i, j int) bool  {; return q[i].estimate < q[j].estimate || (q[i].estimate == q[j].estimate && q[i].cost > q[j].cost); }
+func (q *astarQueue) Push(x interface{}) {; *q = append(*q, x.(astarNode)); }
+func (q *astarQueue) Pop() interface{}   {; old := *q; n := old[len(old) - 1]; *q = old[:len(old) - 1]; return n; }
+
+func (astarSolver) name() string {; return "astar"; }
+
+func (astarSolver) solve(g *raceGrid, visit func(fromX, fromY, toX, toY int, fresh bool) bool) bool {
+    distance := func(x, y int) int {
+        dx, dy := g.endX - x, g.endY - y
+        if dx < 0 {; dx = -dx; }
+        if dy < 0 {; dy = -dy; }
+        return (dx + dy) / 2
+    }
+    x, y  := g.begX, g.begY
+    queue := &astarQueue{{x, y, 0, distance(x, y)}}
+    g.enter(x, y)
+    if !visit(x, y, x, y, true) {
+        return false
+    }
+    for queue.Len() > 0 {
+        n := heap.Pop(queue).(astarNode)
+        if g.isExit(n.x, n.y) {
+            return true
+        }
+        for _, dir := range stdDirection {
+            nx, ny := n.x + dir.x, n.y + dir.y
+            if g.canMove(n.x, n.y, dir) && g.cell[nx][ny] != tried {
+                g.enter(nx, ny)
+                if !visit(n.x, n.y, nx, ny, true) {
+                    return false
+                }
+                heap.Push(queue, astarNode{nx, ny, n.cost + 1, n.cost + 1 + distance(nx, ny)})
+            }
+        }
+    }
+    return false
+}
+
+// wallFollower solves the maze by keeping one hand on the wall, turning toward that hand whenever possible
+type wallFollower struct {
+    leftHand bool
+}
+
+func (w wallFollower) name() string {
+    if w.leftHand {
+        return "wallfollow"
+    }
+    return "wallfollow-right"
+}
+
+func (w wallFollower) solve(g *raceGrid, visit func(fromX, fromY, toX, toY int, fresh bool) bool) bool {
+    turns := [4]int{1, 0, 3, 2}         // right, straight, left, back
+    if w.leftHand {
+        turns = [4]int{3, 0, 1, 2}      // left, straight, right, back
+    }
+    x, y    := g.begX, g.begY
+    heading := 2                        // entering from the top opening heading down
+    g.enter(x, y)
+    if !visit(x, y, x, y, true) {
+        return false
+    }
+    for steps := 0; steps < 4*g.width*g.height; steps++ {   // every passage is walked at most twice in a perfect maze
+        if g.isExit(x, y) {
+            return true
+        }
+        moved := false
+        for _, turn := range turns {
+            d := (heading + turn) % 4
+            if g.canMove(x, y, clockwise[d]) {
+                nx, ny := x + clockwise[d].x, y + clockwise[d].y
+                if !visit(x, y, nx, ny, g.enter(nx, ny)) {
+                    return false
+                }
+                x, y, heading, moved = nx, ny, d, true
+                break
+            }
+        }
+        if !moved {
+            return false
+        }
+    }
+    return g.isExit(x, y)
+}
+
+// ParseRace returns the solvers named in a comma separated list
+func ParseRace(names string) ([]Racer, error) {
+    var solvers []Racer
+    for _, name := range strings.Split(names, ",") {
+        newSolver, ok := raceSolvers[strings.TrimSpace(name)]
+        if !ok {
+            return nil, fmt.Errorf("unknown solver %q (valid solvers: astar, bfs, wallfollow)", name)
+        }
+        solvers = append(solvers, newSolver())
+    }
+    return solvers, nil
+}
+
+// clearRace removes all solvers from the race overlay
+func (m *Maze) clearRace() {
+    for i := 0; i < getInt(&m.maxX); i++ {
+        for j := 0; j < getInt(&m.maxY); j++ {
+            m.setOwner(i, j, 0)
+        }
+    }
+}
+
+// runRace runs the given solvers concurrently on independent copies of the maze, drawing each one's progress
+// on the race overlay.  Every move takes one tick of race time, so the winner is the solver that reaches the exit
+// in the fewest moves (ties go to the first named), and solvers give up once they fall behind the leader.
+func (m *Maze) runRace(solvers []Racer) {
+    m.clearRace()
+    clrInt( &m.raceBest)
+    setBool(&m.raceFlag, true)
+
+    m.raceResults = make([]raceResult, len(solvers))
+    start := make(chan struct{})
+    var wg sync.WaitGroup
+    for i, s := range solvers {
+        m.raceResults[i].name = s.name()
+        wg.Add(1)
+        go func(i int, s Racer, g *raceGrid) {
+            defer wg.Done()
+            r := &m.raceResults[i]
+            visit := func(fromX, fromY, toX, toY int, fresh bool) bool {
+                if best := getInt(&m.raceBest); best != 0 && r.moves >= best {
+                    return false
+                }
+                if m.maxSolveSteps > 0 && r.moves >= m.maxSolveSteps {
+                    r.exhausted = true
+                    return false
+                }
+                m.setOwner((fromX + toX)/2, (fromY + toY)/2, i + 1)
+                m.setOwner(toX, toY, i + 1)
+                r.moves++
+                if fresh {
+                    r.visited++
+                }
+                if getInt(&m.delay) > 0 && m.fps <= 1000 {
+                    m.updateMaze(0)
+                }
+                return true
+            }
+            <- start
+            if s.solve(g, visit) {
+                r.finished = true
+                for {
+                    best := getInt(&m.raceBest)
+                    if (best != 0 && best <= r.moves) || atomic.CompareAndSwapInt32(&m.raceBest, int32(best), int32(r.moves)) {
+                        break
+                    }
+                }
+            }
+        }(i, s, m.copyMaze())
+    }
+    close(start)
+    wg.Wait()
+
+    m.raceWinner = 0
+    for i, r := range m.raceResults {
+        if r.finished && r.moves == getInt(&m.raceBest) && m.raceWinner == 0 {
+            m.raceWinner = i + 1
+        }
+    }
+}
+
+// printRace prints the winner of the race and the number of cells visited by each solver, each name in the color
+// of its solver on the display if color is set
+func (m *Maze) printRace(w io.Writer, color bool) {
+    if m.raceWinner == 0 {
+        fmt.Fprintf(w, "race: no solver reached the exit\n")
+    } else {
+        fmt.Fprintf(w, "race: %s reached the exit first in %d moves\n", m.raceResults[m.raceWinner - 1].name, m.raceResults[m.raceWinner - 1].moves)
+    }
+    for i, r := range m.raceResults {
+        budget := ""
+        if r.exhausted {
+            budget = ", step budget exhausted"
+        }
+        if color {
+            fmt.Fprintf(w, "  %s%-16s\033[30m\033[0m visited %d cells%s\n", raceColors[i % len(raceColors)], r.name, r.visited, budget)
+        } else {
+            fmt.Fprintf(w, "  %-16s visited %d cells%s\n", r.name, r.visited, budget)
+        }
+    }
+}
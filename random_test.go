@@ -0,0 +1,60 @@
+/* random_test.go - Random number source tests
+ *
+ * A maze drawn from a RandSource of the caller's is decided by that source alone, never reseeded by the maze,
+ * and a seed keeps all 64 bits.
+ */
+package maze
+
+import (
+    "bytes"
+    "testing"
+    "math/rand"
+)
+
+// seedCounter is a random number source that counts the times it's seeded
+type seedCounter struct {
+    rand.Source
+    seeds int
+}
+
+func (s *seedCounter) Seed(seed int64) {
+    s.seeds++
+    s.Source.Seed(seed)
+}
+
+// unsolvedGrid returns the grid of the maze's ascii output unsolved, without the parameters ahead of it
+func unsolvedGrid(m *Maze) []byte {
+    saved := m.Snapshot()
+    defer m.Restore(saved)
+    m.Unsolve()
+    var b bytes.Buffer
+    m.writeAsciiGrid(&b)
+    return b.Bytes()
+}
+
+func TestRandSourceDeterministic(t *testing.T) {
+    src := &seedCounter{Source: rand.NewSource(99)}
+    a := goldenMaze(t, WithSize(19, 10), WithSeed(1), WithRandSource(src))
+    if src.seeds != 0 {
+        t.Errorf("the maze seeded its RandSource %d times", src.seeds)
+    }
+    b := goldenMaze(t, WithSize(19, 10), WithSeed(2), WithRandSource(rand.NewSource(99)))
+    if got, want := unsolvedGrid(b), unsolvedGrid(a); !bytes.Equal(got, want) {
+        t.Errorf("mazes from sources seeded alike differ with the Seed:\n%s\nand:\n%s", got, want)
+    }
+    c := goldenMaze(t, WithSize(19, 10), WithSeed(1), WithRandSource(rand.NewSource(100)))
+    if bytes.Equal(unsolvedGrid(c), unsolvedGrid(a)) {
+        t.Errorf("mazes from sources seeded differently are the same")
+    }
+}
+
+func TestSeed64Bits(t *testing.T) {
+    const seed = 1<<40 + 3
+    m := goldenMaze(t, WithSize(12, 8), WithSeed(seed))
+    if m.cfg.seed != seed || m.CurrentSeed() != seed {
+        t.Errorf("seed %d kept as %d and generated with %d", int64(seed), m.cfg.seed, m.CurrentSeed())
+    }
+    if bytes.Equal(unsolvedGrid(m), unsolvedGrid(goldenMaze(t, WithSize(12, 8), WithSeed(3)))) {
+        t.Errorf("seed %d generated the maze of its low 32 bits", int64(seed))
+    }
+}
@@ -0,0 +1,224 @@
+/* render.go - Terminal rendering
+ *
+ * Draws the maze to any writer, a terminal, a buffer or a network connection, with VT100 line drawing
+ * characters and ANSI colors.  Each row is built in memory and written in one call, so a writer that fails
+ * mid-frame stops the rendering at that row and the error is returned to the caller.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "strconv"
+    "unicode/utf8"
+)
+
+// VT100 line drawing characters, with the ascii character each one resembles
+const (
+    blank        = ' '  // ' '
+    block        = 0x61 // '#'
+    rightBottom  = 0x6a // '+'
+    rightTop     = 0x6b // '+'
+    leftTop      = 0x6c // '+'
+    leftBottom   = 0x6d // '+'
+    intersection = 0x6e // '+'
+    horizontal   = 0x71 // '-'
+    rightTee     = 0x74 // '+'
+    leftTee      = 0x75 // '+'
+    upTee        = 0x76 // '+'
+    downTee      = 0x77 // '+'
+    vertical     = 0x78 // '|'
+    diamond      = 0x60 // '*'
+)
+
+var (
+    outputLookup = [16]byte { blank     , vertical   , horizontal, leftBottom  ,
+                              vertical  , vertical   , leftTop   , rightTee    ,
+                              horizontal, rightBottom, horizontal, upTee       ,
+                              rightTop  , leftTee    , downTee   , intersection }
+
+    // componentPalette holds well separated 256 color codes, largest components get the earliest entries
+    componentPalette = [...]int { 27, 160, 34, 214, 129, 37, 202, 100, 169, 67, 136, 90 }
+
+    // heatPalette runs from cool to hot 256 color codes for overlays that measure a quantity
+    heatPalette      = [...]int { 22, 28, 34, 70, 106, 142, 178, 214, 208, 202, 196 }
+)
+
+// RenderOptions chooses how RenderANSI draws the maze.  Each color is the escape sequence selecting it, or
+// empty for the terminal default.
+type RenderOptions struct {
+    Blank   bool                    // draw an empty maze blank rather than as a lattice of walls
+    Look    bool                    // draw the look ahead checks rather than hiding them as walls
+    Margin  int                     // rows and columns left free above and to the left of the maze
+    Wall    string                  // foreground of the walls
+    Path    string                  // background of the paths, set again after every reset
+    Solved  string                  // foreground of the solution
+    Tried   string                  // background of the cells tried and abandoned by the solver
+    Check   string                  // foreground of the look ahead checks
+}
+
+// errWriter passes writes on to w until one fails, then keeps returning that error without writing anything
+type errWriter struct {
+    w   io.Writer
+    err error
+}
+
+func (e *errWriter) Write(p []byte) (int, error) {
+    if e.err != nil {
+        return 0, e.err
+    }
+    n, err := e.w.Write(p)
+    e.err = err
+    return n, err
+}
+
+// isShownWall returns true if a cell is drawn as a wall, look ahead checks being hidden as walls unless look is set
+func isShownWall(cell int, look bool) bool {
+    return cell == wall || (!look && cell == check)
+}
+
+// wallShape returns the index into outputLookup of the line drawing the walls at i, j, a bit for each neighbouring
+// wall it joins: 1 up, 2 right, 4 down and 8 left.  Walls are only joined along the edge of a path, so a solid block
+// of walls is drawn as its outline.
+func wallShape(cell func(int, int) int, isWall func(int) bool, i, j int) int {
+    if isOdd(i) && isOdd(j) {
+        return 1 * bool2int(isWall(cell(i-1, j)) && (!isWall(cell(i-1, j-1)) || !isWall(cell(i-1, j+1)))) +    // wall intersection point
+               2 * bool2int(isWall(cell(i, j+1)) && (!isWall(cell(i-1, j+1)) || !isWall(cell(i+1, j+1)))) +    // check that there is a path on the diagonal
+               4 * bool2int(isWall(cell(i+1, j)) && (!isWall(cell(i+1, j-1)) || !isWall(cell(i+1, j+1)))) +
+               8 * bool2int(isWall(cell(i, j-1)) && (!isWall(cell(i-1, j-1)) || !isWall(cell(i+1, j-1))))
+    }
+    return 1 * bool2int(isWall(cell(i-1, j)) && (!isWall(cell(i  , j-1)) || !isWall(cell(i  , j+1)))) +        // non-intersection point
+           2 * bool2int(isWall(cell(i, j+1)) && (!isWall(cell(i-1, j  )) || !isWall(cell(i+1, j  )))) +        // check that there is a path adjacent
+           4 * bool2int(isWall(cell(i+1, j)) && (!isWall(cell(i  , j-1)) || !isWall(cell(i  , j+1)))) +
+           8 * bool2int(isWall(cell(i, j-1)) && (!isWall(cell(i-1, j  )) || !isWall(cell(i+1, j  ))))
+}
+
+// auxColor returns the 256 color code of the background showing a non-zero value of the overlay being shown
+func (m *Maze) auxColor(value int) int {
+    if getInt(&m.auxShown) == auxDeadEnds {
+        return heatPalette[(value - 1) * len(heatPalette) / max(getInt(&m.auxMax), 1)]
+    }
+    return componentPalette[(value - 1) % len(componentPalette)]
+}
+
+// RenderANSI draws the maze to w from the top left of the screen, or of the margin, with VT100 line drawing
+// characters, or with UTF-8 box drawing characters for the heavy and double line styles.  The solution, tried cells,
+// look ahead checks, race solvers, overlays and the opening search are drawn in their colors.  It stops at the first write that fails and returns its error.
+func (m *Maze) RenderANSI(w io.Writer, opts RenderOptions) error {
+    var line []byte
+    style      := m.style()
+    putchar    := func(c byte)   {; if style == LightLines {; line = append(line, c); } else {; line = utf8.AppendRune(line, lineRunes[LightLines][c]); }; }
+    putwall    := func(c byte)   {; if style == LightLines {; line = append(line, c); } else {; line = utf8.AppendRune(line, lineRunes[style][c]); }; }
+    puts       := func(s string) {; line = append(line, s...); }
+    setSolved  := func()         {; puts(opts.Solved); }
+    clrSolved  := func()         {; puts("\033[30m\033[0m"); puts(opts.Path); }
+    setChecked := func()         {; puts(opts.Check); }
+    clrChecked := func()         {; puts("\033[30m\033[0m"); puts(opts.Path); }
+    setWall    := func()         {; puts(opts.Wall); }
+    clrWall    := func()         {; puts("\033[0m"); puts(opts.Path); }
+    setBlock   := func()         {; puts(opts.Wall); puts("\033[7m"); }
+    clrBlock   := func()         {; puts("\033[0m"); puts(opts.Path); }
+    setTried   := func()         {; puts(opts.Tried); }
+    clrTried   := func()         {; puts("\033[0m"); puts(opts.Path); }
+    setRacer   := func(o int)    {; puts(racerColor(o)); }
+    setAux     := func(v int)    {; puts("\033[48;5;"); line = strconv.AppendInt(line, int64(m.auxColor(v)), 10); puts("m"); }
+    clrAux     := func()         {; puts("\033[49m"); }
+    flush      := func() error   {; _, err := w.Write(line); line = line[:0]; return err; }
+    isWall     := func(c int) bool {; return isShownWall(c, opts.Look); }
+    cell       := m.shownCell
+    racer      := func(x, y int) int {; if !getBool(&m.raceFlag) {; return 0; }; return m.getOwner(x, y); }
+
+    puts("\033[0;0H")
+    if style == LightLines {
+        puts("\033(0")
+    }
+    rows, cols := getInt(&m.maxX), getInt(&m.maxY)
+    overlay    := getInt(&m.auxShown)
+    for i := 1; i < rows - 1; i++ {
+        if opts.Margin > 0 {
+            puts(fmt.Sprintf("\033[%d;%dH", opts.Margin + i, opts.Margin + 1))
+        }
+        puts(opts.Path)
+        for j := 1; j < cols - 1; j++ {
+            var vertexChar, solvedChar, leftChar, rightChar, wallChar byte
+
+            vertexChar = outputLookup[wallShape(cell, isWall, i, j)]
+                solvedChar = outputLookup[1 * bool2int(cell(i-1, j) == cell(i, j)) +
+                                          2 * bool2int(cell(i, j+1) == cell(i, j)) +
+                                          4 * bool2int(cell(i+1, j) == cell(i, j)) +
+                                          8 * bool2int(cell(i, j-1) == cell(i, j))]
+
+            if isEven(i) && (cell(i, j-1) == solved || cell(i, j-1) == check) {;  leftChar = horizontal; } else {;  leftChar = blank; }
+            if isEven(i) && (cell(i, j+1) == solved || cell(i, j+1) == check) {; rightChar = horizontal; } else {; rightChar = blank; }
+
+            if opts.Blank {; wallChar = vertexChar; } else {; wallChar = solvedChar; }
+
+            owner := 0
+            if cell(i, j) != wall {
+                owner = racer(i, j)
+            }
+            if owner != 0 {
+                solvedChar = outputLookup[1 * bool2int(racer(i-1, j) == owner) +
+                                          2 * bool2int(racer(i, j+1) == owner) +
+                                          4 * bool2int(racer(i+1, j) == owner) +
+                                          8 * bool2int(racer(i, j-1) == owner)]
+                if isEven(i) && racer(i, j-1) == owner {;  leftChar = horizontal; } else {;  leftChar = blank; }
+                if isEven(i) && racer(i, j+1) == owner {; rightChar = horizontal; } else {; rightChar = blank; }
+            }
+
+            marker := m.searchMarker(i, j)
+            switch {
+                case marker == 2          :                                setSolved();  putchar(blank); putchar(diamond); putchar(blank); clrSolved()
+                case marker == 1          :                                              putchar(blank); putchar(diamond); putchar(blank)
+                case owner != 0           :                                setRacer(owner); putchar(leftChar); if (isEven(j)) {; putchar(solvedChar); putchar(rightChar); }; clrSolved()
+                case cell(i, j) == solved :                                setSolved();  putchar(leftChar); if (isEven(j)) {; putchar(solvedChar); putchar(rightChar); }; clrSolved()
+                case cell(i, j) == check  : if opts.Look             {;    setChecked(); putchar(leftChar); if (isEven(j)) {; putchar(solvedChar); putchar(rightChar); }; clrChecked();
+                                            } else                   {;                  putchar(blank   ); if (isEven(j)) {; putchar(blank     ); putchar(blank    ); }}
+                case overlay != auxNone && cell(i, j) != wall && m.getAux(i, j) != 0:
+                                                                           setAux(m.getAux(i, j)); putchar(blank); if (isEven(j)) {; putchar(blank); putchar(blank); }; clrAux()
+                case cell(i, j) == tried && opts.Tried != "":              setTried();   putchar(blank   ); if (isEven(j)) {; putchar(blank     ); putchar(blank    ); }; clrTried()
+                case isEven(i) && isEven(j):                                             putchar(blank   ); if (isEven(j)) {; putchar(blank     ); putchar(blank    ); }
+                case cell(i, j) == wall && m.blockWalls:                   setBlock();   putchar(blank   ); if (isEven(j)) {; putchar(blank     ); putchar(blank    ); }; clrBlock()
+                case cell(i, j) == wall && opts.Wall != "":                setWall();    putwall(wallChar); if (isEven(j)) {; putwall(wallChar  ); putwall(wallChar ); }; clrWall()
+                case cell(i, j) == wall   :                                              putwall(wallChar); if (isEven(j)) {; putwall(wallChar  ); putwall(wallChar ); }
+                default                   :                                              putchar(blank   ); if (isEven(j)) {; putchar(blank     ); putchar(blank    ); }
+            }
+        }
+        if opts.Path != "" {
+            puts("\033[0m")
+        }
+        puts("\n")
+        if err := flush(); err != nil {
+            return err
+        }
+    }
+    if style == LightLines {
+        puts("\033(B")
+    }
+    return flush()
+}
+
+// RenderASCII writes the maze to w in the portable ascii format read by ParseASCII, returning the first error
+// from w
+func (m *Maze) RenderASCII(w io.Writer) error {
+    m = m.widened()
+    ew := &errWriter{w: w}
+    m.writeAsciiMaze(ew)
+    return ew.err
+}
+
+// RenderEdges writes the maze to w as a list of the passages between cells, returning the first error from w
+func (m *Maze) RenderEdges(w io.Writer) error {
+    m = m.widened()
+    ew := &errWriter{w: w}
+    m.writeEdges(ew)
+    return ew.err
+}
+
+// RenderBraille writes the maze to w as unicode braille characters, returning the first error from w
+func (m *Maze) RenderBraille(w io.Writer) error {
+    m = m.widened()
+    ew := &errWriter{w: w}
+    m.writeBraille(ew)
+    return ew.err
+}
@@ -0,0 +1,54 @@
+/* render_test.go - Rendering tests
+ *
+ * Renders a maze into a bytes.Buffer with RenderANSI and compares it with its golden file, and renders into
+ * writers that fail part way through to check that rendering stops at the failed write and returns its error.
+ */
+package maze
+
+import (
+    "bytes"
+    "errors"
+    "testing"
+)
+
+// failingWriter accepts writes until it has taken limit bytes, then fails every write after
+type failingWriter struct {
+    limit, written, writes, failed int
+}
+
+var errFull = errors.New("writer full")
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+    f.writes++
+    if f.written + len(p) > f.limit {
+        f.failed++
+        return 0, errFull
+    }
+    f.written += len(p)
+    return len(p), nil
+}
+
+func TestRenderANSIBuffer(t *testing.T) {
+    m := goldenMaze(t, WithSize(12, 8), WithSeed(42), WithDepth(5))
+    var b bytes.Buffer
+    if err := m.RenderANSI(&b, RenderOptions{Wall: "\033[34m", Path: "\033[47m", Solved: "\033[31m"}); err != nil {
+        t.Fatal(err)
+    }
+    checkGolden(t, "12x8-seed42-depth5.ansi", b.Bytes())
+}
+
+func TestRenderWriteError(t *testing.T) {
+    m := goldenMaze(t, WithSize(12, 8), WithSeed(42), WithDepth(5))
+    for name, render := range map[string]func(w *failingWriter) error{
+        "ansi" : func(w *failingWriter) error {; return m.RenderANSI(w, RenderOptions{}); },
+        "ascii": func(w *failingWriter) error {; return m.RenderASCII(w); },
+    } {
+        w := &failingWriter{limit: 100}
+        if err := render(w); !errors.Is(err, errFull) {
+            t.Errorf("%s: error %v, want %v", name, err, errFull)
+        }
+        if w.failed != 1 {
+            t.Errorf("%s: %d writes after the first failed, want none", name, w.failed - 1)
+        }
+    }
+}
@@ -0,0 +1,56 @@
+/* renderer.go - Frame renderers
+ *
+ * The display of a maze being generated is a series of frames, each drawn by a Renderer from a view of the
+ * maze and the generation stats, so the generator never knows what it's being drawn on.  TerminalRenderer is
+ * the maze command's terminal display; a program can supply its own to write the frames to files, say, or to
+ * draw nothing while timing the generation.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+)
+
+const blankLine = "                                                  "
+
+// Renderer draws a frame of the maze from its grid and the generation stats
+type Renderer interface {
+    Frame(g *Grid, stats Stats) error
+}
+
+// TerminalRenderer draws each frame with RenderANSI, RenderBrailleANSI for a maze too big to draw that way or
+// RenderGlyphANSI, followed by a status line giving the number of frames drawn, as updates, and the stats
+type TerminalRenderer struct {
+    W       io.Writer               // the terminal, flushed after each frame if it has a Flush method
+    RenderOptions                   // colors, margin and the Blank and Look styles
+    Status  string                  // color of the status line, empty for the default
+    Braille bool                    // draw with braille characters
+    Glyphs  *GlyphMap               // draw with these glyphs, a glyph for each location, if set
+    frames  int
+}
+
+// Frame draws the maze and the status line under it, returning the first error writing them
+func (t *TerminalRenderer) Frame(g *Grid, stats Stats) error {
+    t.frames++
+    ew := &errWriter{w: t.W}
+    rows := getInt(&g.m.maxX) - 2
+    switch {
+        case t.Braille      : g.m.RenderBrailleANSI(ew, t.RenderOptions)
+                              rows = g.m.brailleRows()
+        case t.Glyphs != nil: g.m.RenderGlyphANSI(ew, t.RenderOptions, *t.Glyphs)
+        default             : g.m.RenderANSI(ew, t.RenderOptions)
+    }
+    if t.Margin > 0 {
+        fmt.Fprintf(ew, "\033[%d;%dH", t.Margin + rows + 1, t.Margin + 1)
+    }
+    reset := ""
+    if t.Status != "" {
+        reset = "\033[0m"
+    }
+    fmt.Fprintf(ew, "%supdates=%d, %s %s%s\r", t.Status, t.frames, FormatStats(stats, ", ", true), blankLine, reset)
+    if f, ok := t.W.(interface{ Flush() error }); ok && ew.err == nil {
+        ew.err = f.Flush()
+    }
+    return ew.err
+}
@@ -0,0 +1,69 @@
+/* reveal.go - Progressive solution hints
+ *
+ * Shows more and more of the solution from the entrance for a series of output files, the first with none of
+ * it and the last with all of it, for handing out as escalating hints.
+ */
+package maze
+
+// solutionRoute returns the solved locations in order from the entrance opening to the exit opening
+func (m *Maze) solutionRoute() []point {
+    var route []point
+    m.walkSolution(func(p point) {; route = append(route, p); })
+    return route
+}
+
+// walkSolution calls visit for each solved location in order from the entrance opening to the exit opening, without
+// building the route, since searchBestOpenings measures it for every pair of openings
+func (m *Maze) walkSolution(visit func(p point)) {
+    x, y, fromX, fromY := getInt(&m.begX) - 1, getInt(&m.begY), -1, -1
+    for j := 1; j < getInt(&m.maxY) - 1 && m.getMaze(x, y) != solved; j++ {     // a widened solution runs down the
+        if m.getMaze(x, j) == solved {                                          // middle of the entrance, beside begY
+            y = j
+        }
+    }
+    for m.getMaze(x, y) == solved {
+        visit(point{x, y})
+        if x == getInt(&m.endX) + 1 {              // out through the exit, which may be wider than one cell
+            break
+        }
+        moved := false
+        for _, dir := range stdDirection {
+            nx, ny := x + dir.x/2, y + dir.y/2
+            if m.isInterior(nx, ny) && (nx != fromX || ny != fromY) && m.getMaze(nx, ny) == solved {
+                x, y, fromX, fromY, moved = nx, ny, x, y, true
+                break
+            }
+        }
+        if !moved {
+            break
+        }
+    }
+}
+
+// revealRoute calls write for each of n hints, hint k showing the fraction (k-1)/(n-1) of the route from the
+// entrance, then returns the route's locations to the states they had before
+func (m *Maze) revealRoute(route []point, n int, write func(k int)) {
+    saved := make([]int, len(route))
+    for i, p := range route {
+        saved[i] = m.getMaze(p.x, p.y)
+    }
+    for k := 1; k <= n; k++ {
+        shown := len(route)
+        if n > 1 {
+            shown = len(route) * (k - 1) / (n - 1)
+        }
+        for i, p := range route {
+            if i < shown {
+                m.setMaze(p.x, p.y, solved)
+            } else if saved[i] == solved {
+                m.setMaze(p.x, p.y, path)
+            } else {
+                m.setMaze(p.x, p.y, saved[i])
+            }
+        }
+        write(k)
+    }
+    for i, p := range route {
+        m.setMaze(p.x, p.y, saved[i])
+    }
+}
@@ -0,0 +1,449 @@
+/* scan.go - Mazes read from images
+ *
+ * Reads a maze to solve from a png or jpeg image of it, one written by the png format or a scan of one drawn by
+ * hand.  The image is thresholded to black walls on white, the brightness splitting the dark pixels from the
+ * light ones, colored pixels such as a solution drawn in red counting as light.  The borders are the first and
+ * last lines dark along most of the maze.  The cell pitch is given, or found from the centres of the walls that
+ * cross each row: the fewest cells whose lines pass through nearly all of them, each line moved onto the walls
+ * near it since a hand drawn line may be a pixel or two off all along its length, and the same for the columns.
+ * Each wall is then read from the pixels along it, present if most of its length is dark within a couple of
+ * pixels of where it should be, so lines that wander, have small gaps or have specks beside them still read
+ * true.  The openings are the gaps in the top and bottom borders, or if there are none there then in the left
+ * and right borders, when the maze is read on its side.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "math"
+    "sort"
+    "bytes"
+    "image"
+    _ "image/jpeg"
+)
+
+// Limits on the images read
+const (
+    MinImageCellSize = 2
+    MaxImageSide     = 16384            // pixels wide or high
+)
+
+// ImageOptions chooses how ParseImage reads the maze
+type ImageOptions struct {
+    CellSize int                    // pixels from one wall line to the next, 0 to find it from the lines
+}
+
+// Check returns an error if the options are out of range
+func (o ImageOptions) Check() error {
+    if o.CellSize != 0 && o.CellSize < MinImageCellSize {
+        return fmt.Errorf("image cell size %d must be at least %d pixels", o.CellSize, MinImageCellSize)
+    }
+    return nil
+}
+
+// bitmap marks the dark pixels of an image
+type bitmap struct {
+    w, h int
+    dark []bool
+}
+
+// at returns true if the pixel at x, y is dark, and false for one outside the image
+func (b *bitmap) at(x, y int) bool {
+    return x >= 0 && x < b.w && y >= 0 && y < b.h && b.dark[y*b.w + x]
+}
+
+// run returns the columns from the first of the run of dark pixels along row y through the one at x to just past its last
+func (b *bitmap) run(x, y int) (int, int) {
+    start, end := x, x + 1
+    for b.at(start - 1, y) {
+        start--
+    }
+    for b.at(end, y) {
+        end++
+    }
+    return start, end
+}
+
+// height returns the length of the run of dark pixels down column x through the one at y
+func (b *bitmap) height(x, y int) int {
+    start, end := y, y + 1
+    for b.at(x, start - 1) {
+        start--
+    }
+    for b.at(x, end) {
+        end++
+    }
+    return end - start
+}
+
+// transposed returns the bitmap with its rows and columns swapped
+func (b *bitmap) transposed() *bitmap {
+    t := &bitmap{w: b.h, h: b.w, dark: make([]bool, len(b.dark))}
+    for y := 0; y < b.h; y++ {
+        for x := 0; x < b.w; x++ {
+            t.dark[x*t.w + y] = b.dark[y*b.w + x]
+        }
+    }
+    return t
+}
+
+// darkPixels returns the bitmap of the dark pixels of an image, with the threshold between dark and light chosen by
+// Otsu's method among the grey pixels.  A colored pixel is light.
+func darkPixels(img image.Image) *bitmap {
+    r := img.Bounds()
+    b := &bitmap{w: r.Dx(), h: r.Dy(), dark: make([]bool, r.Dx()*r.Dy())}
+    luma, grey := make([]uint8, len(b.dark)), make([]bool, len(b.dark))
+    var hist [256]int
+    for y := 0; y < b.h; y++ {
+        for x := 0; x < b.w; x++ {
+            cr, cg, cb, _ := img.At(r.Min.X + x, r.Min.Y + y).RGBA()
+            hi, lo := max(int(cr), max(int(cg), int(cb))) >> 8, min(int(cr), min(int(cg), int(cb))) >> 8
+            k := y*b.w + x
+            luma[k], grey[k] = uint8((299*cr + 587*cg + 114*cb)/1000 >> 8), hi - lo < 32
+            if grey[k] {
+                hist[luma[k]]++
+            }
+        }
+    }
+    threshold := otsu(hist)
+    for k := range b.dark {
+        b.dark[k] = grey[k] && int(luma[k]) <= threshold
+    }
+    return b
+}
+
+// otsu returns the brightness splitting a histogram into the two classes with the greatest variance between them
+func otsu(hist [256]int) int {
+    total, sum := 0, 0.0
+    for v, n := range hist {
+        total += n
+        sum   += float64(v*n)
+    }
+    best, threshold, below, sumBelow := -1.0, 127, 0, 0.0
+    for v := 0; v < 255; v++ {
+        below    += hist[v]
+        sumBelow += float64(v*hist[v])
+        above    := total - below
+        if below == 0 || above == 0 {
+            continue
+        }
+        meanBelow, meanAbove := sumBelow/float64(below), (sum - sumBelow)/float64(above)
+        if between := float64(below)*float64(above)*(meanBelow - meanAbove)*(meanBelow - meanAbove); between > best {
+            best, threshold = between, v
+        }
+    }
+    return threshold
+}
+
+// lineAxis places the wall lines across one axis of the image: the centres of the first and last, their thickness,
+// the number of cells between them, the pitch from one line to the next and the centres of the lines between if they
+// were found
+type lineAxis struct {
+    first, last, thick float64
+    cells              int
+    pitch              float64
+    lines              []float64
+}
+
+// line returns the pixel coordinate of the centre of wall line k
+func (a lineAxis) line(k int) float64 {
+    if a.lines != nil {
+        return a.lines[k]
+    }
+    return a.first + float64(k)*a.pitch
+}
+
+// slack returns how far a line may wander from where it should be, in pixels: up to two, but short of the middle of the
+// cells either side
+func (a lineAxis) slack() float64 {
+    return math.Max(0, math.Min(2, (a.pitch - a.thick)/2 - 1))
+}
+
+// setCells divides the axis into the number of cells, evenly
+func (a *lineAxis) setCells(cells int) {
+    a.cells, a.pitch, a.lines = max(1, cells), (a.last - a.first)/float64(max(1, cells)), nil
+}
+
+// fitLines sets the number of cells to the fewest whose lines pass through nine tenths of the centres of the walls
+// crossing the axis, returning false if there are none to fit or an error if no number of cells fits them.  Each
+// number is tried with its lines evenly spaced between the borders, each then moved to the median of the centres within
+// a quarter of a cell of it, as a hand drawn line may be off by a pixel or two all along, and the rest of them to the
+// best straight fit through the centres.
+func (a *lineAxis) fitLines(centres []float64) (bool, error) {
+    if len(centres) == 0 {
+        return false, nil
+    }
+    for n := 1; n <= maxWidth && (a.last - a.first)/float64(n) >= MinImageCellSize; n++ {
+        pitch := (a.last - a.first)/float64(n)
+        on := make([][]float64, n + 1)
+        sk, sc, skk, skc, count := float64(n), a.first + a.last, float64(n*n), float64(n)*a.last, 2.0
+        for _, c := range centres {                 // and the least squares line through them and the borders
+            if k := int(math.Round((c - a.first)/pitch)); k >= 0 && k <= n && math.Abs(c - a.line(0) - float64(k)*pitch) <= pitch/4 {
+                on[k] = append(on[k], c)
+                sk, sc, skk, skc, count = sk + float64(k), sc + c, skk + float64(k*k), skc + float64(k)*c, count + 1
+            }
+        }
+        first := a.first
+        if d := count*skk - sk*sk; d > 0 {
+            pitch = (count*skc - sk*sc)/d
+            first = (sc - pitch*sk)/count
+        }
+        lines, fits := make([]float64, n + 1), 0
+        near := math.Min(2, pitch/6)                // a line wandering a pixel or two, in cells big enough to tell
+        for k := range lines {
+            lines[k] = first + float64(k)*pitch
+            switch {
+                case k == 0    : lines[k] = a.first
+                case k == n    : lines[k] = a.last
+                case len(on[k]) >= 3: lines[k] = median(on[k])
+            }
+            for _, c := range on[k] {
+                if math.Abs(c - lines[k]) <= near {
+                    fits++
+                }
+            }
+        }
+        if 10*fits >= 9*len(centres) {
+            a.cells, a.pitch, a.lines = n, pitch, lines
+            return true, nil
+        }
+    }
+    return false, fmt.Errorf("no cell size fits the walls found in the image")
+}
+
+// trimEnds places the borders a line's half thickness in from the ends of the lines along an axis with no border lines
+// drawn, the openings in them as wide as the maze
+func (a *lineAxis) trimEnds(thick float64) {
+    if a.thick == 0 {
+        a.first, a.last, a.thick = a.first + thick/2, a.last - thick/2, thick
+        a.setCells(1)
+    }
+}
+
+// median returns the middle of the values, which mustn't be empty
+func median(values []float64) float64 {
+    sort.Float64s(values)
+    return values[len(values)/2]
+}
+
+// columnLines places the left and right borders of the bitmap, the first and last columns dark for a tenth of the
+// height of its dark pixels, and returns them with the centres of the walls crossing each row between them: the runs
+// of dark pixels along a row no wider than a line and down a column longer than two, away from the borders, which
+// leaves out specks.  The thickness of the lines is that of the left border, or 0 if there's no border drawn, only
+// the ends of the lines along the rows.
+func (b *bitmap) columnLines() (lineAxis, []float64, error) {
+    profile, top, bottom := make([]int, b.w), -1, -1
+    for y := 0; y < b.h; y++ {
+        for x := 0; x < b.w; x++ {
+            if b.at(x, y) {
+                profile[x]++
+                if top < 0 {
+                    top = y
+                }
+                bottom = y
+            }
+        }
+    }
+    left, right := -1, -1
+    for x, n := range profile {
+        if n >= max(1, (bottom - top + 1)/10) {
+            if left < 0 {
+                left = x
+            }
+            right = x
+        }
+    }
+    if left < 0 || right - left < 2 {
+        return lineAxis{}, nil, fmt.Errorf("no maze found in the image")
+    }
+
+    var thicks, lefts, rights []float64             // the left border's run of dark pixels along each row, and the right's
+    reach := max(1, (right - left)/4)
+    for y := 0; y < b.h; y++ {
+        for x := left; x < left + reach; x++ {
+            if b.at(x, y) {
+                start, end := b.run(x, y)
+                thicks, lefts = append(thicks, float64(end - start)), append(lefts, float64(start + end)/2)
+                break
+            }
+        }
+        for x := right; x > right - reach; x-- {
+            if b.at(x, y) {
+                start, end := b.run(x, y)
+                rights = append(rights, float64(start + end)/2)
+                break
+            }
+        }
+    }
+    if len(lefts) == 0 || len(rights) == 0 {
+        return lineAxis{}, nil, fmt.Errorf("no maze borders found in the image")
+    }
+    a := lineAxis{first: median(lefts), last: median(rights), thick: median(thicks)}
+    if a.thick > float64(right - left)/2 {          // no border lines, just the ends of the lines along the axis
+        a = lineAxis{first: float64(left), last: float64(right + 1)}
+    }
+    a.setCells(1)
+
+    var centres []float64
+    for y := 0; y < b.h; y++ {
+        for x := left; x <= right; x++ {
+            if !b.at(x, y) {
+                continue
+            }
+            start, end := b.run(x, y)
+            centre := float64(start + end)/2
+            if float64(end - start) <= 1.5*a.thick + 1 && centre > a.first + a.thick && centre < a.last - a.thick &&
+               float64(b.height(int(centre), y)) >= 2*a.thick + 1 {
+                centres = append(centres, centre)
+            }
+            x = end
+        }
+    }
+    return a, centres, nil
+}
+
+// hasLine returns true if wall line k of the lines along x is drawn across cell c of the lines along y: if more than
+// half the rows of the cell, clear of the horizontal walls either side, have a dark pixel near where the line should be
+func (b *bitmap) hasLine(x lineAxis, k int, y lineAxis, c int) bool {
+    lo, hi := y.line(c) + y.thick/2 + y.slack()/2, y.line(c + 1) - y.thick/2 - y.slack()/2
+    rows := []int{}
+    for r := int(math.Floor(lo)); float64(r) < hi; r++ {
+        rows = append(rows, r)
+    }
+    if len(rows) == 0 {
+        rows = append(rows, int(y.line(c) + y.pitch/2))
+    }
+    half := x.thick/2 + x.slack()
+    x0, x1 := int(math.Floor(x.line(k) - half)), int(math.Ceil(x.line(k) + half))
+    hits := 0
+    for _, r := range rows {
+        for p := x0; p < x1; p++ {
+            if b.at(p, r) {
+                hits++
+                break
+            }
+        }
+    }
+    return 2*hits > len(rows)
+}
+
+// ParseImage reads a maze from a png or jpeg image of black walls on white, returning an error if it isn't an image,
+// no maze can be made out in it or the maze doesn't have an opening in its top and bottom borders, or its left and
+// right ones.  A maze open on its left and right is read transposed, its rows as columns, so that it's open at the
+// top and bottom; Transposed reports it.  The side borders are closed, whatever is drawn.
+func ParseImage(r io.Reader, opts ImageOptions) (*Loaded, error) {
+    if err := opts.Check(); err != nil {
+        return nil, err
+    }
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+    config, _, err := image.DecodeConfig(bytes.NewReader(data))
+    if err != nil {
+        return nil, fmt.Errorf("invalid image: %v", err)
+    }
+    if config.Width > MaxImageSide || config.Height > MaxImageSide {
+        return nil, fmt.Errorf("image of %dx%d pixels is over %d pixels wide or high", config.Width, config.Height, MaxImageSide)
+    }
+    img, _, err := image.Decode(bytes.NewReader(data))
+    if err != nil {
+        return nil, fmt.Errorf("invalid image: %v", err)
+    }
+    b := darkPixels(img)
+    t := b.transposed()
+    cols, across, err := b.columnLines()
+    if err != nil {
+        return nil, err
+    }
+    rows, down, err := t.columnLines()
+    if err != nil {
+        return nil, err
+    }
+    cols.trimEnds(rows.thick)
+    rows.trimEnds(cols.thick)
+    if opts.CellSize > 0 {
+        cols.setCells(int(math.Round((cols.last - cols.first)/float64(opts.CellSize))))
+        rows.setCells(int(math.Round((rows.last - rows.first)/float64(opts.CellSize))))
+    } else {
+        colsFit, err := cols.fitLines(across)
+        if err != nil {
+            return nil, err
+        }
+        rowsFit, err := rows.fitLines(down)
+        if err != nil {
+            return nil, err
+        }
+        switch {                                    // the cells are square along an axis with no walls across it
+            case colsFit && !rowsFit:
+                rows.setCells(int(math.Round((rows.last - rows.first)/cols.pitch)))
+            case rowsFit && !colsFit:
+                cols.setCells(int(math.Round((cols.last - cols.first)/rows.pitch)))
+            case !colsFit && !rowsFit && cols.pitch > rows.pitch:   // a single corridor, one cell across
+                cols.setCells(int(math.Round((cols.last - cols.first)/rows.pitch)))
+            case !colsFit && !rowsFit:
+                rows.setCells(int(math.Round((rows.last - rows.first)/cols.pitch)))
+        }
+    }
+
+    h, w := rows.cells, cols.cells
+    drawn := make([][]bool, 2*h + 3)                // the walls as drawn, indexed as the grid
+    for x := range drawn {
+        drawn[x] = make([]bool, 2*w + 3)
+        for y := range drawn[x] {
+            switch {
+                case x < 1 || x > 2*h + 1 || y < 1 || y > 2*w + 1:
+                case x % 2 == 1 && y % 2 == 0:
+                    drawn[x][y] = t.hasLine(rows, (x - 1)/2, cols, (y - 2)/2)
+                case x % 2 == 0 && y % 2 == 1:
+                    drawn[x][y] = b.hasLine(cols, (y - 1)/2, rows, (x - 2)/2)
+            }
+        }
+    }
+    isOpen := func(x, y, dx, dy, n int) bool {     // n wall locations from x, y are open
+        for k := 0; k < n; k++ {
+            if !drawn[x + k*dx][y + k*dy] {
+                return true
+            }
+        }
+        return false
+    }
+    m := &Loaded{height: h, width: w}
+    wallAt := func(x, y int) bool {; return drawn[x][y]; }
+    if !(isOpen(1, 2, 0, 2, w) && isOpen(2*h + 1, 2, 0, 2, w)) && isOpen(2, 1, 2, 0, h) && isOpen(2, 2*w + 1, 2, 0, h) {
+        m.height, m.width, m.transposed = w, h, true
+        wallAt = func(x, y int) bool {; return drawn[y][x]; }
+    }
+    h, w = m.height, m.width
+    if h > maxHeight || w > maxWidth {
+        return nil, fmt.Errorf("invalid maze size %dx%d (height 1-%d, width 1-%d)", w, h, maxHeight, maxWidth)
+    }
+    for x := 1; x <= 2*h + 1; x++ {
+        for y := 1; y <= 2*w + 1; y++ {
+            if x % 2 == 1 && y % 2 == 1 || y == 1 || y == 2*w + 1 || (x + y) % 2 == 1 && wallAt(x, y) {
+                m.grid[x][y] = wall                 // the posts, the side borders and the walls drawn
+            }
+        }
+    }
+
+    var opens [2][]int                              // columns of the openings in the top and bottom walls
+    for k, x := range []int{1, 2*h + 1} {
+        for y := 2; y <= 2*w; y += 2 {
+            if m.grid[x][y] != wall {
+                if len(opens[k]) > 0 && opens[k][len(opens[k]) - 1] == y - 2 {
+                    m.grid[x][y - 1] = path         // a corner post inside a wide opening
+                }
+                opens[k] = append(opens[k], y)
+            }
+        }
+    }
+    if m.begY, err = borderOpening(&m.grid[1], opens[0], 0, "top"); err != nil {
+        return nil, err
+    }
+    if m.endY, err = borderOpening(&m.grid[2*h + 1], opens[1], 0, "bottom"); err != nil {
+        return nil, err
+    }
+    m.lines = m.drawLines()
+    return m, nil
+}
@@ -0,0 +1,131 @@
+/* scan_test.go - Image reading tests
+ *
+ * Reads back the pngs the png format writes, at several cell sizes and wall widths and with the solution drawn
+ * or not, and checks that the walls and openings are those of the maze written.  Then draws a maze as a scan
+ * of one drawn by hand might be, every pixel of its wall lines up to a pixel off and specks beside them, and
+ * checks that it reads as the maze drawn and solves.
+ */
+package maze
+
+import (
+    "bytes"
+    "image"
+    "context"
+    "strings"
+    "testing"
+    "math/rand"
+    "image/png"
+    "image/color"
+)
+
+// parsePNG returns the maze read from the png
+func parsePNG(t *testing.T, img image.Image, opts ImageOptions) *Loaded {
+    t.Helper()
+    var b bytes.Buffer
+    if err := png.Encode(&b, img); err != nil {
+        t.Fatal(err)
+    }
+    l, err := ParseImage(&b, opts)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return l
+}
+
+// unsolvedLoaded returns the maze as the ascii format reads it written unsolved
+func unsolvedLoaded(t *testing.T, m *Maze) *Loaded {
+    t.Helper()
+    l, err := ParseASCII(string(unsolvedASCII(t, m)))
+    if err != nil {
+        t.Fatal(err)
+    }
+    return l
+}
+
+// sameWalls returns true if the mazes have the same size, walls and openings
+func sameWalls(a, b *Loaded) bool {
+    return a.height == b.height && a.width == b.width && a.grid == b.grid && a.begY == b.begY && a.endY == b.endY
+}
+
+func TestImageRoundTrip(t *testing.T) {
+    for _, size := range [][2]int{{1, 1}, {1, 6}, {7, 1}, {12, 8}, {19, 10}} {
+        m := goldenMaze(t, WithSize(size[0], size[1]), WithSeed(5))
+        want := unsolvedLoaded(t, m)
+        for _, opts := range []PNGOptions{
+            {},
+            {CellSize: 8, Margin: 4, Solution: PNGSolutionColor},
+            {CellSize: 5, WallPx: 1},
+            {CellSize: 16, Margin: 10, WallPx: 6, Solution: PNGSolutionColor},
+        } {
+            img := decodePNG(t, m, opts)
+            for _, cellSize := range []int{0, opts.CellSize} {
+                if got := parsePNG(t, img, ImageOptions{CellSize: cellSize}); !sameWalls(got, want) {
+                    t.Errorf("%dx%d %+v, cell size %d: read back as\n%s\nwant\n%s", size[0], size[1], opts, cellSize,
+                             strings.Join(got.drawLines(), "\n"), strings.Join(want.drawLines(), "\n"))
+                }
+            }
+        }
+    }
+}
+
+// drawJittered draws the walls of the maze in black on white as a hand might, cell pixels apart and thick pixels
+// wide, every pixel along each line moved up to a pixel across it, with a speck by every tenth cell
+func drawJittered(l *Loaded, cell, thick int, rng *rand.Rand) *image.Gray {
+    const margin = 6
+    img := image.NewGray(image.Rect(0, 0, l.width*cell + thick + 2*margin, l.height*cell + thick + 2*margin))
+    for k := range img.Pix {
+        img.Pix[k] = 0xff
+    }
+    dot := func(x, y int) {; img.SetGray(margin + y, margin + x, color.Gray{}); }
+    for x := 1; x <= 2*l.height + 1; x++ {
+        for y := 1; y <= 2*l.width + 1; y++ {
+            if l.grid[x][y] != wall || x % 2 == 0 && y % 2 == 0 {
+                continue
+            }
+            switch {
+                case x % 2 == 1 && y % 2 == 1:                  // a post
+                    for i := 0; i < thick; i++ {
+                        for j := 0; j < thick; j++ {
+                            dot((x - 1)/2*cell + i, (y - 1)/2*cell + j)
+                        }
+                    }
+                case x % 2 == 1:                                // a wall along a row
+                    for j := (y - 2)/2*cell; j < y/2*cell + thick; j++ {
+                        off := rng.Intn(3) - 1
+                        for i := 0; i < thick; i++ {
+                            dot((x - 1)/2*cell + i + off, j)
+                        }
+                    }
+                default:                                        // a wall along a column
+                    for i := (x - 2)/2*cell; i < x/2*cell + thick; i++ {
+                        off := rng.Intn(3) - 1
+                        for j := 0; j < thick; j++ {
+                            dot(i, (y - 1)/2*cell + j + off)
+                        }
+                    }
+            }
+        }
+    }
+    for c := 0; c < l.height*l.width; c += 10 {
+        x, y := c/l.width, c%l.width
+        dot(x*cell + cell/2 + rng.Intn(3) - 1, y*cell + cell/2 + rng.Intn(3) - 1)
+    }
+    return img
+}
+
+func TestImageJitter(t *testing.T) {
+    for seed := int64(1); seed <= 4; seed++ {
+        want := unsolvedLoaded(t, goldenMaze(t, WithSize(15, 9), WithSeed(seed)))
+        img := drawJittered(want, 12, 2, rand.New(rand.NewSource(seed)))
+        got := parsePNG(t, img, ImageOptions{})
+        if !sameWalls(got, want) {
+            t.Errorf("seed %d: hand drawn maze read as\n%s\nwant\n%s", seed, strings.Join(got.drawLines(), "\n"), strings.Join(want.drawLines(), "\n"))
+            continue
+        }
+        m := newMaze()
+        m.Load(got)
+        if _, err := m.Solve(context.Background()); err != nil {
+            t.Errorf("seed %d: hand drawn maze: %v", seed, err)
+        }
+    }
+}
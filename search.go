@@ -0,0 +1,61 @@
+/* search.go - Opening search display
+ *
+ * Searching for the best openings solves the maze once for every pair of columns with the display updates
+ * turned off, which can be the longest part of a run.  With the View display option it's instead woken a few
+ * times a second, without any per cell animation, to show the pair being tried as diamonds in the top and
+ * bottom walls and the best pair found so far in the solution color.
+ */
+package maze
+
+import (
+    "time"
+)
+
+const searchInterval = 250*time.Millisecond   // time between display updates while searching
+
+// searchState is the state of the opening search being displayed for a Maze
+type searchState struct {
+    searchShown   int32                     // set while the search is being displayed
+    searchStart   int32                     // columns of the openings being tried
+    searchFinish  int32
+    searchBestS   int32                     // columns of the best openings so far
+    searchBestF   int32
+    searchUpdated time.Time
+}
+
+// showSearch records the openings being tried and the best so far, and wakes the display if it's been
+// searchInterval since it was last woken by calling the update hook.  Unlike updateMaze it never sleeps.
+func (m *Maze) showSearch(start, finish, bestStart, bestFinish int) {
+    if !m.viewFlag {
+        return
+    }
+    setBool(&m.searchShown, true)
+    setInt(&m.searchStart , start     ); setInt(&m.searchFinish, finish    )
+    setInt(&m.searchBestS , bestStart ); setInt(&m.searchBestF , bestFinish)
+    if time.Since(m.searchUpdated) >= searchInterval && m.onUpdate != nil {
+        m.searchUpdated = time.Now()
+        m.onUpdate()
+    }
+}
+
+// endSearch stops showing the search
+func (m *Maze) endSearch() {
+    setBool(&m.searchShown, false)
+}
+
+// searchMarker returns 2 if location x, y is the best opening so far, 1 if it's the opening being tried and
+// 0 otherwise, or if the search isn't being shown
+func (m *Maze) searchMarker(x, y int) int {
+    if !getBool(&m.searchShown) || isOdd(y) || (x != 1 && x != getInt(&m.maxX) - 2) {
+        return 0
+    }
+    start, best := getInt(&m.searchStart), getInt(&m.searchBestS)
+    if x != 1 {
+        start, best = getInt(&m.searchFinish), getInt(&m.searchBestF)
+    }
+    switch y {
+        case best : return 2
+        case start: return 1
+    }
+    return 0
+}
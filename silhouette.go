@@ -0,0 +1,131 @@
+/* silhouette.go - Masks from silhouette images
+ *
+ * A mask can be a png, gif or jpeg image, a silhouette, rather than a text file, scaled to the size of the maze
+ * with its dark pixels left out.  The image is laid over white, so a transparent background is light, and split
+ * into dark and light by its brightness.  Every pixel is shared out among the cells it falls within by the area
+ * it covers, and a cell is dark once an eighth of it is, far short of the half that plain resampling would need,
+ * so the thin limbs of a silhouette still reach across the cells they pass through when the maze is small, down
+ * to a quarter of a cell thick where one falls between two rows or columns of cells.
+ * Inverted, the silhouette is the maze instead, and its thin limbs are kept as passages for the same reason.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "math"
+    "bytes"
+    "image"
+    _ "image/gif"
+)
+
+const silhouetteCover = 0.125       // the share of a cell that must be silhouette for it to be one
+
+// pixelShare is the share of a pixel falling within a cell, along one axis
+type pixelShare struct {
+    cell int
+    part float64
+}
+
+// pixelShares returns the shares of each of the pixels along an axis of the image among the cells along that axis,
+// the pixels being scaled to the cells
+func pixelShares(pixels, cells int) [][]pixelShare {
+    size   := float64(pixels)/float64(cells)    // pixels per cell
+    shares := make([][]pixelShare, pixels)
+    for p := range shares {
+        lo, hi := float64(p), float64(p + 1)
+        for c := int(lo/size); c < cells && float64(c)*size < hi; c++ {
+            if part := math.Min(hi, float64(c + 1)*size) - math.Max(lo, float64(c)*size); part > 0 {
+                shares[p] = append(shares[p], pixelShare{c, part/size})
+            }
+        }
+    }
+    return shares
+}
+
+// silhouette returns which pixels of the image are dark, laid over white and split at the brightness that best
+// separates the dark from the light
+func silhouette(img image.Image) *bitmap {
+    r := img.Bounds()
+    b := &bitmap{w: r.Dx(), h: r.Dy(), dark: make([]bool, r.Dx()*r.Dy())}
+    luma := make([]uint8, len(b.dark))
+    var hist [256]int
+    for y := 0; y < b.h; y++ {
+        for x := 0; x < b.w; x++ {
+            cr, cg, cb, ca := img.At(r.Min.X + x, r.Min.Y + y).RGBA()     // premultiplied, so adding the rest of
+            white := 0xffff - ca                                            // white puts them over a white page
+            k := y*b.w + x
+            luma[k] = uint8((299*(cr + white) + 587*(cg + white) + 114*(cb + white))/1000 >> 8)
+            hist[luma[k]]++
+        }
+    }
+    threshold := otsu(hist)
+    for k := range b.dark {
+        b.dark[k] = int(luma[k]) <= threshold
+    }
+    return b
+}
+
+// ParseImageMask reads a mask from a png, gif or jpeg silhouette scaled to height by width cells, a cell being left out
+// if an eighth or more of the image over it is dark.  The mask isn't checked, Check does that.
+func ParseImageMask(r io.Reader, height, width int) (*Mask, error) {
+    if height < 1 || height > maxHeight || width < 1 || width > maxWidth {
+        return nil, fmt.Errorf("invalid mask size %dx%d (height 1-%d, width 1-%d)", width, height, maxHeight, maxWidth)
+    }
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+    config, _, err := image.DecodeConfig(bytes.NewReader(data))
+    if err != nil {
+        return nil, err
+    }
+    if config.Width > MaxImageSide || config.Height > MaxImageSide {
+        return nil, fmt.Errorf("image of %dx%d pixels is over %d pixels wide or high", config.Width, config.Height, MaxImageSide)
+    }
+    img, _, err := image.Decode(bytes.NewReader(data))
+    if err != nil {
+        return nil, err
+    }
+    b := silhouette(img)
+    if b.w == 0 || b.h == 0 {
+        return nil, fmt.Errorf("image has no pixels")
+    }
+    cover := make([][]float64, height)
+    for row := range cover {
+        cover[row] = make([]float64, width)
+    }
+    rows, cols := pixelShares(b.h, height), pixelShares(b.w, width)
+    for y := 0; y < b.h; y++ {
+        for x := 0; x < b.w; x++ {
+            if !b.at(x, y) {
+                continue
+            }
+            for _, rs := range rows[y] {
+                for _, cs := range cols[x] {
+                    cover[rs.cell][cs.cell] += rs.part*cs.part
+                }
+            }
+        }
+    }
+    k := &Mask{height: height, width: width, excluded: make([][]bool, height)}
+    for row := range cover {
+        k.excluded[row] = make([]bool, width)
+        for col, c := range cover[row] {
+            k.excluded[row][col] = c >= silhouetteCover
+        }
+    }
+    return k, nil
+}
+
+// Inverted returns the mask with the cells it leaves out and those it keeps swapped
+func (k *Mask) Inverted() *Mask {
+    i := &Mask{height: k.height, width: k.width, excluded: make([][]bool, k.height)}
+    for row := range k.excluded {
+        i.excluded[row] = make([]bool, k.width)
+        for col, out := range k.excluded[row] {
+            i.excluded[row][col] = !out
+        }
+    }
+    return i
+}
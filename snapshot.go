@@ -0,0 +1,49 @@
+/* snapshot.go - ANSI colored snapshots
+ *
+ * Writes the maze as the terminal display draws it, colors and all, to a file that can be shown again later with
+ * cat.  The VT100 line drawing characters are turned into UTF-8 box drawing characters and the cursor positioning
+ * is left out, the rows ending in newlines, so the snapshot draws correctly wherever the terminal is, scrolled
+ * along with everything else.  It ends with a reset and the stats as a plain line.
+ */
+package maze
+
+import (
+    "io"
+    "bytes"
+    "unicode/utf8"
+)
+
+// RenderANSISnapshot writes the maze to w as RenderANSI draws it with opts, less the margin, in box drawing
+// characters and without cursor positioning, followed by a reset and a line of the stats.  It returns the first error
+// from w.
+func (m *Maze) RenderANSISnapshot(w io.Writer, opts RenderOptions, stats Stats) error {
+    var frame bytes.Buffer
+    opts.Margin = 0
+    m.RenderANSI(&frame, opts)
+    src  := frame.Bytes()
+    line := make([]byte, 0, 4*len(src))
+    for k := 0; k < len(src); k++ {
+        switch c := src[k]; {
+            case c == '\033' && k+1 < len(src) && src[k+1] == '(':     // selecting the line drawing characters
+                k += 2
+            case c == '\033' && k+1 < len(src) && src[k+1] == '[':     // a control sequence, kept unless it moves the cursor
+                end := k + 2
+                for end < len(src) && (src[end] < 0x40 || src[end] > 0x7e) {
+                    end++
+                }
+                if end < len(src) && src[end] != 'H' {
+                    line = append(line, src[k:end+1]...)
+                }
+                k = end
+            case c < 128 && lineRunes[LightLines][c] != 0:
+                line = utf8.AppendRune(line, lineRunes[LightLines][c])
+            default:
+                line = append(line, c)
+        }
+    }
+    line = append(line, "\033[0m"...)
+    line = append(line, FormatStats(stats, ", ", true)...)
+    line = append(line, '\n')
+    _, err := w.Write(line)
+    return err
+}
@@ -0,0 +1,106 @@
+/* solution.go - Solution route
+ *
+ * Solve returns the solution as the cells along it, in logical cell coordinates rather than maze array
+ * locations, from the entrance to the exit.  The length and turn count of the solution are measured along
+ * the same route, rather than kept by counters the solving threads share, and SolutionCells iterates over
+ * it without building the slice.
+ */
+package maze
+
+import (
+    "errors"
+    "iter"
+)
+
+// Errors returned by Solve when there's no solution to return
+var (
+    ErrUnsolvable      = errors.New("no route between the entrance and the exit")
+    ErrBudgetExhausted = errors.New("solver step budget exhausted")
+    ErrNoMaze          = errors.New("no maze to solve, it must be generated or loaded first")
+)
+
+// Point is a location in logical cell coordinates, X being the row from 0 at the top and Y the column from 0 at the
+// left.  The entrance is at row -1, just above the maze, and the exit at the row the height of the maze, just below it.
+type Point struct {
+    X, Y int
+}
+
+// routeComplete returns true if a solution route runs from the entrance to the exit, through any cell of the gap
+func (m *Maze) routeComplete(route []point) bool {
+    return len(route) > 0 && route[len(route) - 1].x == getInt(&m.endX) + 1
+}
+
+// routePoints returns the cells along a complete solution route, with the entrance and exit at either end
+func (m *Maze) routePoints(route []point) []Point {
+    points := make([]Point, 0, (len(route) + 1)/2)
+    for i, p := range route {
+        switch {
+            case i == 0                    : points = append(points, Point{-1, p.y/2 - 1})
+            case i == len(route) - 1       : points = append(points, Point{m.height, p.y/2 - 1})
+            case isEven(p.x) && isEven(p.y): c := cellAt(p.x, p.y); points = append(points, Point{c.row, c.col})
+        }
+    }
+    return points
+}
+
+// markedSolution returns an iterator over the solution marked in the maze array, from the entrance to the exit, which
+// yields nothing if the marked cells don't reach the exit
+func (m *Maze) markedSolution() iter.Seq[Point] {
+    return func(yield func(Point) bool) {
+        if getInt(&m.maxX) == 0 {
+            return
+        }
+        if route := m.solutionRoute(); m.routeComplete(route) {
+            for _, p := range m.routePoints(route) {
+                if !yield(p) {
+                    return
+                }
+            }
+        }
+    }
+}
+
+// solvedLength returns the number of cells inside the maze along the solution marked in the maze array, 0 if there's
+// none, counted from the cells themselves rather than the solvers' shared counter.  It's the cells routePoints would
+// return but for the entrance and exit, counted as the route is walked.
+func (m *Maze) solvedLength() int {
+    if getInt(&m.maxX) == 0 {
+        return 0
+    }
+    cells, steps, first, last := 0, 0, false, point{}
+    m.walkSolution(func(p point) {
+        if steps == 0 {
+            first = isEven(p.x) && isEven(p.y)
+        }
+        cells, steps, last = cells + bool2int(isEven(p.x) && isEven(p.y)), steps + 1, p
+    })
+    if steps < 2 || last.x != getInt(&m.endX) + 1 {
+        return 0
+    }
+    return cells - bool2int(first) - bool2int(isEven(last.x) && isEven(last.y))
+}
+
+// SolutionCells returns an iterator over the cells of the solution from the entrance, at row -1, to the exit, at the row
+// the height of the maze.  The solution is the one marked in the maze, by solving it or in a solved file loaded, or else
+// the one saved by SaveSolution, and there's nothing to iterate over if neither reaches the exit.  A loaded maze that hasn't
+// been solved or generated yet is installed first, as Solve does.
+func (m *Maze) SolutionCells() iter.Seq[Point] {
+    return func(yield func(Point) bool) {
+        if getInt(&m.maxX) == 0 && m.loaded != nil {
+            m.installMaze(m.loaded)
+        }
+        marked := false
+        for p := range m.markedSolution() {
+            if marked = true; !yield(p) {
+                return
+            }
+        }
+        if !marked {
+            for _, p := range m.routePoints(m.getSolution()) {
+                if !yield(p) {
+                    return
+                }
+            }
+        }
+    }
+}
@@ -0,0 +1,80 @@
+/* source.go - Go and C source code of the maze
+ *
+ * Writes the maze as a source file for a program to build in, firmware or a game say: an array of the json
+ * format's wall mask of each cell, a row at a time, and constants for the size and the cells under the entrance
+ * and over the exit.  The Go is laid out as gofmt lays it out, and the C, which may be included as a header,
+ * declares the array static and marks it as maybe unused so it compiles cleanly with -Wall.  The seed goes in
+ * a comment to trace the array back to the maze it came from.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// sourceOrigin returns where the maze came from for the comment of a source file
+func (m *Maze) sourceOrigin() string {
+    if m.loaded != nil {
+        return fmt.Sprintf("a %dx%d maze, loaded from a file", m.width, m.height)
+    }
+    return fmt.Sprintf("a %dx%d maze generated with seed %d", m.width, m.height, m.getSeed())
+}
+
+// sourceRows returns the wall masks of each row of cells as a braced, comma separated list
+func (m *Maze) sourceRows() []string {
+    rows := make([]string, m.height)
+    for i := 2; i <= 2*m.height; i += 2 {
+        masks := make([]string, m.width)
+        for j := 2; j <= 2*m.width; j += 2 {
+            masks[j/2 - 1] = strconv.Itoa(m.wallMask(i, j))
+        }
+        rows[i/2 - 1] = "{" + strings.Join(masks, ", ") + "}"
+    }
+    return rows
+}
+
+// RenderGoSource writes the maze to w as a Go source file of package maze declaring the wall masks as Maze, a
+// [][]uint8 indexed by row then column, returning the first error from w.  The corridors aren't widened.
+func (m *Maze) RenderGoSource(w io.Writer) error {
+    ew := &errWriter{w: w}
+    beg, end := cellAt(getInt(&m.begX), getInt(&m.begY)), cellAt(getInt(&m.endX), getInt(&m.endY))
+    fmt.Fprintf(ew, "// Code generated by maze %s. DO NOT EDIT.\n\n", Version)
+    fmt.Fprintf(ew, "// Package maze holds %s.\npackage maze\n\n", m.sourceOrigin())
+    fmt.Fprintf(ew, "// Bits of a cell's wall mask, set for each side of the cell with a wall\n")
+    fmt.Fprintf(ew, "const (\n\tWallNorth = %d\n\tWallSouth = %d\n\tWallEast  = %d\n\tWallWest  = %d\n)\n\n", WallNorth, WallSouth, WallEast, WallWest)
+    fmt.Fprintf(ew, "// The size of the maze in cells, and the cells under the entrance and over the exit\n")
+    fmt.Fprintf(ew, "const (\n\tWidth    = %d\n\tHeight   = %d\n\tStartRow = %d\n\tStartCol = %d\n\tEndRow   = %d\n\tEndCol   = %d\n)\n\n",
+                m.width, m.height, beg.row, beg.col, end.row, end.col)
+    fmt.Fprintf(ew, "// Maze is the wall mask of each cell, indexed by row then column\nvar Maze = [][]uint8{\n")
+    for _, row := range m.sourceRows() {
+        fmt.Fprintf(ew, "\t%s,\n", row)
+    }
+    fmt.Fprintf(ew, "}\n")
+    return ew.err
+}
+
+// RenderCSource writes the maze to w as a C source file declaring the wall masks as maze, a static const
+// uint8_t maze[MAZE_HEIGHT][MAZE_WIDTH], returning the first error from w.  The corridors aren't widened.
+func (m *Maze) RenderCSource(w io.Writer) error {
+    ew := &errWriter{w: w}
+    beg, end := cellAt(getInt(&m.begX), getInt(&m.begY)), cellAt(getInt(&m.endX), getInt(&m.endY))
+    fmt.Fprintf(ew, "/* Generated by maze %s, do not edit.\n *\n * Holds %s.\n */\n", Version, m.sourceOrigin())
+    fmt.Fprintf(ew, "#ifndef MAZE_DATA_H\n#define MAZE_DATA_H\n\n#include <stdint.h>\n\n")
+    fmt.Fprintf(ew, "/* Bits of a cell's wall mask, set for each side of the cell with a wall */\n")
+    fmt.Fprintf(ew, "#define MAZE_WALL_NORTH %d\n#define MAZE_WALL_SOUTH %d\n#define MAZE_WALL_EAST  %d\n#define MAZE_WALL_WEST  %d\n\n",
+                WallNorth, WallSouth, WallEast, WallWest)
+    fmt.Fprintf(ew, "/* The size of the maze in cells, and the cells under the entrance and over the exit */\n")
+    fmt.Fprintf(ew, "#define MAZE_WIDTH     %d\n#define MAZE_HEIGHT    %d\n#define MAZE_START_ROW %d\n#define MAZE_START_COL %d\n" +
+                    "#define MAZE_END_ROW   %d\n#define MAZE_END_COL   %d\n\n", m.width, m.height, beg.row, beg.col, end.row, end.col)
+    fmt.Fprintf(ew, "#if defined(__GNUC__)\n#define MAZE_UNUSED __attribute__((unused))\n#else\n#define MAZE_UNUSED\n#endif\n\n")
+    fmt.Fprintf(ew, "/* The wall mask of each cell, indexed by row then column */\n")
+    fmt.Fprintf(ew, "static const uint8_t maze[MAZE_HEIGHT][MAZE_WIDTH] MAZE_UNUSED = {\n")
+    for _, row := range m.sourceRows() {
+        fmt.Fprintf(ew, "    %s,\n", row)
+    }
+    fmt.Fprintf(ew, "};\n\n#endif\n")
+    return ew.err
+}
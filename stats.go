@@ -0,0 +1,109 @@
+/* stats.go - Generation statistics
+ *
+ * The statistics shown on the display's status line, kept apart from the rendering so that the same
+ * values can be written as a machine readable record when the maze is complete, and the measures of the
+ * finished maze recorded with them.
+ */
+package maze
+
+import (
+    "fmt"
+    "time"
+    "strings"
+    "sync/atomic"
+    "github.com/Starfleet2/maze/internal/human"
+)
+
+// Stat is a named generation statistic, formatted for people according to its kind
+type Stat struct {
+    Name  string
+    Value int
+    Kind  int
+}
+
+// Stats are the generation statistics in status line order, as passed to a Renderer with each frame
+type Stats []Stat
+
+// Stat kinds
+const (
+    PlainStat    = iota                  // shown as is: sizes, seeds, thread counts
+    CountStat                            // shown abbreviated, e.g. 12.3k
+    DurationStat                         // milliseconds, shown as a duration without the _ms suffix, e.g. 1m23.4s
+)
+
+// statsState holds the times of a Maze that aren't counted by the generator
+type statsState struct {
+    runStart     time.Time                   // when the Maze was made
+    genElapsed   time.Duration               // time taken to carve and to solve the final maze
+    solveElapsed time.Duration
+    generated    int64                       // when the last maze was generated in unix nanoseconds, for the ascii format's parameters
+}
+
+func (m *Maze) setGenerated(t time.Time) {; atomic.StoreInt64(&m.generated, t.UnixNano()); }
+func (m *Maze) getGenerated() int64      {; return atomic.LoadInt64(&m.generated);          }
+
+// generationStats returns the current generation statistics in status line order
+func (m *Maze) generationStats() Stats {
+    stats := Stats {
+        {"height"          , m.height                                                           , PlainStat   },
+        {"width"           , m.width                                                            , PlainStat   },
+        {"seed"            , int(m.getSeed())                                                   , PlainStat   },
+        {"num_wall_push"   , getInt(&m.numWallPush     )                                        , CountStat   },
+        {"num_maze_created", getInt(&m.numMazeCreated  )                                        , CountStat   },
+        {"num_solves"      , getInt(&m.numSolves       )                                        , CountStat   },
+        {"avg_solve_length", getInt(&m.sumsolveLength  ) / nonZero(getInt(&m.numMazeCreated  )) , CountStat   },
+        {"solve_length"    , getInt(&m.solveLength     )                                        , CountStat   },
+        {"avg_path_length" , getInt(&m.mazeLen         ) / nonZero(getInt(&m.numPaths        )) , CountStat   },
+        {"num_paths"       , getInt(&m.numPaths        )                                        , CountStat   },
+        {"maze_len"        , getInt(&m.mazeLen         )                                        , CountStat   },
+        {"threads"         , getInt(&m.numThreads      )                                        , PlainStat   },
+        {"carve_threads"   , m.carveThreads                                                     , PlainStat   },
+        {"solve_threads"   , m.solveThreads                                                     , PlainStat   },
+        {"length"          , getInt(&m.dspLength       )                                        , CountStat   },
+        {"checks"          , getInt(&m.dspNumChecks    )                                        , CountStat   },
+        {"max_checks"      , getInt(&m.maxChecks       )                                        , CountStat   },
+        {"checks_exceeded" , getInt(&m.numCheckExceeded)                                        , CountStat   },
+        {"stale_checks"    , getInt(&m.numStaleChecks  )                                        , CountStat   },
+        {"elapsed_ms"      , int(time.Since(m.runStart).Milliseconds())                         , DurationStat},
+    }
+    if m.handedFlag {
+        stats = append(stats, Stat{"left_hand_visited", getInt(&m.leftVisited), CountStat}, Stat{"right_hand_visited", getInt(&m.rightVisited), CountStat})
+    }
+    return stats
+}
+
+// FormatStats returns the statistics as name=value pairs joined by sep, with raw values unless readable is set
+func FormatStats(stats []Stat, sep string, readable bool) string {
+    fields := make([]string, len(stats))
+    for i, s := range stats {
+        switch {
+            case !readable || s.Kind == PlainStat: fields[i] = fmt.Sprintf("%s=%d", s.Name, s.Value)
+            case s.Kind == CountStat          : fields[i] = fmt.Sprintf("%s=%s", s.Name, human.Count(s.Value))
+            default                           : fields[i] = fmt.Sprintf("%s=%s", strings.TrimSuffix(s.Name, "_ms"), human.Duration(time.Duration(s.Value) * time.Millisecond))
+        }
+    }
+    return strings.Join(fields, sep)
+}
+
+// countTurns returns the number of changes of direction along a route
+func countTurns(route []point) int {
+    turns := 0
+    for i := 2; i < len(route); i++ {
+        dx1, dy1 := route[i-1].x - route[i-2].x, route[i-1].y - route[i-2].y
+        dx2, dy2 := route[i  ].x - route[i-1].x, route[i  ].y - route[i-1].y
+        turns += bool2int(dx1 != dx2 || dy1 != dy2)
+    }
+    return turns
+}
+
+// countDegrees returns the number of dead end cells and of junction cells, those with three or more passages
+func (m *Maze) countDegrees() (deadEnds, junctions int) {
+    m.cells(func(c cell, state int) bool {
+        switch n := m.degree(c.loc()); {
+            case n == 1: deadEnds++
+            case n >= 3: junctions++
+        }
+        return true
+    })
+    return deadEnds, junctions
+}
@@ -0,0 +1,123 @@
+/* stream.go - Streaming huge mazes
+ *
+ * Writes a maze far too large for the maze array, 10,000 by 10,000 cells say, in the ascii format one row at a
+ * time as it's carved, holding only the row being carved and the one above it.  That only works for an algorithm
+ * that finishes each row before starting the next and never returns to it, which the look ahead carver and the
+ * backtracker don't, so the stream is carved with the sidewinder algorithm: each row is split into runs of cells
+ * joined east to west, and each run is joined to the row above through one of its cells chosen at random, the top
+ * row being a single run.  Nothing is solved, since the solution isn't known until the last row is carved.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "time"
+    "strings"
+    "math/rand"
+)
+
+// Limits and algorithms of the stream
+const (
+    MaxStreamWidth  = 100000
+    MaxStreamHeight = 100000
+)
+
+// streamAlgorithms are the algorithms that carve row by row, which are all that can be streamed
+var streamAlgorithms = []string{"sidewinder"}
+
+// StreamOptions chooses the maze RenderStream writes
+type StreamOptions struct {
+    Width, Height int                           // the size of the maze in cells, up to MaxStreamWidth by MaxStreamHeight
+    Seed          int64                         // the seed of the random number source, 0 for one from the clock
+    Algorithm     string                        // a row by row algorithm, "" for sidewinder
+}
+
+// Check returns an error if the size is out of range or the algorithm doesn't carve row by row
+func (o StreamOptions) Check() error {
+    switch {
+        case o.Width < 1 || o.Width > MaxStreamWidth || o.Height < 1 || o.Height > MaxStreamHeight:
+            return fmt.Errorf("stream size %dx%d is outside 1x1 to %dx%d", o.Width, o.Height, MaxStreamWidth, MaxStreamHeight)
+        case o.Algorithm != "" && o.Algorithm != streamAlgorithms[0]:
+            return fmt.Errorf("algorithm %q can't be streamed, only a row by row algorithm can (%s)", o.Algorithm, strings.Join(streamAlgorithms, ", "))
+    }
+    return nil
+}
+
+// streamRow is a row of the maze being streamed: whether each cell has a wall to its east, the last always having the
+// border, and whether it has one to its north
+type streamRow struct {
+    east, north []bool
+}
+
+// RenderStream generates a maze with the options and writes it to w in the ascii format as it's carved, its
+// parameters first, returning an error if the options are out of range or the first error from w.  Its memory use
+// grows with the width of the maze but not with its height.
+func RenderStream(w io.Writer, opts StreamOptions) error {
+    if err := opts.Check(); err != nil {
+        return err
+    }
+    seed := opts.Seed
+    if seed == 0 {
+        seed = time.Now().UnixNano()
+    }
+    rng := rand.New(rand.NewSource(seed))
+    ew  := &errWriter{w: w}
+    fmt.Fprintf(ew, "# version=%s\n# algorithm=%s\n# seed=%d\n%d %d\n", Version, streamAlgorithms[0], seed, opts.Height, opts.Width)
+
+    width := opts.Width
+    line  := make([]byte, 2*width + 2)          // the characters of a line of the ascii format, with its newline
+    border := func(open int) []bool {           // the top or bottom border, open above or below one cell
+        walls := make([]bool, width)
+        for c := range walls {
+            walls[c] = c != open
+        }
+        return walls
+    }
+    writeWalls := func(above, row *streamRow, north []bool) {     // the wall line between two rows, or a border
+        for k := 0; k <= width; k++ {
+            up    := above != nil && (k == 0 || k == width || above.east[k - 1])
+            down  := row   != nil && (k == 0 || k == width || row.east[k - 1])
+            left  := k > 0     && north[k - 1]
+            right := k < width && north[k]
+            line[2*k] = byte(simpleLookup[1*bool2int(up) + 2*bool2int(right) + 4*bool2int(down) + 8*bool2int(left)])
+            if k < width {
+                line[2*k + 1] = " -"[bool2int(north[k])]
+            }
+        }
+        line[2*width + 1] = '\n'
+        ew.Write(line)
+    }
+    writeCells := func(row *streamRow) {
+        line[0] = '|'
+        for c := 0; c < width; c++ {
+            line[2*c + 1], line[2*c + 2] = ' ', " |"[bool2int(row.east[c])]
+        }
+        line[2*width + 1] = '\n'
+        ew.Write(line)
+    }
+
+    above, row := &streamRow{make([]bool, width), make([]bool, width)}, &streamRow{make([]bool, width), make([]bool, width)}
+    for r := 0; r < opts.Height && ew.err == nil; r++ {
+        start := 0                                                  // the first cell of the run being carved
+        for c := 0; c < width; c++ {
+            row.north[c] = true
+            closed := c == width - 1 || r > 0 && rng.Intn(2) == 0   // the top row is a single run
+            row.east[c] = closed
+            if closed && r > 0 {
+                row.north[start + rng.Intn(c - start + 1)] = false
+            }
+            if closed {
+                start = c + 1
+            }
+        }
+        switch r {
+            case 0 : writeWalls(nil, row, border(rng.Intn(width)))
+            default: writeWalls(above, row, row.north)
+        }
+        writeCells(row)
+        above, row = row, above
+    }
+    writeWalls(above, nil, border(rng.Intn(width)))
+    return ew.err
+}
@@ -0,0 +1,71 @@
+/* stream_test.go - Streaming tests
+ *
+ * Reads small streamed mazes back as perfect mazes written as the ascii writer writes them, and streams a 10,000
+ * by 10,000 maze checking with runtime.MemStats that it's written in a few megabytes rather than the gigabytes the
+ * whole maze would take.
+ */
+package maze
+
+import (
+    "io"
+    "bytes"
+    "context"
+    "runtime"
+    "testing"
+)
+
+func TestStreamPerfect(t *testing.T) {
+    for _, size := range [][2]int{{1, 1}, {1, 6}, {7, 1}, {12, 8}, {19, 10}, {300, 100}} {
+        for seed := int64(1); seed <= 3; seed++ {
+            var b bytes.Buffer
+            if err := RenderStream(&b, StreamOptions{Width: size[0], Height: size[1], Seed: seed}); err != nil {
+                t.Fatal(err)
+            }
+            var m Maze
+            if err := m.UnmarshalText(b.Bytes()); err != nil {
+                t.Fatalf("%dx%d seed %d: %v\n%s", size[0], size[1], seed, err, b.Bytes())
+            }
+            if got := marshalText(t, &m); !bytes.Equal(got, b.Bytes()) {
+                t.Fatalf("%dx%d seed %d: written back differently:\n%s\nstreamed:\n%s", size[0], size[1], seed, got, b.Bytes())
+            }
+            if got, want := m.Passages(), size[0]*size[1] - 1; got != want {
+                t.Errorf("%dx%d seed %d: %d passages, want %d for a perfect maze", size[0], size[1], seed, got, want)
+            }
+            if _, err := m.Solve(context.Background()); err != nil {
+                t.Errorf("%dx%d seed %d: %v", size[0], size[1], seed, err)
+            }
+        }
+    }
+}
+
+func TestStreamCheck(t *testing.T) {
+    for _, opts := range []StreamOptions{
+        {Width: 10, Height: 10, Algorithm: DefaultAlgorithm},
+        {Width: 10, Height: 10, Algorithm: "backtrack"},
+        {Width: 0, Height: 10},
+        {Width: 10, Height: MaxStreamHeight + 1},
+    } {
+        if err := RenderStream(io.Discard, opts); err == nil {
+            t.Errorf("%+v: no error", opts)
+        }
+    }
+}
+
+// TestStreamMemory streams a 10,000 by 10,000 maze, 400MB of ascii, and checks that the allocations made writing it,
+// and so its peak, stay within a few megabytes
+func TestStreamMemory(t *testing.T) {
+    if testing.Short() {
+        t.Skip("streams 400MB")
+    }
+    const limit = 8 << 20
+    var before, after runtime.MemStats
+    runtime.GC()
+    runtime.ReadMemStats(&before)
+    if err := RenderStream(io.Discard, StreamOptions{Width: 10000, Height: 10000, Seed: 1}); err != nil {
+        t.Fatal(err)
+    }
+    runtime.ReadMemStats(&after)
+    if allocated := after.TotalAlloc - before.TotalAlloc; allocated > limit {
+        t.Errorf("streaming a 10,000 by 10,000 maze allocated %d bytes, over %d", allocated, limit)
+    }
+}
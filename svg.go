@@ -0,0 +1,89 @@
+/* svg.go - SVG drawings of the maze
+ *
+ * Draws the maze as vector lines for printing and web pages, in two layers that an editor can show or hide
+ * separately: the walls, merged into one line for each straight run of wall along a grid line, and the part
+ * of the solution marked in the maze as a polyline from the entrance.  Tried cells aren't drawn.  The walls may
+ * be drawn thicker for printing a large maze small, up to leaving a unit of the passages between them open.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "strings"
+)
+
+// Sizes of the svg drawing, in its user units, which are drawn as pixels
+const (
+    svgCell   = 10                                  // from one grid line to the next
+    svgMargin = svgCell
+    svgWall   = 2                                   // stroke width of the walls and the solution
+)
+
+// SVGOptions chooses how RenderSVG draws the maze
+type SVGOptions struct {
+    WallPx int                                      // stroke width of the walls, 0 for 2, less than the 10 of a cell
+}
+
+// Check returns an error if the options are out of range
+func (o SVGOptions) Check() error {
+    if o.WallPx < 0 || o.WallPx >= svgCell {
+        return fmt.Errorf("svg wall thickness %d is outside 0 to %d pixels, less than the cell size", o.WallPx, svgCell - 1)
+    }
+    return nil
+}
+
+// svgCoord returns the user unit coordinate of maze array location x: a grid line for a wall location, the middle
+// between two grid lines for a cell
+func svgCoord(x int) int {
+    return (x - 1)*svgCell/2
+}
+
+// RenderSVG writes the maze to w as an svg drawing, returning an error if the options are out of range or the first
+// error from w
+func (m *Maze) RenderSVG(w io.Writer, opts SVGOptions) error {
+    if err := opts.Check(); err != nil {
+        return err
+    }
+    m = m.widened()
+    ew := &errWriter{w: w}
+    m.writeSVG(ew, opts)
+    return ew.err
+}
+
+// writeSVG writes the svg drawing: the walls as runs along the horizontal grid lines and then the vertical ones,
+// and the solution from the entrance, reaching out of the maze through the openings.  The solution is no wider than
+// the passages left between the walls.
+func (m *Maze) writeSVG(w io.Writer, opts SVGOptions) {
+    width, height := m.width*svgCell, m.height*svgCell
+    wall := svgWall
+    if opts.WallPx > 0 {
+        wall = opts.WallPx
+    }
+    fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+    fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" xmlns:inkscape=\"http://www.inkscape.org/namespaces/inkscape\" " +
+                   "width=\"%d\" height=\"%d\" viewBox=\"%d %d %d %d\">\n",
+                   width + 2*svgMargin, height + 2*svgMargin, -svgMargin, -svgMargin, width + 2*svgMargin, height + 2*svgMargin)
+    fmt.Fprintf(w, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"white\"/>\n", -svgMargin, -svgMargin, width + 2*svgMargin, height + 2*svgMargin)
+
+    var runs strings.Builder
+    m.wallRuns(func(from, to point) {
+        if from.x == to.x {
+            fmt.Fprintf(&runs, "M%d %dh%d\n", svgCoord(from.y), svgCoord(from.x), svgCoord(to.y) - svgCoord(from.y))
+        } else {
+            fmt.Fprintf(&runs, "M%d %dv%d\n", svgCoord(from.y), svgCoord(from.x), svgCoord(to.x) - svgCoord(from.x))
+        }
+    })
+    fmt.Fprintf(w, "<g id=\"walls\" inkscape:groupmode=\"layer\" inkscape:label=\"walls\" stroke=\"black\" stroke-width=\"%d\" stroke-linecap=\"square\" fill=\"none\">\n", wall)
+    fmt.Fprintf(w, "<path d=\"%s\"/>\n</g>\n", strings.TrimSuffix(runs.String(), "\n"))
+
+    fmt.Fprintf(w, "<g id=\"solution\" inkscape:groupmode=\"layer\" inkscape:label=\"solution\" stroke=\"#d02020\" stroke-width=\"%d\" stroke-linejoin=\"round\" fill=\"none\">\n", min(svgWall, svgCell - wall))
+    if line := m.solutionLine(m.solutionRoute()); line != nil {
+        var points []string
+        for _, p := range line {
+            points = append(points, fmt.Sprintf("%d,%d", svgCoord(p.y), svgCoord(p.x)))
+        }
+        fmt.Fprintf(w, "<polyline points=\"%s\"/>\n", strings.Join(points, " "))
+    }
+    fmt.Fprintf(w, "</g>\n</svg>\n")
+}
@@ -0,0 +1,49 @@
+/* text.go - The maze as text
+ *
+ * Makes a Maze an encoding.TextMarshaler and TextUnmarshaler in the portable ascii format, its parameters
+ * written ahead of it, so that a maze can be kept in the json or yaml configuration of another program and go
+ * through the standard encoders.  The text is always plain ascii, whatever character map or line style the
+ * maze is displayed with, and is read back as strictly as ParseASCII reads a file.
+ */
+package maze
+
+import (
+    "bytes"
+)
+
+// MarshalText returns the maze in the portable ascii format with its parameters, as RenderASCII writes it without a
+// character map, box drawing, block walls, branch markers or opening markers.  The same maze always gives the same
+// text.  It returns ErrNoMaze if the maze hasn't been generated or loaded.
+func (m *Maze) MarshalText() ([]byte, error) {
+    if getInt(&m.maxX) == 0 && m.loaded == nil {
+        return nil, ErrNoMaze
+    }
+    m = m.Clone()
+    if getInt(&m.maxX) == 0 {                       // loaded but not yet solved
+        m.installMaze(m.loaded)
+    }
+    m.charMap, m.boxDrawing, m.lineStyle            = CharMap{}, false, LightLines
+    m.blockWalls, m.markersFlag, m.markOpenings     = false, false, false
+    var b bytes.Buffer
+    if err := m.RenderASCII(&b); err != nil {
+        return nil, err
+    }
+    return b.Bytes(), nil
+}
+
+// UnmarshalText reads the maze from text in the portable ascii format, as ParseASCII does, and loads it in place of
+// any maze generated, a maze that Generate or Solve then solves.  The maze takes the size read and any corridor
+// width or mask is cleared, the text being the maze as drawn.  The zero Maze may be unmarshalled into.
+func (m *Maze) UnmarshalText(text []byte) error {
+    l, err := ParseASCII(string(text))
+    if err != nil {
+        return err
+    }
+    if m.rng == nil {
+        m.init()
+    }
+    m.cfg.corridor, m.corridor, m.cfg.mask, m.mask = 1, 1, nil, nil
+    m.Load(l)
+    m.installMaze(l)
+    return nil
+}
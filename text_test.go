@@ -0,0 +1,117 @@
+/* text_test.go - Text marshalling tests
+ *
+ * Marshals mazes to text and back, directly and inside json, checks that bad text is refused, and fuzzes
+ * UnmarshalText, which reads whatever another program's configuration holds.
+ */
+package maze
+
+import (
+    "os"
+    "bytes"
+    "testing"
+    "encoding/json"
+    "path/filepath"
+)
+
+// marshalText returns the maze marshalled to text
+func marshalText(t testing.TB, m *Maze) []byte {
+    t.Helper()
+    text, err := m.MarshalText()
+    if err != nil {
+        t.Fatal(err)
+    }
+    return text
+}
+
+func TestMarshalText(t *testing.T) {
+    m := goldenMaze(t, WithSize(19, 10), WithSeed(1), WithDepth(5))
+    m.cfg.text.BoxDrawing, m.boxDrawing = true, true
+    text := marshalText(t, m)
+    m.cfg.text.BoxDrawing, m.boxDrawing = false, false
+    if want := renderASCII(t, m); !bytes.Equal(text, want) {
+        t.Errorf("text differs from the ascii output:\n%s\nwant:\n%s", text, want)
+    }
+    if again := marshalText(t, m); !bytes.Equal(again, text) {
+        t.Errorf("second marshal differs:\n%s\nfirst:\n%s", again, text)
+    }
+
+    var u Maze
+    if err := u.UnmarshalText(text); err != nil {
+        t.Fatal(err)
+    }
+    if h, w := u.loaded.Size(); u.cfg.height != 10 || u.cfg.width != 19 || h != 10 || w != 19 {
+        t.Errorf("unmarshalled maze is %dx%d, want 19x10", u.cfg.width, u.cfg.height)
+    }
+    if got := marshalText(t, &u); !bytes.Equal(got, text) {
+        t.Errorf("round trip differs:\n%s\nwant:\n%s", got, text)
+    }
+}
+
+func TestMarshalTextJSON(t *testing.T) {
+    type config struct {
+        Name string
+        Maze *Maze
+    }
+    in := config{"level 1", goldenMaze(t, WithSize(12, 8), WithSeed(42))}
+    data, err := json.Marshal(in)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var out config
+    if err := json.Unmarshal(data, &out); err != nil {
+        t.Fatal(err)
+    }
+    if got, want := marshalText(t, out.Maze), marshalText(t, in.Maze); out.Name != in.Name || !bytes.Equal(got, want) {
+        t.Errorf("json round trip gave %q and:\n%s\nwant:\n%s", out.Name, got, want)
+    }
+}
+
+func TestMarshalTextNoMaze(t *testing.T) {
+    if _, err := newMaze().MarshalText(); err != ErrNoMaze {
+        t.Errorf("ungenerated maze: error %v, want %v", err, ErrNoMaze)
+    }
+}
+
+func TestUnmarshalTextStrict(t *testing.T) {
+    for _, text := range []string{
+        "",
+        "1 1\n",
+        "0 1\n+-+\n| |\n+ +\n",
+        "1 1\n+ +\n|x|\n+ +\n",
+        "1 1\n+ +\n| |\n+-+\n",
+        "1 2\n+ +-+\n|   |\n",
+        "1 1\n+ +\n| \n+ +\n",
+    } {
+        var m Maze
+        if err := m.UnmarshalText([]byte(text)); err == nil {
+            t.Errorf("%q: no error", text)
+        }
+    }
+}
+
+// FuzzUnmarshalText checks that no text makes UnmarshalText panic, and that any it accepts marshals to text that
+// reads back the same
+func FuzzUnmarshalText(f *testing.F) {
+    names, _ := filepath.Glob(filepath.Join("testdata", "golden", "*.txt"))
+    for _, name := range names {
+        if text, err := os.ReadFile(name); err == nil {
+            f.Add(text)
+        }
+    }
+    f.Add([]byte("1 1\n+ +\n| |\n+ +\n"))
+    f.Add([]byte("# key=x\n2 1\n+ +\n|*|\n+ +\n| |\n+ +\n"))
+    f.Fuzz(func(t *testing.T, text []byte) {
+        var m Maze
+        if m.UnmarshalText(text) != nil {
+            return
+        }
+        first := marshalText(t, &m)
+        var again Maze
+        if err := again.UnmarshalText(first); err != nil {
+            t.Fatalf("marshalled text doesn't read back: %v\n%s", err, first)
+        }
+        if second := marshalText(t, &again); !bytes.Equal(second, first) {
+            t.Fatalf("marshalled text changed reading it back:\n%s\nfirst:\n%s", second, first)
+        }
+    })
+}
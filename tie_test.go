@@ -0,0 +1,79 @@
+/* tie_test.go - Opening tie-break tests
+ *
+ * Searches mazes whose best openings tie, on a seed where two pairs of openings have the same solution length and
+ * turn count and only the junctions along them tell them apart, and on a corridor where every rule ties but the
+ * columns, and checks that the search picks the pair betterOpening ranks first whatever order the pairs are in.
+ */
+package maze
+
+import (
+    "slices"
+    "testing"
+)
+
+// searchOpenings clears the solution and openings of m, leaving it as carved, then returns every pair of openings
+// scored as searchBestOpenings scores them, and the pair it chooses
+func searchOpenings(m *Maze) ([]openingScore, int, int) {
+    m.restoreMaze()
+    for j := 1; j < getInt(&m.maxY) - 1; j++ {
+        m.setMaze(getInt(&m.begX) - 1, j, wall)
+        m.setMaze(getInt(&m.endX) + 1, j, wall)
+    }
+    var scores []openingScore
+    unsolved := m.Snapshot()
+    for i := 0; i < m.width; i++ {
+        for j := 0; j < m.width; j++ {
+            x, y := 2*(i + 1), 2*(j + 1)
+            m.createOpenings(&x, &y)
+            m.solveMaze(&x, &y)
+            scores = append(scores, openingScore{m.solvedLength(), getInt(&m.turnCnt), m.solvedJunctions(), 2*(i + 1), 2*(j + 1)})
+            m.Restore(unsolved)
+        }
+    }
+    var x, y int
+    m.searchBestOpenings(&x, &y)                // which leaves x, y at the entrance, the openings in begY and endY
+    return scores, getInt(&m.begY), getInt(&m.endY)
+}
+
+// checkTieBreak checks that the search chose start, finish, that it was a tie of want pairs on length and turns, and
+// that ranking the pairs in reverse order picks the same
+func checkTieBreak(t *testing.T, m *Maze, want, start, finish int) {
+    t.Helper()
+    scores, begY, endY := searchOpenings(m)
+    if begY != start || endY != finish {
+        t.Errorf("openings at columns %d and %d, want %d and %d", begY, endY, start, finish)
+    }
+    best := openingScore{}
+    for _, s := range scores {
+        if betterOpening(s, best) {; best = s; }
+    }
+    ties := 0
+    for _, s := range scores {
+        ties += bool2int(s.pathLen == best.pathLen && s.turns == best.turns)
+    }
+    if ties != want {
+        t.Errorf("%d pairs of openings tie on length and turns, want %d", ties, want)
+    }
+    slices.Reverse(scores)
+    reversed := openingScore{}
+    for _, s := range scores {
+        if betterOpening(s, reversed) {; reversed = s; }
+    }
+    if reversed != best {
+        t.Errorf("pairs ranked in reverse order pick %+v, in order %+v", reversed, best)
+    }
+}
+
+// TestOpeningTieJunctions is a maze where the pair the search reaches first, entering at column 14, has one junction
+// fewer along its solution than the pair entering at 18
+func TestOpeningTieJunctions(t *testing.T) {
+    checkTieBreak(t, goldenMaze(t, WithSize(19, 10), WithSeed(9)), 2, 18, 28)
+}
+
+// TestOpeningTieColumns is a corridor three cells long, whose ends tie on everything but the columns of the openings
+func TestOpeningTieColumns(t *testing.T) {
+    m := openRoom(t, 1, 3)
+    m.configure()
+    m.installMaze(m.loaded)
+    checkTieBreak(t, m, 2, 2, 6)
+}
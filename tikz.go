@@ -0,0 +1,90 @@
+/* tikz.go - LaTeX drawings of the maze
+ *
+ * Writes the maze as a standalone LaTeX document drawing it with TikZ, for papers and worksheets.  Each straight
+ * run of wall is a single \draw, so even the widest maze compiles quickly, and the solution, if it's included,
+ * is drawn over them in red a few dozen turns to a \draw.  The coordinates are maze array locations, the picture's
+ * axes scaled to half a cell, with y running down the page as the rows do.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// Defaults and limits of the tikz format's options
+const (
+    DefaultTikZScale     = 0.5          // centimetres from one cell to the next
+    DefaultTikZLineWidth = 0.8          // points
+    MaxTikZScale         = 10
+    MaxTikZLineWidth     = 10
+    tikzPathTurns        = 32           // the most turns of the solution drawn by one \draw
+)
+
+// TikZOptions chooses how RenderTikZ draws the maze
+type TikZOptions struct {
+    Scale     float64               // centimetres from one cell to the next, 0 for DefaultTikZScale
+    LineWidth float64               // points, 0 for DefaultTikZLineWidth
+    Solution  bool                  // draw the solution Solve returns in red
+}
+
+// Check returns an error if the options are out of range
+func (o TikZOptions) Check() error {
+    switch {
+        case !(o.Scale >= 0 && o.Scale <= MaxTikZScale):                    // not a number either
+            return fmt.Errorf("tikz scale %g is outside 0 to %d centimetres", o.Scale, MaxTikZScale)
+        case !(o.LineWidth >= 0 && o.LineWidth <= MaxTikZLineWidth):
+            return fmt.Errorf("tikz line width %g is outside 0 to %d points", o.LineWidth, MaxTikZLineWidth)
+    }
+    return nil
+}
+
+// tikzPoint returns the tikz coordinate of maze array location p
+func tikzPoint(p point) string {
+    return fmt.Sprintf("(%d,%d)", p.y - 1, p.x - 1)
+}
+
+// RenderTikZ writes the maze to w as a LaTeX document, returning an error if the options are out of range or the first
+// error from w.  The document needs only the standalone class and tikz.
+func (m *Maze) RenderTikZ(w io.Writer, opts TikZOptions) error {
+    if err := opts.Check(); err != nil {
+        return err
+    }
+    m = m.widened()
+
+    scale, width := opts.Scale, opts.LineWidth
+    if scale == 0 {
+        scale = DefaultTikZScale
+    }
+    if width == 0 {
+        width = DefaultTikZLineWidth
+    }
+    ew := &errWriter{w: w}
+    fmt.Fprintf(ew, "\\documentclass[tikz,border=2mm]{standalone}\n\\begin{document}\n")
+    num := func(v float64) string {; return strconv.FormatFloat(v, 'f', -1, 64); }     // tikz has no exponents
+    fmt.Fprintf(ew, "\\begin{tikzpicture}[x=%scm,y=-%scm,line width=%spt,line cap=rect]\n", num(scale/2), num(scale/2), num(width))
+    m.wallRuns(func(from, to point) {
+        fmt.Fprintf(ew, "\\draw %s -- %s;\n", tikzPoint(from), tikzPoint(to))
+    })
+    if opts.Solution {
+        route := m.getSolution()
+        if len(route) == 0 {
+            route = m.solutionRoute()
+        }
+        line := m.solutionLine(route)
+        if len(line) > 0 {
+            fmt.Fprintf(ew, "\\definecolor{solution}{RGB}{208,32,32}\n")
+        }
+        for k := 0; k < len(line) - 1; k += tikzPathTurns {    // each draw starting where the one before ended
+            points := make([]string, 0, tikzPathTurns + 1)
+            for _, p := range line[k:min(k + tikzPathTurns + 1, len(line))] {
+                points = append(points, tikzPoint(p))
+            }
+            fmt.Fprintf(ew, "\\draw[solution,line cap=round,line join=round] %s;\n", strings.Join(points, " -- "))
+        }
+    }
+    fmt.Fprintf(ew, "\\end{tikzpicture}\n\\end{document}\n")
+    return ew.err
+}
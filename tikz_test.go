@@ -0,0 +1,55 @@
+/* tikz_test.go - TikZ export tests
+ *
+ * Writes small mazes from fixed seeds as TikZ and compares them with their golden files, with and without the
+ * solution, and checks that collinear walls are merged so that no two draws continue each other.
+ */
+package maze
+
+import (
+    "bytes"
+    "regexp"
+    "testing"
+)
+
+// renderTikZ returns the maze written as TikZ with the options
+func renderTikZ(t *testing.T, m *Maze, opts TikZOptions) []byte {
+    t.Helper()
+    var b bytes.Buffer
+    if err := m.RenderTikZ(&b, opts); err != nil {
+        t.Fatal(err)
+    }
+    return b.Bytes()
+}
+
+func TestGoldenTikZ(t *testing.T) {
+    m := goldenMaze(t, WithSize(12, 8), WithSeed(42), WithDepth(5))
+    checkGolden(t, "12x8-seed42-depth5.tex", renderTikZ(t, m, TikZOptions{}))
+    checkGolden(t, "12x8-seed42-depth5-solved.tex", renderTikZ(t, m, TikZOptions{Scale: 0.4, LineWidth: 1.2, Solution: true}))
+}
+
+var tikzWall = regexp.MustCompile(`\\draw \(([-0-9.]+),([-0-9.]+)\) -- \(([-0-9.]+),([-0-9.]+)\);`)
+
+func TestTikZMergesWalls(t *testing.T) {
+    tex := renderTikZ(t, goldenMaze(t, WithSize(19, 10), WithSeed(1)), TikZOptions{})
+    walls := tikzWall.FindAllSubmatch(tex, -1)
+    if len(walls) == 0 {
+        t.Fatalf("no walls drawn:\n%s", tex)
+    }
+    ends := map[[3]string]bool{}                // the end of each draw, by its direction
+    for _, w := range walls {
+        dir := "h"
+        if string(w[1]) == string(w[3]) {
+            dir = "v"
+        }
+        ends[[3]string{dir, string(w[3]), string(w[4])}] = true
+    }
+    for _, w := range walls {
+        dir := "h"
+        if string(w[1]) == string(w[3]) {
+            dir = "v"
+        }
+        if ends[[3]string{dir, string(w[1]), string(w[2])}] {
+            t.Errorf("wall %s continues another, it should have been merged", w[0])
+        }
+    }
+}
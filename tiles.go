@@ -0,0 +1,118 @@
+/* tiles.go - Tile based PNG images of the maze
+ *
+ * Draws the maze as a png image a tile for each location of the maze array, for themed mazes of hedges or
+ * dungeon bricks.  Each wall location is drawn with the tile of its shape, the walls it joins being found
+ * as for the terminal's line drawing characters, and every path with the tile joining nothing.  The tiles
+ * are taken from a spritesheet of 16 square tiles laid out 4 by 4, tile n at column n%4 and row n/4 where n
+ * has a bit for each wall joined: 1 up, 2 right, 4 down and 8 left.  Without a sheet plain tiles are drawn.
+ */
+package maze
+
+import (
+    "io"
+    "fmt"
+    "image"
+    "image/png"
+    "image/draw"
+    "image/color"
+)
+
+// Limits on the tiles format's options, in pixels
+const (
+    DefaultTileSize = 16
+    MinTileSize     = 2
+    MaxTileSize     = 256
+)
+
+// tileSheetSide is the number of tiles across and down a spritesheet
+const tileSheetSide = 4
+
+// TileOptions chooses how RenderTiles draws the maze
+type TileOptions struct {
+    Sheet    image.Image            // the spritesheet of 4 by 4 square tiles, nil for the plain tiles
+    TileSize int                    // pixels across the plain tiles, 0 for DefaultTileSize, unused with a sheet
+}
+
+// Check returns an error if the sheet isn't 4 by 4 square tiles or the tiles are too small or too big
+func (o TileOptions) Check() error {
+    if o.Sheet == nil {
+        if o.TileSize != 0 && (o.TileSize < MinTileSize || o.TileSize > MaxTileSize) {
+            return fmt.Errorf("tile size %d is outside %d to %d pixels", o.TileSize, MinTileSize, MaxTileSize)
+        }
+        return nil
+    }
+    b := o.Sheet.Bounds()
+    switch {
+        case b.Dx() != b.Dy() || b.Dx() % tileSheetSide != 0:
+            return fmt.Errorf("tile sheet is %dx%d pixels, expected a square of 4x4 tiles", b.Dx(), b.Dy())
+        case b.Dx()/tileSheetSide < MinTileSize || b.Dx()/tileSheetSide > MaxTileSize:
+            return fmt.Errorf("tile sheet's tiles are %d pixels, outside %d to %d pixels", b.Dx()/tileSheetSide, MinTileSize, MaxTileSize)
+    }
+    return nil
+}
+
+// ReadTileSheet reads a png spritesheet for TileOptions, returning an error if it isn't 4 by 4 square tiles
+func ReadTileSheet(r io.Reader) (image.Image, error) {
+    sheet, err := png.Decode(r)
+    if err != nil {
+        return nil, err
+    }
+    return sheet, TileOptions{Sheet: sheet}.Check()
+}
+
+// plainTiles returns a sheet of tiles size pixels across drawing the walls as black bars half a tile thick on white,
+// out from the middle of the tile to each side with a wall joined
+func plainTiles(size int) image.Image {
+    sheet := image.NewPaletted(image.Rect(0, 0, tileSheetSide*size, tileSheetSide*size), color.Palette{color.White, color.Black})
+    bar   := max(1, size/2)
+    lo    := (size - bar)/2
+    hi    := lo + bar
+    fill  := func(tx, ty, x0, y0, x1, y1 int) {
+        draw.Draw(sheet, image.Rect(tx + x0, ty + y0, tx + x1, ty + y1), image.Black, image.Point{}, draw.Src)
+    }
+    for n := 1; n < tileSheetSide*tileSheetSide; n++ {
+        tx, ty := n % tileSheetSide * size, n / tileSheetSide * size
+        fill(tx, ty, lo, lo, hi, hi)
+        if n & 1 != 0 {; fill(tx, ty, lo, 0 , hi  , lo  ); }
+        if n & 2 != 0 {; fill(tx, ty, hi, lo, size, hi  ); }
+        if n & 4 != 0 {; fill(tx, ty, lo, hi, hi  , size); }
+        if n & 8 != 0 {; fill(tx, ty, 0 , lo, lo  , hi  ); }
+    }
+    return sheet
+}
+
+// RenderTiles writes the maze to w as a png image of tiles, (2*width + 1) by (2*height + 1) tiles of the maze as
+// written, with its corridors widened, returning an error if the options are out of range or the first error from w.
+// The solution and the tried cells aren't drawn.
+func (m *Maze) RenderTiles(w io.Writer, opts TileOptions) error {
+    if err := opts.Check(); err != nil {
+        return err
+    }
+    m = m.widened()
+
+    sheet, size := opts.Sheet, opts.TileSize
+    if size == 0 {
+        size = DefaultTileSize
+    }
+    if sheet == nil {
+        sheet = plainTiles(size)
+    } else {
+        size = sheet.Bounds().Dx()/tileSheetSide
+    }
+    rows, cols := getInt(&m.maxX) - 2, getInt(&m.maxY) - 2     // the locations inside the perimeter path
+    isWall     := func(c int) bool {; return c == wall; }
+    origin     := sheet.Bounds().Min
+
+    img := image.NewNRGBA(image.Rect(0, 0, cols*size, rows*size))
+    for i := 1; i <= rows; i++ {
+        for j := 1; j <= cols; j++ {
+            n := 0
+            if isDrawnWall(m.shownCell, i, j) {
+                n = wallShape(m.shownCell, isWall, i, j)
+            }
+            from := origin.Add(image.Pt(n % tileSheetSide * size, n / tileSheetSide * size))
+            draw.Draw(img, image.Rect((j - 1)*size, (i - 1)*size, j*size, i*size), sheet, from, draw.Src)
+        }
+    }
+    return png.Encode(w, img)
+}
@@ -0,0 +1,57 @@
+/* vector.go - Lines through the maze
+ *
+ * The vector formats draw the maze as lines rather than as locations: the walls as one line for each straight
+ * run of wall along a grid line, and the solution as a line through the turns of its route.  The ends of the
+ * lines are maze array locations, the grid lines being at the odd locations and the middles of the cells at
+ * the even ones, so each format only has to scale them.
+ */
+package maze
+
+// wallRuns calls line with the ends of each straight run of wall, the runs along the horizontal grid lines first
+// and then those along the vertical ones, each from its top or left end
+func (m *Maze) wallRuns(line func(from, to point)) {
+    run := func(isWall func(k int) bool, n int, ends func(lo, hi int)) {    // merges the wall locations along a grid line
+        for k := 2; k <= n; k += 2 {
+            if !isWall(k) {
+                continue
+            }
+            lo := k
+            for k + 2 <= n && isWall(k + 2) {
+                k += 2
+            }
+            ends(lo - 1, k + 1)                                             // from post to post
+        }
+    }
+    for i := 1; i < getInt(&m.maxX) - 1; i += 2 {
+        run(func(j int) bool {; return m.getMaze(i, j) == wall; }, 2*m.width,
+            func(lo, hi int) {; line(point{i, lo}, point{i, hi}); })
+    }
+    for j := 1; j < getInt(&m.maxY) - 1; j += 2 {
+        run(func(i int) bool {; return m.getMaze(i, j) == wall; }, 2*m.height,
+            func(lo, hi int) {; line(point{lo, j}, point{hi, j}); })
+    }
+}
+
+// solutionLine returns the turns along a route of solved locations, starting just outside the entrance and, if the
+// route reaches the exit, ending just outside that.  It returns nil for a route too short to draw.
+func (m *Maze) solutionLine(route []point) []point {
+    if len(route) < 2 {
+        return nil
+    }
+    line := []point{{route[0].x - 1, route[0].y}}
+    for i, p := range route {
+        prev := line[0]
+        if i > 0 {
+            prev = route[i-1]
+        }
+        if i < len(route) - 1 && (prev.x == p.x) == (route[i+1].x == p.x) && (prev.y == p.y) == (route[i+1].y == p.y) {
+            continue                                                        // no turn here
+        }
+        line = append(line, p)
+    }
+    if m.routeComplete(route) {
+        end := route[len(route) - 1]
+        line = append(line, point{end.x + 1, end.y})
+    }
+    return line
+}